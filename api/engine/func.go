@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
+	"hideout/internal/common/rqrs"
+	"hideout/services/secrets"
+	"net/http"
+)
+
+// ValidateRequired Reports body parameter left empty
+func ValidateRequired(Localizer *i18n.Localizer, name string, value string) (Errors []rqrs.Error) {
+	if value == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": name}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	return Errors
+}
+
+// ValidateFolderUID Reports body parameter referring to a folder which does not exist, empty one is only reported when
+// it is required
+func ValidateFolderUID(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer, name string, folderUID string, required bool) (Errors []rqrs.Error) {
+	if folderUID == "" {
+		if required {
+			Errors = append(Errors, ValidateRequired(Localizer, name, folderUID)...)
+		}
+		return Errors
+	}
+
+	_, errGetFolderByUID := secretsService.GetFolderByUID(ctx, folderUID)
+	if errGetFolderByUID != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetFolderByUIDError"},
+			TemplateData: map[string]interface{}{"UID": folderUID}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errGetFolderByUID.Error(), Code: 0})
+	}
+	return Errors
+}
+
+// Status HTTP status matching the error returned by engine service, the first status listing the error wins. Missing
+// records are reported as not found by every engine, unknown errors are internal ones
+func Status(err error, statuses []ErrorStatus) int {
+	if errors.Is(err, apperror.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	for _, status := range statuses {
+		for _, statusErr := range status.Errors {
+			if errors.Is(err, statusErr) {
+				return status.Status
+			}
+		}
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,9 @@
+package engine
+
+type (
+	// ErrorStatus HTTP status reported for the errors returned by engine service
+	ErrorStatus struct {
+		Status int
+		Errors []error
+	}
+)
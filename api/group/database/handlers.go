@@ -0,0 +1,293 @@
+package database
+
+import (
+	"context"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	apiconfig "hideout/cmd/api/config"
+	"hideout/internal/common/rqrs"
+	"hideout/internal/pkg/encryption"
+	"hideout/services/database"
+	"hideout/services/secrets"
+	"hideout/structs"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ConfigureHandler
+// @Summary Configure database connection
+// @Description Create or replace administrative Postgres connection of the folder, connection URL is stored encrypted
+// @ID configure-database
+// @Tags Database
+// @Produce json
+// @Param params body ConfigureRQ true "Database connection configuration request"
+// @Success 200 {object} ConfigureRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} ConfigureRS
+// @Failure 404 {object} ConfigureRS
+// @Failure 500 {object} ConfigureRS
+// @Failure 502 {object} ConfigureRS
+// @Router /database/config/ [put]
+func ConfigureHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.configure.database")
+	validationSpan.Description = "rq.validate"
+
+	var request ConfigureRQ
+	response := ConfigureRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "configure.database")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	databaseSvc := database.NewService(secretsSvc, apiconfig.Leases, encryptionKey)
+	errConfigure := databaseSvc.Configure(rqContext, Localizer, database.ConfigureParams{FolderUID: request.FolderUID,
+		ConnectionURL: request.ConnectionURL, Verify: request.Verify})
+	if errConfigure != nil {
+		log.Printf("Error configuring database connection in folder with UID of %s: %s", request.FolderUID, errConfigure.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ConfigureDatabaseError"},
+			TemplateData: map[string]interface{}{"UID": request.FolderUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errConfigure.Error(), Code: 0})
+		c.JSON(engine.Status(errConfigure, errorStatuses), response)
+		return
+	}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// SaveRoleHandler
+// @Summary Save database role
+// @Description Create or replace role with statements creating, renewing and dropping database users
+// @ID save-database-role
+// @Tags Database
+// @Produce json
+// @Param params body SaveRoleRQ true "Database role save request"
+// @Success 200 {object} SaveRoleRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} SaveRoleRS
+// @Failure 404 {object} SaveRoleRS
+// @Failure 500 {object} SaveRoleRS
+// @Router /database/roles/ [put]
+func SaveRoleHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.save.database.role")
+	validationSpan.Description = "rq.validate"
+
+	var request SaveRoleRQ
+	response := SaveRoleRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "save.database.role")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	databaseSvc := database.NewService(secretsSvc, apiconfig.Leases, encryptionKey)
+	role, errSaveRole := databaseSvc.SaveRole(rqContext, Localizer, request.FolderUID, database.Role{Name: request.Role.Name,
+		CreationStatements: request.Role.CreationStatements, RevocationStatements: request.Role.RevocationStatements,
+		RenewStatements: request.Role.RenewStatements, TTL: time.Duration(request.Role.TTL) * time.Second,
+		MaxTTL: time.Duration(request.Role.MaxTTL) * time.Second})
+	if errSaveRole != nil {
+		log.Printf("Error saving database role %s: %s", request.Role.Name, errSaveRole.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SaveDatabaseRoleError"},
+			TemplateData: map[string]interface{}{"Name": request.Role.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errSaveRole.Error(), Code: 0})
+		c.JSON(engine.Status(errSaveRole, errorStatuses), response)
+		return
+	}
+	response.Data = &Role{Name: role.Name, CreationStatements: role.CreationStatements, RevocationStatements: role.RevocationStatements,
+		RenewStatements: role.RenewStatements, TTL: uint(role.TTL.Seconds()), MaxTTL: uint(role.MaxTTL.Seconds())}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCredentialsHandler
+// @Summary Get database credentials
+// @Description Create database user of the role, user is dropped once the lease expires or is revoked
+// @ID get-database-credentials
+// @Tags Database
+// @Produce json
+// @Param params body GetCredentialsRQ true "Database credentials request"
+// @Success 200 {object} GetCredentialsRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} GetCredentialsRS
+// @Failure 404 {object} GetCredentialsRS
+// @Failure 500 {object} GetCredentialsRS
+// @Failure 502 {object} GetCredentialsRS
+// @Router /database/creds/ [post]
+func GetCredentialsHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.get.database.credentials")
+	validationSpan.Description = "rq.validate"
+
+	var request GetCredentialsRQ
+	response := GetCredentialsRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "get.database.credentials")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	databaseSvc := database.NewService(secretsSvc, apiconfig.Leases, encryptionKey)
+	now := time.Now()
+	credentials, errCredentials := databaseSvc.Credentials(rqContext, Localizer, database.CredentialsParams{FolderUID: request.FolderUID,
+		Role: request.Role, TTL: time.Duration(request.TTL) * time.Second}, now)
+	if errCredentials != nil {
+		log.Printf("Error creating database credentials for role %s: %s", request.Role, errCredentials.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateDatabaseCredentialsError"},
+			TemplateData: map[string]interface{}{"Name": request.Role}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCredentials.Error(), Code: 0})
+		c.JSON(engine.Status(errCredentials, errorStatuses), response)
+		return
+	}
+	response.Data = &Credentials{LeaseID: credentials.Lease.LeaseID, LeaseDuration: uint(credentials.Lease.TTL(now).Seconds()),
+		Renewable: credentials.Lease.CanRenew(now), ExpiresAt: credentials.Lease.ExpiresAt, Username: credentials.Username,
+		Password: credentials.Password}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	"hideout/internal/common/rqrs"
+	"hideout/services/secrets"
+)
+
+func (rq ConfigureRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+
+	if rq.ConnectionURL == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "ConnectionURL"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq SaveRoleRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+
+	if rq.Role.Name == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "Role.Name"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	if len(rq.Role.CreationStatements) == 0 {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "Role.CreationStatements"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq GetCredentialsRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+
+	if rq.Role == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "Role"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
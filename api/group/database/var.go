@@ -0,0 +1,16 @@
+package database
+
+import (
+	"hideout/api/engine"
+	"hideout/services/database"
+	"net/http"
+)
+
+var (
+	// errorStatuses HTTP statuses matching the errors returned by database service
+	errorStatuses = []engine.ErrorStatus{
+		{Status: http.StatusNotFound, Errors: []error{database.ErrNotConfigured, database.ErrRoleNotFound}},
+		{Status: http.StatusBadRequest, Errors: []error{database.ErrInvalidConnectionURL, database.ErrInvalidRoleName, database.ErrNoCreationStatements}},
+		{Status: http.StatusBadGateway, Errors: []error{database.ErrStatementExecution}},
+	}
+)
@@ -0,0 +1,20 @@
+package leases
+
+import (
+	leases2 "hideout/internal/leases"
+	"time"
+)
+
+func mapLease(lease leases2.Lease, now time.Time) Lease {
+	return Lease{LeaseID: lease.LeaseID, Engine: lease.Engine, FolderUID: lease.FolderUID, Path: lease.Path, IssuedAt: lease.IssuedAt,
+		ExpiresAt: lease.ExpiresAt, MaxExpiresAt: lease.MaxExpiresAt, LeaseDuration: uint(lease.TTL(now).Seconds()),
+		Renewable: lease.CanRenew(now)}
+}
+
+func mapLeases(leasesList []*leases2.Lease, now time.Time) []Lease {
+	results := []Lease{}
+	for _, lease := range leasesList {
+		results = append(results, mapLease(*lease, now))
+	}
+	return results
+}
@@ -0,0 +1,421 @@
+package leases
+
+import (
+	"context"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	apiconfig "hideout/cmd/api/config"
+	"hideout/internal/common/rqrs"
+	"hideout/services/secrets"
+	"hideout/structs"
+	"log"
+	"net/http"
+	"time"
+)
+
+// GetLeasesHandler
+// @Summary List leases
+// @Description List leases which identifiers start with the prefix
+// @ID get-leases
+// @Tags Leases
+// @Produce json
+// @Param params body GetLeasesRQ true "List leases request"
+// @Success 200 {object} GetLeasesRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} GetLeasesRS
+// @Failure 500 {object} GetLeasesRS
+// @Router /leases/ [post]
+func GetLeasesHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.get.leases")
+	validationSpan.Description = "rq.validate"
+
+	var request GetLeasesRQ
+	response := GetLeasesRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "get.leases")
+	runSpan.Description = "run"
+
+	leasesList, errGetLeases := apiconfig.Leases.List(rqContext, request.Prefix)
+	if errGetLeases != nil {
+		log.Printf("Error retrieving leases with prefix %s: %s", request.Prefix, errGetLeases.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetLeasesError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetLeases.Error(), Code: 0})
+		c.JSON(engine.Status(errGetLeases, errorStatuses), response)
+		return
+	}
+	response.Data = mapLeases(leasesList, time.Now())
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// LookupLeaseHandler
+// @Summary Look up lease
+// @Description Get lease by its identifier
+// @ID lookup-lease
+// @Tags Leases
+// @Produce json
+// @Param params body LookupLeaseRQ true "Look up lease request"
+// @Success 200 {object} LookupLeaseRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} LookupLeaseRS
+// @Failure 404 {object} LookupLeaseRS
+// @Failure 500 {object} LookupLeaseRS
+// @Router /leases/lookup/ [post]
+func LookupLeaseHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.lookup.lease")
+	validationSpan.Description = "rq.validate"
+
+	var request LookupLeaseRQ
+	response := LookupLeaseRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "lookup.lease")
+	runSpan.Description = "run"
+
+	lease, errGetLease := apiconfig.Leases.Get(rqContext, request.LeaseID)
+	if errGetLease != nil {
+		log.Printf("Error retrieving lease %s: %s", request.LeaseID, errGetLease.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetLeaseError"},
+			TemplateData: map[string]interface{}{"ID": request.LeaseID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetLease.Error(), Code: 0})
+		c.JSON(engine.Status(errGetLease, errorStatuses), response)
+		return
+	}
+	leaseData := mapLease(*lease, time.Now())
+	response.Data = &leaseData
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// RenewLeaseHandler
+// @Summary Renew lease
+// @Description Extend lease by the increment, lease cannot be extended past its maximal lifetime
+// @ID renew-lease
+// @Tags Leases
+// @Produce json
+// @Param params body RenewLeaseRQ true "Renew lease request"
+// @Success 200 {object} RenewLeaseRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} RenewLeaseRS
+// @Failure 404 {object} RenewLeaseRS
+// @Failure 409 {object} RenewLeaseRS
+// @Failure 500 {object} RenewLeaseRS
+// @Failure 502 {object} RenewLeaseRS
+// @Router /leases/renew/ [post]
+func RenewLeaseHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.renew.lease")
+	validationSpan.Description = "rq.validate"
+
+	var request RenewLeaseRQ
+	response := RenewLeaseRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "renew.lease")
+	runSpan.Description = "run"
+
+	now := time.Now()
+	lease, errRenew := apiconfig.Leases.Renew(rqContext, Localizer, request.LeaseID, time.Duration(request.Increment)*time.Second, now)
+	if errRenew != nil {
+		log.Printf("Error renewing lease %s: %s", request.LeaseID, errRenew.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RenewLeaseError"},
+			TemplateData: map[string]interface{}{"ID": request.LeaseID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errRenew.Error(), Code: 0})
+		c.JSON(engine.Status(errRenew, errorStatuses), response)
+		return
+	}
+	leaseData := mapLease(*lease, now)
+	response.Data = &leaseData
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeLeaseHandler
+// @Summary Revoke lease
+// @Description Revoke lease immediately, secret handed out with it is invalidated by its engine
+// @ID revoke-lease
+// @Tags Leases
+// @Produce json
+// @Param params body RevokeLeaseRQ true "Revoke lease request"
+// @Success 200 {object} RevokeLeaseRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} RevokeLeaseRS
+// @Failure 404 {object} RevokeLeaseRS
+// @Failure 500 {object} RevokeLeaseRS
+// @Failure 502 {object} RevokeLeaseRS
+// @Router /leases/revoke/ [post]
+func RevokeLeaseHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.revoke.lease")
+	validationSpan.Description = "rq.validate"
+
+	var request RevokeLeaseRQ
+	response := RevokeLeaseRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "revoke.lease")
+	runSpan.Description = "run"
+
+	lease, errRevoke := apiconfig.Leases.Revoke(rqContext, request.LeaseID)
+	if errRevoke != nil {
+		log.Printf("Error revoking lease %s: %s", request.LeaseID, errRevoke.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RevokeLeaseError"},
+			TemplateData: map[string]interface{}{"ID": request.LeaseID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errRevoke.Error(), Code: 0})
+		c.JSON(engine.Status(errRevoke, errorStatuses), response)
+		return
+	}
+	leaseData := mapLease(*lease, time.Now())
+	leaseData.LeaseDuration, leaseData.Renewable = 0, false
+	response.Data = &leaseData
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokePrefixHandler
+// @Summary Revoke leases by prefix
+// @Description Revoke all leases which identifiers start with the prefix, revoked leases are returned even if some failed to be revoked
+// @ID revoke-leases-prefix
+// @Tags Leases
+// @Produce json
+// @Param params body RevokePrefixRQ true "Revoke leases by prefix request"
+// @Success 200 {object} RevokePrefixRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} RevokePrefixRS
+// @Failure 500 {object} RevokePrefixRS
+// @Failure 502 {object} RevokePrefixRS
+// @Router /leases/revoke-prefix/ [post]
+func RevokePrefixHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.revoke.leases.prefix")
+	validationSpan.Description = "rq.validate"
+
+	var request RevokePrefixRQ
+	response := RevokePrefixRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "revoke.leases.prefix")
+	runSpan.Description = "run"
+
+	leasesList, errRevoke := apiconfig.Leases.RevokePrefix(rqContext, request.Prefix)
+	response.Data = mapLeases(leasesList, time.Now())
+	for i := range response.Data {
+		response.Data[i].LeaseDuration, response.Data[i].Renewable = 0, false
+	}
+	if errRevoke != nil {
+		log.Printf("Error revoking leases with prefix %s: %s", request.Prefix, errRevoke.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RevokeLeasesPrefixError"},
+			TemplateData: map[string]interface{}{"Prefix": request.Prefix}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errRevoke.Error(), Code: 0})
+		c.JSON(engine.Status(errRevoke, errorStatuses), response)
+		return
+	}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,66 @@
+package leases
+
+import (
+	"hideout/internal/common/rqrs"
+	"time"
+)
+
+type (
+	Lease struct {
+		LeaseID       string    `json:"LeaseID" description:"Lease identifier" example:"database/abc-def-ghi/readonly/5f1d3c0a9b8e7d6c5b4a3f2e"`
+		Engine        string    `json:"Engine" description:"Engine the lease was handed out by" example:"database"`
+		FolderUID     string    `json:"FolderUID" description:"Folder of the engine" example:"abc-def-ghi"`
+		Path          string    `json:"Path" description:"Engine-specific path (e.g. role name)" example:"readonly"`
+		IssuedAt      time.Time `json:"IssuedAt" description:"Lease creation date" example:"2030-01-01T00:00:00Z"`
+		ExpiresAt     time.Time `json:"ExpiresAt" description:"Date the lease is revoked at unless renewed" example:"2030-01-01T01:00:00Z"`
+		MaxExpiresAt  time.Time `json:"MaxExpiresAt" description:"Date past which the lease cannot be renewed" example:"2030-01-02T00:00:00Z"`
+		LeaseDuration uint      `json:"LeaseDuration" description:"Seconds left until the lease expires" example:"3600"`
+		Renewable     bool      `json:"Renewable" description:"Whether lease can still be extended" example:"true"`
+	}
+
+	GetLeasesRQ struct {
+		Prefix string `json:"Prefix" description:"Lease identifier prefix, all leases are returned if empty" example:"database/abc-def-ghi/"`
+	}
+
+	GetLeasesRS struct {
+		Data []Lease `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	LookupLeaseRQ struct {
+		LeaseID string `json:"LeaseID" description:"Lease identifier" example:"database/abc-def-ghi/readonly/5f1d3c0a9b8e7d6c5b4a3f2e"`
+	}
+
+	LookupLeaseRS struct {
+		Data *Lease `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	RenewLeaseRQ struct {
+		LeaseID   string `json:"LeaseID" description:"Lease identifier" example:"database/abc-def-ghi/readonly/5f1d3c0a9b8e7d6c5b4a3f2e"`
+		Increment uint   `json:"Increment" description:"Seconds to extend the lease by from now, lease increment by default" example:"3600"`
+	}
+
+	RenewLeaseRS struct {
+		Data *Lease `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	RevokeLeaseRQ struct {
+		LeaseID string `json:"LeaseID" description:"Lease identifier" example:"database/abc-def-ghi/readonly/5f1d3c0a9b8e7d6c5b4a3f2e"`
+	}
+
+	RevokeLeaseRS struct {
+		Data *Lease `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	RevokePrefixRQ struct {
+		Prefix string `json:"Prefix" description:"Lease identifier prefix" example:"database/abc-def-ghi/readonly/"`
+	}
+
+	RevokePrefixRS struct {
+		Data []Lease `json:"Data"`
+		rqrs.ResponseRS
+	}
+)
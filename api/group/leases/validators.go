@@ -0,0 +1,29 @@
+package leases
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	"hideout/internal/common/rqrs"
+	"hideout/services/secrets"
+)
+
+func (rq GetLeasesRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	return Errors
+}
+
+func (rq LookupLeaseRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	return engine.ValidateRequired(Localizer, "LeaseID", rq.LeaseID)
+}
+
+func (rq RenewLeaseRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	return engine.ValidateRequired(Localizer, "LeaseID", rq.LeaseID)
+}
+
+func (rq RevokeLeaseRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	return engine.ValidateRequired(Localizer, "LeaseID", rq.LeaseID)
+}
+
+func (rq RevokePrefixRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	return engine.ValidateRequired(Localizer, "Prefix", rq.Prefix)
+}
@@ -0,0 +1,18 @@
+package leases
+
+import (
+	"hideout/api/engine"
+	"hideout/services/database"
+	"hideout/services/leases"
+	"net/http"
+)
+
+var (
+	// errorStatuses HTTP statuses matching the errors returned by lease service
+	errorStatuses = []engine.ErrorStatus{
+		{Status: http.StatusNotFound, Errors: []error{leases.ErrLeaseNotFound}},
+		{Status: http.StatusConflict, Errors: []error{leases.ErrLeaseExpired, leases.ErrNotRenewable, leases.ErrMaxTTLReached}},
+		{Status: http.StatusBadRequest, Errors: []error{leases.ErrInvalidLeaseID, leases.ErrInvalidPrefix}},
+		{Status: http.StatusBadGateway, Errors: []error{database.ErrStatementExecution}},
+	}
+)
@@ -0,0 +1,6 @@
+package pki
+
+const (
+	CRLFormat_PEM = "pem"
+	CRLFormat_DER = "der"
+)
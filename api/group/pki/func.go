@@ -0,0 +1,25 @@
+package pki
+
+import (
+	"hideout/services/pki"
+	"time"
+)
+
+func toServiceRole(role Role) pki.Role {
+	return pki.Role{Name: role.Name, AllowedDomains: role.AllowedDomains, AllowBareDomains: role.AllowBareDomains,
+		AllowSubdomains: role.AllowSubdomains, AllowIPSANs: role.AllowIPSANs, KeyType: role.KeyType, KeyBits: role.KeyBits,
+		TTL: time.Duration(role.TTL) * time.Second, MaxTTL: time.Duration(role.MaxTTL) * time.Second,
+		ServerFlag: role.ServerFlag, ClientFlag: role.ClientFlag}
+}
+
+func fromServiceRole(role pki.Role) *Role {
+	return &Role{Name: role.Name, AllowedDomains: role.AllowedDomains, AllowBareDomains: role.AllowBareDomains,
+		AllowSubdomains: role.AllowSubdomains, AllowIPSANs: role.AllowIPSANs, KeyType: role.KeyType, KeyBits: role.KeyBits,
+		TTL: uint(role.TTL / time.Second), MaxTTL: uint(role.MaxTTL / time.Second),
+		ServerFlag: role.ServerFlag, ClientFlag: role.ClientFlag}
+}
+
+func fromBundle(bundle *pki.CertificateBundle) *Certificate {
+	return &Certificate{SerialNumber: bundle.SerialNumber, Certificate: bundle.Certificate, PrivateKey: bundle.PrivateKey,
+		CAChain: bundle.CAChain, NotAfter: bundle.NotAfter}
+}
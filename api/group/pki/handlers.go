@@ -0,0 +1,489 @@
+package pki
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	apiconfig "hideout/cmd/api/config"
+	"hideout/internal/common/rqrs"
+	"hideout/internal/pkg/encryption"
+	"hideout/services/pki"
+	"hideout/services/secrets"
+	"hideout/structs"
+	"log"
+	"net/http"
+	"time"
+)
+
+// CreateCAHandler
+// @Summary Create certificate authority
+// @Description Create root or intermediate certificate authority stored as secrets in the folder
+// @ID pki-create-ca
+// @Tags PKI
+// @Produce json
+// @Param params body CreateCARQ true "Certificate authority create request"
+// @Success 200 {object} CreateCARS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} CreateCARS
+// @Failure 404 {object} CreateCARS
+// @Failure 409 {object} CreateCARS
+// @Failure 500 {object} CreateCARS
+// @Router /pki/ca/ [put]
+func CreateCAHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.create.ca")
+	validationSpan.Description = "rq.validate"
+
+	var request CreateCARQ
+	response := CreateCARS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "create.ca")
+	runSpan.Description = "run"
+
+	maxPathLen := -1
+	if request.MaxPathLen != nil {
+		maxPathLen = *request.MaxPathLen
+	}
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	pkiSvc := pki.NewService(secretsSvc, encryptionKey)
+	bundle, errCreateCA := pkiSvc.CreateCA(rqContext, Localizer, pki.CreateCAParams{
+		FolderUID: request.FolderUID, ParentFolderUID: request.ParentFolderUID, CommonName: request.CommonName,
+		Organization: request.Organization, Country: request.Country, KeyType: request.KeyType, KeyBits: request.KeyBits,
+		TTL: time.Duration(request.TTL) * time.Second, MaxPathLen: maxPathLen,
+	})
+	if errCreateCA != nil {
+		log.Printf("Error creating certificate authority in folder with UID of %s: %s", request.FolderUID, errCreateCA.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateCAError"},
+			TemplateData: map[string]interface{}{"UID": request.FolderUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateCA.Error(), Code: 0})
+		c.JSON(engine.Status(errCreateCA, errorStatuses), response)
+		return
+	}
+	response.Data = fromBundle(bundle)
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// SaveRoleHandler
+// @Summary Create or replace certificate authority role
+// @Description Create or replace role constraining certificates issued by the authority
+// @ID pki-save-role
+// @Tags PKI
+// @Produce json
+// @Param params body SaveRoleRQ true "Role save request"
+// @Success 200 {object} SaveRoleRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} SaveRoleRS
+// @Failure 404 {object} SaveRoleRS
+// @Failure 500 {object} SaveRoleRS
+// @Router /pki/roles/ [put]
+func SaveRoleHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.save.role")
+	validationSpan.Description = "rq.validate"
+
+	var request SaveRoleRQ
+	response := SaveRoleRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "save.role")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	pkiSvc := pki.NewService(secretsSvc, encryptionKey)
+	savedRole, errSaveRole := pkiSvc.SaveRole(rqContext, Localizer, request.FolderUID, toServiceRole(request.Role))
+	if errSaveRole != nil {
+		log.Printf("Error saving role %s in folder with UID of %s: %s", request.Role.Name, request.FolderUID, errSaveRole.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SaveRoleError"},
+			TemplateData: map[string]interface{}{"Name": request.Role.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errSaveRole.Error(), Code: 0})
+		c.JSON(engine.Status(errSaveRole, errorStatuses), response)
+		return
+	}
+	response.Data = fromServiceRole(*savedRole)
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// IssueCertificateHandler
+// @Summary Issue certificate
+// @Description Issue leaf certificate for signing request or generated key pair under the role constraints
+// @ID pki-issue-certificate
+// @Tags PKI
+// @Produce json
+// @Param params body IssueCertificateRQ true "Certificate issue request"
+// @Success 200 {object} IssueCertificateRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} IssueCertificateRS
+// @Failure 404 {object} IssueCertificateRS
+// @Failure 500 {object} IssueCertificateRS
+// @Router /pki/issue/ [post]
+func IssueCertificateHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.issue.certificate")
+	validationSpan.Description = "rq.validate"
+
+	var request IssueCertificateRQ
+	response := IssueCertificateRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "issue.certificate")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	pkiSvc := pki.NewService(secretsSvc, encryptionKey)
+	bundle, errIssue := pkiSvc.Issue(rqContext, Localizer, pki.IssueParams{
+		FolderUID: request.FolderUID, Role: request.Role, CommonName: request.CommonName, AltNames: request.AltNames,
+		IPSANs: request.IPSANs, TTL: time.Duration(request.TTL) * time.Second, CSR: request.CSR,
+		TargetFolderUID: request.TargetFolderUID, Prefix: request.Prefix,
+	})
+	if errIssue != nil {
+		log.Printf("Error issuing certificate for %s under role %s: %s", request.CommonName, request.Role, errIssue.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "IssueCertificateError"},
+			TemplateData: map[string]interface{}{"Name": request.Role}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errIssue.Error(), Code: 0})
+		c.JSON(engine.Status(errIssue, errorStatuses), response)
+		return
+	}
+	response.Data = fromBundle(bundle)
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeCertificateHandler
+// @Summary Revoke certificate
+// @Description Revoke certificate issued by the authority, it is included in the revocation list until it expires
+// @ID pki-revoke-certificate
+// @Tags PKI
+// @Produce json
+// @Param params body RevokeCertificateRQ true "Certificate revoke request"
+// @Success 200 {object} RevokeCertificateRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} RevokeCertificateRS
+// @Failure 404 {object} RevokeCertificateRS
+// @Failure 500 {object} RevokeCertificateRS
+// @Router /pki/revoke/ [post]
+func RevokeCertificateHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.revoke.certificate")
+	validationSpan.Description = "rq.validate"
+
+	var request RevokeCertificateRQ
+	response := RevokeCertificateRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "revoke.certificate")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	pkiSvc := pki.NewService(secretsSvc, encryptionKey)
+	record, errRevoke := pkiSvc.Revoke(rqContext, Localizer, request.FolderUID, request.SerialNumber)
+	if errRevoke != nil {
+		log.Printf("Error revoking certificate with serial number %s: %s", request.SerialNumber, errRevoke.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RevokeCertificateError"},
+			TemplateData: map[string]interface{}{"SerialNumber": request.SerialNumber}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errRevoke.Error(), Code: 0})
+		c.JSON(engine.Status(errRevoke, errorStatuses), response)
+		return
+	}
+	response.Data = &CertificateRecord{SerialNumber: record.SerialNumber, CommonName: record.CommonName, Role: record.Role,
+		NotBefore: record.NotBefore, NotAfter: record.NotAfter, RevokedAt: record.RevokedAt}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCRLHandler
+// @Summary Get certificate revocation list
+// @Description Get certificate revocation list of the authority signed by its key
+// @ID pki-get-crl
+// @Tags PKI
+// @Produce application/pkix-crl
+// @Param uid path string true "Folder of the authority"
+// @Param format query string false "Encoding of the revocation list (pem or der)"
+// @Success 200 {string} string "Certificate revocation list"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} GetCRLRS
+// @Failure 404 {object} GetCRLRS
+// @Failure 500 {object} GetCRLRS
+// @Router /pki/crl/{uid} [get]
+func GetCRLHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.get.crl")
+	validationSpan.Description = "rq.validate"
+
+	var request GetCRLRQ
+	response := GetCRLRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindURI := c.ShouldBindUri(&request)
+	if errBindURI != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestURIMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindURI.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	errBindQuery := c.ShouldBindQuery(&request)
+	if errBindQuery != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestQueryMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindQuery.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "get.crl")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	pkiSvc := pki.NewService(secretsSvc, encryptionKey)
+	crlDER, errGenerateCRL := pkiSvc.CRL(rqContext, request.FolderUID)
+	if errGenerateCRL != nil {
+		log.Printf("Error generating revocation list of authority in folder with UID of %s: %s", request.FolderUID, errGenerateCRL.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GenerateCRLError"},
+			TemplateData: map[string]interface{}{"UID": request.FolderUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGenerateCRL.Error(), Code: 0})
+		c.JSON(engine.Status(errGenerateCRL, errorStatuses), response)
+		return
+	}
+
+	runSpan.Finish()
+
+	if request.Format == CRLFormat_DER {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.crl", request.FolderUID))
+		c.Data(http.StatusOK, "application/pkix-crl", crlDER)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.crl.pem", request.FolderUID))
+	c.Data(http.StatusOK, "application/x-pem-file", pem.EncodeToMemory(&pem.Block{Type: pki.PEMType_CRL, Bytes: crlDER}))
+}
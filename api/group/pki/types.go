@@ -0,0 +1,102 @@
+package pki
+
+import (
+	"hideout/internal/common/rqrs"
+	"time"
+)
+
+type (
+	Certificate struct {
+		SerialNumber string    `json:"SerialNumber" description:"Serial number (hexadecimal)" example:"3f1c0a"`
+		Certificate  string    `json:"Certificate" description:"PEM-encoded certificate"`
+		PrivateKey   string    `json:"PrivateKey,omitempty" description:"PEM-encoded private key (only when key pair was generated)"`
+		CAChain      string    `json:"CAChain" description:"PEM-encoded certificate chain of the issuing authority"`
+		NotAfter     time.Time `json:"NotAfter" description:"Certificate expiration date" example:"2030-01-01T00:00:00Z"`
+	}
+
+	CertificateRecord struct {
+		SerialNumber string     `json:"SerialNumber" description:"Serial number (hexadecimal)" example:"3f1c0a"`
+		CommonName   string     `json:"CommonName" description:"Subject common name" example:"api.example.com"`
+		Role         string     `json:"Role" description:"Role the certificate was issued under" example:"web"`
+		NotBefore    time.Time  `json:"NotBefore" description:"Certificate validity start date" example:"2030-01-01T00:00:00Z"`
+		NotAfter     time.Time  `json:"NotAfter" description:"Certificate expiration date" example:"2030-02-01T00:00:00Z"`
+		RevokedAt    *time.Time `json:"RevokedAt,omitempty" description:"Revocation date" example:"2030-01-15T00:00:00Z"`
+	}
+
+	Role struct {
+		Name             string   `json:"Name" description:"Role name" example:"web"`
+		AllowedDomains   []string `json:"AllowedDomains" description:"Allowed domains, glob patterns are supported" example:"example.com,*.example.org"`
+		AllowBareDomains bool     `json:"AllowBareDomains" description:"Allow certificates for allowed domains themselves" example:"true"`
+		AllowSubdomains  bool     `json:"AllowSubdomains" description:"Allow certificates for subdomains of allowed domains" example:"true"`
+		AllowIPSANs      bool     `json:"AllowIPSANs" description:"Allow IP subject alternative names" example:"false"`
+		KeyType          string   `json:"KeyType" enums:"any,rsa,ec,ed25519" description:"Allowed key type" example:"ec"`
+		KeyBits          int      `json:"KeyBits" description:"Minimal key size (generated key size)" example:"256"`
+		TTL              uint     `json:"TTL" description:"Default certificate lifetime in seconds" example:"2592000"`
+		MaxTTL           uint     `json:"MaxTTL" description:"Maximal certificate lifetime in seconds" example:"7776000"`
+		ServerFlag       bool     `json:"ServerFlag" description:"Certificates are usable for server authentication" example:"true"`
+		ClientFlag       bool     `json:"ClientFlag" description:"Certificates are usable for client authentication" example:"false"`
+	}
+
+	CreateCARQ struct {
+		FolderUID       string `json:"FolderUID" description:"Folder to store authority in" example:"abc-def-ghi"`
+		ParentFolderUID string `json:"ParentFolderUID" description:"Folder of the signing authority, root authority is created if empty" example:""`
+		CommonName      string `json:"CommonName" description:"Subject common name" example:"Hideout Root CA"`
+		Organization    string `json:"Organization" description:"Subject organization" example:"Hideout"`
+		Country         string `json:"Country" description:"Subject country" example:"US"`
+		KeyType         string `json:"KeyType" enums:"rsa,ec,ed25519" description:"Key type" example:"ec"`
+		KeyBits         int    `json:"KeyBits" description:"Key size" example:"384"`
+		TTL             uint   `json:"TTL" description:"Lifetime in seconds" example:"315360000"`
+		MaxPathLen      *int   `json:"MaxPathLen" description:"Maximal number of intermediate authorities below, unlimited if not set" example:"1"`
+	}
+
+	CreateCARS struct {
+		Data *Certificate `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	SaveRoleRQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder of the authority" example:"abc-def-ghi"`
+		Role      Role   `json:"Role"`
+	}
+
+	SaveRoleRS struct {
+		Data *Role `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	IssueCertificateRQ struct {
+		FolderUID       string   `json:"FolderUID" description:"Folder of the authority" example:"abc-def-ghi"`
+		Role            string   `json:"Role" description:"Role name" example:"web"`
+		CommonName      string   `json:"CommonName" description:"Subject common name (taken from signing request if empty)" example:"api.example.com"`
+		AltNames        []string `json:"AltNames" description:"DNS subject alternative names" example:"www.example.com"`
+		IPSANs          []string `json:"IPSANs" description:"IP subject alternative names" example:"10.0.0.1"`
+		TTL             uint     `json:"TTL" description:"Lifetime in seconds, role default if not set" example:"86400"`
+		CSR             string   `json:"CSR" description:"PEM-encoded certificate signing request, key pair is generated if empty"`
+		TargetFolderUID string   `json:"TargetFolderUID" description:"Folder to store issued certificate, chain and key as secrets in" example:"abc-def-ghi"`
+		Prefix          string   `json:"Prefix" description:"Prefix of the stored secret names" example:"TLS"`
+	}
+
+	IssueCertificateRS struct {
+		Data *Certificate `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	RevokeCertificateRQ struct {
+		FolderUID    string `json:"FolderUID" description:"Folder of the authority" example:"abc-def-ghi"`
+		SerialNumber string `json:"SerialNumber" description:"Serial number (hexadecimal)" example:"3f1c0a"`
+	}
+
+	RevokeCertificateRS struct {
+		Data *CertificateRecord `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	GetCRLRQ struct {
+		FolderUID string `uri:"uid" description:"Folder of the authority" example:"abc-def-ghi"`
+		Format    string `form:"format" enums:"pem,der" description:"Encoding of the revocation list, PEM by default" example:"pem"`
+	}
+
+	GetCRLRS struct {
+		rqrs.ResponseRS
+	}
+)
@@ -0,0 +1,86 @@
+package pki
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	"hideout/internal/common/rqrs"
+	"hideout/services/pki"
+	"hideout/services/secrets"
+	"strings"
+)
+
+func (rq CreateCARQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "ParentFolderUID", rq.ParentFolderUID, false)...)
+
+	if rq.CommonName == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "CommonName"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	if rq.KeyType != "" && (rq.KeyType == pki.KeyType_Any || !pki.KeyTypesMap[rq.KeyType]) {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "KeyType", "Values": strings.Join([]string{pki.KeyType_RSA, pki.KeyType_EC,
+				pki.KeyType_Ed25519}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq SaveRoleRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+
+	role := toServiceRole(rq.Role)
+	errValidateRole := role.Validate()
+	if errValidateRole != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidRoleError"},
+			TemplateData: map[string]interface{}{"Name": rq.Role.Name}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errValidateRole.Error(), Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq IssueCertificateRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "TargetFolderUID", rq.TargetFolderUID, false)...)
+
+	if rq.Role == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "Role"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	if rq.CommonName == "" && rq.CSR == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "CommonName"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq RevokeCertificateRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+
+	if rq.SerialNumber == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "SerialNumber"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq GetCRLRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "uid", rq.FolderUID, true)...)
+
+	if rq.Format != "" && rq.Format != CRLFormat_PEM && rq.Format != CRLFormat_DER {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "QueryParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "format", "Values": strings.Join([]string{CRLFormat_PEM, CRLFormat_DER}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
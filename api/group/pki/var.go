@@ -0,0 +1,16 @@
+package pki
+
+import (
+	"hideout/api/engine"
+	"hideout/services/pki"
+	"net/http"
+)
+
+var (
+	// errorStatuses HTTP statuses matching the errors returned by PKI service
+	errorStatuses = []engine.ErrorStatus{
+		{Status: http.StatusNotFound, Errors: []error{pki.ErrRoleNotFound, pki.ErrCertificateNotFound, pki.ErrNotCertificateAuthority}},
+		{Status: http.StatusConflict, Errors: []error{pki.ErrAlreadyCA}},
+		{Status: http.StatusBadRequest, Errors: []error{pki.ErrDomainNotAllowed, pki.ErrKeyTypeNotAllowed, pki.ErrKeyTooWeak, pki.ErrInvalidCSR, pki.ErrInvalidKeyType, pki.ErrInvalidRoleName}},
+	}
+)
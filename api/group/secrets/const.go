@@ -1,6 +1,7 @@
 package secrets
 
 const (
+	CompressionType_None      = 0
 	CompressionType_Brotli    = 1  // .br
 	CompressionType_Bzip2     = 2  // .bzip2
 	CompressionType_Flate     = 3  // .zip
@@ -17,5 +18,81 @@ const (
 	ArchiveType_Zip  = 1
 	ArchiveType_Tar  = 2
 
-	ExportFormat_DotEnv = 1
+	ExportFormat_DotEnv     = 1
+	ExportFormat_PEM        = 2
+	ExportFormat_JSON       = 3
+	ExportFormat_JSONNested = 4
+	ExportFormat_YAML       = 5
+	ExportFormat_TOML       = 6
+	ExportFormat_Properties = 7
+	ExportFormat_Kubernetes = 8
+	ExportFormat_Bash       = 9
+	ExportFormat_Fish       = 10
+	ExportFormat_PowerShell = 11
+	ExportFormat_Systemd    = 12
+	ExportFormat_Compose    = 13
+	ExportFormat_TFVars     = 14
+	ExportFormat_TFVarsJSON = 15
+	ExportFormat_Template   = 16
+
+	KubernetesKind_Secret    = "Secret"
+	KubernetesKind_ConfigMap = "ConfigMap"
+
+	KubernetesSecretType_Opaque = "Opaque"
+	KubernetesName_Default      = "secrets"
+
+	TerraformNameCase_Lower   = "lower"
+	TerraformDeclarationsFile = "variables.tf"
+	TerraformHeredocDelimiter = "EOT"
+
+	RecursiveFileName = "secrets" // Name (without extension) of files exported into folder directories
+
+	ExportFileLimit = 64 << 20 // Maximum length of single exported file, which is kept in memory until streamed
+
+	TemplateName        = "export"
+	TemplateTextLimit   = 64 << 10 // Maximum length of export template
+	TemplateOutputLimit = 16 << 20 // Maximum length of rendered export template
+
+	TemplateIterationLimit = 1 << 18        // Maximum number of range iterations and template calls while rendering
+	TemplateBudgetFunction = "renderBudget" // Function called on every range iteration and template call
+
+	EncryptionType_None    = 0
+	EncryptionType_Age     = 1
+	EncryptionType_OpenPGP = 2
+
+	ImportFormat_Detect  = 0
+	ImportFormat_DotEnv  = 1
+	ImportFormat_JSON    = 2
+	ImportFormat_YAML    = 3
+	ImportFormat_Archive = 4
+	// Formats of exports made by other secret managers
+	ImportFormat_Vault       = 5
+	ImportFormat_Bitwarden   = 6
+	ImportFormat_OnePassword = 7
+	ImportFormat_KeePass     = 8
+	ImportFormat_AWS         = 9
+
+	ImportConflict_Skip      = 0
+	ImportConflict_Overwrite = 1
+	ImportConflict_Fail      = 2
+
+	ImportAction_CreateFolder = "create-folder"
+	ImportAction_Create       = "create"
+	ImportAction_Update       = "update"
+	ImportAction_Unchanged    = "unchanged"
+	ImportAction_Skip         = "skip"
+
+	// Names of secrets standing for common fields of items imported from other secret managers
+	ImportField_UserName = "USERNAME"
+	ImportField_Password = "PASSWORD"
+	ImportField_URL      = "URL"
+	ImportField_TOTP     = "TOTP"
+	ImportField_Notes    = "NOTES"
+	ImportField_Tags     = "TAGS"
+
+	ImportSizeLimit = 32 << 20 // Maximum size of the uploaded file as well as of the decompressed archive
+
+	ExpiredSecrets_Include = 0
+	ExpiredSecrets_Exclude = 1
+	ExpiredSecrets_Flag    = 2
 )
@@ -0,0 +1,212 @@
+package secrets
+
+import (
+	"context"
+	"filippo.io/age"
+	ageArmor "filippo.io/age/armor"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgpArmor "github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
+	"hideout/services/secrets"
+	"io"
+	"strings"
+	"time"
+)
+
+// NewExportEncryptor Encryptor for recipient public keys or the passphrase, keys are parsed upfront so that invalid ones
+// are reported before anything is written to the client
+func NewExportEncryptor(encryptionType uint, recipientKeys []string, passphrase string, armored bool) (ExportEncryptor, error) {
+	switch encryptionType {
+	case EncryptionType_Age:
+		return newAgeEncryptor(recipientKeys, passphrase, armored)
+	case EncryptionType_OpenPGP:
+		return newOpenPGPEncryptor(recipientKeys, passphrase, armored)
+	}
+	return nil, ErrUnknownEncryption
+}
+
+// ParseRecipientKey Checks that public key is usable for encryption of the given type
+func ParseRecipientKey(encryptionType uint, recipientKey string) error {
+	switch encryptionType {
+	case EncryptionType_Age:
+		_, errParse := parseAgeRecipients([]string{recipientKey})
+		return errParse
+	case EncryptionType_OpenPGP:
+		_, errParse := parseOpenPGPRecipients([]string{recipientKey})
+		return errParse
+	}
+	return ErrUnknownEncryption
+}
+
+// GetRecipientKeys Public keys given in request along with the ones stored in the referenced secrets
+func (o EncryptionOptions) GetRecipientKeys(ctx context.Context, secretsSvc *secrets.SecretsService) ([]string, error) {
+	recipientKeys := append([]string{}, o.Recipients...)
+	for _, recipientUID := range o.RecipientUIDs {
+		recipientKey, errGetRecipient := o.getRecipientKey(ctx, secretsSvc, recipientUID)
+		if errGetRecipient != nil {
+			return nil, errGetRecipient
+		}
+		recipientKeys = append(recipientKeys, recipientKey)
+	}
+	return recipientKeys, nil
+}
+
+// getRecipientKey Public key stored in the secret, which must be a recipient of the requested encryption type. Secrets
+// hidden from exports cannot be used as recipients
+func (o EncryptionOptions) getRecipientKey(ctx context.Context, secretsSvc *secrets.SecretsService, recipientUID string) (string, error) {
+	recipientSecret, errGetSecret := secretsSvc.GetSecretByUID(ctx, recipientUID)
+	if errGetSecret != nil {
+		return "", errGetSecret
+	}
+	if recipientSecret.IsHidden() {
+		return "", errors.Wrapf(apperror.ErrRecordNotFound, "Secret with UID of %s", recipientUID)
+	}
+	recipientType := EncryptionRecipientTypesMap[EncryptionTypesMapInv[o.Type]]
+	if recipientSecret.Type != recipientType {
+		return "", errors.Wrapf(ErrInvalidRecipient, "Secret with UID of %s is of type %q instead of %q", recipientUID,
+			recipientSecret.Type, recipientType)
+	}
+	return recipientSecret.Value, nil
+}
+
+// EncryptExport Writes export through the encryptor into the writer, the encryptor is closed even if writing fails
+func EncryptExport(writer io.Writer, encryptor ExportEncryptor, writeExport func(writer io.Writer) error) error {
+	encryptWriter, errEncrypt := encryptor(writer)
+	if errEncrypt != nil {
+		return errEncrypt
+	}
+	errWrite := writeExport(encryptWriter)
+	errClose := encryptWriter.Close()
+	if errWrite != nil {
+		return errWrite
+	}
+	return errClose
+}
+
+// EncryptedFileName Name of the exported file or archive once encrypted
+func EncryptedFileName(fileName string, encryptionType uint, armored bool) string {
+	switch encryptionType {
+	case EncryptionType_Age:
+		return fileName + ".age"
+	case EncryptionType_OpenPGP:
+		if armored {
+			return fileName + ".asc"
+		}
+		return fileName + ".gpg"
+	}
+	return fileName
+}
+
+// EncryptedContentType Media type of the encrypted export, armored output is plain text
+func EncryptedContentType(encryptionType uint, armored bool) string {
+	if armored {
+		return "text/plain; charset=utf-8"
+	}
+	if contentType, exists := EncryptionContentTypesMap[encryptionType]; exists {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+func (w encryptedWriter) Close() error {
+	errClose := w.WriteCloser.Close()
+	errCloseEncoder := w.encoder.Close()
+	if errClose != nil {
+		return errClose
+	}
+	return errCloseEncoder
+}
+
+func parseAgeRecipients(recipientKeys []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, 0, len(recipientKeys))
+	for _, recipientKey := range recipientKeys {
+		recipient, errParse := age.ParseX25519Recipient(strings.TrimSpace(recipientKey))
+		if errParse != nil {
+			return nil, errors.Wrap(ErrInvalidRecipient, errParse.Error())
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+func newAgeEncryptor(recipientKeys []string, passphrase string, armored bool) (ExportEncryptor, error) {
+	var recipients []age.Recipient
+	if passphrase != "" {
+		// age does not allow passphrase to be mixed with other recipients
+		scryptRecipient, errScrypt := age.NewScryptRecipient(passphrase)
+		if errScrypt != nil {
+			return nil, errScrypt
+		}
+		recipients = append(recipients, scryptRecipient)
+	} else {
+		parsedRecipients, errParse := parseAgeRecipients(recipientKeys)
+		if errParse != nil {
+			return nil, errParse
+		}
+		recipients = parsedRecipients
+	}
+
+	return func(writer io.Writer) (io.WriteCloser, error) {
+		if !armored {
+			return age.Encrypt(writer, recipients...)
+		}
+		armorWriter := ageArmor.NewWriter(writer)
+		encryptWriter, errEncrypt := age.Encrypt(armorWriter, recipients...)
+		if errEncrypt != nil {
+			return nil, errEncrypt
+		}
+		return encryptedWriter{WriteCloser: encryptWriter, encoder: armorWriter}, nil
+	}, nil
+}
+
+func parseOpenPGPRecipients(recipientKeys []string) (openpgp.EntityList, error) {
+	var entities openpgp.EntityList
+	for _, recipientKey := range recipientKeys {
+		keyRing, errRead := openpgp.ReadArmoredKeyRing(strings.NewReader(recipientKey))
+		if errRead != nil {
+			return nil, errors.Wrap(ErrInvalidRecipient, errRead.Error())
+		}
+		for _, entity := range keyRing {
+			if _, canEncrypt := entity.EncryptionKey(time.Now()); !canEncrypt {
+				return nil, errors.Wrapf(ErrInvalidRecipient, "Key %X has no valid encryption key", entity.PrimaryKey.Fingerprint)
+			}
+		}
+		entities = append(entities, keyRing...)
+	}
+	return entities, nil
+}
+
+func newOpenPGPEncryptor(recipientKeys []string, passphrase string, armored bool) (ExportEncryptor, error) {
+	var entities openpgp.EntityList
+	if passphrase == "" {
+		parsedEntities, errParse := parseOpenPGPRecipients(recipientKeys)
+		if errParse != nil {
+			return nil, errParse
+		}
+		entities = parsedEntities
+	}
+
+	encrypt := func(writer io.Writer) (io.WriteCloser, error) {
+		hints := &openpgp.FileHints{IsBinary: true}
+		if passphrase != "" {
+			return openpgp.SymmetricallyEncrypt(writer, []byte(passphrase), hints, nil)
+		}
+		return openpgp.Encrypt(writer, entities, nil, hints, nil)
+	}
+
+	return func(writer io.Writer) (io.WriteCloser, error) {
+		if !armored {
+			return encrypt(writer)
+		}
+		armorWriter, errArmor := pgpArmor.Encode(writer, "PGP MESSAGE", nil)
+		if errArmor != nil {
+			return nil, errArmor
+		}
+		encryptWriter, errEncrypt := encrypt(armorWriter)
+		if errEncrypt != nil {
+			return nil, errEncrypt
+		}
+		return encryptedWriter{WriteCloser: encryptWriter, encoder: armorWriter}, nil
+	}, nil
+}
@@ -0,0 +1,1237 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"hideout/internal/pkg/archive"
+	"hideout/internal/pkg/signing"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+)
+
+// exportNode Folder (or secret) in the tree of exported values, keeping the order in which secrets were listed
+type exportNode struct {
+	keys     []string
+	children map[string]*exportNode
+	secret   *Secret
+}
+
+// Export Writes secrets in the given format, expired secrets are marked with comments if the format supports them
+func Export(ctx context.Context, writer io.Writer, format uint, secrets []Secret, options ExportOptions) error {
+	flagExpired := options.FlagExpired
+	switch format {
+	case ExportFormat_DotEnv:
+		return ExportToDotEnv(ctx, writer, secrets, flagExpired)
+	case ExportFormat_PEM:
+		return ExportToPEM(ctx, writer, secrets)
+	case ExportFormat_JSON:
+		return ExportToJSON(ctx, writer, secrets)
+	case ExportFormat_JSONNested:
+		return ExportToNestedJSON(ctx, writer, secrets)
+	case ExportFormat_YAML:
+		return ExportToYAML(ctx, writer, secrets, flagExpired)
+	case ExportFormat_TOML:
+		return ExportToTOML(ctx, writer, secrets, flagExpired)
+	case ExportFormat_Properties:
+		return ExportToProperties(ctx, writer, secrets, flagExpired)
+	case ExportFormat_Kubernetes:
+		return ExportToKubernetes(ctx, writer, secrets, options.Kubernetes, flagExpired)
+	case ExportFormat_Bash:
+		return exportVariables(writer, secrets, flagExpired, func(name string, value string) string {
+			return fmt.Sprintf("export %s=%s", name, singleQuoteEscape(value))
+		})
+	case ExportFormat_Fish:
+		return exportVariables(writer, secrets, flagExpired, func(name string, value string) string {
+			return fmt.Sprintf("set -gx %s %s", name, fishQuoteEscape(value))
+		})
+	case ExportFormat_PowerShell:
+		return exportVariables(writer, secrets, flagExpired, func(name string, value string) string {
+			return fmt.Sprintf("$env:%s = %s", name, powerShellQuoteEscape(value))
+		})
+	case ExportFormat_Systemd:
+		return exportVariables(writer, secrets, flagExpired, func(name string, value string) string {
+			return fmt.Sprintf(`%s="%s"`, name, systemdQuoteEscape(value))
+		})
+	case ExportFormat_Compose:
+		return ExportToCompose(ctx, writer, secrets, flagExpired)
+	case ExportFormat_TFVars:
+		return ExportToTFVars(ctx, writer, secrets, options.Terraform, flagExpired)
+	case ExportFormat_TFVarsJSON:
+		return ExportToTFVarsJSON(ctx, writer, secrets, options.Terraform)
+	case ExportFormat_Template:
+		return ExportToTemplate(ctx, writer, secrets, options.TemplateText)
+	}
+
+	return errors.Wrapf(ErrUnknownExportFormat, "Format %d", format)
+}
+
+// ExportFiles Files to be archived, which is a single file unless the format splits it by folders. Every file is
+// rendered once right away into memory, so that errors are reported before anything is streamed and the streamed file
+// is exactly the measured one
+func ExportFiles(ctx context.Context, format uint, secrets []Secret, options ExportOptions, fileName string) ([]ExportFile, error) {
+	var files []ExportFile
+	if format == ExportFormat_Kubernetes && options.Kubernetes.Split {
+		kubernetesFiles, errExport := ExportToKubernetesFiles(ctx, secrets, options.Kubernetes, options.FlagExpired)
+		if errExport != nil {
+			return nil, errExport
+		}
+		files = kubernetesFiles
+	} else {
+		files = append(files, ExportFile{File: archive.File{Name: fileName, Write: func(writer io.Writer) error {
+			return Export(ctx, writer, format, secrets, options)
+		}}})
+		if (format == ExportFormat_TFVars || format == ExportFormat_TFVarsJSON) && options.Terraform.Declarations {
+			files = append(files, ExportFile{File: archive.File{Name: TerraformDeclarationsFile, Write: func(writer io.Writer) error {
+				return ExportTerraformDeclarations(ctx, writer, secrets, options.Terraform)
+			}}})
+		}
+	}
+
+	for fileIndex := range files {
+		errRender := files[fileIndex].render()
+		if errRender != nil {
+			return nil, errRender
+		}
+	}
+	return files, nil
+}
+
+// render Renders the file into memory up to the size limit, the file then writes the rendered data along with its
+// size and checksum
+func (f *ExportFile) render() error {
+	var rendered bytes.Buffer
+	errWrite := f.Write(&limitedWriter{writer: &rendered, limit: ExportFileLimit, err: ErrExportFileLimit})
+	if errWrite != nil {
+		return errWrite
+	}
+	checksum := sha256.Sum256(rendered.Bytes())
+	f.File = archive.Bytes(f.Name, rendered.Bytes())
+	f.SHA256 = hex.EncodeToString(checksum[:])
+	return nil
+}
+
+func doubleQuoteEscape(line string) string {
+	const doubleQuoteSpecialChars = "\\\n\r\"!$`"
+	for _, c := range doubleQuoteSpecialChars {
+		toReplace := "\\" + string(c)
+		if c == '\n' {
+			toReplace = `\n`
+		}
+		if c == '\r' {
+			toReplace = `\r`
+		}
+		line = strings.Replace(line, string(c), toReplace, -1)
+	}
+	return line
+}
+
+func ExportToDotEnv(ctx context.Context, writer io.Writer, secrets []Secret, flagExpired bool) error {
+	lines := &exportLines{writer: writer}
+	for _, secret := range secrets {
+		if flagExpired && secret.Expired && secret.ExpiresAt != nil {
+			lines.Line(fmt.Sprintf("# EXPIRED at %s", secret.ExpiresAt.UTC().Format(time.RFC3339)))
+		}
+		if d, err := strconv.Atoi(secret.Value); err == nil {
+			lines.Line(fmt.Sprintf(`%s=%d`, secret.Name, d))
+		} else {
+			lines.Line(fmt.Sprintf(`%s="%s"`, secret.Name, doubleQuoteEscape(secret.Value)))
+		}
+	}
+
+	return lines.err
+}
+
+// ExportToPEM Concatenates PEM blocks found in secret values into a single bundle, certificates (in the order of
+// secrets) go before private keys, secrets without PEM blocks are skipped
+func ExportToPEM(ctx context.Context, writer io.Writer, secrets []Secret) error {
+	var certificateBlocks, otherBlocks [][]byte
+	var seenBlocks = make(map[string]bool)
+	for _, secret := range secrets {
+		rest := []byte(secret.Value)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			encodedBlock := pem.EncodeToMemory(block)
+			// Chains usually repeat the certificates stored separately
+			if seenBlocks[string(encodedBlock)] {
+				continue
+			}
+			seenBlocks[string(encodedBlock)] = true
+			if block.Type == "CERTIFICATE" {
+				certificateBlocks = append(certificateBlocks, encodedBlock)
+			} else {
+				otherBlocks = append(otherBlocks, encodedBlock)
+			}
+		}
+	}
+
+	for _, block := range append(certificateBlocks, otherBlocks...) {
+		_, errWrite := writer.Write(block)
+		if errWrite != nil {
+			return errWrite
+		}
+	}
+	return nil
+}
+
+// ExportRecursive Exports secrets of every folder into its own file placed by folder path, along with the manifest
+// listing exported files
+func ExportRecursive(ctx context.Context, format uint, secrets []Secret, options ExportOptions, fileName string) ([]ExportFile, error) {
+	var folderPaths [][]string
+	var folderSecrets = make(map[string][]Secret)
+	for _, secret := range secrets {
+		folderKey := strings.Join(secret.Path, "/")
+		if _, exists := folderSecrets[folderKey]; !exists {
+			folderPaths = append(folderPaths, secret.Path)
+		}
+		// Every file holds secrets of a single folder, so they are not nested any further
+		folderSecret := secret
+		folderSecret.Path = nil
+		folderSecrets[folderKey] = append(folderSecrets[folderKey], folderSecret)
+	}
+
+	manifest := ExportManifest{Format: ExportFormatsMap[format], CreatedAt: time.Now().UTC(), Files: []ExportManifestFile{}}
+	var files []ExportFile
+	for _, folderPath := range folderPaths {
+		folderKey := strings.Join(folderPath, "/")
+		folderOptions := options
+		// Manifests of different folders still end up in the same namespace
+		folderOptions.Kubernetes.Name = kubernetesName(options.Kubernetes.Name, folderPath)
+
+		folderFiles, errExport := ExportFiles(ctx, format, folderSecrets[folderKey], folderOptions, fileName)
+		if errExport != nil {
+			return nil, errors.Wrapf(errExport, "Folder %s", folderKey)
+		}
+		for _, folderFile := range folderFiles {
+			folderFile.Name = archivePath(folderPath, folderFile.Name)
+			manifest.Files = append(manifest.Files, ExportManifestFile{Path: folderFile.Name, Folder: folderKey,
+				Secrets: len(folderSecrets[folderKey]), SHA256: folderFile.SHA256})
+			files = append(files, folderFile)
+		}
+	}
+
+	manifestFiles, errManifest := exportManifestFiles(manifest, options.SigningKey)
+	if errManifest != nil {
+		return nil, errManifest
+	}
+
+	return append(files, manifestFiles...), nil
+}
+
+// SignExport Adds manifest with checksums of exported files along with its signature
+func SignExport(format uint, files []ExportFile, signingKey ed25519.PrivateKey) ([]ExportFile, error) {
+	manifest := ExportManifest{Format: ExportFormatsMap[format], CreatedAt: time.Now().UTC(), Files: []ExportManifestFile{}}
+	for _, file := range files {
+		manifest.Files = append(manifest.Files, ExportManifestFile{Path: file.Name, SHA256: file.SHA256})
+	}
+
+	manifestFiles, errManifest := exportManifestFiles(manifest, signingKey)
+	if errManifest != nil {
+		return nil, errManifest
+	}
+
+	return append(files, manifestFiles...), nil
+}
+
+// exportManifestFiles Manifest file followed by its detached signature if signing key is given
+func exportManifestFiles(manifest ExportManifest, signingKey ed25519.PrivateKey) ([]ExportFile, error) {
+	if signingKey != nil {
+		manifest.KeyID = signing.KeyID(signingKey.Public().(ed25519.PublicKey))
+	}
+	manifestData, errMarshal := json.MarshalIndent(manifest, "", "  ")
+	if errMarshal != nil {
+		return nil, errMarshal
+	}
+
+	manifestFiles := []ExportFile{{File: archive.Bytes(signing.ManifestFile, manifestData)}}
+	if signingKey != nil {
+		manifestFiles = append(manifestFiles, ExportFile{File: archive.Bytes(signing.ManifestSignatureFile, signing.Sign(signingKey, manifestData))})
+	}
+	return manifestFiles, nil
+}
+
+// archivePath Path of the file in archive, folder names are made safe to be used as directories
+func archivePath(folderPath []string, fileName string) string {
+	parts := make([]string, 0, len(folderPath)+1)
+	for _, folderName := range folderPath {
+		folderName = strings.NewReplacer("/", "_", "\\", "_").Replace(folderName)
+		if folderName == "" || folderName == "." || folderName == ".." {
+			folderName = "_"
+		}
+		parts = append(parts, folderName)
+	}
+	return strings.Join(append(parts, fileName), "/")
+}
+
+// ExportToJSON Single JSON object of secret names and values, folders are ignored
+func ExportToJSON(ctx context.Context, writer io.Writer, secrets []Secret) error {
+	root := &exportNode{children: map[string]*exportNode{}}
+	for secretIndex := range secrets {
+		errAdd := root.add(nil, &secrets[secretIndex])
+		if errAdd != nil {
+			return errAdd
+		}
+	}
+
+	lines := &exportLines{writer: writer}
+	writeJSONNode(lines, root, "")
+	return lines.err
+}
+
+// ExportToNestedJSON JSON object with a nested object for every folder below the exported one
+func ExportToNestedJSON(ctx context.Context, writer io.Writer, secrets []Secret) error {
+	root, errBuildTree := buildExportTree(secrets)
+	if errBuildTree != nil {
+		return errBuildTree
+	}
+
+	lines := &exportLines{writer: writer}
+	writeJSONNode(lines, root, "")
+	return lines.err
+}
+
+// ExportToYAML YAML document with a nested mapping for every folder below the exported one
+func ExportToYAML(ctx context.Context, writer io.Writer, secrets []Secret, flagExpired bool) error {
+	root, errBuildTree := buildExportTree(secrets)
+	if errBuildTree != nil {
+		return errBuildTree
+	}
+	if len(root.keys) == 0 {
+		return nil
+	}
+
+	return encodeYAML(writer, yamlNode(root, flagExpired))
+}
+
+// ExportToTOML TOML document with a table for every folder below the exported one
+func ExportToTOML(ctx context.Context, writer io.Writer, secrets []Secret, flagExpired bool) error {
+	root, errBuildTree := buildExportTree(secrets)
+	if errBuildTree != nil {
+		return errBuildTree
+	}
+
+	lines := &exportLines{writer: writer}
+	writeTOMLNode(lines, root, nil, flagExpired)
+	return lines.err
+}
+
+// ExportToProperties Java properties file, keys of secrets below the exported folder are prefixed with folder names
+// separated by dots
+func ExportToProperties(ctx context.Context, writer io.Writer, secrets []Secret, flagExpired bool) error {
+	lines := &exportLines{writer: writer}
+	for _, secret := range secrets {
+		if flagExpired && secret.Expired && secret.ExpiresAt != nil {
+			lines.Line(fmt.Sprintf("# EXPIRED at %s", secret.ExpiresAt.UTC().Format(time.RFC3339)))
+		}
+		key := strings.Join(append(append([]string{}, secret.Path...), secret.Name), ".")
+		lines.Line(fmt.Sprintf("%s=%s", propertiesEscape(key, true), propertiesEscape(secret.Value, false)))
+	}
+
+	return lines.err
+}
+
+// buildExportTree Arranges secrets by their folder path
+func buildExportTree(secrets []Secret) (*exportNode, error) {
+	root := &exportNode{children: map[string]*exportNode{}}
+	for secretIndex := range secrets {
+		errAdd := root.add(secrets[secretIndex].Path, &secrets[secretIndex])
+		if errAdd != nil {
+			return nil, errAdd
+		}
+	}
+
+	return root, nil
+}
+
+// add Places secret under the folder path, secret and folder (or two secrets) sharing the same key is a conflict
+func (n *exportNode) add(path []string, secret *Secret) error {
+	node := n
+	for pathIndex, folderName := range path {
+		child, exists := node.children[folderName]
+		if !exists {
+			child = &exportNode{children: map[string]*exportNode{}}
+			node.children[folderName] = child
+			node.keys = append(node.keys, folderName)
+		} else if child.secret != nil {
+			return errors.Wrapf(ErrExportKeyConflict, "Key %s", strings.Join(path[:pathIndex+1], "/"))
+		}
+		node = child
+	}
+
+	if _, exists := node.children[secret.Name]; exists {
+		return errors.Wrapf(ErrExportKeyConflict, "Key %s", strings.Join(append(append([]string{}, path...), secret.Name), "/"))
+	}
+	node.children[secret.Name] = &exportNode{secret: secret}
+	node.keys = append(node.keys, secret.Name)
+
+	return nil
+}
+
+func writeJSONNode(lines *exportLines, node *exportNode, indent string) {
+	if len(node.keys) == 0 {
+		lines.Text("{}")
+		return
+	}
+
+	lines.Text("{\n")
+	for keyIndex, key := range node.keys {
+		child := node.children[key]
+		lines.Text(indent + "  " + jsonString(key) + ": ")
+		if child.secret != nil {
+			lines.Text(jsonString(child.secret.Value))
+		} else {
+			writeJSONNode(lines, child, indent+"  ")
+		}
+		if keyIndex < len(node.keys)-1 {
+			lines.Text(",")
+		}
+		lines.Text("\n")
+	}
+	lines.Text(indent + "}")
+}
+
+// jsonString Quoted JSON string, HTML characters are left as is
+func jsonString(value string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func yamlNode(node *exportNode, flagExpired bool) *yaml.Node {
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range node.keys {
+		child := node.children[key]
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+		if child.secret == nil {
+			mapping.Content = append(mapping.Content, keyNode, yamlNode(child, flagExpired))
+			continue
+		}
+		if flagExpired && child.secret.Expired && child.secret.ExpiresAt != nil {
+			keyNode.HeadComment = fmt.Sprintf("EXPIRED at %s", child.secret.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+		// Explicit tag makes the encoder quote values which would otherwise be read back as numbers, booleans or nulls
+		valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: child.secret.Value}
+		if strings.Contains(child.secret.Value, "\n") {
+			valueNode.Style = yaml.LiteralStyle
+		}
+		mapping.Content = append(mapping.Content, keyNode, valueNode)
+	}
+
+	return mapping
+}
+
+// writeTOMLNode Values of the folder go first, as TOML attributes every key-value pair after a table header to it
+func writeTOMLNode(lines *exportLines, node *exportNode, path []string, flagExpired bool) {
+	var headerWritten = len(path) == 0
+	for _, key := range node.keys {
+		child := node.children[key]
+		if child.secret == nil {
+			continue
+		}
+		if !headerWritten {
+			if lines.count > 0 {
+				lines.Line("")
+			}
+			lines.Line(fmt.Sprintf("[%s]", tomlKey(path)))
+			headerWritten = true
+		}
+		if flagExpired && child.secret.Expired && child.secret.ExpiresAt != nil {
+			lines.Line(fmt.Sprintf("# EXPIRED at %s", child.secret.ExpiresAt.UTC().Format(time.RFC3339)))
+		}
+		lines.Line(fmt.Sprintf("%s = %s", tomlKey([]string{key}), tomlString(child.secret.Value)))
+	}
+
+	for _, key := range node.keys {
+		child := node.children[key]
+		if child.secret == nil {
+			writeTOMLNode(lines, child, append(append([]string{}, path...), key), flagExpired)
+		}
+	}
+}
+
+// tomlKey Dotted key, parts which are not allowed to be bare are quoted
+func tomlKey(path []string) string {
+	parts := make([]string, 0, len(path))
+	for _, part := range path {
+		if tomlBareKeyRegex.MatchString(part) {
+			parts = append(parts, part)
+		} else {
+			parts = append(parts, tomlString(part))
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// tomlString Basic TOML string, control characters are escaped
+func tomlString(value string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\r':
+			builder.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				builder.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// propertiesEscape Escapes key (value) the way java.util.Properties reads it, characters outside of ISO 8859-1 are
+// written as unicode escapes
+func propertiesEscape(value string, isKey bool) string {
+	var builder strings.Builder
+	for index, r := range value {
+		switch r {
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case ' ':
+			// Leading whitespace of values is dropped by the reader, any whitespace ends the key
+			if isKey || index == 0 {
+				builder.WriteByte('\\')
+			}
+			builder.WriteRune(r)
+		default:
+			if r < 0x20 || r > 0x7e {
+				for _, unit := range utf16Units(r) {
+					builder.WriteString(fmt.Sprintf(`\u%04X`, unit))
+				}
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// utf16Units UTF-16 code units of the rune, invalid runes are replaced
+func utf16Units(r rune) []uint16 {
+	if r == utf8.RuneError || r > utf8.MaxRune {
+		return []uint16{0xFFFD}
+	}
+	if r < 0x10000 {
+		return []uint16{uint16(r)}
+	}
+	r -= 0x10000
+	return []uint16{uint16(0xD800 + (r>>10)&0x3FF), uint16(0xDC00 + r&0x3FF)}
+}
+
+// ExportToKubernetes Multi-document YAML with a Secret (ConfigMap) manifest for every folder
+func ExportToKubernetes(ctx context.Context, writer io.Writer, secrets []Secret, options KubernetesOptions, flagExpired bool) error {
+	manifests, errBuildManifests := kubernetesManifests(secrets, options, flagExpired)
+	if errBuildManifests != nil {
+		return errBuildManifests
+	}
+
+	nodes := make([]*yaml.Node, 0, len(manifests))
+	for _, manifest := range manifests {
+		nodes = append(nodes, manifest.node)
+	}
+	return encodeYAML(writer, nodes...)
+}
+
+// ExportToKubernetesFiles Separate file with a Secret (ConfigMap) manifest for every folder, placed by folder path
+func ExportToKubernetesFiles(ctx context.Context, secrets []Secret, options KubernetesOptions, flagExpired bool) ([]ExportFile, error) {
+	manifests, errBuildManifests := kubernetesManifests(secrets, options, flagExpired)
+	if errBuildManifests != nil {
+		return nil, errBuildManifests
+	}
+
+	files := make([]ExportFile, 0, len(manifests))
+	for _, manifest := range manifests {
+		node := manifest.node
+		files = append(files, ExportFile{File: archive.File{Name: archivePath(manifest.path, manifest.name+ExportExtensionsMap[ExportFormat_Kubernetes]),
+			Write: func(writer io.Writer) error {
+				return encodeYAML(writer, node)
+			},
+		}})
+	}
+
+	return files, nil
+}
+
+// encodeYAML Writes every node as a separate YAML document
+func encodeYAML(writer io.Writer, nodes ...*yaml.Node) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	for _, node := range nodes {
+		errEncode := encoder.Encode(node)
+		if errEncode != nil {
+			return errEncode
+		}
+	}
+	return encoder.Close()
+}
+
+type kubernetesManifest struct {
+	path []string
+	name string
+	node *yaml.Node
+}
+
+// kubernetesManifests Manifest of every folder in the order folders were first met in the list of secrets
+func kubernetesManifests(secrets []Secret, options KubernetesOptions, flagExpired bool) ([]kubernetesManifest, error) {
+	var manifests []kubernetesManifest
+	var manifestIndexes = make(map[string]int)
+	var dataKeys = make(map[string]bool)
+	for _, secret := range secrets {
+		if !kubernetesKeyRegex.MatchString(secret.Name) {
+			return nil, errors.Wrapf(ErrInvalidKubernetesKey, "Key %s", secret.Name)
+		}
+
+		folderKey := strings.Join(secret.Path, "/")
+		if dataKeys[folderKey+"/"+secret.Name] {
+			return nil, errors.Wrapf(ErrExportKeyConflict, "Key %s", folderKey+"/"+secret.Name)
+		}
+		dataKeys[folderKey+"/"+secret.Name] = true
+
+		manifestIndex, exists := manifestIndexes[folderKey]
+		if !exists {
+			name := kubernetesName(options.Name, secret.Path)
+			manifests = append(manifests, kubernetesManifest{path: secret.Path, name: name, node: kubernetesManifestNode(name, options)})
+			manifestIndex = len(manifests) - 1
+			manifestIndexes[folderKey] = manifestIndex
+		}
+
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: secret.Name}
+		if flagExpired && secret.Expired && secret.ExpiresAt != nil {
+			keyNode.HeadComment = fmt.Sprintf("EXPIRED at %s", secret.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+		valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: secret.Value}
+		if options.Kind == KubernetesKind_ConfigMap {
+			if strings.Contains(secret.Value, "\n") {
+				valueNode.Style = yaml.LiteralStyle
+			}
+		} else {
+			valueNode.Value = base64.StdEncoding.EncodeToString([]byte(secret.Value))
+		}
+		// Data mapping is always the last one of the manifest
+		manifestNode := manifests[manifestIndex].node
+		dataNode := manifestNode.Content[len(manifestNode.Content)-1]
+		dataNode.Content = append(dataNode.Content, keyNode, valueNode)
+	}
+
+	return manifests, nil
+}
+
+// kubernetesName Name of the manifest with sub-folder names appended, reduced to a valid DNS subdomain
+func kubernetesName(baseName string, path []string) string {
+	if baseName == "" {
+		baseName = KubernetesName_Default
+	}
+	parts := []string{baseName}
+	for _, folderName := range path {
+		part := strings.Trim(kubernetesInvalidRegex.ReplaceAllString(strings.ToLower(folderName), "-"), "-.")
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	name := strings.Join(parts, "-")
+	if len(name) > 253 {
+		name = strings.TrimRight(name[:253], "-.")
+	}
+	return name
+}
+
+func kubernetesManifestNode(name string, options KubernetesOptions) *yaml.Node {
+	kind := options.Kind
+	if kind == "" {
+		kind = KubernetesKind_Secret
+	}
+
+	metadata := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	metadata.Content = append(metadata.Content, yamlString("name"), yamlString(name))
+	if options.Namespace != "" {
+		metadata.Content = append(metadata.Content, yamlString("namespace"), yamlString(options.Namespace))
+	}
+	if len(options.Labels) > 0 {
+		metadata.Content = append(metadata.Content, yamlString("labels"), yamlStringMap(options.Labels))
+	}
+	if len(options.Annotations) > 0 {
+		metadata.Content = append(metadata.Content, yamlString("annotations"), yamlStringMap(options.Annotations))
+	}
+
+	manifest := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	manifest.Content = append(manifest.Content, yamlString("apiVersion"), yamlString("v1"), yamlString("kind"), yamlString(kind),
+		yamlString("metadata"), metadata)
+	if kind == KubernetesKind_Secret {
+		secretType := options.Type
+		if secretType == "" {
+			secretType = KubernetesSecretType_Opaque
+		}
+		manifest.Content = append(manifest.Content, yamlString("type"), yamlString(secretType))
+	}
+	manifest.Content = append(manifest.Content, yamlString("data"), &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+
+	return manifest
+}
+
+func yamlString(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// yamlStringMap Mapping with keys in alphabetical order, so that manifests do not change between exports
+func yamlStringMap(values map[string]string) *yaml.Node {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range keys {
+		mapping.Content = append(mapping.Content, yamlString(key), yamlString(values[key]))
+	}
+	return mapping
+}
+
+// exportVariables Line of every secret, built by the format, names have to be valid environment variable names
+func exportVariables(writer io.Writer, secrets []Secret, flagExpired bool, formatLine func(name string, value string) string) error {
+	for _, secret := range secrets {
+		if !variableNameRegex.MatchString(secret.Name) {
+			return errors.Wrapf(ErrInvalidVariableName, "Name %s", secret.Name)
+		}
+	}
+
+	lines := &exportLines{writer: writer}
+	for _, secret := range secrets {
+		if flagExpired && secret.Expired && secret.ExpiresAt != nil {
+			lines.Line(fmt.Sprintf("# EXPIRED at %s", secret.ExpiresAt.UTC().Format(time.RFC3339)))
+		}
+		lines.Line(formatLine(secret.Name, secret.Value))
+	}
+
+	return lines.err
+}
+
+// singleQuoteEscape POSIX shell single-quoted string, nothing is special inside of it except for the quote itself
+func singleQuoteEscape(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// fishQuoteEscape Fish single-quoted string, where only quote and backslash are escaped
+func fishQuoteEscape(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
+
+// powerShellQuoteEscape PowerShell verbatim string, quotes (including typographic ones PowerShell treats the same way)
+// are doubled
+func powerShellQuoteEscape(value string) string {
+	return "'" + strings.NewReplacer("'", "''", "\u2018", "\u2018\u2018", "\u2019", "\u2019\u2019", "\u201A", "\u201A\u201A",
+		"\u201B", "\u201B\u201B").Replace(value) + "'"
+}
+
+// systemdQuoteEscape Double-quoted value of systemd EnvironmentFile, newlines are kept as is since quoted values may span
+// several lines
+func systemdQuoteEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", "$", `\$`).Replace(value)
+}
+
+// ExportToCompose Environment block of docker-compose service, dollar signs are doubled to avoid interpolation
+func ExportToCompose(ctx context.Context, writer io.Writer, secrets []Secret, flagExpired bool) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	environment := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	var seenNames = make(map[string]bool)
+	for _, secret := range secrets {
+		if seenNames[secret.Name] {
+			return errors.Wrapf(ErrExportKeyConflict, "Key %s", secret.Name)
+		}
+		seenNames[secret.Name] = true
+
+		keyNode := yamlString(secret.Name)
+		if flagExpired && secret.Expired && secret.ExpiresAt != nil {
+			keyNode.HeadComment = fmt.Sprintf("EXPIRED at %s", secret.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+		valueNode := yamlString(strings.ReplaceAll(secret.Value, "$", "$$"))
+		if strings.Contains(secret.Value, "\n") {
+			valueNode.Style = yaml.LiteralStyle
+		}
+		environment.Content = append(environment.Content, keyNode, valueNode)
+	}
+
+	document := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{yamlString("environment"), environment}}
+	return encodeYAML(writer, document)
+}
+
+// ExportToTFVars Terraform variable definitions file, values spanning several lines are written as heredocs
+func ExportToTFVars(ctx context.Context, writer io.Writer, secrets []Secret, options TerraformOptions, flagExpired bool) error {
+	names, errNames := terraformNames(secrets, options)
+	if errNames != nil {
+		return errNames
+	}
+
+	lines := &exportLines{writer: writer}
+	for secretIndex, secret := range secrets {
+		if flagExpired && secret.Expired && secret.ExpiresAt != nil {
+			lines.Line(fmt.Sprintf("# EXPIRED at %s", secret.ExpiresAt.UTC().Format(time.RFC3339)))
+		}
+		lines.Line(fmt.Sprintf("%s = %s", names[secretIndex], hclValue(secret.Value)))
+	}
+
+	return lines.err
+}
+
+// ExportToTFVarsJSON Terraform variable definitions file in JSON syntax
+func ExportToTFVarsJSON(ctx context.Context, writer io.Writer, secrets []Secret, options TerraformOptions) error {
+	names, errNames := terraformNames(secrets, options)
+	if errNames != nil {
+		return errNames
+	}
+
+	root := &exportNode{children: map[string]*exportNode{}}
+	for secretIndex := range secrets {
+		// Variable files are evaluated without context, so strings of JSON syntax are taken literally, unlike native one
+		root.keys = append(root.keys, names[secretIndex])
+		root.children[names[secretIndex]] = &exportNode{secret: &secrets[secretIndex]}
+	}
+
+	lines := &exportLines{writer: writer}
+	writeJSONNode(lines, root, "")
+	return lines.err
+}
+
+// ExportTerraformDeclarations Stubs declaring exported variables as sensitive strings
+func ExportTerraformDeclarations(ctx context.Context, writer io.Writer, secrets []Secret, options TerraformOptions) error {
+	names, errNames := terraformNames(secrets, options)
+	if errNames != nil {
+		return errNames
+	}
+
+	lines := &exportLines{writer: writer}
+	for nameIndex, name := range names {
+		if nameIndex > 0 {
+			lines.Line("")
+		}
+		lines.Line(fmt.Sprintf("variable %s {\n  type      = string\n  sensitive = true\n}", hclString(name)))
+	}
+	lines.Text("\n")
+	return lines.err
+}
+
+// terraformNames Variable names of secrets after transformation, which have to be unique valid identifiers
+func terraformNames(secrets []Secret, options TerraformOptions) ([]string, error) {
+	names := make([]string, 0, len(secrets))
+	var seenNames = make(map[string]bool)
+	for _, secret := range secrets {
+		name := secret.Name
+		if options.PrefixPath && len(secret.Path) > 0 {
+			parts := make([]string, 0, len(secret.Path)+1)
+			for _, folderName := range secret.Path {
+				parts = append(parts, strings.Trim(terraformInvalidRegex.ReplaceAllString(folderName, "_"), "_"))
+			}
+			name = strings.Join(append(parts, name), "_")
+		}
+		if options.NameCase == TerraformNameCase_Lower {
+			name = strings.ToLower(name)
+		}
+
+		if !terraformNameRegex.MatchString(name) || TerraformReservedNamesMap[name] {
+			return nil, errors.Wrapf(ErrInvalidVariableName, "Name %s", name)
+		}
+		if seenNames[name] {
+			return nil, errors.Wrapf(ErrExportKeyConflict, "Key %s", name)
+		}
+		seenNames[name] = true
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// hclValue Heredoc for multi-line values it reproduces exactly (ending with a newline and free of other control
+// characters), quoted string otherwise
+func hclValue(value string) string {
+	if !strings.HasSuffix(value, "\n") || strings.ContainsFunc(value, func(r rune) bool { return r < 0x20 && r != '\n' && r != '\t' }) {
+		return hclString(value)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(value, "\n"), "\n")
+	delimiter := TerraformHeredocDelimiter
+	for delimiterIndex := 1; ; delimiterIndex++ {
+		var conflicts bool
+		for _, line := range lines {
+			if strings.TrimSpace(line) == delimiter {
+				conflicts = true
+				break
+			}
+		}
+		if !conflicts {
+			break
+		}
+		delimiter = fmt.Sprintf("%s%d", TerraformHeredocDelimiter, delimiterIndex)
+	}
+
+	return fmt.Sprintf("<<%s\n%s%s", delimiter, hclTemplateEscape(value), delimiter)
+}
+
+// hclString Quoted HCL string with template sequences escaped
+func hclString(value string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range hclTemplateEscape(value) {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				builder.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// hclTemplateEscape Escapes interpolation and directive sequences, so that the value is taken literally
+func hclTemplateEscape(value string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(value)
+}
+
+// ExportToTemplate Renders Go template with exported secrets, see TemplateData for available data and templateFunctions
+// for available functions
+func ExportToTemplate(ctx context.Context, writer io.Writer, secrets []Secret, templateText string) error {
+	exportTemplate, errParse := ParseTemplate(templateText)
+	if errParse != nil {
+		return errParse
+	}
+
+	data := TemplateData{Secrets: secrets, Values: make(map[string]string, len(secrets))}
+	for _, secret := range secrets {
+		data.Values[secret.Name] = secret.Value
+	}
+
+	var iterations int
+	exportTemplate.Funcs(template.FuncMap{TemplateBudgetFunction: func() (string, error) {
+		iterations++
+		if iterations > TemplateIterationLimit {
+			return "", ErrTemplateIterationLimit
+		}
+		return "", ctx.Err()
+	}})
+
+	errExecute := exportTemplate.Execute(&limitedWriter{writer: writer, limit: TemplateOutputLimit, err: ErrTemplateOutputLimit}, data)
+	if errExecute != nil {
+		switch {
+		case errors.Is(errExecute, ErrTemplateOutputLimit):
+			{
+				return ErrTemplateOutputLimit
+			}
+		case errors.Is(errExecute, ErrTemplateIterationLimit):
+			{
+				return ErrTemplateIterationLimit
+			}
+		case ctx.Err() != nil:
+			{
+				return ctx.Err()
+			}
+		}
+		return errors.Wrap(ErrTemplateInvalid, errExecute.Error())
+	}
+
+	return nil
+}
+
+// ParseTemplate Parses export template with the restricted set of functions. Ranging is only allowed over fields and
+// variables, and every range iteration or template call goes through the rendering budget, since nothing else bounds
+// the time rendering takes
+func ParseTemplate(templateText string) (*template.Template, error) {
+	if len(templateText) > TemplateTextLimit {
+		return nil, errors.Wrapf(ErrTemplateInvalid, "Template is longer than %d bytes", TemplateTextLimit)
+	}
+
+	exportTemplate, errParse := template.New(TemplateName).Option("missingkey=error").Funcs(templateFunctions).Parse(templateText)
+	if errParse != nil {
+		return nil, errors.Wrap(ErrTemplateInvalid, errParse.Error())
+	}
+
+	for _, definedTemplate := range exportTemplate.Templates() {
+		if definedTemplate.Tree == nil {
+			continue
+		}
+		errCheck := checkTemplateNode(definedTemplate.Tree.Root)
+		if errCheck != nil {
+			return nil, errCheck
+		}
+		addTemplateBudget(definedTemplate.Tree.Root)
+	}
+
+	return exportTemplate, nil
+}
+
+// checkTemplateNode Rejects range actions over anything but fields and variables, and calls of functions outside the
+// restricted set
+func checkTemplateNode(node parse.Node) error {
+	switch typedNode := node.(type) {
+	case *parse.ListNode:
+		{
+			if typedNode == nil {
+				return nil
+			}
+			for _, childNode := range typedNode.Nodes {
+				errCheck := checkTemplateNode(childNode)
+				if errCheck != nil {
+					return errCheck
+				}
+			}
+		}
+	case *parse.PipeNode:
+		{
+			if typedNode == nil {
+				return nil
+			}
+			for _, command := range typedNode.Cmds {
+				for _, argument := range command.Args {
+					errCheck := checkTemplateNode(argument)
+					if errCheck != nil {
+						return errCheck
+					}
+				}
+			}
+		}
+	case *parse.ChainNode:
+		{
+			return checkTemplateNode(typedNode.Node)
+		}
+	case *parse.IdentifierNode:
+		{
+			_, isFunction := templateFunctions[typedNode.Ident]
+			if !isFunction && !slices.Contains(TemplateBuiltins, typedNode.Ident) {
+				return errors.Wrapf(ErrTemplateInvalid, "Function %s is not available", typedNode.Ident)
+			}
+		}
+	case *parse.ActionNode:
+		{
+			return checkTemplateNode(typedNode.Pipe)
+		}
+	case *parse.TemplateNode:
+		{
+			return checkTemplateNode(typedNode.Pipe)
+		}
+	case *parse.RangeNode:
+		{
+			if !isRangeable(typedNode.Pipe) {
+				return errors.Wrapf(ErrTemplateInvalid, "Ranging is only allowed over fields and variables (%s)", typedNode.Pipe.String())
+			}
+			return checkTemplateBranch(&typedNode.BranchNode)
+		}
+	case *parse.IfNode:
+		{
+			return checkTemplateBranch(&typedNode.BranchNode)
+		}
+	case *parse.WithNode:
+		{
+			return checkTemplateBranch(&typedNode.BranchNode)
+		}
+	}
+
+	return nil
+}
+
+func checkTemplateBranch(branch *parse.BranchNode) error {
+	for _, node := range []parse.Node{branch.Pipe, branch.List, branch.ElseList} {
+		errCheck := checkTemplateNode(node)
+		if errCheck != nil {
+			return errCheck
+		}
+	}
+	return nil
+}
+
+// isRangeable Whether the range pipeline is a single field or variable, which may hold a slice or a map
+func isRangeable(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode, *parse.VariableNode:
+		{
+			return true
+		}
+	}
+	return false
+}
+
+// addTemplateBudget Calls the rendering budget at the start of the list and of every range iteration below it, as
+// fields and variables may still hold integers
+func addTemplateBudget(node parse.Node) {
+	switch typedNode := node.(type) {
+	case *parse.ListNode:
+		{
+			if typedNode == nil {
+				return
+			}
+			for _, childNode := range typedNode.Nodes {
+				addTemplateBudget(childNode)
+			}
+			budgetCommand := &parse.CommandNode{NodeType: parse.NodeCommand, Args: []parse.Node{parse.NewIdentifier(TemplateBudgetFunction)}}
+			budgetAction := &parse.ActionNode{NodeType: parse.NodeAction,
+				Pipe: &parse.PipeNode{NodeType: parse.NodePipe, Cmds: []*parse.CommandNode{budgetCommand}}}
+			typedNode.Nodes = append([]parse.Node{budgetAction}, typedNode.Nodes...)
+		}
+	case *parse.RangeNode:
+		{
+			if typedNode.List == nil {
+				typedNode.List = &parse.ListNode{NodeType: parse.NodeList}
+			}
+			addTemplateBudget(typedNode.List)
+			addBranchTemplateBudget(typedNode.ElseList)
+		}
+	case *parse.IfNode:
+		{
+			addBranchTemplateBudget(typedNode.List)
+			addBranchTemplateBudget(typedNode.ElseList)
+		}
+	case *parse.WithNode:
+		{
+			addBranchTemplateBudget(typedNode.List)
+			addBranchTemplateBudget(typedNode.ElseList)
+		}
+	}
+}
+
+// addBranchTemplateBudget Adds the rendering budget to range iterations nested in the branch, without charging the
+// branch itself
+func addBranchTemplateBudget(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, childNode := range list.Nodes {
+		addTemplateBudget(childNode)
+	}
+}
+
+// templateFunctions Functions available in export templates, all of them are pure string helpers
+var templateFunctions = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix string, value string) string { return strings.TrimPrefix(value, prefix) },
+	"trimSuffix": func(suffix string, value string) string { return strings.TrimSuffix(value, suffix) },
+	"replace":    func(old string, new string, value string) string { return strings.ReplaceAll(value, old, new) },
+	"contains":   func(substring string, value string) bool { return strings.Contains(value, substring) },
+	"hasPrefix":  func(prefix string, value string) bool { return strings.HasPrefix(value, prefix) },
+	"hasSuffix":  func(suffix string, value string) bool { return strings.HasSuffix(value, suffix) },
+	"split":      func(separator string, value string) []string { return strings.Split(value, separator) },
+	"join":       func(separator string, values []string) string { return strings.Join(values, separator) },
+	"default": func(defaultValue string, value string) string {
+		if value == "" {
+			return defaultValue
+		}
+		return value
+	},
+	"indent": func(spaces int, value string) string {
+		padding := strings.Repeat(" ", min(max(spaces, 0), 64))
+		return padding + strings.ReplaceAll(value, "\n", "\n"+padding)
+	},
+	"quote":      strconv.Quote,
+	"shellQuote": singleQuoteEscape,
+	"json":       jsonString,
+	"base64":     func(value string) string { return base64.StdEncoding.EncodeToString([]byte(value)) },
+
+	TemplateBudgetFunction: func() (string, error) { return "", nil }, // Replaced with the actual budget when rendering
+}
+
+// limitedWriter Passes rendered output through, refusing to write beyond the limit with the given error
+type limitedWriter struct {
+	writer  io.Writer
+	limit   int
+	written int
+	err     error
+}
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	if w.written+len(data) > w.limit {
+		return 0, w.err
+	}
+	written, errWrite := w.writer.Write(data)
+	w.written += written
+	return written, errWrite
+}
+
+// exportLines Writes lines of exported file separated by newlines, keeping the first error, so that exporters only
+// check it once done
+type exportLines struct {
+	writer io.Writer
+	count  int
+	err    error
+}
+
+// Line Writes the line, preceded by a newline unless it is the first one
+func (l *exportLines) Line(line string) {
+	if l.count > 0 {
+		l.Text("\n")
+	}
+	l.Text(line)
+	l.count++
+}
+
+// Text Writes the text as is
+func (l *exportLines) Text(text string) {
+	if l.err != nil {
+		return
+	}
+	_, l.err = io.WriteString(l.writer, text)
+}
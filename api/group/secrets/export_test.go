@@ -0,0 +1,127 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"github.com/pkg/errors"
+	"hideout/internal/pkg/archive"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "Rewrite golden files with current output of exporters")
+
+// goldenSecrets Values every shell-like format has to quote: quotes, dollar signs, newlines and unicode
+func goldenSecrets() []Secret {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []Secret{
+		{Name: "PLAIN", Value: "value"},
+		{Name: "EMPTY", Value: ""},
+		{Name: "SINGLE_QUOTE", Value: "it's"},
+		{Name: "DOUBLE_QUOTE", Value: `say "hi"`},
+		{Name: "TYPOGRAPHIC_QUOTE", Value: "\u2018curly\u2019"},
+		{Name: "DOLLAR", Value: "$HOME ${PATH} $$"},
+		{Name: "BACKTICK", Value: "`id` \\n"},
+		{Name: "NEWLINE", Value: "first\nsecond\n"},
+		{Name: "UNICODE", Value: "zażółć 日本語 🔑"},
+		{Name: "EXPIRED", Value: "old", ExpiresAt: &expiresAt, Expired: true},
+	}
+}
+
+func TestExportGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		format uint
+	}{
+		{name: "bash", format: ExportFormat_Bash},
+		{name: "fish", format: ExportFormat_Fish},
+		{name: "powershell", format: ExportFormat_PowerShell},
+		{name: "systemd", format: ExportFormat_Systemd},
+		{name: "compose", format: ExportFormat_Compose},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var output bytes.Buffer
+			errExport := Export(context.Background(), &output, test.format, goldenSecrets(), ExportOptions{FlagExpired: true})
+			if errExport != nil {
+				t.Fatalf("Export failed: %s", errExport)
+			}
+
+			goldenFile := filepath.Join("testdata", "export", test.name+".golden")
+			if *updateGolden {
+				if errWrite := os.WriteFile(goldenFile, output.Bytes(), 0644); errWrite != nil {
+					t.Fatalf("Failed to update golden file: %s", errWrite)
+				}
+			}
+			expected, errRead := os.ReadFile(goldenFile)
+			if errRead != nil {
+				t.Fatalf("Failed to read golden file: %s", errRead)
+			}
+			if !bytes.Equal(output.Bytes(), expected) {
+				t.Errorf("Output differs from %s:\n%s\nexpected:\n%s", goldenFile, output.String(), expected)
+			}
+		})
+	}
+}
+
+func TestExportVariablesInvalidName(t *testing.T) {
+	for _, format := range []uint{ExportFormat_Bash, ExportFormat_Fish, ExportFormat_PowerShell, ExportFormat_Systemd} {
+		errExport := Export(context.Background(), &bytes.Buffer{}, format, []Secret{{Name: "NOT-VALID", Value: "value"}}, ExportOptions{})
+		if !errors.Is(errExport, ErrInvalidVariableName) {
+			t.Errorf("Format %d: expected %s, got %v", format, ErrInvalidVariableName, errExport)
+		}
+	}
+}
+
+func TestExportComposeConflict(t *testing.T) {
+	secrets := []Secret{{Name: "SAME", Value: "a"}, {Name: "SAME", Value: "b", Path: []string{"folder"}}}
+	errExport := Export(context.Background(), &bytes.Buffer{}, ExportFormat_Compose, secrets, ExportOptions{})
+	if !errors.Is(errExport, ErrExportKeyConflict) {
+		t.Errorf("Expected %s, got %v", ErrExportKeyConflict, errExport)
+	}
+}
+
+func TestExportFileRendered(t *testing.T) {
+	// Every rendering differs, as it would with dynamic secrets or timestamps
+	var renderings int
+	file := ExportFile{File: archive.File{Name: "secrets.env", Write: func(writer io.Writer) error {
+		renderings++
+		_, errWrite := io.WriteString(writer, strings.Repeat("x", renderings))
+		return errWrite
+	}}}
+	if errRender := file.render(); errRender != nil {
+		t.Fatalf("render failed: %s", errRender)
+	}
+
+	for i := 0; i < 2; i++ {
+		var written bytes.Buffer
+		if errWrite := file.Write(&written); errWrite != nil {
+			t.Fatalf("Write failed: %s", errWrite)
+		}
+		checksum := sha256.Sum256(written.Bytes())
+		if int64(written.Len()) != file.Size || hex.EncodeToString(checksum[:]) != file.SHA256 {
+			t.Errorf("Written %q does not match measured size %d and checksum %s", written.String(), file.Size, file.SHA256)
+		}
+	}
+	if renderings != 1 {
+		t.Errorf("Expected file to be rendered once, got %d renderings", renderings)
+	}
+}
+
+func TestExportFileLimit(t *testing.T) {
+	file := ExportFile{File: archive.File{Name: "secrets.env", Write: func(writer io.Writer) error {
+		_, errWrite := writer.Write(make([]byte, ExportFileLimit+1))
+		return errWrite
+	}}}
+	if errRender := file.render(); !errors.Is(errRender, ErrExportFileLimit) {
+		t.Errorf("Expected %s, got %v", ErrExportFileLimit, errRender)
+	}
+}
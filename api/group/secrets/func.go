@@ -1,7 +1,9 @@
 package secrets
 
 import (
+	"archive/zip"
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/mholt/archives"
@@ -11,12 +13,14 @@ import (
 	"hideout/internal/common/apperror"
 	"hideout/internal/common/generics"
 	"hideout/internal/common/model"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/archive"
 	secrets2 "hideout/internal/secrets"
 	"hideout/services/secrets"
-	"log"
-	"os"
-	"strconv"
+	"io"
+	"net/http"
 	"strings"
+	"time"
 )
 
 func (s *Secret) Process(ctx context.Context, secretsSvc *secrets.SecretsService) (string, string, error) {
@@ -38,7 +42,7 @@ func (s *Secret) Process(ctx context.Context, secretsSvc *secrets.SecretsService
 	var globalValues = map[string]any{}
 	// Reference secrets by {{id}} and {{uid}} constructs
 	for _, secretEntry := range secretsList {
-		if secretEntry.UID == s.UID {
+		if secretEntry.UID == s.UID || secretEntry.IsHidden() {
 			continue
 		}
 		globalValues[fmt.Sprintf("{{%s}}", secretEntry.UID)] = secretEntry.Value
@@ -67,161 +71,251 @@ func (s *Secret) Process(ctx context.Context, secretsSvc *secrets.SecretsService
 	return "", string(valueType), fmt.Errorf("Cannot process result with type of %s", string(valueType))
 }
 
-func doubleQuoteEscape(line string) string {
-	const doubleQuoteSpecialChars = "\\\n\r\"!$`"
-	for _, c := range doubleQuoteSpecialChars {
-		toReplace := "\\" + string(c)
-		if c == '\n' {
-			toReplace = `\n`
-		}
-		if c == '\r' {
-			toReplace = `\r`
-		}
-		line = strings.Replace(line, string(c), toReplace, -1)
-	}
-	return line
-}
-
-func ExportToDotEnv(ctx context.Context, secrets []Secret) (string, error) {
-	if len(secrets) == 0 {
-		return "", nil
+func toNullTime(value *time.Time) sql.NullTime {
+	if value == nil || value.IsZero() {
+		return sql.NullTime{}
 	}
 
-	lines := make([]string, 0, len(secrets))
+	return sql.NullTime{Time: *value, Valid: true}
+}
 
-	for _, secret := range secrets {
-		if d, err := strconv.Atoi(secret.Value); err == nil {
-			lines = append(lines, fmt.Sprintf(`%s=%d`, secret.Name, d))
-		} else {
-			lines = append(lines, fmt.Sprintf(`%s="%s"`, secret.Name, doubleQuoteEscape(secret.Value)))
-		}
+func fromNullTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
 	}
 
-	return strings.Join(lines, "\n"), nil
+	return &value.Time
 }
 
-func ArchiveExport(ctx context.Context, data []byte, archiveType uint, compressionType uint, exportType uint) (string, error) {
-	var uuid = gofakeit.UUID()
-	exportTypeVal, _ := ExportExtensionsMap[exportType]
-	secretsFile := fmt.Sprintf("secrets-%s%s", strings.ReplaceAll(uuid, "-", ""), exportTypeVal)
-
-	secretsTemporaryFile, errCreateTemporaryFile := os.CreateTemp("", secretsFile)
-	if errCreateTemporaryFile != nil {
-		return "", errCreateTemporaryFile
-	}
-
-	defer func() {
-		if err := secretsTemporaryFile.Close(); err != nil {
-			log.Printf("Error closing secrets temporary file: %v", err)
+// ArchiveFormat Archive format with compression, which is checked before anything gets written to the client
+func ArchiveFormat(archiveType uint, compressionType uint) (archives.CompressedArchive, error) {
+	format := archives.CompressedArchive{}
+	switch archiveType {
+	case ArchiveType_Tar:
+		{
+			format.Archival = archives.Tar{}
+			break
 		}
-		if err := os.Remove(secretsTemporaryFile.Name()); err != nil {
-			log.Printf("Error removing secrets temporary file: %v", err)
+	case ArchiveType_Zip:
+		{
+			format.Archival = archives.Zip{}
+			break
 		}
-	}()
+	}
 
-	_, errWrite := secretsTemporaryFile.Write(data)
-	if errWrite != nil {
-		return "", errWrite
+	// Zip archives compress every file with deflate by themselves rather than being compressed as a whole
+	if compressionType == CompressionType_Flate && archiveType == ArchiveType_Zip {
+		format.Archival = archives.Zip{Compression: zip.Deflate}
+		return format, nil
 	}
 
-	// map files on disk to their paths in the archive using default settings (second arg)
-	archiveFiles, errCreateArchive := archives.FilesFromDisk(ctx, nil, map[string]string{
-		secretsTemporaryFile.Name(): secretsFile,
-	})
-	if errCreateArchive != nil {
-		return "", errCreateArchive
+	compression, errCompression := CompressionFormat(compressionType)
+	if errCompression != nil {
+		return format, errCompression
 	}
+	format.Compression = compression
+	return format, nil
+}
 
-	format := archives.CompressedArchive{}
+// CompressionFormat Compression of the whole stream (nil if none), which is checked before anything gets written to the
+// client
+func CompressionFormat(compressionType uint) (archives.Compression, error) {
+	var compression archives.Compression
 	switch compressionType {
 	case CompressionType_Brotli:
 		{
-			format.Compression = archives.Brotli{}
+			compression = archives.Brotli{}
 			break
 		}
 	case CompressionType_Bzip2:
 		{
-			format.Compression = archives.Bz2{}
+			compression = archives.Bz2{}
 			break
 		}
 	case CompressionType_Flate:
 		{
-			// @TODO Figure out how to do ZIP compression
-			return "", apperror.ErrNotImplemented
+			// Deflate is the compression method of zip entries, there is no such compression of a whole stream
+			return nil, ErrFlateZipOnly
 		}
 	case CompressionType_Gzip:
 		{
-			format.Compression = archives.Gz{}
+			compression = archives.Gz{}
 			break
 		}
 	case CompressionType_Lz4:
 		{
-			format.Compression = archives.Lz4{}
+			compression = archives.Lz4{}
 			break
 		}
 	case CompressionType_Lzip:
 		{
-			format.Compression = archives.Lzip{}
+			compression = archives.Lzip{}
 			break
 		}
 	case CompressionType_Minlz:
 		{
-			format.Compression = archives.MinLZ{}
+			compression = archives.MinLZ{}
 			break
 		}
 	case CompressionType_Snappy:
 		{
-			format.Compression = archives.Sz{}
+			compression = archives.Sz{}
 			break
 		}
 	case CompressionType_XZ:
 		{
-			format.Compression = archives.Xz{}
+			compression = archives.Xz{}
 			break
 		}
 	case CompressionType_Zlib:
 		{
-			format.Compression = archives.Zlib{}
+			compression = archives.Zlib{}
 			break
 		}
 	case CompressionType_Zstandard:
 		{
-			format.Compression = archives.Zstd{}
+			compression = archives.Zstd{}
 			break
 		}
 	}
 
-	switch archiveType {
-	case ArchiveType_Tar:
-		{
-			format.Archival = archives.Tar{}
-			break
-		}
-	case ArchiveType_Zip:
-		{
-			format.Archival = archives.Zip{}
+	return compression, nil
+}
+
+// CompressExport Writes export through the compressor into the writer, the compressor is closed even if writing fails
+func CompressExport(writer io.Writer, compression archives.Compression, writeExport func(writer io.Writer) error) error {
+	compressWriter, errCompress := compression.OpenWriter(writer)
+	if errCompress != nil {
+		return errCompress
+	}
+	errWrite := writeExport(compressWriter)
+	errClose := compressWriter.Close()
+	if errWrite != nil {
+		return errWrite
+	}
+	return errClose
+}
+
+// CompressedFileName Name of the file exported without archiving once compressed
+func CompressedFileName(fileName string, compressionType uint) string {
+	return fileName + CompressionExtensionsMap[compressionType]
+}
+
+// CompressedContentType Media type of the file exported without archiving once compressed
+func CompressedContentType(compressionType uint) string {
+	if contentType, exists := CompressionContentTypesMap[compressionType]; exists {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// ArchiveExport Streams exported files packed into a (compressed) archive into the writer, files are rendered right into
+// their archive entries
+func ArchiveExport(ctx context.Context, writer io.Writer, files []ExportFile, format archives.CompressedArchive) error {
+	archiveFiles := make([]archive.File, 0, len(files))
+	for _, file := range files {
+		archiveFiles = append(archiveFiles, file.File)
+	}
+	return archive.Pack(ctx, writer, archiveFiles, format)
+}
+
+// ArchiveFileName Name of the archive with extensions of archive and compression types
+func ArchiveFileName(archiveType uint, compressionType uint) string {
+	var uuid = gofakeit.UUID()
+	return fmt.Sprintf("secrets-%s.%s%s", strings.ReplaceAll(uuid, "-", ""), ArchiveTypesMap[archiveType],
+		CompressionExtensionsMap[compressionType])
+}
+
+// ExportContentType Media type of the file exported without archiving
+func ExportContentType(exportType uint) string {
+	if contentType, exists := ExportContentTypesMap[exportType]; exists {
+		return contentType
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// ArchiveContentType Media type of the archive, compressed archives are described by their compression
+func ArchiveContentType(archiveType uint, compressionType uint) string {
+	if contentType, exists := CompressionContentTypesMap[compressionType]; exists {
+		return contentType
+	}
+	if contentType, exists := ArchiveContentTypesMap[archiveType]; exists {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// folderPath Names of folders from the one below rootFolderID down to the folder with given identifier
+func folderPath(foldersMap map[uint]*folders.Folder, folderID uint, rootFolderID uint) []string {
+	var path []string
+	// Bounded by the amount of folders in case of a broken (cyclic) hierarchy
+	for depth := 0; folderID != 0 && folderID != rootFolderID && depth < len(foldersMap); depth++ {
+		folder, exists := foldersMap[folderID]
+		if !exists {
 			break
 		}
+		path = append([]string{folder.Name}, path...)
+		folderID = folder.ParentID
 	}
+	return path
+}
 
-	archiveTypeVal, _ := ArchiveTypesMap[archiveType]
-	secretsArchiveFile := fmt.Sprintf("secrets-%s.%s", strings.ReplaceAll(uuid, "-", ""), archiveTypeVal)
+// GetText Text of the template given in request or stored in the referenced secret, secrets hidden from exports cannot
+// be used as templates
+func (o TemplateOptions) GetText(ctx context.Context, secretsSvc *secrets.SecretsService) (string, error) {
+	if o.SecretUID == "" {
+		return o.Text, nil
+	}
 
-	secretsArchiveTemporaryFile, errCreateSecretsArchiveTemporaryFile := os.CreateTemp("", secretsArchiveFile)
-	if errCreateSecretsArchiveTemporaryFile != nil {
-		return "", errCreateSecretsArchiveTemporaryFile
+	templateSecret, errGetSecret := secretsSvc.GetSecretByUID(ctx, o.SecretUID)
+	if errGetSecret != nil {
+		return "", errGetSecret
+	}
+	if templateSecret.IsHidden() {
+		return "", errors.Wrapf(apperror.ErrRecordNotFound, "Secret with UID of %s", o.SecretUID)
 	}
 
-	defer func() {
-		if err := secretsArchiveTemporaryFile.Close(); err != nil {
-			log.Printf("Error closing secrets archive temporary file: %v", err)
-		}
-	}()
+	return templateSecret.Value, nil
+}
+
+// exportErrorStatus HTTP status matching the error of rendering exported secrets
+func exportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrExportKeyConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidKubernetesKey), errors.Is(err, ErrInvalidVariableName), errors.Is(err, ErrTemplateInvalid),
+		errors.Is(err, ErrTemplateOutputLimit), errors.Is(err, ErrTemplateIterationLimit), errors.Is(err, ErrInvalidRecipient),
+		errors.Is(err, ErrFlateZipOnly), errors.Is(err, ErrExportFileLimit):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// descendantFolderIDs Identifiers of folders below the root one up to the given depth (unlimited if 0)
+func descendantFolderIDs(foldersMap map[uint]*folders.Folder, rootFolderID uint, depth uint) []uint {
+	var childrenMap = make(map[uint][]uint)
+	for _, folder := range foldersMap {
+		childrenMap[folder.ParentID] = append(childrenMap[folder.ParentID], folder.ID)
+	}
 
-	errArchive := format.Archive(ctx, secretsArchiveTemporaryFile, archiveFiles)
-	if errArchive != nil {
-		return "", errArchive
+	var folderIDs []uint
+	var visitedMap = map[uint]bool{rootFolderID: true}
+	levelIDs := []uint{rootFolderID}
+	for level := uint(1); len(levelIDs) > 0 && (depth == 0 || level <= depth); level++ {
+		var nextLevelIDs []uint
+		for _, folderID := range levelIDs {
+			for _, childID := range childrenMap[folderID] {
+				// Guards against a broken (cyclic) hierarchy
+				if visitedMap[childID] {
+					continue
+				}
+				visitedMap[childID] = true
+				nextLevelIDs = append(nextLevelIDs, childID)
+			}
+		}
+		folderIDs = append(folderIDs, nextLevelIDs...)
+		levelIDs = nextLevelIDs
 	}
 
-	return secretsArchiveTemporaryFile.Name(), nil
+	return folderIDs
 }
@@ -0,0 +1,135 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"github.com/pkg/errors"
+	"hideout/internal/pkg/archive"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// compressionTypes Every compression type, including none
+var compressionTypes = []uint{CompressionType_None, CompressionType_Brotli, CompressionType_Bzip2, CompressionType_Flate,
+	CompressionType_Gzip, CompressionType_Lz4, CompressionType_Lzip, CompressionType_Minlz, CompressionType_Snappy,
+	CompressionType_XZ, CompressionType_Zlib, CompressionType_Zstandard}
+
+func TestArchiveFormat(t *testing.T) {
+	secrets := []Secret{{Name: "ROOT", Value: "root value"}, {Name: "NESTED", Value: "nested\nvalue", Path: []string{"folder"}}}
+	expected := map[string]string{"secrets.env": `ROOT="root value"`, "folder/secrets.env": `NESTED="nested\nvalue"`}
+
+	for _, archiveType := range []uint{ArchiveType_Tar, ArchiveType_Zip} {
+		for _, compressionType := range compressionTypes {
+			name := ArchiveTypesMap[archiveType]
+			if extensions, exists := CompressionTypesMap[compressionType]; exists {
+				name += "/" + extensions[0]
+			}
+			t.Run(name, func(t *testing.T) {
+				format, errFormat := ArchiveFormat(archiveType, compressionType)
+				if archiveType == ArchiveType_Tar && compressionType == CompressionType_Flate {
+					if !errors.Is(errFormat, ErrFlateZipOnly) {
+						t.Fatalf("Expected %s, got %v", ErrFlateZipOnly, errFormat)
+					}
+					return
+				}
+				if errFormat != nil {
+					t.Fatalf("ArchiveFormat failed: %s", errFormat)
+				}
+
+				files, errExport := ExportRecursive(context.Background(), ExportFormat_DotEnv, secrets, ExportOptions{}, "secrets.env")
+				if errExport != nil {
+					t.Fatalf("ExportRecursive failed: %s", errExport)
+				}
+				var packed bytes.Buffer
+				errArchive := ArchiveExport(context.Background(), &packed, files, format)
+				if errArchive != nil {
+					t.Fatalf("ArchiveExport failed: %s", errArchive)
+				}
+
+				extracted, errExtract := archive.Extract(context.Background(), ArchiveFileName(archiveType, compressionType), &packed,
+					archive.DefaultSizeLimit)
+				if errExtract != nil {
+					t.Fatalf("Extract failed: %s", errExtract)
+				}
+				for fileName, content := range expected {
+					if string(extracted[fileName]) != content {
+						t.Errorf("File %s: expected %q, got %q", fileName, content, extracted[fileName])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCompressionFormat(t *testing.T) {
+	for _, compressionType := range compressionTypes {
+		if compressionType == CompressionType_None {
+			continue
+		}
+		t.Run(CompressionTypesMap[compressionType][0], func(t *testing.T) {
+			compression, errCompression := CompressionFormat(compressionType)
+			if compressionType == CompressionType_Flate {
+				if !errors.Is(errCompression, ErrFlateZipOnly) {
+					t.Fatalf("Expected %s, got %v", ErrFlateZipOnly, errCompression)
+				}
+				return
+			}
+			if errCompression != nil {
+				t.Fatalf("CompressionFormat failed: %s", errCompression)
+			}
+
+			content := "KEY=\"value\""
+			var compressed bytes.Buffer
+			errCompress := CompressExport(&compressed, compression, func(writer io.Writer) error {
+				_, errWrite := io.WriteString(writer, content)
+				return errWrite
+			})
+			if errCompress != nil {
+				t.Fatalf("CompressExport failed: %s", errCompress)
+			}
+
+			extracted, errExtract := archive.Extract(context.Background(), CompressedFileName("secrets.env", compressionType),
+				&compressed, archive.DefaultSizeLimit)
+			if errExtract != nil {
+				t.Fatalf("Extract failed: %s", errExtract)
+			}
+			if string(extracted["secrets.env"]) != content {
+				t.Errorf("Expected %q, got %v", content, extracted)
+			}
+		})
+	}
+}
+
+// TestExtractFixture Archive types which are only imported, so their archives cannot be written by ArchiveExport
+func TestExtractFixture(t *testing.T) {
+	expected := map[string]string{"secrets.env": `ROOT="root value"`, "folder/secrets.env": `NESTED="nested\nvalue"`}
+	tests := []struct {
+		name     string
+		fileName string
+	}{
+		{name: "7z", fileName: "secrets.7z"}, // Stored (uncompressed) entries
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, errRead := os.ReadFile(filepath.Join("testdata", "import", test.fileName))
+			if errRead != nil {
+				t.Fatalf("Failed to read fixture: %s", errRead)
+			}
+			extracted, errExtract := archive.Extract(context.Background(), test.fileName, bytes.NewReader(data), archive.DefaultSizeLimit)
+			if errExtract != nil {
+				t.Fatalf("Extract failed: %s", errExtract)
+			}
+			if len(extracted) != len(expected) {
+				t.Errorf("Expected %d files, got %d", len(expected), len(extracted))
+			}
+			for fileName, content := range expected {
+				if string(extracted[fileName]) != content {
+					t.Errorf("File %s: expected %q, got %q", fileName, content, extracted[fileName])
+				}
+			}
+		})
+	}
+}
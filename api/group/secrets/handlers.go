@@ -1,8 +1,9 @@
 package secrets
 
 import (
-	"bytes"
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/brianvoe/gofakeit/v7"
@@ -16,15 +17,17 @@ import (
 	"hideout/internal/common/model"
 	"hideout/internal/common/rqrs"
 	"hideout/internal/folders"
+	"hideout/internal/pkg/archive"
+	"hideout/internal/pkg/signing"
 	secrets2 "hideout/internal/secrets"
 	"hideout/services/secrets"
 	"hideout/structs"
 	"io"
 	"log"
+	"mime"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
+	"time"
 )
 
 // GetSecretsHandler
@@ -117,6 +120,12 @@ func GetSecretsHandler(c *gin.Context) {
 	listSecretParams := secrets2.ListSecretParams{
 		ListParams: generics.ListParams{Deleted: model.No, Pagination: request.SecretsPagination, Order: request.SecretsOrder},
 		Scriptable: model.YesOrNo,
+		ExpiresAt:  generics.FromTo[time.Time]{From: request.ExpiresFrom, To: request.ExpiresTo},
+	}
+	if request.ExpiresWithin != 0 {
+		listSecretParams.ExpiresAt = generics.FromTo[time.Time]{
+			To: time.Now().Add(time.Duration(request.ExpiresWithin) * time.Second),
+		}
 	}
 	if parentFolder != nil {
 		listSecretParams.FolderIDs = append(listSecretParams.IDs, parentFolder.ID)
@@ -148,7 +157,8 @@ func GetSecretsHandler(c *gin.Context) {
 	}
 
 	for _, secret := range secretResults {
-		secretEntry := Secret{ID: secret.ID, UID: secret.UID, Name: secret.Name, Value: secret.Value, Script: secret.Script}
+		secretEntry := Secret{ID: secret.ID, UID: secret.UID, Name: secret.Name, Value: secret.VisibleValue(), Script: secret.Script,
+			Type: secret.Type, ExpiresAt: fromNullTime(secret.ExpiresAt), Expired: secret.IsExpired(time.Now())}
 		if parentFolder != nil {
 			secretEntry.FolderUID = parentFolder.UID
 		}
@@ -251,9 +261,28 @@ func UpdateSecretsHandler(c *gin.Context) {
 			response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetFolderByUID.Error(), Code: 0})
 			continue
 		}
+		existingSecret, errGetSecretByUID := secretsSvc.GetSecretByUID(rqContext, updateSecretEntry.UID)
+		if errGetSecretByUID != nil {
+			log.Printf("Error retrieving secret with UID of %s: %s", updateSecretEntry.UID, errGetSecretByUID.Error())
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetSecretByUIDError"},
+				TemplateData: map[string]interface{}{"UID": updateSecretEntry.UID}})
+			response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetSecretByUID.Error(), Code: 0})
+			continue
+		}
+		if existingSecret.IsHidden() {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SealedSecretError"},
+				TemplateData: map[string]interface{}{"UID": updateSecretEntry.UID}})
+			response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+			continue
+		}
+		expiresAt := existingSecret.ExpiresAt
+		if updateSecretEntry.ExpiresAt.Set {
+			expiresAt = toNullTime(updateSecretEntry.ExpiresAt.Value)
+		}
 		updatedSecret, errUpdateSecret := secretsSvc.UpdateSecret(rqContext, Localizer, secrets2.Secret{
-			FolderID: folderByUID.ID, Name: updateSecretEntry.Name, Value: updateSecretEntry.Value,
-			Script: updateSecretEntry.Script,
+			Model: existingSecret.Model, UID: existingSecret.UID, FolderID: folderByUID.ID, Name: updateSecretEntry.Name,
+			Value: updateSecretEntry.Value, Script: updateSecretEntry.Script, Type: existingSecret.Type,
+			ExpiresAt: expiresAt,
 		})
 		if errUpdateSecret != nil {
 			log.Printf("Error updating secret with UID of %s: %s", updateSecretEntry.UID, errUpdateSecret.Error())
@@ -264,7 +293,8 @@ func UpdateSecretsHandler(c *gin.Context) {
 		}
 		response.Data = append(response.Data, Secret{
 			ID: updatedSecret.ID, UID: updatedSecret.UID, FolderUID: folderByUID.UID, Name: updatedSecret.Name,
-			Value: updatedSecret.Value, Script: updatedSecret.Script,
+			Value: updatedSecret.Value, Script: updatedSecret.Script, Type: updatedSecret.Type, ExpiresAt: fromNullTime(updatedSecret.ExpiresAt),
+			Expired: updatedSecret.IsExpired(time.Now()),
 		})
 	}
 
@@ -470,7 +500,7 @@ func CreateSecretsHandler(c *gin.Context) {
 		}
 		newSecret, errCreateSecret := secretsSvc.CreateSecret(rqContext, Localizer, secrets2.Secret{
 			FolderID: folderByUID.ID, UID: gofakeit.UUID(), Name: secretToCreate.Name,
-			Value: secretToCreate.Value, Script: secretToCreate.Script,
+			Value: secretToCreate.Value, Script: secretToCreate.Script, ExpiresAt: toNullTime(secretToCreate.ExpiresAt),
 		})
 		if errCreateSecret != nil {
 			log.Printf("Error creating secret with name of %s: %s", secretToCreate.Name, errCreateSecret.Error())
@@ -480,7 +510,8 @@ func CreateSecretsHandler(c *gin.Context) {
 		}
 		response.Data = append(response.Data, Secret{
 			ID: newSecret.ID, UID: newSecret.UID, FolderUID: folderByUID.UID, Name: newSecret.Name,
-			Value: newSecret.Value, Script: newSecret.Script,
+			Value: newSecret.Value, Script: newSecret.Script, Type: newSecret.Type, ExpiresAt: fromNullTime(newSecret.ExpiresAt),
+			Expired: newSecret.IsExpired(time.Now()),
 		})
 	}
 
@@ -628,7 +659,8 @@ func CopyPasteSecretsHandler(c *gin.Context) {
 		}
 		response.Secrets = append(response.Secrets, Secret{
 			ID: copiedSecret.ID, UID: copiedSecret.UID, FolderUID: copiedSecretFolder.UID,
-			Name: copiedSecret.Name, Value: copiedSecret.Value, Script: copiedSecret.Script,
+			Name: copiedSecret.Name, Value: copiedSecret.VisibleValue(), Script: copiedSecret.Script,
+			Type: copiedSecret.Type, ExpiresAt: fromNullTime(copiedSecret.ExpiresAt), Expired: copiedSecret.IsExpired(time.Now()),
 		})
 	}
 
@@ -665,12 +697,13 @@ func CopyPasteSecretsHandler(c *gin.Context) {
 
 // ExportSecretsHandler
 // @Summary Export secrets into various formats
-// @Description Export secrets into various formats
+// @Description Export secrets into various formats, optionally archived with a signed manifest (or compressed as a single file) and encrypted for age or OpenPGP recipients
 // @ID export-secrets
 // @Tags Secrets
 // @Param params body ExportSecretsRQ true "Secrets export request"
 // @Produce application/octet-stream
 // @Success 200 {string} string ""
+// @Failure 400 {object} ExportSecretsRS
 // @Failure 401 {string} string ""
 // @Failure 404 {string} string ""
 // @Failure 500 {string} string ""
@@ -748,11 +781,31 @@ func ExportSecretsHandler(c *gin.Context) {
 		parentFolder = folderByUID
 	}
 
+	foldersMap, errGetFolders := secretsSvc.GetFoldersMapByID(rqContext, folders.ListFolderParams{
+		ListParams: generics.ListParams{Deleted: model.No},
+	})
+	if errGetFolders != nil {
+		log.Printf("Error fetching folders: %s", errGetFolders.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetFoldersError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
 	listSecretParams := secrets2.ListSecretParams{
 		ListParams: generics.ListParams{Deleted: model.No, Pagination: request.Pagination, Order: request.Order},
 		Scriptable: model.YesOrNo,
 	}
-	if parentFolder != nil {
+	if request.Recursive {
+		var rootFolderID uint
+		if parentFolder != nil {
+			rootFolderID = parentFolder.ID
+		}
+		// Secrets of all folders are listed anyway when exporting everything without depth limit
+		if parentFolder != nil || request.Depth != 0 {
+			listSecretParams.FolderIDs = append(descendantFolderIDs(foldersMap, rootFolderID, request.Depth), rootFolderID)
+		}
+	} else if parentFolder != nil {
 		listSecretParams.FolderIDs = append(listSecretParams.IDs, parentFolder.ID)
 	}
 	secretResults, errGetSecrets := secretsSvc.GetSecrets(rqContext, listSecretParams)
@@ -764,10 +817,23 @@ func ExportSecretsHandler(c *gin.Context) {
 		return
 	}
 
+	expiredSecrets, _ := ExpiredSecretsMapInv[request.ExpiredSecrets]
 	for _, secret := range secretResults {
-		secretEntry := Secret{ID: secret.ID, UID: secret.UID, Name: secret.Name, Value: secret.Value, Script: secret.Script}
+		// Sealed and hidden secrets never leave the storage via exports
+		if secret.IsHidden() {
+			continue
+		}
+		isExpired := secret.IsExpired(time.Now())
+		if isExpired && expiredSecrets == ExpiredSecrets_Exclude {
+			continue
+		}
+		secretEntry := Secret{ID: secret.ID, UID: secret.UID, Name: secret.Name, Value: secret.VisibleValue(), Script: secret.Script,
+			Type: secret.Type, ExpiresAt: fromNullTime(secret.ExpiresAt), Expired: isExpired}
 		if parentFolder != nil {
 			secretEntry.FolderUID = parentFolder.UID
+			secretEntry.Path = folderPath(foldersMap, secret.FolderID, parentFolder.ID)
+		} else {
+			secretEntry.Path = folderPath(foldersMap, secret.FolderID, 0)
 		}
 		response.Secrets = append(response.Secrets, secretEntry)
 	}
@@ -790,65 +856,437 @@ func ExportSecretsHandler(c *gin.Context) {
 
 	exportSpan := sentry.StartSpan(rqContext, "export.secrets")
 	exportSpan.Description = "run"
-	var exportedData []byte
-	switch request.Format {
-	case ExportFormatsMap[ExportFormat_DotEnv]:
-		{
-			exportedDataVal, errExportToDotEnv := ExportToDotEnv(rqContext, response.Secrets)
-			if errExportToDotEnv != nil {
-				msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ExportSecretsError"}})
-				response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errExportToDotEnv.Error(), Code: 0})
-				c.JSON(http.StatusInternalServerError, response)
-				return
-			}
-			exportedData = []byte(exportedDataVal)
+	exportType, _ := ExportFormatsMapInv[request.Format]
+	var uuid = gofakeit.UUID()
+	exportTypeVal, _ := ExportExtensionsMap[exportType]
+	if exportType == ExportFormat_Template && request.Template.Extension != "" {
+		exportTypeVal = request.Template.Extension
+	}
+	secretsFile := fmt.Sprintf("secrets-%s%s", strings.ReplaceAll(uuid, "-", ""), exportTypeVal)
+	exportOptions := ExportOptions{FlagExpired: expiredSecrets == ExpiredSecrets_Flag, Kubernetes: request.Kubernetes,
+		Terraform: request.Terraform}
+	if exportType == ExportFormat_Template {
+		templateText, errGetTemplate := request.Template.GetText(rqContext, secretsSvc)
+		if errGetTemplate != nil {
+			log.Printf("Error retrieving template secret with UID of %s: %s", request.Template.SecretUID, errGetTemplate.Error())
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetSecretByUIDError"},
+				TemplateData: map[string]interface{}{"UID": request.Template.SecretUID}})
+			response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetTemplate.Error(), Code: 0})
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		exportOptions.TemplateText = templateText
+	}
+	if request.Sign {
+		signingKey, errParseKey := signing.ParseKey(apiconfig.Settings.Security.SigningKey)
+		if errParseKey != nil {
+			log.Printf("Error parsing signing key: %s", errParseKey.Error())
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SigningKeyError"}})
+			response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+			c.JSON(http.StatusInternalServerError, response)
+			return
+		}
+		exportOptions.SigningKey = signingKey
+	}
+	var exportedFiles []ExportFile
+	var errExport error
+	if request.Recursive {
+		exportedFiles, errExport = ExportRecursive(rqContext, exportType, response.Secrets, exportOptions,
+			fmt.Sprintf("%s%s", RecursiveFileName, exportTypeVal))
+	} else {
+		exportedFiles, errExport = ExportFiles(rqContext, exportType, response.Secrets, exportOptions, secretsFile)
+		if errExport == nil && exportOptions.SigningKey != nil {
+			exportedFiles, errExport = SignExport(exportType, exportedFiles, exportOptions.SigningKey)
+		}
+	}
+	if errExport != nil {
+		messageID := "ExportSecretsError"
+		if errors.Is(errExport, ErrTemplateInvalid) || errors.Is(errExport, ErrTemplateOutputLimit) ||
+			errors.Is(errExport, ErrTemplateIterationLimit) {
+			messageID = "TemplateError"
+		}
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: messageID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errExport.Error(), Code: 0})
+		c.JSON(exportErrorStatus(errExport), response)
+		return
+	}
+
+	encryptionType, _ := EncryptionTypesMapInv[request.Encryption.Type]
+	var encryptor ExportEncryptor
+	if encryptionType != EncryptionType_None {
+		recipientKeys, errGetRecipientKeys := request.Encryption.GetRecipientKeys(rqContext, secretsSvc)
+		if errGetRecipientKeys == nil {
+			encryptor, errGetRecipientKeys = NewExportEncryptor(encryptionType, recipientKeys, request.Encryption.Passphrase,
+				request.Encryption.Armor)
+		}
+		if errGetRecipientKeys != nil {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionRecipientError"}})
+			response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetRecipientKeys.Error(), Code: 0})
+			c.JSON(exportErrorStatus(errGetRecipientKeys), response)
+			return
 		}
 	}
 
 	archiveType, _ := ArchiveTypesMapInv[request.ArchiveType]
 	compressionType, _ := CompressionTypesMapInv[request.CompressionType]
-	exportType, _ := ExportFormatsMapInv[request.Format]
+	var writeExport func(writer io.Writer) error
+	var exportFileName, exportContentType string
+	// Validation makes sure several files are only exported into archives
 	if archiveType == ArchiveType_None {
-		var uuid = gofakeit.UUID()
-		exportTypeVal, _ := ExportExtensionsMap[exportType]
-		secretsFile := fmt.Sprintf("secrets-%s%s", strings.ReplaceAll(uuid, "-", ""), exportTypeVal)
-		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", secretsFile))
-		c.Data(http.StatusOK, "application/octet-stream", exportedData)
+		writeExport = func(writer io.Writer) error {
+			if len(exportedFiles) == 0 {
+				return nil
+			}
+			return exportedFiles[0].Write(writer)
+		}
+		exportFileName, exportContentType = secretsFile, ExportContentType(exportType)
+
+		if compressionType != CompressionType_None {
+			compression, errCompression := CompressionFormat(compressionType)
+			if errCompression != nil {
+				msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ArchiveSecretsError"}})
+				response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCompression.Error(), Code: 0})
+				c.JSON(exportErrorStatus(errCompression), response)
+				return
+			}
+			writePlain := writeExport
+			writeExport = func(writer io.Writer) error {
+				return CompressExport(writer, compression, writePlain)
+			}
+			exportFileName, exportContentType = CompressedFileName(secretsFile, compressionType), CompressedContentType(compressionType)
+		}
+	} else {
+		archiveFormat, errArchiveFormat := ArchiveFormat(archiveType, compressionType)
+		if errArchiveFormat != nil {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ArchiveSecretsError"}})
+			response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errArchiveFormat.Error(), Code: 0})
+			c.JSON(exportErrorStatus(errArchiveFormat), response)
+			return
+		}
+		writeExport = func(writer io.Writer) error {
+			return ArchiveExport(rqContext, writer, exportedFiles, archiveFormat)
+		}
+		exportFileName, exportContentType = ArchiveFileName(archiveType, compressionType), ArchiveContentType(archiveType, compressionType)
+	}
+	if encryptor != nil {
+		exportFileName = EncryptedFileName(exportFileName, encryptionType, request.Encryption.Armor)
+		exportContentType = EncryptedContentType(encryptionType, request.Encryption.Armor)
+	}
+
+	// Export is streamed right into the response, so the status cannot be changed once writing has started
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": exportFileName}))
+	c.Header("Content-Type", exportContentType)
+	c.Status(http.StatusOK)
+	var errWriteExport error
+	if encryptor != nil {
+		errWriteExport = EncryptExport(c.Writer, encryptor, writeExport)
+	} else {
+		errWriteExport = writeExport(c.Writer)
+	}
+	if errWriteExport != nil {
+		log.Printf("Error streaming exported secrets: %s", errWriteExport.Error())
+		_ = c.Error(errWriteExport)
+	}
+
+	exportSpan.Finish()
+}
+
+// CreateExportRecipientHandler
+// @Summary Save public key of export recipient
+// @Description Save age or OpenPGP public key as a named secret of the folder, so that encrypted exports can reference it by UID
+// @ID create-export-recipient
+// @Tags Secrets
+// @Produce json
+// @Param params body CreateExportRecipientRQ true "Export recipient request"
+// @Success 200 {object} CreateExportRecipientRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} CreateExportRecipientRS
+// @Failure 404 {object} CreateExportRecipientRS
+// @Failure 500 {object} CreateExportRecipientRS
+// @Router /secrets/export/recipients/ [put]
+func CreateExportRecipientHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.create.export.recipient")
+	validationSpan.Description = "rq.validate"
+
+	var request CreateExportRecipientRQ
+	response := CreateExportRecipientRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
-	archiveFilename, errArchiveData := ArchiveExport(rqContext, exportedData, archiveType, compressionType, exportType)
-	if errArchiveData != nil {
-		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ArchiveSecretsError"}})
-		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errArchiveData.Error(), Code: 0})
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "create.export.recipient")
+	runSpan.Description = "run"
+
+	folder, errGetFolder := secretsSvc.GetFolderByUID(rqContext, request.FolderUID)
+	if errGetFolder != nil {
+		log.Printf("Error fetching folder with UID of %s: %s", request.FolderUID, errGetFolder.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetFolderByUIDError"},
+			TemplateData: map[string]interface{}{"UID": request.FolderUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetFolder.Error(), Code: 0})
+		c.JSON(http.StatusNotFound, response)
+		return
+	}
+
+	encryptionType, _ := EncryptionTypesMapInv[request.Type]
+	recipientSecret, errSaveRecipient := secretsSvc.SaveSecret(rqContext, Localizer, secrets2.Secret{FolderID: folder.ID,
+		Name: request.Name, Value: strings.TrimSpace(request.PublicKey), Type: EncryptionRecipientTypesMap[encryptionType]})
+	if errSaveRecipient != nil {
+		log.Printf("Error saving export recipient %s in folder with UID of %s: %s", request.Name, request.FolderUID, errSaveRecipient.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateExportRecipientError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errSaveRecipient.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	response.Data = &Secret{ID: recipientSecret.ID, UID: recipientSecret.UID, FolderUID: folder.UID, Name: recipientSecret.Name,
+		Value: recipientSecret.Value, Type: recipientSecret.Type, ExpiresAt: fromNullTime(recipientSecret.ExpiresAt)}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetExportPublicKeyHandler
+// @Summary Get public key of export signatures
+// @Description Get Ed25519 public key manifests of signed exports are verified with
+// @ID get-export-public-key
+// @Tags Secrets
+// @Produce json
+// @Success 200 {object} ExportPublicKeyRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {object} ExportPublicKeyRS
+// @Router /secrets/export/public-key/ [get]
+func GetExportPublicKeyHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	response := ExportPublicKeyRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	runSpan := sentry.StartSpan(rqContext, "get.export.public.key")
+	runSpan.Description = "run"
+
+	signingKey, errParseKey := signing.ParseKey(apiconfig.Settings.Security.SigningKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing signing key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SigningKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
 		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
+	publicKey := signingKey.Public().(ed25519.PublicKey)
+	publicKeyPEM, errEncodeKey := signing.EncodePublicKey(publicKey)
+	if errEncodeKey != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SigningKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errEncodeKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	response.Data = &ExportPublicKey{Algorithm: "ed25519", KeyID: signing.KeyID(publicKey),
+		PublicKey: base64.StdEncoding.EncodeToString(publicKey), PEM: publicKeyPEM}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// ImportSecretsHandler
+// @Summary Import secrets from file
+// @Description Import secrets from dotenv, JSON or YAML file, or from tar, zip or 7z archive of such files (optionally compressed) or a compressed file, into the folder. Nested objects and directories of archives become subfolders. Exports of HashiCorp Vault KV, Bitwarden, 1Password CLI, KeePass XML and AWS Secrets Manager are imported with every item as a folder of its fields
+// @ID import-secrets
+// @Tags Secrets
+// @Accept multipart/form-data
+// @Produce json
+// @Param File formData file true "Imported file"
+// @Param FolderUID formData string true "Folder secrets are imported into"
+// @Param Format formData string false "Format of the file, detected by its extension by default" Enums(dotenv, json, yaml, archive, vault, bitwarden, 1password, keepass, aws)
+// @Param Conflict formData string false "What to do with secrets that already exist, skip by default" Enums(skip, overwrite, fail)
+// @Param DryRun formData bool false "Only report changes the import would make"
+// @Success 200 {object} ImportSecretsRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} ImportSecretsRS
+// @Failure 404 {object} ImportSecretsRS
+// @Failure 409 {object} ImportSecretsRS
+// @Failure 500 {object} ImportSecretsRS
+// @Router /secrets/import/ [post]
+func ImportSecretsHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
 
-	archiveFile, errOpenSecretsArchive := os.Open(archiveFilename)
-	if errOpenSecretsArchive != nil {
-		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "OpenSecretsArchiveError"}})
-		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errOpenSecretsArchive.Error(), Code: 0})
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.import.secrets")
+	validationSpan.Description = "rq.validate"
+
+	var request ImportSecretsRQ
+	response := ImportSecretsRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
 		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
 
-	defer func() {
-		archiveFile.Close()
-		os.Remove(archiveFilename)
-	}()
+	// Multipart body holds the file along with the form, so it is limited a bit above the size of the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ImportSizeLimit+1<<20)
+	errBindBody := c.ShouldBindWith(&request, binding.FormMultipart)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	fileHeader, errGetFile := c.FormFile("File")
+	if errGetFile != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "File"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetFile.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "import.secrets")
+	runSpan.Description = "run"
 
-	var buffer bytes.Buffer
-	_, errReadArchiveSecretsFile := io.Copy(&buffer, archiveFile)
-	if errReadArchiveSecretsFile != nil {
-		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ReadSecretsArchiveError"}})
-		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errReadArchiveSecretsFile.Error(), Code: 0})
+	file, errOpenFile := fileHeader.Open()
+	if errOpenFile != nil {
+		log.Printf("Error opening imported file %s: %s", fileHeader.Filename, errOpenFile.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ImportParseError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errOpenFile.Error(), Code: 0})
 		c.JSON(http.StatusInternalServerError, response)
 		return
 	}
+	defer file.Close()
+
+	fileData, errReadFile := archive.ReadLimited(file, ImportSizeLimit)
+	if errReadFile != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ImportParseError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errReadFile.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
 
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filepath.Base(archiveFilename)))
-	c.Data(http.StatusOK, "application/octet-stream", buffer.Bytes())
+	format, _ := ImportFormatsMapInv[request.Format]
+	entries, errParse := ParseImport(rqContext, format, fileHeader.Filename, fileData)
+	if errParse != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ImportParseError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParse.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
 
-	exportSpan.Finish()
+	folder, errGetFolder := secretsSvc.GetFolderByUID(rqContext, request.FolderUID)
+	if errGetFolder != nil {
+		log.Printf("Error fetching folder with UID of %s: %s", request.FolderUID, errGetFolder.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetFolderByUIDError"},
+			TemplateData: map[string]interface{}{"UID": request.FolderUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetFolder.Error(), Code: 0})
+		c.JSON(http.StatusNotFound, response)
+		return
+	}
+
+	conflict, _ := ImportConflictsMapInv[request.Conflict]
+	steps, errPlan := PlanImport(rqContext, secretsSvc, folder, entries, conflict)
+	if errPlan != nil {
+		status := http.StatusConflict
+		if !errors.Is(errPlan, ErrImportConflict) {
+			log.Printf("Error planning import into folder with UID of %s: %s", request.FolderUID, errPlan.Error())
+			status = http.StatusInternalServerError
+		}
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ImportSecretsError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errPlan.Error(), Code: 0})
+		c.JSON(status, response)
+		return
+	}
+
+	if request.DryRun {
+		response.Data = NewImportResult(ImportChanges(steps), true)
+		runSpan.Finish()
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Changes made before a failure are reported along with the error, as they are not rolled back
+	changes, errApply := ApplyImport(rqContext, secretsSvc, Localizer, steps)
+	response.Data = NewImportResult(changes, false)
+	if errApply != nil {
+		log.Printf("Error importing secrets into folder with UID of %s: %s", request.FolderUID, errApply.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ImportSecretsError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errApply.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
 }
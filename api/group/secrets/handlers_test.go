@@ -0,0 +1,127 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/BurntSushi/toml"
+	"github.com/gin-gonic/gin"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+	apiconfig "hideout/cmd/api/config"
+	"hideout/internal/folders"
+	leases2 "hideout/internal/leases"
+	"hideout/internal/translations"
+	"hideout/services/leases"
+	"hideout/services/secrets"
+	"hideout/structs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testRevoker struct{}
+
+func (testRevoker) Revoke(context.Context, leases2.Lease) error { return nil }
+
+// serve Runs handler with JSON request the way router would, returning status and body of the response
+func serve(t *testing.T, handler gin.HandlerFunc, request any) (int, string) {
+	t.Helper()
+	body, errMarshal := json.Marshal(request)
+	if errMarshal != nil {
+		t.Fatalf("Failed to serialize request: %s", errMarshal)
+	}
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("Language", translations.English)
+	handler(c)
+	return recorder.Code, recorder.Body.String()
+}
+
+// newTestSettings Settings keeping folders and secrets in empty in-memory repositories, along with their service
+func newTestSettings(t *testing.T) *secrets.SecretsService {
+	t.Helper()
+	bundle := i18n.NewBundle(language.English)
+	bundle.RegisterUnmarshalFunc("toml", toml.Unmarshal)
+	if _, errLoad := bundle.LoadMessageFile("../../../data/i18n/translate.en-US.toml"); errLoad != nil {
+		t.Fatalf("Failed to load translations: %s", errLoad)
+	}
+	apiconfig.Settings = &apiconfig.Config{Bundle: bundle}
+	structs.Folders, structs.Secrets = nil, nil
+	secretsSvc, errCreateService := secrets.NewService(context.Background(), apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		t.Fatalf("Failed to create secrets service: %s", errCreateService)
+	}
+	return secretsSvc
+}
+
+func TestLeasesHidden(t *testing.T) {
+	secretsSvc := newTestSettings(t)
+	engineFolder, errCreateFolder := secretsSvc.CreateFolder(context.Background(), folders.Folder{Name: "database"})
+	if errCreateFolder != nil {
+		t.Fatalf("Failed to create folder: %s", errCreateFolder)
+	}
+	leaseSvc := leases.NewService(secretsSvc)
+	leaseSvc.Register("database", testRevoker{})
+	now := time.Now()
+	_, errCreateLease := leaseSvc.Create(context.Background(), nil, engineFolder, leases2.Lease{Engine: "database", Path: "readonly",
+		IssuedAt: now, ExpiresAt: now.Add(time.Hour), MaxExpiresAt: now.Add(time.Hour), Data: map[string]string{"User": "LEASED_USER"}})
+	if errCreateLease != nil {
+		t.Fatalf("Failed to create lease: %s", errCreateLease)
+	}
+	leasesFolder, errGetFolder := secretsSvc.GetFolderByName(context.Background(), engineFolder.ID, leases.FolderName)
+	if errGetFolder != nil {
+		t.Fatalf("Failed to retrieve leases folder: %s", errGetFolder)
+	}
+
+	status, body := serve(t, GetSecretsHandler, GetSecretsRQ{FolderUID: leasesFolder.UID})
+	if status != http.StatusOK {
+		t.Fatalf("Listing failed with status %d: %s", status, body)
+	}
+	var listed GetSecretsRS
+	if errUnmarshal := json.Unmarshal([]byte(body), &listed); errUnmarshal != nil {
+		t.Fatalf("Failed to parse listing: %s", errUnmarshal)
+	}
+	if len(listed.Secrets) != 1 || listed.Secrets[0].Value != "" {
+		t.Errorf("Expected single lease without value, got %+v", listed.Secrets)
+	}
+
+	status, body = serve(t, ExportSecretsHandler, ExportSecretsRQ{Format: "json", FolderUID: leasesFolder.UID})
+	if status != http.StatusOK {
+		t.Fatalf("Export failed with status %d: %s", status, body)
+	}
+	if strings.Contains(body, "LEASED_USER") {
+		t.Errorf("Export contains lease payload: %s", body)
+	}
+}
+
+func TestGetSecretsExpiresWithin(t *testing.T) {
+	newTestSettings(t)
+	from, to := time.Now(), time.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		request  GetSecretsRQ
+		expected int
+	}{
+		{name: "within", request: GetSecretsRQ{ExpiresWithin: 3600}, expected: http.StatusOK},
+		{name: "from and to", request: GetSecretsRQ{ExpiresFrom: from, ExpiresTo: to}, expected: http.StatusOK},
+		{name: "within and from", request: GetSecretsRQ{ExpiresWithin: 3600, ExpiresFrom: from}, expected: http.StatusBadRequest},
+		{name: "within and to", request: GetSecretsRQ{ExpiresWithin: 3600, ExpiresTo: to}, expected: http.StatusBadRequest},
+		{name: "within, from and to", request: GetSecretsRQ{ExpiresWithin: 3600, ExpiresFrom: from, ExpiresTo: to}, expected: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, body := serve(t, GetSecretsHandler, test.request)
+			if status != test.expected {
+				t.Errorf("Expected status %d, got %d: %s", test.expected, status, body)
+			}
+		})
+	}
+}
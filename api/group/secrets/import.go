@@ -0,0 +1,465 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"hideout/internal/common/apperror"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/archive"
+	"hideout/internal/pkg/signing"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ParseImport Secrets of the imported file, format is detected by the extension of the file name unless given, files
+// which are neither of the known extensions are treated as archives or compressed files
+func ParseImport(ctx context.Context, format uint, fileName string, data []byte) ([]importEntry, error) {
+	if format == ImportFormat_Detect {
+		format = ImportFormat_Archive
+		if extensionFormat, exists := ImportExtensionsMap[strings.ToLower(path.Ext(fileName))]; exists {
+			format = extensionFormat
+		}
+	}
+
+	var entries []importEntry
+	var errParse error
+	if format == ImportFormat_Archive {
+		entries, errParse = parseImportArchive(ctx, fileName, data)
+	} else {
+		entries, errParse = parseImportFile(format, data)
+	}
+	if errParse != nil {
+		return nil, errParse
+	}
+
+	errValidate := validateImportEntries(entries)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+	return entries, nil
+}
+
+// PlanImport Changes needed to bring imported secrets into the target folder, nothing is written yet
+func PlanImport(ctx context.Context, secretsSvc *secrets.SecretsService, targetFolder *folders.Folder, entries []importEntry, conflict uint) ([]importStep, error) {
+	// Existing folders by their paths below the target one, folders planned to be created are known by paths only
+	existingFolders := map[string]*folders.Folder{"": targetFolder}
+	plannedFolders := make(map[string]bool)
+
+	var steps []importStep
+	for _, entry := range entries {
+		for depth := 1; depth <= len(entry.path); depth++ {
+			folderKey := strings.Join(entry.path[:depth], "/")
+			if existingFolders[folderKey] != nil || plannedFolders[folderKey] {
+				continue
+			}
+			parentKey := strings.Join(entry.path[:depth-1], "/")
+			parentFolder := existingFolders[parentKey]
+			if parentFolder != nil {
+				existingFolder, errGetFolder := secretsSvc.GetFolderByName(ctx, parentFolder.ID, entry.path[depth-1])
+				if errGetFolder == nil {
+					existingFolders[folderKey] = existingFolder
+					continue
+				}
+				if !errors.Is(errGetFolder, apperror.ErrRecordNotFound) {
+					return nil, errGetFolder
+				}
+			}
+			plannedFolders[folderKey] = true
+			steps = append(steps, importStep{change: ImportChange{Action: ImportAction_CreateFolder, Folder: folderKey},
+				folder: parentFolder, parentKey: parentKey, name: entry.path[depth-1]})
+		}
+
+		folderKey := strings.Join(entry.path, "/")
+		step := importStep{change: ImportChange{Action: ImportAction_Create, Folder: folderKey, Name: entry.name},
+			folder: existingFolders[folderKey], parentKey: folderKey, name: entry.name, value: entry.value, expiresAt: entry.expiresAt}
+		if step.folder == nil {
+			steps = append(steps, step)
+			continue
+		}
+
+		existingSecret, errGetSecret := secretsSvc.GetSecretByName(ctx, step.folder.ID, entry.name)
+		if errGetSecret != nil {
+			if !errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+				return nil, errGetSecret
+			}
+			steps = append(steps, step)
+			continue
+		}
+
+		step.change.UID = existingSecret.UID
+		step.existing = existingSecret
+		switch {
+		case existingSecret.Type == secrets2.Type_Plain && existingSecret.Script == "" && existingSecret.Value == entry.value &&
+			(entry.expiresAt.IsZero() || existingSecret.ExpiresAt.Valid && existingSecret.ExpiresAt.Time.Equal(entry.expiresAt)):
+			step.change.Action = ImportAction_Unchanged
+		case conflict == ImportConflict_Fail:
+			return nil, errors.Wrapf(ErrImportConflict, "Secret %s", importChangePath(step.change))
+		case conflict == ImportConflict_Overwrite && existingSecret.Type == secrets2.Type_Plain:
+			step.change.Action = ImportAction_Update
+		default:
+			// Secrets of other types are managed by their engines, so they are never overwritten by an import
+			step.change.Action = ImportAction_Skip
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// ApplyImport Creates folders and secrets and updates existing secrets as planned, changes made before a failure are
+// returned along with the error
+func ApplyImport(ctx context.Context, secretsSvc *secrets.SecretsService, Localizer *i18n.Localizer, steps []importStep) ([]ImportChange, error) {
+	createdFolders := make(map[string]*folders.Folder)
+	changes := make([]ImportChange, 0, len(steps))
+	for _, step := range steps {
+		folder := step.folder
+		if folder == nil {
+			folder = createdFolders[step.parentKey]
+		}
+
+		switch step.change.Action {
+		case ImportAction_CreateFolder:
+			newFolder, errCreateFolder := secretsSvc.CreateFolder(ctx, folders.Folder{ParentID: folder.ID, UID: gofakeit.UUID(), Name: step.name})
+			if errCreateFolder != nil {
+				return changes, errors.Wrapf(errCreateFolder, "Failed to create folder %s", step.change.Folder)
+			}
+			createdFolders[step.change.Folder] = newFolder
+			step.change.UID = newFolder.UID
+		case ImportAction_Create:
+			newSecret, errCreateSecret := secretsSvc.CreateSecret(ctx, Localizer, secrets2.Secret{FolderID: folder.ID,
+				UID: gofakeit.UUID(), Name: step.name, Value: step.value,
+				ExpiresAt: sql.NullTime{Time: step.expiresAt, Valid: !step.expiresAt.IsZero()}})
+			if errCreateSecret != nil {
+				return changes, errors.Wrapf(errCreateSecret, "Failed to create secret %s", importChangePath(step.change))
+			}
+			step.change.UID = newSecret.UID
+		case ImportAction_Update:
+			updatedSecret := *step.existing
+			updatedSecret.Value = step.value
+			updatedSecret.Script = ""
+			// Expiration date is only replaced when the imported file keeps one
+			if !step.expiresAt.IsZero() {
+				updatedSecret.ExpiresAt = sql.NullTime{Time: step.expiresAt, Valid: true}
+			}
+			_, errUpdateSecret := secretsSvc.UpdateSecret(ctx, Localizer, updatedSecret)
+			if errUpdateSecret != nil {
+				return changes, errors.Wrapf(errUpdateSecret, "Failed to update secret %s", importChangePath(step.change))
+			}
+		}
+		changes = append(changes, step.change)
+	}
+	return changes, nil
+}
+
+// ImportChanges Changes of the planned import
+func ImportChanges(steps []importStep) []ImportChange {
+	changes := make([]ImportChange, 0, len(steps))
+	for _, step := range steps {
+		changes = append(changes, step.change)
+	}
+	return changes
+}
+
+// NewImportResult Summary of the changes made (or planned) by import
+func NewImportResult(changes []ImportChange, dryRun bool) *ImportResult {
+	result := ImportResult{DryRun: dryRun, Changes: changes}
+	for _, change := range changes {
+		switch change.Action {
+		case ImportAction_CreateFolder:
+			result.FoldersCreated++
+		case ImportAction_Create:
+			result.Created++
+		case ImportAction_Update:
+			result.Updated++
+		case ImportAction_Unchanged:
+			result.Unchanged++
+		case ImportAction_Skip:
+			result.Skipped++
+		}
+	}
+	return &result
+}
+
+// parseImportFile Secrets of a single file of the given format
+func parseImportFile(format uint, data []byte) ([]importEntry, error) {
+	switch format {
+	case ImportFormat_DotEnv:
+		return parseDotEnv(data)
+	case ImportFormat_JSON:
+		return parseJSON(data)
+	case ImportFormat_YAML:
+		return parseYAML(data)
+	case ImportFormat_Vault:
+		return parseVault(data)
+	case ImportFormat_Bitwarden:
+		return parseBitwarden(data)
+	case ImportFormat_OnePassword:
+		return parseOnePassword(data)
+	case ImportFormat_KeePass:
+		return parseKeePass(data)
+	case ImportFormat_AWS:
+		return parseAWS(data)
+	}
+	return nil, ErrUnknownImportFormat
+}
+
+func importChangePath(change ImportChange) string {
+	if change.Folder == "" {
+		return change.Name
+	}
+	return change.Folder + "/" + change.Name
+}
+
+// validateImportEntries Names of folders and secrets must be usable as path segments, and every secret is only
+// imported once
+func validateImportEntries(entries []importEntry) error {
+	importedSecrets := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		for _, folderName := range entry.path {
+			if folderName == "" || folderName == "." || folderName == ".." || strings.ContainsAny(folderName, "/\\") {
+				return errors.Wrapf(ErrImportInvalidName, "Folder %q", folderName)
+			}
+		}
+		if !importNameRegex.MatchString(entry.name) {
+			return errors.Wrapf(ErrImportInvalidName, "Secret %q", entry.name)
+		}
+
+		secretKey := strings.Join(append(append([]string{}, entry.path...), entry.name), "/")
+		if importedSecrets[secretKey] {
+			return errors.Wrapf(ErrImportDuplicate, "Secret %s", secretKey)
+		}
+		importedSecrets[secretKey] = true
+	}
+	return nil
+}
+
+// parseDotEnv Variables of dotenv file, values are taken literally as no variables are expanded
+func parseDotEnv(data []byte) ([]importEntry, error) {
+	var entries []importEntry
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for lineIndex := 0; lineIndex < len(lines); lineIndex++ {
+		line := strings.TrimSpace(lines[lineIndex])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, errors.Wrapf(ErrImportSyntax, "Line %d has no value", lineIndex+1)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimLeft(value, " \t")
+
+		switch {
+		case strings.HasPrefix(value, `"`):
+			// Double quoted values may span several lines and contain escape sequences
+			var unquoted strings.Builder
+			startLine, closed := lineIndex, false
+			rest := value[1:]
+			for !closed {
+				for charIndex := 0; charIndex < len(rest); charIndex++ {
+					char := rest[charIndex]
+					if char == '"' {
+						closed = true
+						break
+					}
+					if char == '\\' && charIndex+1 < len(rest) {
+						charIndex++
+						switch rest[charIndex] {
+						case 'n':
+							unquoted.WriteByte('\n')
+						case 'r':
+							unquoted.WriteByte('\r')
+						case 't':
+							unquoted.WriteByte('\t')
+						default:
+							unquoted.WriteByte(rest[charIndex])
+						}
+						continue
+					}
+					unquoted.WriteByte(char)
+				}
+				if !closed {
+					lineIndex++
+					if lineIndex >= len(lines) {
+						return nil, errors.Wrapf(ErrImportSyntax, "Line %d has unterminated quoted value", startLine+1)
+					}
+					unquoted.WriteByte('\n')
+					rest = lines[lineIndex]
+				}
+			}
+			value = unquoted.String()
+		case strings.HasPrefix(value, `'`):
+			closingIndex := strings.Index(value[1:], `'`)
+			if closingIndex < 0 {
+				return nil, errors.Wrapf(ErrImportSyntax, "Line %d has unterminated quoted value", lineIndex+1)
+			}
+			value = value[1 : closingIndex+1]
+		default:
+			if commentIndex := strings.Index(value, " #"); commentIndex >= 0 {
+				value = value[:commentIndex]
+			}
+			value = strings.TrimSpace(value)
+		}
+
+		entries = append(entries, importEntry{name: name, value: value})
+	}
+	return entries, nil
+}
+
+// parseJSON Object of secret names and values, nested objects are folders
+func parseJSON(data []byte) ([]importEntry, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var root map[string]interface{}
+	errDecode := decoder.Decode(&root)
+	if errDecode != nil {
+		return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+	}
+	if _, errToken := decoder.Token(); errToken != io.EOF {
+		return nil, errors.Wrap(ErrImportSyntax, "Unexpected data after JSON object")
+	}
+
+	var entries []importEntry
+	errWalk := walkJSON(root, nil, &entries)
+	if errWalk != nil {
+		return nil, errWalk
+	}
+	return entries, nil
+}
+
+func walkJSON(object map[string]interface{}, folderPath []string, entries *[]importEntry) error {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch value := object[key].(type) {
+		case map[string]interface{}:
+			errWalk := walkJSON(value, append(append([]string{}, folderPath...), key), entries)
+			if errWalk != nil {
+				return errWalk
+			}
+		case []interface{}:
+			return errors.Wrapf(ErrImportValue, "Key %s", strings.Join(append(append([]string{}, folderPath...), key), "."))
+		case string:
+			*entries = append(*entries, importEntry{path: folderPath, name: key, value: value})
+		case json.Number:
+			*entries = append(*entries, importEntry{path: folderPath, name: key, value: value.String()})
+		case bool:
+			*entries = append(*entries, importEntry{path: folderPath, name: key, value: map[bool]string{true: "true", false: "false"}[value]})
+		case nil:
+			*entries = append(*entries, importEntry{path: folderPath, name: key})
+		}
+	}
+	return nil
+}
+
+// parseYAML Mapping of secret names and values, nested mappings are folders
+func parseYAML(data []byte) ([]importEntry, error) {
+	var document yaml.Node
+	errDecode := yaml.Unmarshal(data, &document)
+	if errDecode != nil {
+		return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+	}
+	// Empty documents have nothing to import
+	if document.Kind == 0 || len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	root := resolveYAMLAlias(document.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.Wrap(ErrImportSyntax, "YAML document is not a mapping")
+	}
+
+	var entries []importEntry
+	errWalk := walkYAML(root, nil, &entries)
+	if errWalk != nil {
+		return nil, errWalk
+	}
+	return entries, nil
+}
+
+func walkYAML(mapping *yaml.Node, folderPath []string, entries *[]importEntry) error {
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		key := mapping.Content[index].Value
+		value := resolveYAMLAlias(mapping.Content[index+1])
+		switch value.Kind {
+		case yaml.MappingNode:
+			errWalk := walkYAML(value, append(append([]string{}, folderPath...), key), entries)
+			if errWalk != nil {
+				return errWalk
+			}
+		case yaml.SequenceNode:
+			return errors.Wrapf(ErrImportValue, "Key %s", strings.Join(append(append([]string{}, folderPath...), key), "."))
+		case yaml.ScalarNode:
+			scalarValue := value.Value
+			if value.Tag == "!!null" {
+				scalarValue = ""
+			}
+			*entries = append(*entries, importEntry{path: folderPath, name: key, value: scalarValue})
+		}
+	}
+	return nil
+}
+
+func resolveYAMLAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// parseImportArchive Files of the archive (tar, zip or 7z) imported into folders of their directories, so that recursive
+// exports can be imported back, manifest and its signature are ignored. Compressed file which is not an archive is
+// imported by the extension it has without compression one
+func parseImportArchive(ctx context.Context, fileName string, data []byte) ([]importEntry, error) {
+	files, errExtract := archive.Extract(ctx, fileName, bytes.NewReader(data), ImportSizeLimit)
+	if errExtract != nil {
+		return nil, errExtract
+	}
+
+	filePaths := make([]string, 0, len(files))
+	for filePath := range files {
+		if filePath == signing.ManifestFile || filePath == signing.ManifestSignatureFile {
+			continue
+		}
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+
+	var entries []importEntry
+	for _, filePath := range filePaths {
+		format, exists := ImportExtensionsMap[strings.ToLower(path.Ext(filePath))]
+		if !exists {
+			return nil, errors.Wrapf(ErrUnknownImportFormat, "File %s", filePath)
+		}
+
+		fileEntries, errParse := parseImportFile(format, files[filePath])
+		if errParse != nil {
+			return nil, errors.Wrapf(errParse, "File %s", filePath)
+		}
+
+		var folderPath []string
+		if directory := path.Dir(filePath); directory != "." {
+			folderPath = strings.Split(directory, "/")
+		}
+		for _, fileEntry := range fileEntries {
+			fileEntry.path = append(append([]string{}, folderPath...), fileEntry.path...)
+			entries = append(entries, fileEntry)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,437 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/pkg/errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// parseVault Dump of HashiCorp Vault KV engine, either an object of secret paths and their data (as dumped by most
+// backup tools) or the output of "vault kv get -format=json", every Vault secret becomes a folder of its keys
+func parseVault(data []byte) ([]importEntry, error) {
+	var dump map[string]json.RawMessage
+	errDecode := json.Unmarshal(data, &dump)
+	if errDecode != nil {
+		return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+	}
+
+	// Output of "vault kv get" keeps no path of the secret, so its keys are imported right into the target folder
+	if _, isResponse := dump["request_id"]; isResponse {
+		secretData, errData := vaultSecretData(data)
+		if errData != nil {
+			return nil, errData
+		}
+		return keyValueEntries(nil, secretData), nil
+	}
+
+	secretPaths := make([]string, 0, len(dump))
+	for secretPath := range dump {
+		secretPaths = append(secretPaths, secretPath)
+	}
+	sort.Strings(secretPaths)
+
+	var entries []importEntry
+	for _, secretPath := range secretPaths {
+		secretData, errData := vaultSecretData(dump[secretPath])
+		if errData != nil {
+			return nil, errors.Wrapf(errData, "Secret %s", secretPath)
+		}
+		entries = append(entries, keyValueEntries(importFolderPath(secretPath), secretData)...)
+	}
+	return entries, nil
+}
+
+// vaultSecretData Keys of Vault secret, unwrapping API response and versioned data of KV version 2
+func vaultSecretData(data []byte) (map[string]interface{}, error) {
+	var response vaultResponse
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	errDecode := decoder.Decode(&response)
+	if errDecode != nil {
+		return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+	}
+	if response.RequestID == "" {
+		// Secret data stored right under its path
+		var secretData map[string]interface{}
+		decoder = json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		errDecode = decoder.Decode(&secretData)
+		if errDecode != nil {
+			return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+		}
+		response.Data = secretData
+	}
+
+	versionedData, isVersioned := response.Data["data"].(map[string]interface{})
+	if _, hasMetadata := response.Data["metadata"].(map[string]interface{}); isVersioned && hasMetadata {
+		return versionedData, nil
+	}
+	return response.Data, nil
+}
+
+// keyValueEntries Secrets of the folder for keys of JSON object (of Vault or AWS secret)
+func keyValueEntries(folderPath []string, secretData map[string]interface{}) []importEntry {
+	keys := make([]string, 0, len(secretData))
+	for key := range secretData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	item := newImportItem(folderPath, time.Time{})
+	for _, key := range keys {
+		item.add(key, importValue(secretData[key]))
+	}
+	return item.entries
+}
+
+// parseBitwarden Unencrypted JSON export of Bitwarden vault or organization, every item becomes a folder of its fields
+// within the folder (or the first collection) it belongs to
+func parseBitwarden(data []byte) ([]importEntry, error) {
+	var export bitwardenExport
+	errDecode := json.Unmarshal(data, &export)
+	if errDecode != nil {
+		return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+	}
+	if export.Encrypted {
+		return nil, ErrImportEncrypted
+	}
+
+	folderPaths := make(map[string][]string)
+	for _, folder := range append(export.Folders, export.Collections...) {
+		// Bitwarden nests folders by slashes in their names
+		folderPaths[folder.ID] = importFolderPath(folder.Name)
+	}
+
+	var entries []importEntry
+	itemNames := make(map[string]bool)
+	for _, bitwardenItem := range export.Items {
+		folderPath := folderPaths[bitwardenItem.FolderID]
+		if bitwardenItem.FolderID == "" && len(bitwardenItem.CollectionIDs) > 0 {
+			folderPath = folderPaths[bitwardenItem.CollectionIDs[0]]
+		}
+		item := newImportItem(appendItemFolder(itemNames, folderPath, bitwardenItem.Name), time.Time{})
+
+		for _, loginKey := range []string{"username", "password", "totp"} {
+			item.add(bitwardenLoginFieldsMap[loginKey], importValue(bitwardenItem.Login[loginKey]))
+		}
+		if uris, isList := bitwardenItem.Login["uris"].([]interface{}); isList {
+			for _, uri := range uris {
+				if uriObject, isObject := uri.(map[string]interface{}); isObject {
+					item.add(ImportField_URL, importValue(uriObject["uri"]))
+				}
+			}
+		}
+		for _, details := range []map[string]interface{}{bitwardenItem.Card, bitwardenItem.Identity, bitwardenItem.SSHKey} {
+			detailKeys := make([]string, 0, len(details))
+			for detailKey := range details {
+				detailKeys = append(detailKeys, detailKey)
+			}
+			sort.Strings(detailKeys)
+			for _, detailKey := range detailKeys {
+				item.add(upperSnakeCase(detailKey), importValue(details[detailKey]))
+			}
+		}
+		for _, field := range bitwardenItem.Fields {
+			item.add(field.Name, importValue(field.Value))
+		}
+		item.add(ImportField_Notes, bitwardenItem.Notes)
+
+		entries = append(entries, item.entries...)
+	}
+	return entries, nil
+}
+
+// parseOnePassword Items printed by 1Password CLI ("op item get --format json"), either a list of items or items
+// following one another, every item becomes a folder of its fields within the folder of its vault
+func parseOnePassword(data []byte) ([]importEntry, error) {
+	var onePasswordItems []onePasswordItem
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		errDecode := json.Unmarshal(trimmed, &onePasswordItems)
+		if errDecode != nil {
+			return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var onePasswordItem onePasswordItem
+			errDecode := decoder.Decode(&onePasswordItem)
+			if errDecode == io.EOF {
+				break
+			}
+			if errDecode != nil {
+				return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+			}
+			onePasswordItems = append(onePasswordItems, onePasswordItem)
+		}
+	}
+
+	var entries []importEntry
+	itemNames := make(map[string]bool)
+	for _, onePasswordItem := range onePasswordItems {
+		var folderPath []string
+		if onePasswordItem.Vault.Name != "" {
+			folderPath = []string{importFolderName(onePasswordItem.Vault.Name)}
+		}
+		item := newImportItem(appendItemFolder(itemNames, folderPath, onePasswordItem.Title), time.Time{})
+
+		for _, field := range onePasswordItem.Fields {
+			fieldName, isCommon := onePasswordPurposesMap[field.Purpose]
+			if !isCommon {
+				fieldName = field.Label
+				if field.Section.Label != "" {
+					fieldName = field.Section.Label + "_" + field.Label
+				}
+			}
+			item.add(fieldName, field.Value)
+		}
+		for _, url := range onePasswordItem.URLs {
+			item.add(ImportField_URL, url.Href)
+		}
+		item.add(ImportField_Tags, strings.Join(onePasswordItem.Tags, ","))
+
+		entries = append(entries, item.entries...)
+	}
+	return entries, nil
+}
+
+// parseKeePass XML export of KeePass 2 database, groups become folders and every entry becomes a folder of its
+// strings, expiration dates of entries are kept and the recycle bin is left out
+func parseKeePass(data []byte) ([]importEntry, error) {
+	var database keePassFile
+	errDecode := xml.Unmarshal(data, &database)
+	if errDecode != nil {
+		return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+	}
+
+	recycleBinUUID := ""
+	if strings.EqualFold(database.Meta.RecycleBinEnabled, "True") {
+		recycleBinUUID = database.Meta.RecycleBinUUID
+	}
+
+	var entries []importEntry
+	itemNames := make(map[string]bool)
+	// Root group stands for the database itself, so its contents go right into the target folder
+	for _, rootGroup := range database.Root.Groups {
+		groupEntries, errWalk := walkKeePass(rootGroup, nil, recycleBinUUID, itemNames)
+		if errWalk != nil {
+			return nil, errWalk
+		}
+		entries = append(entries, groupEntries...)
+	}
+	return entries, nil
+}
+
+func walkKeePass(group keePassGroup, folderPath []string, recycleBinUUID string, itemNames map[string]bool) ([]importEntry, error) {
+	var entries []importEntry
+	for _, keePassEntry := range group.Entries {
+		title := ""
+		for _, entryString := range keePassEntry.Strings {
+			if entryString.Key == "Title" {
+				title = entryString.Value
+			}
+		}
+
+		var expiresAt time.Time
+		if strings.EqualFold(keePassEntry.Times.Expires, "True") {
+			parsedTime, errParseTime := parseKeePassTime(keePassEntry.Times.ExpiryTime)
+			if errParseTime != nil {
+				return nil, errors.Wrapf(errParseTime, "Entry %s", title)
+			}
+			expiresAt = parsedTime
+		}
+
+		item := newImportItem(appendItemFolder(itemNames, folderPath, title), expiresAt)
+		for _, entryString := range keePassEntry.Strings {
+			if entryString.Key == "Title" {
+				continue
+			}
+			fieldName, isCommon := keePassStringsMap[entryString.Key]
+			if !isCommon {
+				fieldName = entryString.Key
+			}
+			item.add(fieldName, entryString.Value)
+		}
+		entries = append(entries, item.entries...)
+	}
+
+	for _, childGroup := range group.Groups {
+		if recycleBinUUID != "" && childGroup.UUID == recycleBinUUID {
+			continue
+		}
+		childPath := append(append([]string{}, folderPath...), importFolderName(childGroup.Name))
+		childEntries, errWalk := walkKeePass(childGroup, childPath, recycleBinUUID, itemNames)
+		if errWalk != nil {
+			return nil, errWalk
+		}
+		entries = append(entries, childEntries...)
+	}
+	return entries, nil
+}
+
+// parseKeePassTime Times are written in ISO 8601 by XML export, while KDBX 4 databases keep base64 encoded seconds
+// since year one
+func parseKeePassTime(value string) (time.Time, error) {
+	parsedTime, errParse := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if errParse == nil {
+		return parsedTime.UTC(), nil
+	}
+	encodedTime, errDecode := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if errDecode != nil || len(encodedTime) != 8 {
+		return time.Time{}, errors.Wrapf(ErrImportSyntax, "Invalid time %q", value)
+	}
+	// Seconds overflow time.Duration, so they are normalized by the date instead
+	return time.Date(1, time.January, 1, 0, 0, int(binary.LittleEndian.Uint64(encodedTime)), 0, time.UTC), nil
+}
+
+// parseAWS Secrets printed by AWS CLI (get-secret-value, batch-get-secret-value or list-secrets with values added),
+// slashes in names become folders and secrets holding JSON objects become folders of their keys
+func parseAWS(data []byte) ([]importEntry, error) {
+	var listing awsListing
+	errDecode := json.Unmarshal(data, &listing)
+	if errDecode != nil {
+		return nil, errors.Wrap(ErrImportSyntax, errDecode.Error())
+	}
+	awsSecrets := append(listing.SecretValues, listing.SecretList...)
+	if listing.Name != "" {
+		awsSecrets = append(awsSecrets, listing.awsSecret)
+	}
+
+	var entries []importEntry
+	for _, awsSecret := range awsSecrets {
+		secretPath := importFolderPath(awsSecret.Name)
+		if len(secretPath) == 0 {
+			return nil, errors.Wrapf(ErrImportInvalidName, "Secret %q", awsSecret.Name)
+		}
+
+		var value string
+		switch {
+		case awsSecret.SecretString != nil:
+			value = *awsSecret.SecretString
+		case awsSecret.SecretBinary != "":
+			// Binary secrets are kept base64 encoded, as printed by AWS CLI
+			value = awsSecret.SecretBinary
+		default:
+			// Listings of secrets only hold their metadata unless values are fetched along
+			return nil, errors.Wrapf(ErrImportNoValue, "Secret %s", awsSecret.Name)
+		}
+
+		var keyValues map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(value))
+		decoder.UseNumber()
+		if strings.HasPrefix(strings.TrimSpace(value), "{") && decoder.Decode(&keyValues) == nil {
+			entries = append(entries, keyValueEntries(secretPath, keyValues)...)
+			continue
+		}
+
+		item := newImportItem(secretPath[:len(secretPath)-1], time.Time{})
+		item.add(secretPath[len(secretPath)-1], value)
+		entries = append(entries, item.entries...)
+	}
+	return entries, nil
+}
+
+func newImportItem(folderPath []string, expiresAt time.Time) *importItem {
+	return &importItem{path: folderPath, expiresAt: expiresAt, names: make(map[string]bool)}
+}
+
+// add Adds field of the item as a secret unless it is empty, names are made valid and unique within the item, so
+// that repeated fields (such as several URLs) are all kept
+func (i *importItem) add(name string, value string) {
+	if value == "" {
+		return
+	}
+	name = uniqueImportName(i.names, importSecretName(name), "_")
+	i.entries = append(i.entries, importEntry{path: i.path, name: name, value: value, expiresAt: i.expiresAt})
+}
+
+// appendItemFolder Path of the folder of the item, items of the same name in a folder are told apart by a number
+func appendItemFolder(itemNames map[string]bool, folderPath []string, itemName string) []string {
+	if strings.TrimSpace(itemName) == "" {
+		itemName = "Untitled"
+	}
+	itemKey := strings.Join(folderPath, "/") + "/"
+	itemName = uniqueImportName(itemNames, itemKey+importFolderName(itemName), " ")
+	return append(append([]string{}, folderPath...), strings.TrimPrefix(itemName, itemKey))
+}
+
+// uniqueImportName Name suffixed with a number if it has already been taken
+func uniqueImportName(takenNames map[string]bool, name string, separator string) string {
+	uniqueName := name
+	for counter := 2; takenNames[uniqueName]; counter++ {
+		uniqueName = name + separator + strconv.Itoa(counter)
+	}
+	takenNames[uniqueName] = true
+	return uniqueName
+}
+
+// importSecretName Field name of other secret manager turned into a valid secret name
+func importSecretName(name string) string {
+	name = strings.Trim(importNameInvalidRegex.ReplaceAllString(strings.TrimSpace(name), "_"), "_")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// importFolderName Name of item or group of other secret manager turned into a valid folder name
+func importFolderName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// importFolderPath Folders of slash separated path, empty segments are skipped
+func importFolderPath(folderPath string) []string {
+	var folderNames []string
+	for _, folderName := range strings.Split(folderPath, "/") {
+		if strings.TrimSpace(folderName) != "" {
+			folderNames = append(folderNames, importFolderName(folderName))
+		}
+	}
+	return folderNames
+}
+
+// importValue Scalar value as a string, objects and lists are kept as JSON
+func importValue(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	case json.Number:
+		return typedValue.String()
+	case bool:
+		return strconv.FormatBool(typedValue)
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	}
+	encodedValue, errEncode := json.Marshal(value)
+	if errEncode != nil {
+		return ""
+	}
+	return string(encodedValue)
+}
+
+// upperSnakeCase Name of camel case field (such as cardholderName) in upper snake case (CARDHOLDER_NAME)
+func upperSnakeCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for index, char := range runes {
+		if index > 0 && unicode.IsUpper(char) && !unicode.IsUpper(runes[index-1]) && runes[index-1] != '_' {
+			builder.WriteRune('_')
+		}
+		builder.WriteRune(unicode.ToUpper(char))
+	}
+	return builder.String()
+}
@@ -1,19 +1,30 @@
 package secrets
 
 import (
+	"crypto/ed25519"
+	"hideout/internal/common/generics"
 	"hideout/internal/common/ordering"
 	"hideout/internal/common/pagination"
 	"hideout/internal/common/rqrs"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/archive"
+	secrets2 "hideout/internal/secrets"
+	"io"
+	"time"
 )
 
 type (
 	Secret struct {
-		ID        uint   `json:"ID" description:"Secret primary unique identifier" example:"1"`
-		UID       string `json:"UID" description:"Secondary unique identifier" example:"abc-def-ghi"`
-		FolderUID string `json:"FolderUID" description:"Folder unique identifier" example:"/"`
-		Name      string `json:"Name" description:"Secret name" example:"DEBUG"`
-		Value     string `json:"Value" description:"Secret value" example:"Test"`
-		Script    string `json:"Script" description:"Script for dynamically calculated value" example:"time.RFC3339"`
+		ID        uint       `json:"ID" description:"Secret primary unique identifier" example:"1"`
+		UID       string     `json:"UID" description:"Secondary unique identifier" example:"abc-def-ghi"`
+		FolderUID string     `json:"FolderUID" description:"Folder unique identifier" example:"/"`
+		Name      string     `json:"Name" description:"Secret name" example:"DEBUG"`
+		Value     string     `json:"Value" description:"Secret value" example:"Test"`
+		Script    string     `json:"Script" description:"Script for dynamically calculated value" example:"time.RFC3339"`
+		Type      string     `json:"Type" description:"Secret type (empty for plain secrets)" example:"certificate"`
+		ExpiresAt *time.Time `json:"ExpiresAt,omitempty" description:"External expiration date of the secret" example:"2030-01-01T00:00:00Z"`
+		Expired   bool       `json:"Expired" description:"Whether secret has already expired" example:"false"`
+		Path      []string   `json:"-"` // Names of folders between the exported folder and the one of the secret
 	}
 
 	Folder struct {
@@ -24,10 +35,11 @@ type (
 	}
 
 	CreateSecret struct {
-		FolderUID string `json:"FolderUID" description:"Folder unique identifier" example:"/"`
-		Name      string `json:"Name" description:"Secret name" example:"DEBUG"`
-		Value     string `json:"Value" description:"Secret value" example:"Test"`
-		Script    string `json:"Script" description:"Script for dynamically calculated value" example:"time.RFC3339"`
+		FolderUID string     `json:"FolderUID" description:"Folder unique identifier" example:"/"`
+		Name      string     `json:"Name" description:"Secret name" example:"DEBUG"`
+		Value     string     `json:"Value" description:"Secret value" example:"Test"`
+		Script    string     `json:"Script" description:"Script for dynamically calculated value" example:"time.RFC3339"`
+		ExpiresAt *time.Time `json:"ExpiresAt,omitempty" description:"External expiration date of the secret" example:"2030-01-01T00:00:00Z"`
 	}
 
 	GetSecretsRQ struct {
@@ -36,6 +48,9 @@ type (
 		SecretsOrder      []ordering.Order      `json:"SecretsOrder" description:"Secrets order"`
 		FoldersPagination pagination.Pagination `json:"FoldersPagination" description:"Folders pagination"`
 		FoldersOrder      []ordering.Order      `json:"FoldersOrder" description:"Folders order"`
+		ExpiresFrom       time.Time             `json:"ExpiresFrom" description:"Only secrets expiring at or after the date" example:"2030-01-01T00:00:00Z"`
+		ExpiresTo         time.Time             `json:"ExpiresTo" description:"Only secrets expiring at or before the date" example:"2030-02-01T00:00:00Z"`
+		ExpiresWithin     uint                  `json:"ExpiresWithin" description:"Only secrets expiring within given amount of seconds (including already expired), cannot be combined with ExpiresFrom and ExpiresTo" example:"604800"`
 	}
 
 	GetSecretsRS struct {
@@ -53,8 +68,14 @@ type (
 		rqrs.ResponseListRS
 	}
 
+	// UpdateSecret Secret to update, expiration date is kept when omitted and cleared when null
+	UpdateSecret struct {
+		Secret
+		ExpiresAt generics.Optional[time.Time] `json:"ExpiresAt" description:"External expiration date of the secret, kept when omitted, cleared when null" example:"2030-01-01T00:00:00Z"`
+	}
+
 	UpdateSecretsRQ struct {
-		Data []Secret `json:"Data"`
+		Data []UpdateSecret `json:"Data"`
 	}
 
 	UpdateSecretsRS struct {
@@ -85,12 +106,270 @@ type (
 	}
 
 	ExportSecretsRQ struct {
-		Format          string                `json:"Format" enums:"dotenv"`
-		CompressionType string                `json:"CompressionType" enums:"brotli,bzip2,zip,gzip,lz4,lz,mz,sz,s2,xz,zz,zst"`
+		Format          string                `json:"Format" enums:"dotenv,pem,json,json-nested,yaml,toml,properties,kubernetes,bash,fish,powershell,systemd,docker-compose,tfvars,tfvars-json,template"`
+		CompressionType string                `json:"CompressionType" enums:"brotli,bzip2,zip,gzip,lz4,lz,mz,sz,s2,xz,zz,zst" description:"Compression of the archive or of the single exported file without archive type, zip (deflate) is only available for zip archives"`
 		ArchiveType     string                `json:"ArchiveType" enums:"tar,zip"`
 		FolderUID       string                `json:"FolderUID" description:"Folder unique identifier" example:"abc-def-ghi"`
 		Pagination      pagination.Pagination `json:"Pagination" description:"Secrets pagination"`
 		Order           []ordering.Order      `json:"SOrder" description:"Secrets order"`
+		ExpiredSecrets  string                `json:"ExpiredSecrets" enums:"include,exclude,flag" description:"What to do with expired secrets, include by default"`
+		Kubernetes      KubernetesOptions     `json:"Kubernetes" description:"Options of kubernetes format"`
+		Terraform       TerraformOptions      `json:"Terraform" description:"Options of tfvars and tfvars-json formats"`
+		Template        TemplateOptions       `json:"Template" description:"Options of template format"`
+		Recursive       bool                  `json:"Recursive" description:"Export sub-folders as well, each one into its own file in the archive, requires archive type" example:"false"`
+		Depth           uint                  `json:"Depth" description:"How many levels of sub-folders are exported recursively, unlimited if 0" example:"0"`
+		Sign            bool                  `json:"Sign" description:"Add manifest with checksums of files signed by the server signing key, requires archive type" example:"false"`
+		Encryption      EncryptionOptions     `json:"Encryption" description:"Encryption of the exported file or archive"`
+	}
+
+	EncryptionOptions struct {
+		Type          string   `json:"Type" enums:"age,openpgp" description:"Type of encryption, nothing is encrypted by default" example:"age"`
+		Recipients    []string `json:"Recipients" description:"Public keys of recipients, age X25519 (age1...) or armored OpenPGP ones"`
+		RecipientUIDs []string `json:"RecipientUIDs" description:"Secrets storing public keys of recipients"`
+		Passphrase    string   `json:"Passphrase" description:"Passphrase to encrypt with instead of recipient public keys"`
+		Armor         bool     `json:"Armor" description:"Encode encrypted data as ASCII armor (PEM-like text)" example:"false"`
+	}
+
+	CreateExportRecipientRQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder to store public key in" example:"abc-def-ghi"`
+		Name      string `json:"Name" description:"Name of the public key secret" example:"OPS_TEAM"`
+		Type      string `json:"Type" enums:"age,openpgp" description:"Type of the public key" example:"age"`
+		PublicKey string `json:"PublicKey" description:"Public key, age X25519 (age1...) or armored OpenPGP one" example:"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"`
+	}
+
+	ExportPublicKey struct {
+		Algorithm string `json:"Algorithm" description:"Signature algorithm" example:"ed25519"`
+		KeyID     string `json:"KeyID" description:"Identifier of the key written into signed manifests" example:"3f2a9c4e1b7d8a60"`
+		PublicKey string `json:"PublicKey" description:"Base64-encoded raw public key" example:"MCowBQYDK2VwAyEA..."`
+		PEM       string `json:"PEM" description:"Public key as PKIX PEM block"`
+	}
+
+	ExportPublicKeyRS struct {
+		Data *ExportPublicKey `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	CreateExportRecipientRS struct {
+		Data *Secret `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	KubernetesOptions struct {
+		Kind        string            `json:"Kind" enums:"Secret,ConfigMap" description:"Kind of manifests, Secret by default" example:"Secret"`
+		Name        string            `json:"Name" description:"Name of the manifest, folder names are appended for sub-folders (secrets by default)" example:"backend"`
+		Namespace   string            `json:"Namespace" description:"Namespace of manifests" example:"production"`
+		Type        string            `json:"Type" description:"Type of Secret manifests, Opaque by default" example:"Opaque"`
+		Labels      map[string]string `json:"Labels" description:"Labels of manifests"`
+		Annotations map[string]string `json:"Annotations" description:"Annotations of manifests"`
+		Split       bool              `json:"Split" description:"Put manifest of every folder in a separate file instead of multi-document YAML, requires archive type" example:"false"`
+	}
+
+	TerraformOptions struct {
+		NameCase     string `json:"NameCase" enums:"lower" description:"Case of variable names, kept as is by default" example:"lower"`
+		PrefixPath   bool   `json:"PrefixPath" description:"Prefix variable names with names of folders below the exported one" example:"false"`
+		Declarations bool   `json:"Declarations" description:"Add variables.tf with sensitive variable declarations, requires archive type" example:"false"`
+	}
+
+	TemplateOptions struct {
+		Text      string `json:"Text" description:"Go text/template rendered with exported secrets, either it or SecretUID is required" example:"{{range .Secrets}}{{.Name}}={{.Value}}\n{{end}}"`
+		SecretUID string `json:"SecretUID" description:"Secret storing the template" example:"abc-def-ghi"`
+		Extension string `json:"Extension" description:"Extension of the exported file, .txt by default" example:".conf"`
+	}
+
+	// TemplateData Data export templates are rendered with
+	TemplateData struct {
+		Secrets []Secret          // Exported secrets in the requested order, including their folder path
+		Values  map[string]string // Values of exported secrets by their names
+	}
+
+	ExportOptions struct {
+		FlagExpired  bool
+		Kubernetes   KubernetesOptions
+		Terraform    TerraformOptions
+		TemplateText string             // Text of template, either from request or from the referenced secret
+		SigningKey   ed25519.PrivateKey // Key manifests are signed with, nothing is signed if empty
+	}
+
+	// ExportManifest Contents of the archive with recursively exported secrets
+	ExportManifest struct {
+		Format    string               `json:"Format"`
+		CreatedAt time.Time            `json:"CreatedAt"`
+		KeyID     string               `json:"KeyID,omitempty"` // Identifier of the key manifest is signed with
+		Files     []ExportManifestFile `json:"Files"`
+	}
+
+	ExportManifestFile struct {
+		Path    string `json:"Path"`              // Path of the file in archive
+		Folder  string `json:"Folder,omitempty"`  // Path of the folder relative to the exported one (recursive exports only)
+		Secrets int    `json:"Secrets,omitempty"` // Amount of secrets in the file (recursive exports only)
+		SHA256  string `json:"SHA256"`            // Hex-encoded checksum of the file
+	}
+
+	// ExportFile Exported file, rendered again whenever written instead of being kept in memory
+	ExportFile struct {
+		archive.File
+		SHA256 string // Checksum of the rendered file, known once measured
+	}
+
+	// ExportEncryptor Wraps writer of exported data into encryption, closing the returned writer finishes encrypted output
+	ExportEncryptor func(writer io.Writer) (io.WriteCloser, error)
+
+	// encryptedWriter Encrypting writer put on top of an encoding one (ASCII armor), both are closed in order
+	encryptedWriter struct {
+		io.WriteCloser
+		encoder io.Closer
+	}
+
+	ImportSecretsRQ struct {
+		FolderUID string `form:"FolderUID" json:"FolderUID" description:"Folder secrets are imported into" example:"abc-def-ghi"`
+		Format    string `form:"Format" json:"Format" enums:"dotenv,json,yaml,archive,vault,bitwarden,1password,keepass,aws" description:"Format of the file, detected by its extension by default (exports of other secret managers other than KeePass XML need it to be given)" example:"dotenv"`
+		Conflict  string `form:"Conflict" json:"Conflict" enums:"skip,overwrite,fail" description:"What to do with secrets that already exist, skip by default" example:"skip"`
+		DryRun    bool   `form:"DryRun" json:"DryRun" description:"Only report changes the import would make" example:"false"`
+	}
+
+	ImportChange struct {
+		Action string `json:"Action" enums:"create-folder,create,update,unchanged,skip" description:"What is done with the folder or secret" example:"create"`
+		Folder string `json:"Folder" description:"Path of the folder relative to the target one" example:"backend/db"`
+		Name   string `json:"Name" description:"Name of the secret (empty for folders)" example:"DB_PASSWORD"`
+		UID    string `json:"UID" description:"Unique identifier of the existing or created folder or secret" example:"abc-def-ghi"`
+	}
+
+	ImportResult struct {
+		DryRun         bool           `json:"DryRun" description:"Whether changes were only planned" example:"false"`
+		Changes        []ImportChange `json:"Changes" description:"Changes in the order they are made"`
+		FoldersCreated int            `json:"FoldersCreated" example:"1"`
+		Created        int            `json:"Created" example:"10"`
+		Updated        int            `json:"Updated" example:"2"`
+		Unchanged      int            `json:"Unchanged" example:"3"`
+		Skipped        int            `json:"Skipped" example:"0"`
+	}
+
+	ImportSecretsRS struct {
+		Data *ImportResult `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	// importEntry Secret read from imported file along with names of folders below the target one
+	importEntry struct {
+		path      []string
+		name      string
+		value     string
+		expiresAt time.Time // Expiration date kept by the other secret manager (zero if none)
+	}
+
+	// importItem Item of other secret manager (login, note, card etc.) imported as a folder with a secret per field
+	importItem struct {
+		path      []string
+		expiresAt time.Time
+		names     map[string]bool
+		entries   []importEntry
+	}
+
+	vaultResponse struct {
+		RequestID string                 `json:"request_id"`
+		Data      map[string]interface{} `json:"data"`
+	}
+
+	bitwardenExport struct {
+		Encrypted   bool                  `json:"encrypted"`
+		Folders     []bitwardenCollection `json:"folders"`
+		Collections []bitwardenCollection `json:"collections"`
+		Items       []bitwardenItem       `json:"items"`
+	}
+
+	bitwardenCollection struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	bitwardenItem struct {
+		FolderID      string                 `json:"folderId"`
+		CollectionIDs []string               `json:"collectionIds"`
+		Name          string                 `json:"name"`
+		Notes         string                 `json:"notes"`
+		Fields        []bitwardenField       `json:"fields"`
+		Login         map[string]interface{} `json:"login"`
+		Card          map[string]interface{} `json:"card"`
+		Identity      map[string]interface{} `json:"identity"`
+		SSHKey        map[string]interface{} `json:"sshKey"`
+	}
+
+	bitwardenField struct {
+		Name  string      `json:"name"`
+		Value interface{} `json:"value"`
+	}
+
+	onePasswordItem struct {
+		Title string `json:"title"`
+		Vault struct {
+			Name string `json:"name"`
+		} `json:"vault"`
+		Tags   []string           `json:"tags"`
+		Fields []onePasswordField `json:"fields"`
+		URLs   []struct {
+			Href string `json:"href"`
+		} `json:"urls"`
+	}
+
+	onePasswordField struct {
+		Purpose string `json:"purpose"`
+		Label   string `json:"label"`
+		Value   string `json:"value"`
+		Section struct {
+			Label string `json:"label"`
+		} `json:"section"`
+	}
+
+	keePassFile struct {
+		Meta struct {
+			RecycleBinEnabled string `xml:"RecycleBinEnabled"`
+			RecycleBinUUID    string `xml:"RecycleBinUUID"`
+		} `xml:"Meta"`
+		Root struct {
+			Groups []keePassGroup `xml:"Group"`
+		} `xml:"Root"`
+	}
+
+	keePassGroup struct {
+		UUID    string         `xml:"UUID"`
+		Name    string         `xml:"Name"`
+		Entries []keePassEntry `xml:"Entry"`
+		Groups  []keePassGroup `xml:"Group"`
+	}
+
+	keePassEntry struct {
+		Strings []struct {
+			Key   string `xml:"Key"`
+			Value string `xml:"Value"`
+		} `xml:"String"`
+		Times struct {
+			Expires    string `xml:"Expires"`
+			ExpiryTime string `xml:"ExpiryTime"`
+		} `xml:"Times"`
+	}
+
+	// awsListing Output of get-secret-value (single secret), batch-get-secret-value or list-secrets with values added
+	awsListing struct {
+		awsSecret
+		SecretValues []awsSecret `json:"SecretValues"`
+		SecretList   []awsSecret `json:"SecretList"`
+	}
+
+	awsSecret struct {
+		Name         string  `json:"Name"`
+		SecretString *string `json:"SecretString"`
+		SecretBinary string  `json:"SecretBinary"`
+	}
+
+	// importStep Planned change of import, folders which do not exist yet are only known by their paths
+	importStep struct {
+		change    ImportChange
+		folder    *folders.Folder  // Existing folder of the secret or parent of the created folder
+		parentKey string           // Path of the folder (or parent of the created folder) to look it up once created
+		name      string           // Name of the secret or created folder
+		value     string           // Imported value of the secret
+		expiresAt time.Time        // Imported expiration date of the secret (zero if none)
+		existing  *secrets2.Secret // Existing secret being updated
 	}
 
 	ExportSecretsRS struct {
@@ -5,6 +5,7 @@ import (
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
 	"hideout/internal/common/generics"
 	"hideout/internal/common/model"
 	"hideout/internal/common/rqrs"
@@ -12,6 +13,7 @@ import (
 	"hideout/services/secrets"
 	"regexp"
 	"strings"
+	"time"
 )
 
 func (rq GetSecretsRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
@@ -51,6 +53,18 @@ func (rq GetSecretsRQ) Validate(ctx context.Context, secretsService *secrets.Sec
 		}
 	}
 
+	errExpiresAt := generics.FromTo[time.Time]{From: rq.ExpiresFrom, To: rq.ExpiresTo}.Validate(ctx)
+	if errExpiresAt != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ExpiresAtWindowError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errors.Wrap(errExpiresAt, "Expiration window validation failed").Error(), Code: 0})
+	}
+	// Relative window would silently replace the absolute one
+	if rq.ExpiresWithin != 0 && (!rq.ExpiresFrom.IsZero() || !rq.ExpiresTo.IsZero()) {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ExpiresAtWindowError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errors.Wrap(apperror.ErrInvalidParameter,
+			"ExpiresWithin cannot be combined with ExpiresFrom and ExpiresTo").Error(), Code: 0})
+	}
+
 	return Errors
 }
 
@@ -241,11 +255,43 @@ func (rq ExportSecretsRQ) Validate(ctx context.Context, secretsService *secrets.
 		_, exportFormatExists := ExportFormatsMapInv[rq.Format]
 		if !exportFormatExists {
 			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
-				TemplateData: map[string]interface{}{"Name": "Format", "Values": strings.Join([]string{ExportFormatsMap[ExportFormat_DotEnv]}, ",")}})
+				TemplateData: map[string]interface{}{"Name": "Format", "Values": strings.Join([]string{ExportFormatsMap[ExportFormat_DotEnv], ExportFormatsMap[ExportFormat_PEM],
+					ExportFormatsMap[ExportFormat_JSON], ExportFormatsMap[ExportFormat_JSONNested], ExportFormatsMap[ExportFormat_YAML],
+					ExportFormatsMap[ExportFormat_TOML], ExportFormatsMap[ExportFormat_Properties], ExportFormatsMap[ExportFormat_Kubernetes],
+					ExportFormatsMap[ExportFormat_Bash], ExportFormatsMap[ExportFormat_Fish], ExportFormatsMap[ExportFormat_PowerShell],
+					ExportFormatsMap[ExportFormat_Systemd], ExportFormatsMap[ExportFormat_Compose], ExportFormatsMap[ExportFormat_TFVars],
+					ExportFormatsMap[ExportFormat_TFVarsJSON], ExportFormatsMap[ExportFormat_Template]}, ",")}})
 			Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
 		}
 	}
 
+	_, expiredSecretsExists := ExpiredSecretsMapInv[rq.ExpiredSecrets]
+	if !expiredSecretsExists {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "ExpiredSecrets", "Values": strings.Join([]string{
+				ExpiredSecretsMap[ExpiredSecrets_Include], ExpiredSecretsMap[ExpiredSecrets_Exclude], ExpiredSecretsMap[ExpiredSecrets_Flag],
+			}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	if rq.Format == ExportFormatsMap[ExportFormat_Kubernetes] {
+		Errors = append(Errors, rq.Kubernetes.Validate(ctx, Localizer, rq.ArchiveType)...)
+	}
+	if rq.Format == ExportFormatsMap[ExportFormat_TFVars] || rq.Format == ExportFormatsMap[ExportFormat_TFVarsJSON] {
+		Errors = append(Errors, rq.Terraform.Validate(ctx, Localizer, rq.ArchiveType)...)
+	}
+	if rq.Format == ExportFormatsMap[ExportFormat_Template] {
+		Errors = append(Errors, rq.Template.Validate(ctx, secretsService, Localizer)...)
+	}
+
+	Errors = append(Errors, rq.Encryption.Validate(ctx, secretsService, Localizer)...)
+
+	if (rq.Recursive || rq.Sign) && rq.ArchiveType == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "ArchiveType"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
 	if rq.ArchiveType != "" {
 		_, exportArchiveTypeExists := ArchiveTypesMapInv[rq.ArchiveType]
 		if !exportArchiveTypeExists {
@@ -254,28 +300,239 @@ func (rq ExportSecretsRQ) Validate(ctx context.Context, secretsService *secrets.
 					ArchiveTypesMap[ArchiveType_Tar]}, ",")}})
 			Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
 			return Errors
-		} else {
-			if rq.CompressionType == "" {
-				msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
-					TemplateData: map[string]interface{}{"Name": "CompressionType"}})
-				Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
-			} else {
-				_, compressionTypeExists := CompressionTypesMapInv[rq.CompressionType]
-				if !compressionTypeExists {
-					msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
-						TemplateData: map[string]interface{}{"Name": "CompressionType", "Values": strings.Join([]string{
-							strings.Join(CompressionTypesMap[CompressionType_Brotli], ","), strings.Join(CompressionTypesMap[CompressionType_Bzip2], ","),
-							strings.Join(CompressionTypesMap[CompressionType_Flate], ","), strings.Join(CompressionTypesMap[CompressionType_Gzip], ","),
-							strings.Join(CompressionTypesMap[CompressionType_Lz4], ","), strings.Join(CompressionTypesMap[CompressionType_Lzip], ","),
-							strings.Join(CompressionTypesMap[CompressionType_Minlz], ","), strings.Join(CompressionTypesMap[CompressionType_Snappy], ","),
-							strings.Join(CompressionTypesMap[CompressionType_XZ], ","), strings.Join(CompressionTypesMap[CompressionType_Zlib], ","),
-							strings.Join(CompressionTypesMap[CompressionType_Zstandard], ","),
-						}, ", ")}})
-					Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
-				}
-			}
+		} else if rq.CompressionType == "" {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+				TemplateData: map[string]interface{}{"Name": "CompressionType"}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		}
+	}
+
+	// Without archive type the single exported file is compressed as is
+	if rq.CompressionType != "" {
+		compressionType, compressionTypeExists := CompressionTypesMapInv[rq.CompressionType]
+		if !compressionTypeExists {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+				TemplateData: map[string]interface{}{"Name": "CompressionType", "Values": strings.Join([]string{
+					strings.Join(CompressionTypesMap[CompressionType_Brotli], ","), strings.Join(CompressionTypesMap[CompressionType_Bzip2], ","),
+					strings.Join(CompressionTypesMap[CompressionType_Flate], ","), strings.Join(CompressionTypesMap[CompressionType_Gzip], ","),
+					strings.Join(CompressionTypesMap[CompressionType_Lz4], ","), strings.Join(CompressionTypesMap[CompressionType_Lzip], ","),
+					strings.Join(CompressionTypesMap[CompressionType_Minlz], ","), strings.Join(CompressionTypesMap[CompressionType_Snappy], ","),
+					strings.Join(CompressionTypesMap[CompressionType_XZ], ","), strings.Join(CompressionTypesMap[CompressionType_Zlib], ","),
+					strings.Join(CompressionTypesMap[CompressionType_Zstandard], ","),
+				}, ", ")}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		} else if compressionType == CompressionType_Flate && rq.ArchiveType != ArchiveTypesMap[ArchiveType_Zip] {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "FlateCompressionError"}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: ErrFlateZipOnly.Error(), Code: 0})
+		}
+	}
+
+	return Errors
+}
+
+func (rq KubernetesOptions) Validate(ctx context.Context, Localizer *i18n.Localizer, archiveType string) (Errors []rqrs.Error) {
+	if rq.Kind != "" && !KubernetesKindsMap[rq.Kind] {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Kubernetes.Kind", "Values": strings.Join([]string{KubernetesKind_Secret,
+				KubernetesKind_ConfigMap}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	// Folder names are appended to the name, so it is kept well below the limit of 253 characters
+	if rq.Name != "" && (len(rq.Name) > 200 || !kubernetesNameRegex.MatchString(rq.Name)) {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "KubernetesNameError"},
+			TemplateData: map[string]interface{}{"Name": "Kubernetes.Name"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	if rq.Namespace != "" && (len(rq.Namespace) > 63 || !kubernetesNamespaceRegex.MatchString(rq.Namespace)) {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "KubernetesNameError"},
+			TemplateData: map[string]interface{}{"Name": "Kubernetes.Namespace"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	if rq.Split && archiveType == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "ArchiveType"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq TerraformOptions) Validate(ctx context.Context, Localizer *i18n.Localizer, archiveType string) (Errors []rqrs.Error) {
+	if !TerraformNameCasesMap[rq.NameCase] {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Terraform.NameCase", "Values": TerraformNameCase_Lower}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	if rq.Declarations && archiveType == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "ArchiveType"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq TemplateOptions) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	if (rq.Text == "") == (rq.SecretUID == "") {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "OnlyTemplateOrSecretError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		return Errors
+	}
+
+	if rq.Extension != "" && !templateExtensionRegex.MatchString(rq.Extension) {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "TemplateExtensionError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	templateText, errGetTemplate := rq.GetText(ctx, secretsService)
+	if errGetTemplate != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetSecretByUIDError"},
+			TemplateData: map[string]interface{}{"UID": rq.SecretUID}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errGetTemplate.Error(), Code: 0})
+		return Errors
+	}
+
+	_, errParse := ParseTemplate(templateText)
+	if errParse != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "TemplateError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errParse.Error(), Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq EncryptionOptions) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	encryptionType, encryptionTypeExists := EncryptionTypesMapInv[rq.Type]
+	if !encryptionTypeExists {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Encryption.Type", "Values": strings.Join([]string{
+				EncryptionTypesMap[EncryptionType_Age], EncryptionTypesMap[EncryptionType_OpenPGP]}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		return Errors
+	}
+
+	hasRecipients := len(rq.Recipients) > 0 || len(rq.RecipientUIDs) > 0
+	if encryptionType == EncryptionType_None {
+		if hasRecipients || rq.Passphrase != "" || rq.Armor {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionNotRequestedError"}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		}
+		return Errors
+	}
+
+	if hasRecipients == (rq.Passphrase != "") {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "OnlyRecipientsOrPassphraseError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		return Errors
+	}
+
+	for _, recipientUID := range rq.RecipientUIDs {
+		_, errGetRecipient := rq.getRecipientKey(ctx, secretsService, recipientUID)
+		if errors.Is(errGetRecipient, ErrInvalidRecipient) {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionRecipientError"}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: errGetRecipient.Error(), Code: 0})
+		} else if errGetRecipient != nil {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetSecretByUIDError"},
+				TemplateData: map[string]interface{}{"UID": recipientUID}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: errGetRecipient.Error(), Code: 0})
+		}
+	}
+	if len(Errors) > 0 {
+		return Errors
+	}
+
+	recipientKeys, errGetRecipientKeys := rq.GetRecipientKeys(ctx, secretsService)
+	if errGetRecipientKeys == nil {
+		_, errGetRecipientKeys = NewExportEncryptor(encryptionType, recipientKeys, rq.Passphrase, rq.Armor)
+	}
+	if errGetRecipientKeys != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionRecipientError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errGetRecipientKeys.Error(), Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq CreateExportRecipientRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	folderByUID, errGetFolderByUID := secretsService.GetFolderByUID(ctx, rq.FolderUID)
+	if errGetFolderByUID != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetFolderByUIDError"},
+			TemplateData: map[string]interface{}{"UID": rq.FolderUID}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errGetFolderByUID.Error(), Code: 0})
+	} else if rq.Name != "" {
+		// Public keys of recipients are replaced, while other secrets of the same name are left intact
+		existingSecret, errGetSecret := secretsService.GetSecretByName(ctx, folderByUID.ID, rq.Name)
+		if errGetSecret == nil && existingSecret.Type != secrets2.Type_AgeRecipient && existingSecret.Type != secrets2.Type_OpenPGPPublicKey {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SecretAlreadyExistsError"},
+				TemplateData: map[string]interface{}{"Name": rq.Name, "FolderUID": rq.FolderUID}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
 		}
 	}
 
+	if rq.Name == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "Name"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	} else if !variableNameRegex.MatchString(rq.Name) {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "InvalidSecretNameError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	encryptionType, encryptionTypeExists := EncryptionTypesMapInv[rq.Type]
+	if !encryptionTypeExists || encryptionType == EncryptionType_None {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Type", "Values": strings.Join([]string{
+				EncryptionTypesMap[EncryptionType_Age], EncryptionTypesMap[EncryptionType_OpenPGP]}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+		return Errors
+	}
+
+	if rq.PublicKey == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "PublicKey"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	} else if errParse := ParseRecipientKey(encryptionType, rq.PublicKey); errParse != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionRecipientError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errParse.Error(), Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq ImportSecretsRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	if rq.FolderUID == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "FolderUID"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	} else {
+		_, errGetFolderByUID := secretsService.GetFolderByUID(ctx, rq.FolderUID)
+		if errGetFolderByUID != nil {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetFolderByUIDError"},
+				TemplateData: map[string]interface{}{"UID": rq.FolderUID}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: errGetFolderByUID.Error(), Code: 0})
+		}
+	}
+
+	if _, formatExists := ImportFormatsMapInv[rq.Format]; !formatExists {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Format", "Values": strings.Join([]string{
+				ImportFormatsMap[ImportFormat_DotEnv], ImportFormatsMap[ImportFormat_JSON], ImportFormatsMap[ImportFormat_YAML],
+				ImportFormatsMap[ImportFormat_Archive], ImportFormatsMap[ImportFormat_Vault], ImportFormatsMap[ImportFormat_Bitwarden],
+				ImportFormatsMap[ImportFormat_OnePassword], ImportFormatsMap[ImportFormat_KeePass], ImportFormatsMap[ImportFormat_AWS]}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	if _, conflictExists := ImportConflictsMapInv[rq.Conflict]; !conflictExists {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Conflict", "Values": strings.Join([]string{
+				ImportConflictsMap[ImportConflict_Skip], ImportConflictsMap[ImportConflict_Overwrite],
+				ImportConflictsMap[ImportConflict_Fail]}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
 	return Errors
 }
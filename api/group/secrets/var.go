@@ -1,6 +1,36 @@
 package secrets
 
+import (
+	"github.com/pkg/errors"
+	secrets2 "hideout/internal/secrets"
+	"regexp"
+)
+
 var (
+	ErrUnknownExportFormat    = errors.New("Unknown export format")
+	ErrExportKeyConflict      = errors.New("Secret and folder (or two secrets) share the same key in export")
+	ErrInvalidKubernetesKey   = errors.New("Secret name is not a valid kubernetes data key")
+	ErrInvalidVariableName    = errors.New("Secret name is not a valid variable name for the export format")
+	ErrTemplateInvalid        = errors.New("Invalid export template")
+	ErrTemplateOutputLimit    = errors.New("Rendered export template exceeds the size limit")
+	ErrTemplateIterationLimit = errors.New("Rendering export template exceeds the iteration limit")
+	ErrExportFileLimit        = errors.New("Exported file exceeds the size limit")
+	ErrUnknownEncryption      = errors.New("Unknown encryption type")
+	ErrInvalidRecipient       = errors.New("Invalid public key of export recipient")
+	ErrFlateZipOnly           = errors.New("Zip compression is only available for zip archives")
+	ErrUnknownImportFormat    = errors.New("Unknown format of imported file")
+	ErrImportSyntax           = errors.New("Malformed imported file")
+	ErrImportValue            = errors.New("Lists cannot be imported as secret values")
+	ErrImportInvalidName      = errors.New("Invalid name of imported secret or folder")
+	ErrImportDuplicate        = errors.New("Secret is imported more than once")
+	ErrImportConflict         = errors.New("Secret already exists in the folder")
+	ErrImportEncrypted        = errors.New("Encrypted exports cannot be imported")
+	ErrImportNoValue          = errors.New("Imported secret has no value")
+
+	// TemplateBuiltins Built-in template functions allowed in export templates, those formatting, indexing or calling
+	// arbitrary values are not
+	TemplateBuiltins = []string{"and", "or", "not", "len", "eq", "ne", "lt", "le", "gt", "ge"}
+
 	CompressionTypesMap = map[uint][]string{CompressionType_Brotli: {"brotli"}, CompressionType_Bzip2: {"bzip2"}, CompressionType_Flate: {"zip"},
 		CompressionType_Gzip: {"gzip"}, CompressionType_Lz4: {"lz4"}, CompressionType_Lzip: {"lz"}, CompressionType_Minlz: {"mz"},
 		CompressionType_Snappy: {"sz", "s2"}, CompressionType_XZ: {"xz"}, CompressionType_Zlib: {"zz"}, CompressionType_Zstandard: {"zst"}}
@@ -9,13 +39,97 @@ var (
 		"gzip": CompressionType_Gzip, "lz4": CompressionType_Lz4, "lz": CompressionType_Lzip, "mz": CompressionType_Minlz,
 		"sz": CompressionType_Snappy, "s2": CompressionType_Snappy, "xz": CompressionType_XZ, "zz": CompressionType_Zlib, "zst": CompressionType_Zstandard}
 
+	CompressionExtensionsMap = map[uint]string{CompressionType_Brotli: ".br", CompressionType_Bzip2: ".bz2", CompressionType_Gzip: ".gz",
+		CompressionType_Lz4: ".lz4", CompressionType_Lzip: ".lz", CompressionType_Minlz: ".mz", CompressionType_Snappy: ".sz",
+		CompressionType_XZ: ".xz", CompressionType_Zlib: ".zz", CompressionType_Zstandard: ".zst"}
+
+	CompressionContentTypesMap = map[uint]string{CompressionType_Brotli: "application/x-brotli", CompressionType_Bzip2: "application/x-bzip2",
+		CompressionType_Gzip: "application/gzip", CompressionType_Lz4: "application/x-lz4", CompressionType_Lzip: "application/x-lzip",
+		CompressionType_XZ: "application/x-xz", CompressionType_Zlib: "application/zlib", CompressionType_Zstandard: "application/zstd"}
+
+	ArchiveContentTypesMap = map[uint]string{ArchiveType_Tar: "application/x-tar", ArchiveType_Zip: "application/zip"}
+
 	ArchiveTypesMap = map[uint]string{ArchiveType_None: "", ArchiveType_Tar: "tar", ArchiveType_Zip: "zip"}
 
 	ArchiveTypesMapInv = map[string]uint{"": ArchiveType_None, "tar": ArchiveType_Tar, "zip": ArchiveType_Zip}
 
-	ExportFormatsMap = map[uint]string{ExportFormat_DotEnv: "dotenv"}
+	ExportFormatsMap = map[uint]string{ExportFormat_DotEnv: "dotenv", ExportFormat_PEM: "pem", ExportFormat_JSON: "json",
+		ExportFormat_JSONNested: "json-nested", ExportFormat_YAML: "yaml", ExportFormat_TOML: "toml", ExportFormat_Properties: "properties",
+		ExportFormat_Kubernetes: "kubernetes", ExportFormat_Bash: "bash", ExportFormat_Fish: "fish", ExportFormat_PowerShell: "powershell",
+		ExportFormat_Systemd: "systemd", ExportFormat_Compose: "docker-compose", ExportFormat_TFVars: "tfvars", ExportFormat_TFVarsJSON: "tfvars-json",
+		ExportFormat_Template: "template"}
+
+	ExportFormatsMapInv = map[string]uint{"dotenv": ExportFormat_DotEnv, "pem": ExportFormat_PEM, "json": ExportFormat_JSON,
+		"json-nested": ExportFormat_JSONNested, "yaml": ExportFormat_YAML, "toml": ExportFormat_TOML, "properties": ExportFormat_Properties,
+		"kubernetes": ExportFormat_Kubernetes, "bash": ExportFormat_Bash, "fish": ExportFormat_Fish, "powershell": ExportFormat_PowerShell,
+		"systemd": ExportFormat_Systemd, "docker-compose": ExportFormat_Compose, "tfvars": ExportFormat_TFVars, "tfvars-json": ExportFormat_TFVarsJSON,
+		"template": ExportFormat_Template}
+
+	ExportExtensionsMap = map[uint]string{ExportFormat_DotEnv: ".env", ExportFormat_PEM: ".pem", ExportFormat_JSON: ".json",
+		ExportFormat_JSONNested: ".json", ExportFormat_YAML: ".yaml", ExportFormat_TOML: ".toml", ExportFormat_Properties: ".properties",
+		ExportFormat_Kubernetes: ".yaml", ExportFormat_Bash: ".sh", ExportFormat_Fish: ".fish", ExportFormat_PowerShell: ".ps1",
+		ExportFormat_Systemd: ".conf", ExportFormat_Compose: ".yaml", ExportFormat_TFVars: ".tfvars", ExportFormat_TFVarsJSON: ".tfvars.json",
+		ExportFormat_Template: ".txt"}
+
+	ExportContentTypesMap = map[uint]string{ExportFormat_PEM: "application/x-pem-file", ExportFormat_JSON: "application/json",
+		ExportFormat_JSONNested: "application/json", ExportFormat_YAML: "application/yaml", ExportFormat_TOML: "application/toml",
+		ExportFormat_Kubernetes: "application/yaml", ExportFormat_Compose: "application/yaml", ExportFormat_TFVarsJSON: "application/json"}
+
+	ExpiredSecretsMap = map[uint]string{ExpiredSecrets_Include: "include", ExpiredSecrets_Exclude: "exclude", ExpiredSecrets_Flag: "flag"}
+
+	ExpiredSecretsMapInv = map[string]uint{"": ExpiredSecrets_Include, "include": ExpiredSecrets_Include, "exclude": ExpiredSecrets_Exclude,
+		"flag": ExpiredSecrets_Flag}
+
+	EncryptionTypesMap = map[uint]string{EncryptionType_None: "", EncryptionType_Age: "age", EncryptionType_OpenPGP: "openpgp"}
+
+	EncryptionTypesMapInv = map[string]uint{"": EncryptionType_None, "age": EncryptionType_Age, "openpgp": EncryptionType_OpenPGP}
+
+	EncryptionContentTypesMap = map[uint]string{EncryptionType_Age: "application/octet-stream", EncryptionType_OpenPGP: "application/pgp-encrypted"}
+
+	// Types of secrets recipient public keys of encrypted exports are stored as
+	EncryptionRecipientTypesMap = map[uint]string{EncryptionType_Age: secrets2.Type_AgeRecipient,
+		EncryptionType_OpenPGP: secrets2.Type_OpenPGPPublicKey}
+
+	ImportFormatsMap = map[uint]string{ImportFormat_Detect: "", ImportFormat_DotEnv: "dotenv", ImportFormat_JSON: "json",
+		ImportFormat_YAML: "yaml", ImportFormat_Archive: "archive", ImportFormat_Vault: "vault", ImportFormat_Bitwarden: "bitwarden",
+		ImportFormat_OnePassword: "1password", ImportFormat_KeePass: "keepass", ImportFormat_AWS: "aws"}
+
+	ImportFormatsMapInv = map[string]uint{"": ImportFormat_Detect, "dotenv": ImportFormat_DotEnv, "json": ImportFormat_JSON,
+		"yaml": ImportFormat_YAML, "archive": ImportFormat_Archive, "vault": ImportFormat_Vault, "bitwarden": ImportFormat_Bitwarden,
+		"1password": ImportFormat_OnePassword, "keepass": ImportFormat_KeePass, "aws": ImportFormat_AWS}
+
+	// Formats of imported files (standalone or inside archives) by their extensions
+	ImportExtensionsMap = map[string]uint{".env": ImportFormat_DotEnv, ".json": ImportFormat_JSON, ".yaml": ImportFormat_YAML,
+		".yml": ImportFormat_YAML, ".xml": ImportFormat_KeePass}
+
+	// Bitwarden login fields and 1Password field purposes imported under common names
+	bitwardenLoginFieldsMap = map[string]string{"username": ImportField_UserName, "password": ImportField_Password, "totp": ImportField_TOTP}
+	onePasswordPurposesMap  = map[string]string{"USERNAME": ImportField_UserName, "PASSWORD": ImportField_Password, "NOTES": ImportField_Notes}
+	keePassStringsMap       = map[string]string{"UserName": ImportField_UserName, "Password": ImportField_Password,
+		"URL": ImportField_URL, "Notes": ImportField_Notes}
+
+	ImportConflictsMap = map[uint]string{ImportConflict_Skip: "skip", ImportConflict_Overwrite: "overwrite", ImportConflict_Fail: "fail"}
+
+	ImportConflictsMapInv = map[string]uint{"": ImportConflict_Skip, "skip": ImportConflict_Skip, "overwrite": ImportConflict_Overwrite,
+		"fail": ImportConflict_Fail}
+
+	KubernetesKindsMap = map[string]bool{KubernetesKind_Secret: true, KubernetesKind_ConfigMap: true}
+
+	kubernetesNameRegex      = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	kubernetesNamespaceRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	kubernetesKeyRegex       = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	kubernetesInvalidRegex   = regexp.MustCompile(`[^a-z0-9.-]+`)
+	tomlBareKeyRegex         = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	variableNameRegex        = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	terraformNameRegex       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	terraformInvalidRegex    = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+	templateExtensionRegex   = regexp.MustCompile(`^(\.[A-Za-z0-9]{1,16}){1,3}$`)
+	importNameRegex          = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	importNameInvalidRegex   = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
 
-	ExportFormatsMapInv = map[string]uint{"dotenv": ExportFormat_DotEnv}
+	// Names Terraform reserves for its own use in variable blocks
+	TerraformReservedNamesMap = map[string]bool{"source": true, "version": true, "providers": true, "count": true, "for_each": true,
+		"lifecycle": true, "depends_on": true, "locals": true}
 
-	ExportExtensionsMap = map[uint]string{ExportFormat_DotEnv: ".env"}
+	TerraformNameCasesMap = map[string]bool{"": true, TerraformNameCase_Lower: true}
 )
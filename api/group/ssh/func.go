@@ -0,0 +1,17 @@
+package ssh
+
+import (
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/internal/common/rqrs"
+	"hideout/services/ssh"
+	"strings"
+)
+
+func validateKeyType(Localizer *i18n.Localizer, keyType string) (Errors []rqrs.Error) {
+	if keyType != "" && keyType != ssh.KeyType_Ed25519 && keyType != ssh.KeyType_RSA {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "KeyType", "Values": strings.Join([]string{ssh.KeyType_Ed25519, ssh.KeyType_RSA}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	return Errors
+}
@@ -0,0 +1,378 @@
+package ssh
+
+import (
+	"context"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	apiconfig "hideout/cmd/api/config"
+	"hideout/internal/common/rqrs"
+	"hideout/internal/pkg/encryption"
+	"hideout/services/secrets"
+	"hideout/services/ssh"
+	"hideout/structs"
+	"log"
+	"net/http"
+	"time"
+)
+
+// GenerateKeyPairHandler
+// @Summary Generate SSH key pair
+// @Description Generate SSH key pair stored as linked private and public key secrets of the folder, existing pair is only replaced when rotated
+// @ID ssh-generate-key-pair
+// @Tags SSH
+// @Produce json
+// @Param params body GenerateKeyPairRQ true "Key pair generation request"
+// @Success 200 {object} GenerateKeyPairRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} GenerateKeyPairRS
+// @Failure 404 {object} GenerateKeyPairRS
+// @Failure 409 {object} GenerateKeyPairRS
+// @Failure 500 {object} GenerateKeyPairRS
+// @Router /ssh/keys/ [put]
+func GenerateKeyPairHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.generate.ssh.key.pair")
+	validationSpan.Description = "rq.validate"
+
+	var request GenerateKeyPairRQ
+	response := GenerateKeyPairRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "generate.ssh.key.pair")
+	runSpan.Description = "run"
+
+	// Only private key of the certificate authority is encrypted
+	sshSvc := ssh.NewService(secretsSvc, nil)
+	keyPair, errGenerate := sshSvc.GenerateKeyPair(rqContext, Localizer, ssh.GenerateKeyPairParams{
+		FolderUID: request.FolderUID, Name: request.Name, KeyType: request.KeyType, Bits: request.Bits, Comment: request.Comment,
+		Rotate: request.Rotate,
+	})
+	if errGenerate != nil {
+		log.Printf("Error generating SSH key pair %s in folder with UID of %s: %s", request.Name, request.FolderUID, errGenerate.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GenerateSSHKeyPairError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGenerate.Error(), Code: 0})
+		c.JSON(engine.Status(errGenerate, errorStatuses), response)
+		return
+	}
+	response.Data = &KeyPair{PrivateKey: keyPair.PrivateKey, PublicKey: keyPair.PublicKey, Fingerprint: keyPair.Fingerprint,
+		PrivateKeyUID: keyPair.PrivateKeyUID, PublicKeyUID: keyPair.PublicKeyUID}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCAHandler
+// @Summary Create SSH certificate authority
+// @Description Create SSH certificate authority in the folder, its private key never leaves the storage
+// @ID ssh-create-ca
+// @Tags SSH
+// @Produce json
+// @Param params body CreateCARQ true "Certificate authority create request"
+// @Success 200 {object} CreateCARS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} CreateCARS
+// @Failure 404 {object} CreateCARS
+// @Failure 409 {object} CreateCARS
+// @Failure 500 {object} CreateCARS
+// @Router /ssh/ca/ [put]
+func CreateCAHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.create.ssh.ca")
+	validationSpan.Description = "rq.validate"
+
+	var request CreateCARQ
+	response := CreateCARS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "create.ssh.ca")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	sshSvc := ssh.NewService(secretsSvc, encryptionKey)
+	publicKey, errCreateCA := sshSvc.CreateCA(rqContext, Localizer, request.FolderUID, request.KeyType, request.Bits)
+	if errCreateCA != nil {
+		log.Printf("Error creating SSH certificate authority in folder with UID of %s: %s", request.FolderUID, errCreateCA.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSSHCAError"},
+			TemplateData: map[string]interface{}{"UID": request.FolderUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateCA.Error(), Code: 0})
+		c.JSON(engine.Status(errCreateCA, errorStatuses), response)
+		return
+	}
+	response.Data = &CAPublicKey{PublicKey: publicKey}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCAHandler
+// @Summary Get SSH certificate authority public key
+// @Description Get public key of the SSH certificate authority to be trusted by servers and clients
+// @ID ssh-get-ca
+// @Tags SSH
+// @Produce json
+// @Param uid path string true "Folder of the certificate authority"
+// @Success 200 {object} GetCARS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} GetCARS
+// @Failure 404 {object} GetCARS
+// @Failure 500 {object} GetCARS
+// @Router /ssh/ca/{uid} [get]
+func GetCAHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.get.ssh.ca")
+	validationSpan.Description = "rq.validate"
+
+	var request GetCARQ
+	response := GetCARS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindURI := c.ShouldBindUri(&request)
+	if errBindURI != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestURIMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindURI.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "get.ssh.ca")
+	runSpan.Description = "run"
+
+	// Only private key of the certificate authority is encrypted
+	sshSvc := ssh.NewService(secretsSvc, nil)
+	publicKey, errGetCA := sshSvc.GetCAPublicKey(rqContext, request.FolderUID)
+	if errGetCA != nil {
+		log.Printf("Error retrieving SSH certificate authority in folder with UID of %s: %s", request.FolderUID, errGetCA.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetSSHCAError"},
+			TemplateData: map[string]interface{}{"UID": request.FolderUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetCA.Error(), Code: 0})
+		c.JSON(engine.Status(errGetCA, errorStatuses), response)
+		return
+	}
+	response.Data = &CAPublicKey{PublicKey: publicKey}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// SignHandler
+// @Summary Sign SSH certificate
+// @Description Sign user or host certificate for the public key with the SSH certificate authority
+// @ID ssh-sign
+// @Tags SSH
+// @Produce json
+// @Param params body SignRQ true "Certificate sign request"
+// @Success 200 {object} SignRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} SignRS
+// @Failure 404 {object} SignRS
+// @Failure 500 {object} SignRS
+// @Router /ssh/sign/ [post]
+func SignHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.sign.ssh.certificate")
+	validationSpan.Description = "rq.validate"
+
+	var request SignRQ
+	response := SignRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "sign.ssh.certificate")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	sshSvc := ssh.NewService(secretsSvc, encryptionKey)
+	signParams := ssh.SignParams{FolderUID: request.FolderUID, PublicKey: request.PublicKey, CertType: request.CertType,
+		KeyID: request.KeyID, Principals: request.Principals, CriticalOptions: request.CriticalOptions, Extensions: request.Extensions}
+	if request.ValidAfter != nil {
+		signParams.ValidAfter = *request.ValidAfter
+	}
+	if request.ValidBefore != nil {
+		signParams.ValidBefore = *request.ValidBefore
+	} else if request.TTL > 0 {
+		validAfter := signParams.ValidAfter
+		if validAfter.IsZero() {
+			validAfter = time.Now()
+		}
+		signParams.ValidBefore = validAfter.Add(time.Duration(request.TTL) * time.Second)
+	}
+	certificate, errSign := sshSvc.Sign(rqContext, signParams)
+	if errSign != nil {
+		log.Printf("Error signing SSH certificate %s: %s", request.KeyID, errSign.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "SignSSHCertificateError"},
+			TemplateData: map[string]interface{}{"UID": request.FolderUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errSign.Error(), Code: 0})
+		c.JSON(engine.Status(errSign, errorStatuses), response)
+		return
+	}
+	response.Data = &Certificate{Certificate: certificate.Certificate, Serial: certificate.Serial,
+		ValidAfter: certificate.ValidAfter, ValidBefore: certificate.ValidBefore}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,79 @@
+package ssh
+
+import (
+	"hideout/internal/common/rqrs"
+	"time"
+)
+
+type (
+	KeyPair struct {
+		PrivateKey    string `json:"PrivateKey" description:"Private key in OpenSSH format"`
+		PublicKey     string `json:"PublicKey" description:"Public key in authorized_keys format" example:"ssh-ed25519 AAAA... deploy@ci"`
+		Fingerprint   string `json:"Fingerprint" description:"SHA256 fingerprint of the public key" example:"SHA256:Xv3..."`
+		PrivateKeyUID string `json:"PrivateKeyUID" description:"Unique identifier of the private key secret" example:"abc-def-ghi"`
+		PublicKeyUID  string `json:"PublicKeyUID" description:"Unique identifier of the public key secret" example:"abc-def-ghi"`
+	}
+
+	CAPublicKey struct {
+		PublicKey string `json:"PublicKey" description:"Public key of the certificate authority in authorized_keys format" example:"ssh-ed25519 AAAA..."`
+	}
+
+	Certificate struct {
+		Certificate string    `json:"Certificate" description:"Signed certificate in authorized_keys format" example:"ssh-ed25519-cert-v01@openssh.com AAAA..."`
+		Serial      uint64    `json:"Serial" description:"Certificate serial number" example:"1"`
+		ValidAfter  time.Time `json:"ValidAfter" description:"Certificate validity start date" example:"2030-01-01T00:00:00Z"`
+		ValidBefore time.Time `json:"ValidBefore" description:"Certificate expiration date" example:"2030-01-02T00:00:00Z"`
+	}
+
+	GenerateKeyPairRQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder to store key pair in" example:"abc-def-ghi"`
+		Name      string `json:"Name" description:"Prefix of the private (_PRIVATE_KEY) and public (_PUBLIC_KEY) key secret names" example:"DEPLOY"`
+		KeyType   string `json:"KeyType" enums:"ed25519,rsa" description:"Key type, ed25519 by default" example:"ed25519"`
+		Bits      int    `json:"Bits" description:"RSA key size" example:"3072"`
+		Comment   string `json:"Comment" description:"Key comment" example:"deploy@ci"`
+		Rotate    bool   `json:"Rotate" description:"Replace existing key pair of the same name, otherwise it is reported as a conflict" example:"false"`
+	}
+
+	GenerateKeyPairRS struct {
+		Data *KeyPair `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	CreateCARQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder to store certificate authority in" example:"abc-def-ghi"`
+		KeyType   string `json:"KeyType" enums:"ed25519,rsa" description:"Key type, ed25519 by default" example:"ed25519"`
+		Bits      int    `json:"Bits" description:"RSA key size" example:"4096"`
+	}
+
+	CreateCARS struct {
+		Data *CAPublicKey `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	GetCARQ struct {
+		FolderUID string `uri:"uid" description:"Folder of the certificate authority" example:"abc-def-ghi"`
+	}
+
+	GetCARS struct {
+		Data *CAPublicKey `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	SignRQ struct {
+		FolderUID       string            `json:"FolderUID" description:"Folder of the certificate authority" example:"abc-def-ghi"`
+		PublicKey       string            `json:"PublicKey" description:"Public key to sign in authorized_keys format" example:"ssh-ed25519 AAAA..."`
+		CertType        string            `json:"CertType" enums:"user,host" description:"Certificate type" example:"user"`
+		KeyID           string            `json:"KeyID" description:"Key identifier logged by the server" example:"john.doe"`
+		Principals      []string          `json:"Principals" description:"User names (user certificates) or host names (host certificates)" example:"ubuntu"`
+		ValidAfter      *time.Time        `json:"ValidAfter" description:"Validity start date, now if not set" example:"2030-01-01T00:00:00Z"`
+		ValidBefore     *time.Time        `json:"ValidBefore" description:"Validity end date, takes precedence over TTL" example:"2030-01-02T00:00:00Z"`
+		TTL             uint              `json:"TTL" description:"Validity duration in seconds, 1 day by default" example:"3600"`
+		CriticalOptions map[string]string `json:"CriticalOptions" description:"Critical options (e.g. force-command, source-address)"`
+		Extensions      map[string]string `json:"Extensions" description:"Extensions, OpenSSH defaults for user certificates if not set"`
+	}
+
+	SignRS struct {
+		Data *Certificate `json:"Data"`
+		rqrs.ResponseRS
+	}
+)
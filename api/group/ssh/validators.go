@@ -0,0 +1,65 @@
+package ssh
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	"hideout/internal/common/rqrs"
+	"hideout/services/secrets"
+	"hideout/services/ssh"
+	"strings"
+)
+
+func (rq GenerateKeyPairRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+	Errors = append(Errors, validateKeyType(Localizer, rq.KeyType)...)
+
+	if rq.Name == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "Name"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq CreateCARQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+	Errors = append(Errors, validateKeyType(Localizer, rq.KeyType)...)
+	return Errors
+}
+
+func (rq GetCARQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	return engine.ValidateFolderUID(ctx, secretsService, Localizer, "uid", rq.FolderUID, true)
+}
+
+func (rq SignRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", rq.FolderUID, true)...)
+
+	if rq.PublicKey == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "PublicKey"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	if rq.CertType == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "CertType"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	if _, certTypeExists := ssh.CertTypesMap[rq.CertType]; rq.CertType != "" && !certTypeExists {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "CertType", "Values": strings.Join([]string{ssh.CertType_User, ssh.CertType_Host}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	if len(rq.Principals) == 0 {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "Principals"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	if rq.ValidAfter != nil && rq.ValidBefore != nil && !rq.ValidBefore.After(*rq.ValidAfter) {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ValidityWindowError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
@@ -0,0 +1,17 @@
+package ssh
+
+import (
+	"hideout/api/engine"
+	"hideout/internal/common/apperror"
+	"hideout/services/ssh"
+	"net/http"
+)
+
+var (
+	// errorStatuses HTTP statuses matching the errors returned by SSH service
+	errorStatuses = []engine.ErrorStatus{
+		{Status: http.StatusNotFound, Errors: []error{ssh.ErrNotCertificateAuthority}},
+		{Status: http.StatusConflict, Errors: []error{ssh.ErrAlreadyCA, apperror.ErrAlreadyExists, ssh.ErrNotKeyPair}},
+		{Status: http.StatusBadRequest, Errors: []error{ssh.ErrInvalidKeyType, ssh.ErrInvalidCertType, ssh.ErrInvalidPublicKey, ssh.ErrInvalidName, ssh.ErrInvalidValidity, ssh.ErrNoPrincipals}},
+	}
+)
@@ -0,0 +1,204 @@
+package totp
+
+import (
+	"context"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	apiconfig "hideout/cmd/api/config"
+	"hideout/internal/audit"
+	"hideout/internal/common/rqrs"
+	"hideout/internal/pkg/encryption"
+	"hideout/services/secrets"
+	"hideout/services/totp"
+	"hideout/structs"
+	"log"
+	"net/http"
+	"time"
+)
+
+// CreateTOTPHandler
+// @Summary Create TOTP secret
+// @Description Create or replace TOTP secret from otpauth URI or base32 seed, seed is stored encrypted and never returned
+// @ID create-totp
+// @Tags TOTP
+// @Produce json
+// @Param params body CreateTOTPRQ true "TOTP secret create request"
+// @Success 200 {object} CreateTOTPRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} CreateTOTPRS
+// @Failure 404 {object} CreateTOTPRS
+// @Failure 409 {object} CreateTOTPRS
+// @Failure 500 {object} CreateTOTPRS
+// @Router /totp/ [put]
+func CreateTOTPHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.create.totp")
+	validationSpan.Description = "rq.validate"
+
+	var request CreateTOTPRQ
+	response := CreateTOTPRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "create.totp")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	totpSvc := totp.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	info, errCreate := totpSvc.Create(rqContext, Localizer, totp.CreateParams{
+		FolderUID: request.FolderUID, Name: request.Name, URI: request.URI, Seed: request.Seed, Issuer: request.Issuer,
+		AccountName: request.AccountName, Digits: request.Digits, Period: request.Period, Algorithm: request.Algorithm,
+	})
+	if errCreate != nil {
+		log.Printf("Error creating TOTP secret %s: %s", request.Name, errCreate.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateTOTPError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreate.Error(), Code: 0})
+		c.JSON(engine.Status(errCreate, errorStatuses), response)
+		return
+	}
+	response.Data = &TOTP{UID: info.UID, Name: info.Name, Issuer: info.Issuer, AccountName: info.AccountName, Digits: info.Digits,
+		Period: info.Period, Algorithm: info.Algorithm}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCodeHandler
+// @Summary Get TOTP code
+// @Description Get current code of the TOTP secret and seconds remaining, every request is audited
+// @ID get-totp-code
+// @Tags TOTP
+// @Produce json
+// @Param params body GetCodeRQ true "TOTP code request"
+// @Success 200 {object} GetCodeRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} GetCodeRS
+// @Failure 404 {object} GetCodeRS
+// @Failure 500 {object} GetCodeRS
+// @Router /totp/code/ [post]
+func GetCodeHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.get.totp.code")
+	validationSpan.Description = "rq.validate"
+
+	var request GetCodeRQ
+	response := GetCodeRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "get.totp.code")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	totpSvc := totp.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	code, errGetCode := totpSvc.Code(rqContext, request.SecretUID, request.Reveal, time.Now())
+	if errGetCode != nil {
+		log.Printf("Error generating code of TOTP secret with UID of %s: %s", request.SecretUID, errGetCode.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetTOTPCodeError"},
+			TemplateData: map[string]interface{}{"UID": request.SecretUID}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGetCode.Error(), Code: 0})
+		c.JSON(engine.Status(errGetCode, errorStatuses), response)
+		return
+	}
+	response.Data = &Code{Code: code.Code, SecondsRemaining: code.SecondsRemaining, Period: code.Period, ValidUntil: code.ValidUntil,
+		URI: code.URI}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,53 @@
+package totp
+
+import (
+	"hideout/internal/common/rqrs"
+	"time"
+)
+
+type (
+	TOTP struct {
+		UID         string `json:"UID" description:"Secret unique identifier" example:"abc-def-ghi"`
+		Name        string `json:"Name" description:"Secret name" example:"GITHUB_SERVICE_ACCOUNT"`
+		Issuer      string `json:"Issuer" description:"Issuer" example:"GitHub"`
+		AccountName string `json:"AccountName" description:"Account name" example:"ci@example.com"`
+		Digits      uint   `json:"Digits" description:"Number of digits in code" example:"6"`
+		Period      uint   `json:"Period" description:"Code lifetime in seconds" example:"30"`
+		Algorithm   string `json:"Algorithm" description:"HMAC algorithm" example:"SHA1"`
+	}
+
+	Code struct {
+		Code             string    `json:"Code" description:"Current code" example:"123456"`
+		SecondsRemaining uint      `json:"SecondsRemaining" description:"Seconds until the code changes" example:"17"`
+		Period           uint      `json:"Period" description:"Code lifetime in seconds" example:"30"`
+		ValidUntil       time.Time `json:"ValidUntil" description:"Date the code changes at" example:"2030-01-01T00:00:30Z"`
+		URI              string    `json:"URI,omitempty" description:"otpauth URI with the seed (only if revealing was requested)"`
+	}
+
+	CreateTOTPRQ struct {
+		FolderUID   string `json:"FolderUID" description:"Folder unique identifier" example:"abc-def-ghi"`
+		Name        string `json:"Name" description:"Secret name" example:"GITHUB_SERVICE_ACCOUNT"`
+		URI         string `json:"URI" description:"otpauth:// URI, either it or Seed is required" example:"otpauth://totp/GitHub:ci@example.com?secret=JBSWY3DPEHPK3PXP&issuer=GitHub"`
+		Seed        string `json:"Seed" description:"Base32-encoded seed" example:"JBSWY3DPEHPK3PXP"`
+		Issuer      string `json:"Issuer" description:"Issuer (used with Seed)" example:"GitHub"`
+		AccountName string `json:"AccountName" description:"Account name (used with Seed)" example:"ci@example.com"`
+		Digits      uint   `json:"Digits" description:"Number of digits in code, 6 by default (used with Seed)" example:"6"`
+		Period      uint   `json:"Period" description:"Code lifetime in seconds, 30 by default (used with Seed)" example:"30"`
+		Algorithm   string `json:"Algorithm" enums:"SHA1,SHA256,SHA512" description:"HMAC algorithm, SHA1 by default (used with Seed)" example:"SHA1"`
+	}
+
+	CreateTOTPRS struct {
+		Data *TOTP `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	GetCodeRQ struct {
+		SecretUID string `json:"SecretUID" description:"TOTP secret unique identifier" example:"abc-def-ghi"`
+		Reveal    bool   `json:"Reveal" description:"Also return the otpauth URI with the seed" example:"false"`
+	}
+
+	GetCodeRS struct {
+		Data *Code `json:"Data"`
+		rqrs.ResponseRS
+	}
+)
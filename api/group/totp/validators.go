@@ -0,0 +1,55 @@
+package totp
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/internal/common/rqrs"
+	"hideout/services/secrets"
+	"hideout/services/totp"
+	"strings"
+)
+
+func (rq CreateTOTPRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	if rq.FolderUID == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "FolderUID"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	} else {
+		_, errGetFolderByUID := secretsService.GetFolderByUID(ctx, rq.FolderUID)
+		if errGetFolderByUID != nil {
+			msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetFolderByUIDError"},
+				TemplateData: map[string]interface{}{"UID": rq.FolderUID}})
+			Errors = append(Errors, rqrs.Error{Message: msg, Description: errGetFolderByUID.Error(), Code: 0})
+		}
+	}
+
+	if rq.Name == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "Name"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	if (rq.URI == "") == (rq.Seed == "") {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "OnlyURIOrSeedError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	if _, algorithmExists := totp.AlgorithmsMap[strings.ToUpper(rq.Algorithm)]; rq.Algorithm != "" && !algorithmExists {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Algorithm", "Values": strings.Join([]string{totp.Algorithm_SHA1,
+				totp.Algorithm_SHA256, totp.Algorithm_SHA512}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq GetCodeRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	if rq.SecretUID == "" {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "SecretUID"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
@@ -0,0 +1,15 @@
+package totp
+
+import (
+	"hideout/api/engine"
+	"hideout/services/totp"
+	"net/http"
+)
+
+var (
+	// errorStatuses HTTP statuses matching the errors returned by TOTP service
+	errorStatuses = []engine.ErrorStatus{
+		{Status: http.StatusConflict, Errors: []error{totp.ErrNameTaken}},
+		{Status: http.StatusBadRequest, Errors: []error{totp.ErrInvalidURI, totp.ErrInvalidSeed, totp.ErrInvalidAlgorithm, totp.ErrInvalidDigits, totp.ErrNotTOTP}},
+	}
+)
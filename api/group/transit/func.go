@@ -0,0 +1,55 @@
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	"hideout/internal/common/rqrs"
+	"hideout/services/secrets"
+	"hideout/services/transit"
+	"strings"
+)
+
+func validateKey(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer, folderUID string, name string) (Errors []rqrs.Error) {
+	Errors = append(Errors, engine.ValidateFolderUID(ctx, secretsService, Localizer, "FolderUID", folderUID, true)...)
+	return append(Errors, engine.ValidateRequired(Localizer, "Name", name)...)
+}
+
+func validateBase64(Localizer *i18n.Localizer, name string, value string) (Errors []rqrs.Error) {
+	if value == "" {
+		return Errors
+	}
+	_, errDecode := base64.StdEncoding.DecodeString(value)
+	if errDecode != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamBase64Error"},
+			TemplateData: map[string]interface{}{"Name": name}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: errDecode.Error(), Code: 0})
+	}
+	return Errors
+}
+
+func validateHashAlgorithm(Localizer *i18n.Localizer, algorithm string) (Errors []rqrs.Error) {
+	if algorithm != "" && !transit.HashAlgorithmsMap[algorithm] {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Algorithm", "Values": strings.Join([]string{transit.HashAlgorithm_SHA256,
+				transit.HashAlgorithm_SHA512}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+	return Errors
+}
+
+// decodeBase64 Decodes base64 parameter which was already checked by validators
+func decodeBase64(value string) []byte {
+	data, _ := base64.StdEncoding.DecodeString(value)
+	return data
+}
+
+func mapKey(info *transit.KeyInfo) *Key {
+	key := &Key{UID: info.UID, Name: info.Name, Type: info.Type, LatestVersion: info.LatestVersion,
+		MinDecryptionVersion: info.MinDecryptionVersion, Versions: []KeyVersion{}}
+	for _, version := range info.Versions {
+		key.Versions = append(key.Versions, KeyVersion{Version: version.Version, CreatedAt: version.CreatedAt, PublicKey: version.PublicKey})
+	}
+	return key
+}
@@ -0,0 +1,915 @@
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	apiconfig "hideout/cmd/api/config"
+	"hideout/internal/audit"
+	"hideout/internal/common/rqrs"
+	"hideout/internal/pkg/encryption"
+	"hideout/services/secrets"
+	"hideout/services/transit"
+	"hideout/structs"
+	"log"
+	"net/http"
+)
+
+// CreateKeyHandler
+// @Summary Create transit key
+// @Description Create named encryption (signing) key which never leaves the server
+// @ID create-transit-key
+// @Tags Transit
+// @Produce json
+// @Param params body CreateKeyRQ true "Create transit key request"
+// @Success 200 {object} KeyRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} KeyRS
+// @Failure 404 {object} KeyRS
+// @Failure 409 {object} KeyRS
+// @Failure 500 {object} KeyRS
+// @Router /transit/keys/ [put]
+func CreateKeyHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.create.transit.key")
+	validationSpan.Description = "rq.validate"
+
+	var request CreateKeyRQ
+	response := KeyRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "create.transit.key")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	info, errCreate := transitSvc.CreateKey(rqContext, Localizer, request.FolderUID, request.Name, request.Type)
+	if errCreate != nil {
+		log.Printf("Error creating transit key %s: %s", request.Name, errCreate.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateTransitKeyError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreate.Error(), Code: 0})
+		c.JSON(engine.Status(errCreate, errorStatuses), response)
+		return
+	}
+	response.Data = mapKey(info)
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetKeyHandler
+// @Summary Get transit key
+// @Description Get transit key versions and settings without key material
+// @ID get-transit-key
+// @Tags Transit
+// @Produce json
+// @Param params body KeyRQ true "Get transit key request"
+// @Success 200 {object} KeyRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} KeyRS
+// @Failure 404 {object} KeyRS
+// @Failure 500 {object} KeyRS
+// @Router /transit/keys/ [post]
+func GetKeyHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.get.transit.key")
+	validationSpan.Description = "rq.validate"
+
+	var request KeyRQ
+	response := KeyRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "get.transit.key")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	info, errGet := transitSvc.GetKey(rqContext, request.FolderUID, request.Name)
+	if errGet != nil {
+		log.Printf("Error retrieving transit key %s: %s", request.Name, errGet.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "GetTransitKeyError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errGet.Error(), Code: 0})
+		c.JSON(engine.Status(errGet, errorStatuses), response)
+		return
+	}
+	response.Data = mapKey(info)
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// RotateKeyHandler
+// @Summary Rotate transit key
+// @Description Create new version of transit key, used for all subsequent operations
+// @ID rotate-transit-key
+// @Tags Transit
+// @Produce json
+// @Param params body KeyRQ true "Rotate transit key request"
+// @Success 200 {object} KeyRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} KeyRS
+// @Failure 404 {object} KeyRS
+// @Failure 500 {object} KeyRS
+// @Router /transit/keys/rotate/ [post]
+func RotateKeyHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.rotate.transit.key")
+	validationSpan.Description = "rq.validate"
+
+	var request KeyRQ
+	response := KeyRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "rotate.transit.key")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	info, errRotate := transitSvc.RotateKey(rqContext, Localizer, request.FolderUID, request.Name)
+	if errRotate != nil {
+		log.Printf("Error rotating transit key %s: %s", request.Name, errRotate.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RotateTransitKeyError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errRotate.Error(), Code: 0})
+		c.JSON(engine.Status(errRotate, errorStatuses), response)
+		return
+	}
+	response.Data = mapKey(info)
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// ConfigureKeyHandler
+// @Summary Configure transit key
+// @Description Set minimum key version allowed to decrypt and verify data
+// @ID configure-transit-key
+// @Tags Transit
+// @Produce json
+// @Param params body ConfigureKeyRQ true "Configure transit key request"
+// @Success 200 {object} KeyRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} KeyRS
+// @Failure 404 {object} KeyRS
+// @Failure 500 {object} KeyRS
+// @Router /transit/keys/ [patch]
+func ConfigureKeyHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.configure.transit.key")
+	validationSpan.Description = "rq.validate"
+
+	var request ConfigureKeyRQ
+	response := KeyRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "configure.transit.key")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	info, errConfigure := transitSvc.ConfigureKey(rqContext, Localizer, request.FolderUID, request.Name, request.MinDecryptionVersion)
+	if errConfigure != nil {
+		log.Printf("Error configuring transit key %s: %s", request.Name, errConfigure.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "ConfigureTransitKeyError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errConfigure.Error(), Code: 0})
+		c.JSON(engine.Status(errConfigure, errorStatuses), response)
+		return
+	}
+	response.Data = mapKey(info)
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// EncryptHandler
+// @Summary Encrypt data
+// @Description Encrypt base64-encoded plaintext with the latest version of transit key
+// @ID transit-encrypt
+// @Tags Transit
+// @Produce json
+// @Param params body EncryptRQ true "Encrypt request"
+// @Success 200 {object} CiphertextRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} CiphertextRS
+// @Failure 404 {object} CiphertextRS
+// @Failure 500 {object} CiphertextRS
+// @Router /transit/encrypt/ [post]
+func EncryptHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.transit.encrypt")
+	validationSpan.Description = "rq.validate"
+
+	var request EncryptRQ
+	response := CiphertextRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "transit.encrypt")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	ciphertext, errEncrypt := transitSvc.Encrypt(rqContext, request.FolderUID, request.Name, decodeBase64(request.Plaintext),
+		decodeBase64(request.AssociatedData))
+	if errEncrypt != nil {
+		log.Printf("Error encrypting data with transit key %s: %s", request.Name, errEncrypt.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "TransitEncryptError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errEncrypt.Error(), Code: 0})
+		c.JSON(engine.Status(errEncrypt, errorStatuses), response)
+		return
+	}
+	response.Data = &Ciphertext{Ciphertext: ciphertext}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// DecryptHandler
+// @Summary Decrypt data
+// @Description Decrypt ciphertext produced by transit key
+// @ID transit-decrypt
+// @Tags Transit
+// @Produce json
+// @Param params body DecryptRQ true "Decrypt request"
+// @Success 200 {object} PlaintextRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} PlaintextRS
+// @Failure 404 {object} PlaintextRS
+// @Failure 500 {object} PlaintextRS
+// @Router /transit/decrypt/ [post]
+func DecryptHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.transit.decrypt")
+	validationSpan.Description = "rq.validate"
+
+	var request DecryptRQ
+	response := PlaintextRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "transit.decrypt")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	plaintext, errDecrypt := transitSvc.Decrypt(rqContext, request.FolderUID, request.Name, request.Ciphertext,
+		decodeBase64(request.AssociatedData))
+	if errDecrypt != nil {
+		log.Printf("Error decrypting data with transit key %s: %s", request.Name, errDecrypt.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "TransitDecryptError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errDecrypt.Error(), Code: 0})
+		c.JSON(engine.Status(errDecrypt, errorStatuses), response)
+		return
+	}
+	response.Data = &Plaintext{Plaintext: base64.StdEncoding.EncodeToString(plaintext)}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// RewrapHandler
+// @Summary Rewrap data
+// @Description Re-encrypt ciphertext with the latest version of transit key without revealing plaintext
+// @ID transit-rewrap
+// @Tags Transit
+// @Produce json
+// @Param params body DecryptRQ true "Rewrap request"
+// @Success 200 {object} CiphertextRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} CiphertextRS
+// @Failure 404 {object} CiphertextRS
+// @Failure 500 {object} CiphertextRS
+// @Router /transit/rewrap/ [post]
+func RewrapHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.transit.rewrap")
+	validationSpan.Description = "rq.validate"
+
+	var request DecryptRQ
+	response := CiphertextRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "transit.rewrap")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	ciphertext, errRewrap := transitSvc.Rewrap(rqContext, request.FolderUID, request.Name, request.Ciphertext,
+		decodeBase64(request.AssociatedData))
+	if errRewrap != nil {
+		log.Printf("Error rewrapping data with transit key %s: %s", request.Name, errRewrap.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "TransitRewrapError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errRewrap.Error(), Code: 0})
+		c.JSON(engine.Status(errRewrap, errorStatuses), response)
+		return
+	}
+	response.Data = &Ciphertext{Ciphertext: ciphertext}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// SignHandler
+// @Summary Sign data
+// @Description Sign base64-encoded input with the latest version of ed25519 transit key
+// @ID transit-sign
+// @Tags Transit
+// @Produce json
+// @Param params body SignRQ true "Sign request"
+// @Success 200 {object} SignRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} SignRS
+// @Failure 404 {object} SignRS
+// @Failure 500 {object} SignRS
+// @Router /transit/sign/ [post]
+func SignHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.transit.sign")
+	validationSpan.Description = "rq.validate"
+
+	var request SignRQ
+	response := SignRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "transit.sign")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	signature, errSign := transitSvc.Sign(rqContext, request.FolderUID, request.Name, decodeBase64(request.Input))
+	if errSign != nil {
+		log.Printf("Error signing data with transit key %s: %s", request.Name, errSign.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "TransitSignError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errSign.Error(), Code: 0})
+		c.JSON(engine.Status(errSign, errorStatuses), response)
+		return
+	}
+	response.Data = &Signature{Signature: signature}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// VerifyHandler
+// @Summary Verify data
+// @Description Verify signature or HMAC of base64-encoded input
+// @ID transit-verify
+// @Tags Transit
+// @Produce json
+// @Param params body VerifyRQ true "Verify request"
+// @Success 200 {object} VerifyRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} VerifyRS
+// @Failure 404 {object} VerifyRS
+// @Failure 500 {object} VerifyRS
+// @Router /transit/verify/ [post]
+func VerifyHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.transit.verify")
+	validationSpan.Description = "rq.validate"
+
+	var request VerifyRQ
+	response := VerifyRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "transit.verify")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	valid, errVerify := transitSvc.Verify(rqContext, request.FolderUID, request.Name, decodeBase64(request.Input), request.Signature,
+		request.HMAC, request.Algorithm)
+	if errVerify != nil {
+		log.Printf("Error verifying data with transit key %s: %s", request.Name, errVerify.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "TransitVerifyError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errVerify.Error(), Code: 0})
+		c.JSON(engine.Status(errVerify, errorStatuses), response)
+		return
+	}
+	response.Data = &Verification{Valid: valid}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
+
+// HMACHandler
+// @Summary Compute HMAC
+// @Description Compute HMAC of base64-encoded input with the latest version of transit key
+// @ID transit-hmac
+// @Tags Transit
+// @Produce json
+// @Param params body HMACRQ true "HMAC request"
+// @Success 200 {object} HMACRS
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 400 {object} HMACRS
+// @Failure 404 {object} HMACRS
+// @Failure 500 {object} HMACRS
+// @Router /transit/hmac/ [post]
+func HMACHandler(c *gin.Context) {
+	rqContext := c.Request.Context()
+	SentryHub := sentry.GetHubFromContext(rqContext)
+	if SentryHub == nil {
+		SentryHub = sentry.CurrentHub().Clone()
+		rqContext = sentry.SetHubOnContext(rqContext, SentryHub)
+	}
+
+	Language, _ := c.Get("Language")
+	Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, Language.(string))
+
+	rqContext = context.WithValue(rqContext, "Sentry", SentryHub)
+	rqContext = context.WithValue(rqContext, "Localizer", Localizer)
+	rqContext = context.WithValue(rqContext, "Language", Language)
+
+	validationSpan := sentry.StartSpan(rqContext, "validate.transit.hmac")
+	validationSpan.Description = "rq.validate"
+
+	var request HMACRQ
+	response := HMACRS{ResponseRS: rqrs.ResponseRS{Errors: []rqrs.Error{}}}
+
+	secretsSvc, errCreateService := secrets.NewService(rqContext, apiconfig.Settings.SecretsRepository,
+		apiconfig.Settings.FoldersRepository, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		log.Printf("Error creating secrets service: %s", errCreateService.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "CreateSecretsServiceError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errCreateService.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	errBindBody := c.ShouldBindBodyWith(&request, binding.JSON)
+	if errBindBody != nil {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "RequestBodyMappingError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errBindBody.Error(), Code: 0})
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	errValidate := request.Validate(rqContext, secretsSvc, Localizer)
+	if errValidate != nil {
+		response.Errors = errValidate
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	validationSpan.Finish()
+
+	runSpan := sentry.StartSpan(rqContext, "transit.hmac")
+	runSpan.Description = "run"
+
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Error parsing encryption key: %s", errParseKey.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "EncryptionKeyError"}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errParseKey.Error(), Code: 0})
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	rqContext = audit.WithActor(rqContext, audit.Actor{RemoteAddr: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	transitSvc := transit.NewService(secretsSvc, encryptionKey, audit.NewLogger(apiconfig.Settings.Audit))
+	hmacValue, errHMAC := transitSvc.HMAC(rqContext, request.FolderUID, request.Name, decodeBase64(request.Input), request.Algorithm)
+	if errHMAC != nil {
+		log.Printf("Error computing HMAC with transit key %s: %s", request.Name, errHMAC.Error())
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "TransitHMACError"},
+			TemplateData: map[string]interface{}{"Name": request.Name}})
+		response.Errors = append(response.Errors, rqrs.Error{Message: msg, Description: errHMAC.Error(), Code: 0})
+		c.JSON(engine.Status(errHMAC, errorStatuses), response)
+		return
+	}
+	response.Data = &HMAC{HMAC: hmacValue}
+
+	runSpan.Finish()
+	c.JSON(http.StatusOK, response)
+}
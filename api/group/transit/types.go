@@ -0,0 +1,126 @@
+package transit
+
+import (
+	"hideout/internal/common/rqrs"
+	"time"
+)
+
+type (
+	Key struct {
+		UID                  string       `json:"UID" description:"Secret unique identifier of the key" example:"abc-def-ghi"`
+		Name                 string       `json:"Name" description:"Key name" example:"payments"`
+		Type                 string       `json:"Type" description:"Key type" example:"aes256-gcm96"`
+		LatestVersion        int          `json:"LatestVersion" description:"Version used to encrypt, sign and compute HMAC" example:"2"`
+		MinDecryptionVersion int          `json:"MinDecryptionVersion" description:"Minimum version allowed to decrypt and verify data" example:"1"`
+		Versions             []KeyVersion `json:"Versions"`
+	}
+
+	KeyVersion struct {
+		Version   int       `json:"Version" description:"Key version" example:"1"`
+		CreatedAt time.Time `json:"CreatedAt" description:"Version creation date" example:"2030-01-01T00:00:00Z"`
+		PublicKey string    `json:"PublicKey,omitempty" description:"Base64-encoded public key (ed25519 keys only)"`
+	}
+
+	KeyRQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder of the key" example:"abc-def-ghi"`
+		Name      string `json:"Name" description:"Key name" example:"payments"`
+	}
+
+	KeyRS struct {
+		Data *Key `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	CreateKeyRQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder to store key in" example:"abc-def-ghi"`
+		Name      string `json:"Name" description:"Key name" example:"payments"`
+		Type      string `json:"Type" enums:"aes256-gcm96,chacha20-poly1305,ed25519" description:"Key type" example:"aes256-gcm96"`
+	}
+
+	ConfigureKeyRQ struct {
+		FolderUID            string `json:"FolderUID" description:"Folder of the key" example:"abc-def-ghi"`
+		Name                 string `json:"Name" description:"Key name" example:"payments"`
+		MinDecryptionVersion int    `json:"MinDecryptionVersion" description:"Minimum version allowed to decrypt and verify data" example:"2"`
+	}
+
+	EncryptRQ struct {
+		FolderUID      string `json:"FolderUID" description:"Folder of the key" example:"abc-def-ghi"`
+		Name           string `json:"Name" description:"Key name" example:"payments"`
+		Plaintext      string `json:"Plaintext" description:"Base64-encoded plaintext" example:"aGVsbG8gd29ybGQ="`
+		AssociatedData string `json:"AssociatedData" description:"Base64-encoded associated data, required to decrypt (optional)" example:""`
+	}
+
+	DecryptRQ struct {
+		FolderUID      string `json:"FolderUID" description:"Folder of the key" example:"abc-def-ghi"`
+		Name           string `json:"Name" description:"Key name" example:"payments"`
+		Ciphertext     string `json:"Ciphertext" description:"Ciphertext returned by encryption" example:"hideout:v1:AAAAAAAAAAAAAAAA"`
+		AssociatedData string `json:"AssociatedData" description:"Base64-encoded associated data used to encrypt" example:""`
+	}
+
+	CiphertextRS struct {
+		Data *Ciphertext `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	Ciphertext struct {
+		Ciphertext string `json:"Ciphertext" description:"Ciphertext prefixed with the key version" example:"hideout:v1:AAAAAAAAAAAAAAAA"`
+	}
+
+	PlaintextRS struct {
+		Data *Plaintext `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	Plaintext struct {
+		Plaintext string `json:"Plaintext" description:"Base64-encoded plaintext" example:"aGVsbG8gd29ybGQ="`
+	}
+
+	SignRQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder of the key" example:"abc-def-ghi"`
+		Name      string `json:"Name" description:"Key name" example:"releases"`
+		Input     string `json:"Input" description:"Base64-encoded input" example:"aGVsbG8gd29ybGQ="`
+	}
+
+	SignRS struct {
+		Data *Signature `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	Signature struct {
+		Signature string `json:"Signature" description:"Signature prefixed with the key version" example:"hideout:v1:AAAAAAAAAAAAAAAA"`
+	}
+
+	VerifyRQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder of the key" example:"abc-def-ghi"`
+		Name      string `json:"Name" description:"Key name" example:"releases"`
+		Input     string `json:"Input" description:"Base64-encoded input" example:"aGVsbG8gd29ybGQ="`
+		Signature string `json:"Signature" description:"Signature to verify, either it or HMAC is required" example:"hideout:v1:AAAAAAAAAAAAAAAA"`
+		HMAC      string `json:"HMAC" description:"HMAC to verify" example:""`
+		Algorithm string `json:"Algorithm" enums:"sha2-256,sha2-512" description:"HMAC hash algorithm, sha2-256 by default" example:"sha2-256"`
+	}
+
+	VerifyRS struct {
+		Data *Verification `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	Verification struct {
+		Valid bool `json:"Valid" description:"Whether signature (HMAC) matches the input" example:"true"`
+	}
+
+	HMACRQ struct {
+		FolderUID string `json:"FolderUID" description:"Folder of the key" example:"abc-def-ghi"`
+		Name      string `json:"Name" description:"Key name" example:"payments"`
+		Input     string `json:"Input" description:"Base64-encoded input" example:"aGVsbG8gd29ybGQ="`
+		Algorithm string `json:"Algorithm" enums:"sha2-256,sha2-512" description:"Hash algorithm, sha2-256 by default" example:"sha2-256"`
+	}
+
+	HMACRS struct {
+		Data *HMAC `json:"Data"`
+		rqrs.ResponseRS
+	}
+
+	HMAC struct {
+		HMAC string `json:"HMAC" description:"HMAC prefixed with the key version" example:"hideout:v1:AAAAAAAAAAAAAAAA"`
+	}
+)
@@ -0,0 +1,75 @@
+package transit
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"hideout/api/engine"
+	"hideout/internal/common/rqrs"
+	"hideout/services/secrets"
+	"hideout/services/transit"
+	"strings"
+)
+
+func (rq KeyRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	return validateKey(ctx, secretsService, Localizer, rq.FolderUID, rq.Name)
+}
+
+func (rq CreateKeyRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = validateKey(ctx, secretsService, Localizer, rq.FolderUID, rq.Name)
+
+	if !transit.KeyTypesMap[rq.Type] {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamInvalidError"},
+			TemplateData: map[string]interface{}{"Name": "Type", "Values": strings.Join([]string{transit.KeyType_AES256GCM,
+				transit.KeyType_ChaCha20Poly1305, transit.KeyType_Ed25519}, ",")}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq ConfigureKeyRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = validateKey(ctx, secretsService, Localizer, rq.FolderUID, rq.Name)
+
+	if rq.MinDecryptionVersion < 1 {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "BodyParamMissingError"},
+			TemplateData: map[string]interface{}{"Name": "MinDecryptionVersion"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return Errors
+}
+
+func (rq EncryptRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = validateKey(ctx, secretsService, Localizer, rq.FolderUID, rq.Name)
+	Errors = append(Errors, validateBase64(Localizer, "Plaintext", rq.Plaintext)...)
+	return append(Errors, validateBase64(Localizer, "AssociatedData", rq.AssociatedData)...)
+}
+
+func (rq DecryptRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = validateKey(ctx, secretsService, Localizer, rq.FolderUID, rq.Name)
+	Errors = append(Errors, engine.ValidateRequired(Localizer, "Ciphertext", rq.Ciphertext)...)
+	return append(Errors, validateBase64(Localizer, "AssociatedData", rq.AssociatedData)...)
+}
+
+func (rq SignRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = validateKey(ctx, secretsService, Localizer, rq.FolderUID, rq.Name)
+	return append(Errors, validateBase64(Localizer, "Input", rq.Input)...)
+}
+
+func (rq VerifyRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = validateKey(ctx, secretsService, Localizer, rq.FolderUID, rq.Name)
+	Errors = append(Errors, validateBase64(Localizer, "Input", rq.Input)...)
+
+	if (rq.Signature == "") == (rq.HMAC == "") {
+		msg := Localizer.MustLocalize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: "OnlySignatureOrHMACError"}})
+		Errors = append(Errors, rqrs.Error{Message: msg, Description: msg, Code: 0})
+	}
+
+	return append(Errors, validateHashAlgorithm(Localizer, rq.Algorithm)...)
+}
+
+func (rq HMACRQ) Validate(ctx context.Context, secretsService *secrets.SecretsService, Localizer *i18n.Localizer) (Errors []rqrs.Error) {
+	Errors = validateKey(ctx, secretsService, Localizer, rq.FolderUID, rq.Name)
+	Errors = append(Errors, validateBase64(Localizer, "Input", rq.Input)...)
+	return append(Errors, validateHashAlgorithm(Localizer, rq.Algorithm)...)
+}
@@ -0,0 +1,16 @@
+package transit
+
+import (
+	"hideout/api/engine"
+	"hideout/services/transit"
+	"net/http"
+)
+
+var (
+	// errorStatuses HTTP statuses matching the errors returned by transit service
+	errorStatuses = []engine.ErrorStatus{
+		{Status: http.StatusNotFound, Errors: []error{transit.ErrKeyNotFound}},
+		{Status: http.StatusConflict, Errors: []error{transit.ErrKeyExists, transit.ErrNameTaken}},
+		{Status: http.StatusBadRequest, Errors: []error{transit.ErrInvalidName, transit.ErrInvalidKeyType, transit.ErrUnsupportedOperation, transit.ErrInvalidFormat, transit.ErrVersionNotFound, transit.ErrVersionBelowMinimum, transit.ErrInvalidMinVersion, transit.ErrInvalidHashAlgorithm, transit.ErrDecryptionFailed}},
+	}
+)
@@ -5,8 +5,14 @@ import (
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"hideout/api/group/database"
+	"hideout/api/group/leases"
+	"hideout/api/group/pki"
 	"hideout/api/group/public"
 	"hideout/api/group/secrets"
+	"hideout/api/group/ssh"
+	"hideout/api/group/totp"
+	"hideout/api/group/transit"
 	"hideout/api/middleware"
 	apiconfig "hideout/cmd/api/config"
 	"log"
@@ -46,6 +52,12 @@ func Serve() {
 
 	v1Public := route.Group("/api/v1/public")
 	v1Secrets := route.Group("/api/v1/secrets")
+	v1PKI := route.Group("/api/v1/pki")
+	v1SSH := route.Group("/api/v1/ssh")
+	v1TOTP := route.Group("/api/v1/totp")
+	v1Database := route.Group("/api/v1/database")
+	v1Leases := route.Group("/api/v1/leases")
+	v1Transit := route.Group("/api/v1/transit")
 
 	v1Public.GET("/sitemap/", public.GetSitemapHandler)
 
@@ -55,6 +67,44 @@ func Serve() {
 	v1Secrets.DELETE("/", secrets.DeleteSecretsHandler)
 	v1Secrets.PUT("/copy-paste/", secrets.CopyPasteSecretsHandler)
 	v1Secrets.POST("/export/", secrets.ExportSecretsHandler)
+	v1Secrets.PUT("/export/recipients/", secrets.CreateExportRecipientHandler)
+	v1Secrets.GET("/export/public-key/", secrets.GetExportPublicKeyHandler)
+	v1Secrets.POST("/import/", secrets.ImportSecretsHandler)
+
+	v1PKI.PUT("/ca/", pki.CreateCAHandler)
+	v1PKI.PUT("/roles/", pki.SaveRoleHandler)
+	v1PKI.POST("/issue/", pki.IssueCertificateHandler)
+	v1PKI.POST("/revoke/", pki.RevokeCertificateHandler)
+	v1PKI.GET("/crl/:uid", pki.GetCRLHandler)
+
+	v1SSH.PUT("/keys/", ssh.GenerateKeyPairHandler)
+	v1SSH.PUT("/ca/", ssh.CreateCAHandler)
+	v1SSH.GET("/ca/:uid", ssh.GetCAHandler)
+	v1SSH.POST("/sign/", ssh.SignHandler)
+
+	v1TOTP.PUT("/", totp.CreateTOTPHandler)
+	v1TOTP.POST("/code/", totp.GetCodeHandler)
+
+	v1Database.PUT("/config/", database.ConfigureHandler)
+	v1Database.PUT("/roles/", database.SaveRoleHandler)
+	v1Database.POST("/creds/", database.GetCredentialsHandler)
+
+	v1Leases.POST("/", leases.GetLeasesHandler)
+	v1Leases.POST("/lookup/", leases.LookupLeaseHandler)
+	v1Leases.POST("/renew/", leases.RenewLeaseHandler)
+	v1Leases.POST("/revoke/", leases.RevokeLeaseHandler)
+	v1Leases.POST("/revoke-prefix/", leases.RevokePrefixHandler)
+
+	v1Transit.PUT("/keys/", transit.CreateKeyHandler)
+	v1Transit.POST("/keys/", transit.GetKeyHandler)
+	v1Transit.PATCH("/keys/", transit.ConfigureKeyHandler)
+	v1Transit.POST("/keys/rotate/", transit.RotateKeyHandler)
+	v1Transit.POST("/encrypt/", transit.EncryptHandler)
+	v1Transit.POST("/decrypt/", transit.DecryptHandler)
+	v1Transit.POST("/rewrap/", transit.RewrapHandler)
+	v1Transit.POST("/sign/", transit.SignHandler)
+	v1Transit.POST("/verify/", transit.VerifyHandler)
+	v1Transit.POST("/hmac/", transit.HMACHandler)
 
 	errRun := route.Run(fmt.Sprintf("%s:%d", apiconfig.Settings.Server.Host, apiconfig.Settings.Server.Port))
 	log.Panic(errRun)
@@ -0,0 +1,6 @@
+package client
+
+const (
+	// MaxArchiveSize Maximum size of the archive (once decompressed) accepted for verification
+	MaxArchiveSize = 256 << 20
+)
@@ -0,0 +1,101 @@
+// Package client contains helpers for consumers of hideout, such as verification of signed exports
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/pkg/errors"
+	"hideout/internal/pkg/archive"
+	"hideout/internal/pkg/signing"
+	"io"
+	"strings"
+)
+
+// ParsePublicKey Decodes public key served by the export public key endpoint, either PKIX PEM block or base64 of raw key
+func ParsePublicKey(encodedKey string) (ed25519.PublicKey, error) {
+	encodedKey = strings.TrimSpace(encodedKey)
+	if block, _ := pem.Decode([]byte(encodedKey)); block != nil {
+		publicKey, errParse := x509.ParsePKIXPublicKey(block.Bytes)
+		if errParse != nil {
+			return nil, errors.Wrap(ErrInvalidPublicKey, errParse.Error())
+		}
+		ed25519Key, isEd25519 := publicKey.(ed25519.PublicKey)
+		if !isEd25519 {
+			return nil, ErrInvalidPublicKey
+		}
+		return ed25519Key, nil
+	}
+
+	rawKey, errDecode := base64.StdEncoding.DecodeString(encodedKey)
+	if errDecode != nil || len(rawKey) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+	return rawKey, nil
+}
+
+// VerifyManifest Checks detached signature of the manifest and decodes it
+func VerifyManifest(publicKey ed25519.PublicKey, manifestData []byte, signature []byte) (*Manifest, error) {
+	rawSignature, errDecode := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if errDecode != nil || !ed25519.Verify(publicKey, manifestData, rawSignature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var manifest Manifest
+	errUnmarshal := json.Unmarshal(manifestData, &manifest)
+	if errUnmarshal != nil {
+		return nil, errors.Wrap(errUnmarshal, "Failed to decode manifest")
+	}
+	if manifest.KeyID != signing.KeyID(publicKey) {
+		return nil, ErrKeyMismatch
+	}
+	return &manifest, nil
+}
+
+// VerifyFiles Checks signed manifest among extracted files (by their paths in archive) and checksums of all files,
+// files not listed in the manifest are rejected as well
+func VerifyFiles(publicKey ed25519.PublicKey, files map[string][]byte) (*Manifest, error) {
+	manifestData, manifestExists := files[signing.ManifestFile]
+	signature, signatureExists := files[signing.ManifestSignatureFile]
+	if !manifestExists || !signatureExists {
+		return nil, ErrManifestMissing
+	}
+	manifest, errVerify := VerifyManifest(publicKey, manifestData, signature)
+	if errVerify != nil {
+		return nil, errVerify
+	}
+
+	var listedMap = map[string]bool{signing.ManifestFile: true, signing.ManifestSignatureFile: true}
+	for _, manifestFile := range manifest.Files {
+		data, exists := files[manifestFile.Path]
+		if !exists {
+			return nil, errors.Wrapf(ErrFileMissing, "File %s", manifestFile.Path)
+		}
+		checksum := sha256.Sum256(data)
+		if hex.EncodeToString(checksum[:]) != manifestFile.SHA256 {
+			return nil, errors.Wrapf(ErrChecksumMismatch, "File %s", manifestFile.Path)
+		}
+		listedMap[manifestFile.Path] = true
+	}
+	for filePath := range files {
+		if !listedMap[filePath] {
+			return nil, errors.Wrapf(ErrUnexpectedFile, "File %s", filePath)
+		}
+	}
+
+	return manifest, nil
+}
+
+// VerifyArchive Extracts (decrypted) exported archive of any supported archive and compression type and verifies it
+func VerifyArchive(ctx context.Context, publicKey ed25519.PublicKey, archiveReader io.Reader) (*Manifest, error) {
+	files, errExtract := archive.Extract(ctx, "", archiveReader, MaxArchiveSize)
+	if errExtract != nil {
+		return nil, errExtract
+	}
+	return VerifyFiles(publicKey, files)
+}
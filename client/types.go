@@ -0,0 +1,20 @@
+package client
+
+import "time"
+
+type (
+	// Manifest Contents of the exported archive as listed and signed by the server
+	Manifest struct {
+		Format    string         `json:"Format"`
+		CreatedAt time.Time      `json:"CreatedAt"`
+		KeyID     string         `json:"KeyID"`
+		Files     []ManifestFile `json:"Files"`
+	}
+
+	ManifestFile struct {
+		Path    string `json:"Path"`              // Path of the file in archive
+		Folder  string `json:"Folder,omitempty"`  // Path of the folder relative to the exported one (recursive exports only)
+		Secrets int    `json:"Secrets,omitempty"` // Amount of secrets in the file (recursive exports only)
+		SHA256  string `json:"SHA256"`            // Hex-encoded checksum of the file
+	}
+)
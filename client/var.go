@@ -0,0 +1,18 @@
+package client
+
+import (
+	"github.com/pkg/errors"
+	"hideout/internal/pkg/archive"
+)
+
+var (
+	ErrInvalidPublicKey = errors.New("Invalid Ed25519 public key")
+	ErrNotArchive       = archive.ErrNotArchive
+	ErrArchiveTooLarge  = archive.ErrTooLarge
+	ErrManifestMissing  = errors.New("Archive contains no signed manifest")
+	ErrInvalidSignature = errors.New("Manifest signature is invalid")
+	ErrKeyMismatch      = errors.New("Manifest is signed by a different key")
+	ErrFileMissing      = errors.New("File listed in manifest is missing")
+	ErrChecksumMismatch = errors.New("File checksum does not match manifest")
+	ErrUnexpectedFile   = errors.New("File is not listed in manifest")
+)
@@ -13,29 +13,44 @@ import (
 	"gorm.io/gorm"
 	"hideout/config"
 	"hideout/internal/folders"
+	"hideout/internal/pkg/embedded"
 	"hideout/internal/pkg/extra"
 	"hideout/internal/secrets"
+	"hideout/internal/store"
 	"hideout/internal/translations"
+	"hideout/services/leases"
 	secrets2 "hideout/services/secrets"
 	"hideout/structs"
 	"log"
+	"slices"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Server            config.ServerConfig      // API server configuration
-	Environment       config.EnvironmentConfig // Server environment configuration
-	Bundle            *i18n.Bundle             // I18n bundle instance (localization)
-	I18n              *i18n.Localizer          // I18n configuration (i18n)
-	Redis             config.RedisConfig       // Redis configuration
-	Database          config.DatabaseConfig    // Database configuration
-	SecretsRepository config.RepositoryConfig  // Secrets data store (repository) configuration
-	FoldersRepository config.RepositoryConfig  // Folders data store (repository) configuration
-	Debug             bool                     // Debugging flag
+	Server            config.ServerConfig        // API server configuration
+	Environment       config.EnvironmentConfig   // Server environment configuration
+	Bundle            *i18n.Bundle               // I18n bundle instance (localization)
+	I18n              *i18n.Localizer            // I18n configuration (i18n)
+	Redis             config.RedisConfig         // Redis configuration
+	Database          config.DatabaseConfig      // Database configuration
+	SecretsRepository config.RepositoryConfig    // Secrets data store (repository) configuration
+	FoldersRepository config.RepositoryConfig    // Folders data store (repository) configuration
+	Store             config.StoreConfig         // Single file store of folders and secrets configuration
+	Embedded          config.EmbeddedConfig      // Embedded key-value store configuration
+	Expiry            config.ExpiryConfig        // Secrets expiration checker configuration
+	Notifications     config.NotificationsConfig // Notification sinks (webhook, SMTP) configuration
+	Security          config.SecurityConfig      // Encryption at rest and signing configuration
+	Audit             config.AuditConfig         // Audit log configuration
+	Leases            config.LeasesConfig        // Dynamic credentials leases configuration
+	Debug             bool                       // Debugging flag
 }
 
 var Settings *Config
 
+// Leases Lease service shared by API and expired leases reaper, with engines handing out leases registered
+var Leases *leases.LeaseService
+
 func Init(ctx context.Context) {
 	bundle := i18n.NewBundle(language.English)
 	bundle.RegisterUnmarshalFunc("toml", toml.Unmarshal)
@@ -86,30 +101,63 @@ func Init(ctx context.Context) {
 		SecretsRepository: config.RepositoryConfig{
 			FileName:        config.GetEnv("SECRETS_REPOSITORY_FILE_NAME", ""),
 			PreloadInMemory: config.GetEnvAsBool("SECRETS_REPOSITORY_MEMORY_PRELOAD", true),
+			Watch:           config.GetEnvAsBool("SECRETS_REPOSITORY_FILE_WATCH", true),
 		},
 		FoldersRepository: config.RepositoryConfig{
 			FileName:        config.GetEnv("FOLDERS_REPOSITORY_FILE_NAME", ""),
 			PreloadInMemory: config.GetEnvAsBool("FOLDERS_REPOSITORY_MEMORY_PRELOAD", true),
+			Watch:           config.GetEnvAsBool("FOLDERS_REPOSITORY_FILE_WATCH", true),
+		},
+		Expiry: config.ExpiryConfig{
+			Enabled:       config.GetEnvAsBool("EXPIRY_CHECK_ENABLED", false),
+			CheckInterval: time.Duration(config.GetEnvAsInt("EXPIRY_CHECK_INTERVAL", 3600)) * time.Second,
+			Window:        time.Duration(config.GetEnvAsInt("EXPIRY_CHECK_WINDOW", 7*24*3600)) * time.Second,
+		},
+		Notifications: config.NotificationsConfig{
+			WebhookURL:     config.GetEnv("NOTIFICATIONS_WEBHOOK_URL", ""),
+			WebhookTimeout: time.Duration(config.GetEnvAsInt("NOTIFICATIONS_WEBHOOK_TIMEOUT", 10)) * time.Second,
+			SMTP: config.SMTPConfig{
+				Host:     config.GetEnv("NOTIFICATIONS_SMTP_HOST", ""),
+				Port:     config.GetEnvAsInt("NOTIFICATIONS_SMTP_PORT", 25),
+				Username: config.GetEnv("NOTIFICATIONS_SMTP_USERNAME", ""),
+				Password: config.GetEnv("NOTIFICATIONS_SMTP_PASSWORD", ""),
+				From:     config.GetEnv("NOTIFICATIONS_SMTP_FROM", "hideout@localhost"),
+				To:       extra.RemoveEmptyString(strings.Split(config.GetEnv("NOTIFICATIONS_SMTP_TO", ""), ",")),
+			},
+		},
+		Security: config.SecurityConfig{
+			EncryptionKey: config.GetEnv("ENCRYPTION_KEY", ""),
+			SigningKey:    config.GetEnv("SIGNING_KEY", ""),
+		},
+		Audit: config.AuditConfig{
+			FileName: config.GetEnv("AUDIT_LOG_FILE", ""),
+		},
+		Leases: config.LeasesConfig{
+			ReaperEnabled:  config.GetEnvAsBool("LEASE_REAPER_ENABLED", true),
+			ReaperInterval: time.Duration(config.GetEnvAsInt("LEASE_REAPER_INTERVAL", 60)) * time.Second,
 		},
 	}
 
 	secretsAdapterType := config.GetEnv("SECRETS_REPOSITORY_TYPE", "memory")
 	secretsAdapterTypeVal, secretsAdapterTypeExists := secrets2.TypeMap[secretsAdapterType]
 	if !secretsAdapterTypeExists {
-		log.Fatalf("Invalid secrets adapter type, allowed: %s, %s, %s, %s",
+		log.Fatalf("Invalid secrets adapter type, allowed: %s, %s, %s, %s, %s, %s",
 			secrets2.TypeMapInv[secrets2.RepositoryType_InMemory],
 			secrets2.TypeMapInv[secrets2.RepositoryType_Redis],
 			secrets2.TypeMapInv[secrets2.RepositoryType_Database],
-			secrets2.TypeMapInv[secrets2.RepositoryType_File])
+			secrets2.TypeMapInv[secrets2.RepositoryType_File],
+			secrets2.TypeMapInv[secrets2.RepositoryType_Store],
+			secrets2.TypeMapInv[secrets2.RepositoryType_Embedded])
 	}
 	Settings.SecretsRepository.Type = secretsAdapterTypeVal
 	if Settings.SecretsRepository.Type == secrets2.RepositoryType_File {
 		secretsEncodingTypeVal := config.GetEnv("SECRETS_REPOSITORY_FILE_ENCODING", extra.EncodingTypeMap[extra.Encoding_JSON])
 		secretsEncodingType, secretEncodingExists := extra.EncodingTypeMapInv[secretsEncodingTypeVal]
 		if !secretEncodingExists {
-			log.Fatalf("Invalid secrets repository encoding type, allowed: %s, %s, %s, %s, %s, %s", extra.EncodingTypeMap[extra.Encoding_Binary],
+			log.Fatalf("Invalid secrets repository encoding type, allowed: %s, %s, %s, %s, %s, %s, %s, %s", extra.EncodingTypeMap[extra.Encoding_Binary],
 				extra.EncodingTypeMap[extra.Encoding_GOB], extra.EncodingTypeMap[extra.Encoding_CSV], extra.EncodingTypeMap[extra.Encoding_JSON],
-				extra.EncodingTypeMap[extra.Encoding_XML], extra.EncodingTypeMap[extra.Encoding_Archive])
+				extra.EncodingTypeMap[extra.Encoding_XML], extra.EncodingTypeMap[extra.Encoding_Archive],
+				extra.EncodingTypeMap[extra.Encoding_YAML], extra.EncodingTypeMap[extra.Encoding_TOML])
 		}
 		Settings.SecretsRepository.FileEncoding = secretsEncodingType
 	}
@@ -117,24 +165,71 @@ func Init(ctx context.Context) {
 	foldersAdapterType := config.GetEnv("FOLDERS_REPOSITORY_TYPE", "memory")
 	foldersAdapterTypeVal, foldersAdapterTypeExists := secrets2.TypeMap[foldersAdapterType]
 	if !foldersAdapterTypeExists {
-		log.Fatalf("Invalid folders adapter type, allowed: %s, %s, %s, %s",
+		log.Fatalf("Invalid folders adapter type, allowed: %s, %s, %s, %s, %s, %s",
 			secrets2.TypeMapInv[secrets2.RepositoryType_InMemory],
 			secrets2.TypeMapInv[secrets2.RepositoryType_Redis],
 			secrets2.TypeMapInv[secrets2.RepositoryType_Database],
-			secrets2.TypeMapInv[secrets2.RepositoryType_File])
+			secrets2.TypeMapInv[secrets2.RepositoryType_File],
+			secrets2.TypeMapInv[secrets2.RepositoryType_Store],
+			secrets2.TypeMapInv[secrets2.RepositoryType_Embedded])
 	}
 	Settings.FoldersRepository.Type = foldersAdapterTypeVal
 	if Settings.FoldersRepository.Type == secrets2.RepositoryType_File {
 		foldersEncodingTypeVal := config.GetEnv("FOLDERS_REPOSITORY_FILE_ENCODING", extra.EncodingTypeMap[extra.Encoding_JSON])
 		foldersEncodingType, folderEncodingExists := extra.EncodingTypeMapInv[foldersEncodingTypeVal]
 		if !folderEncodingExists {
-			log.Fatalf("Invalid folders repository encoding type, allowed: %s, %s, %s, %s, %s, %s", extra.EncodingTypeMap[extra.Encoding_Binary],
+			log.Fatalf("Invalid folders repository encoding type, allowed: %s, %s, %s, %s, %s, %s, %s, %s", extra.EncodingTypeMap[extra.Encoding_Binary],
 				extra.EncodingTypeMap[extra.Encoding_GOB], extra.EncodingTypeMap[extra.Encoding_CSV], extra.EncodingTypeMap[extra.Encoding_JSON],
-				extra.EncodingTypeMap[extra.Encoding_XML], extra.EncodingTypeMap[extra.Encoding_Archive])
+				extra.EncodingTypeMap[extra.Encoding_XML], extra.EncodingTypeMap[extra.Encoding_Archive],
+				extra.EncodingTypeMap[extra.Encoding_YAML], extra.EncodingTypeMap[extra.Encoding_TOML])
 		}
 		Settings.FoldersRepository.FileEncoding = foldersEncodingType
 	}
 
+	if Settings.SecretsRepository.Type == secrets2.RepositoryType_Store || Settings.FoldersRepository.Type == secrets2.RepositoryType_Store {
+		// Store keeps folders and secrets together, so either both or none of them are kept there
+		if Settings.SecretsRepository.Type != Settings.FoldersRepository.Type {
+			log.Fatalf("Secrets and folders adapter types must both be %s", secrets2.TypeMapInv[secrets2.RepositoryType_Store])
+		}
+
+		storeEncodingTypeVal := config.GetEnv("STORE_FILE_ENCODING", extra.EncodingTypeMap[extra.Encoding_JSON])
+		storeEncodingType, storeEncodingExists := extra.EncodingTypeMapInv[storeEncodingTypeVal]
+		if !storeEncodingExists || !slices.Contains(store.Encodings, storeEncodingType) {
+			log.Fatalf("Invalid store encoding type, allowed: %s, %s, %s", extra.EncodingTypeMap[extra.Encoding_JSON],
+				extra.EncodingTypeMap[extra.Encoding_GOB], extra.EncodingTypeMap[extra.Encoding_XML])
+		}
+		storeSyncPolicyVal := config.GetEnv("STORE_SYNC", store.SyncPoliciesMapInv[store.Sync_Always])
+		storeSyncPolicy, storeSyncPolicyExists := store.SyncPoliciesMap[storeSyncPolicyVal]
+		if !storeSyncPolicyExists {
+			log.Fatalf("Invalid store sync policy, allowed: %s, %s, %s", store.SyncPoliciesMapInv[store.Sync_Always],
+				store.SyncPoliciesMapInv[store.Sync_File], store.SyncPoliciesMapInv[store.Sync_Never])
+		}
+		Settings.Store = config.StoreConfig{
+			FileName:     config.GetEnv("STORE_FILE_NAME", "store.json"),
+			FileEncoding: storeEncodingType,
+			SyncPolicy:   storeSyncPolicy,
+		}
+
+		fileStore, errCreateStore := store.NewStore(Settings.Store.FileName, Settings.Store.FileEncoding, Settings.Store.SyncPolicy)
+		if errCreateStore != nil {
+			log.Fatalf("Error creating store: %s", errCreateStore)
+		}
+		structs.Store = fileStore
+	}
+
+	if Settings.SecretsRepository.Type == secrets2.RepositoryType_Embedded || Settings.FoldersRepository.Type == secrets2.RepositoryType_Embedded {
+		// Single file is opened once and shared, as it is locked by the process holding it open
+		Settings.Embedded = config.EmbeddedConfig{
+			FileName: config.GetEnv("EMBEDDED_FILE_NAME", "hideout.db"),
+		}
+
+		db, errOpenEmbedded := embedded.Open(Settings.Embedded.FileName)
+		if errOpenEmbedded != nil {
+			log.Fatalf("Error opening embedded store: %s", errOpenEmbedded)
+		}
+		structs.Embedded = db
+	}
+
 	if Settings.SecretsRepository.Type == secrets2.RepositoryType_Redis || Settings.FoldersRepository.Type == secrets2.RepositoryType_Redis {
 		client := redis.NewClient(&redis.Options{
 			Network: Settings.Redis.Proto, Addr: fmt.Sprintf("%s:%d", Settings.Redis.Host, Settings.Redis.Port),
@@ -5,10 +5,15 @@ import (
 	"github.com/joho/godotenv"
 	"hideout/api"
 	apiconfig "hideout/cmd/api/config"
+	"hideout/internal/pkg/encryption"
+	"hideout/services/database"
+	"hideout/services/expiry"
+	"hideout/services/leases"
 	"hideout/services/secrets"
 	"hideout/structs"
 	"log"
 	"runtime/debug"
+	"strings"
 	"time"
 )
 
@@ -30,6 +35,11 @@ func main() {
 		}
 	}()
 
+	errRecover := secrets.Recover(ctx, apiconfig.Settings.SecretsRepository, apiconfig.Settings.FoldersRepository)
+	if errRecover != nil {
+		log.Fatal(errRecover)
+	}
+
 	secretsSvc, errCreateService := secrets.NewService(ctx, apiconfig.Settings.SecretsRepository, apiconfig.Settings.FoldersRepository,
 		&structs.Folders, &structs.Secrets)
 	if errCreateService != nil {
@@ -41,6 +51,34 @@ func main() {
 		log.Fatal(errLoad)
 	}
 
+	errWatch := secrets.Watch(ctx, apiconfig.Settings.SecretsRepository, apiconfig.Settings.FoldersRepository)
+	if errWatch != nil {
+		log.Fatal(errWatch)
+	}
+
+	if apiconfig.Settings.Expiry.Enabled {
+		expirySvc := expiry.NewService(apiconfig.Settings.Expiry, secretsSvc, expiry.SinksFromConfig(apiconfig.Settings.Notifications))
+		go expirySvc.Run(ctx)
+		log.Printf("Secrets expiration checker started with interval of %s", apiconfig.Settings.Expiry.CheckInterval)
+	}
+
+	apiconfig.Leases = leases.NewService(secretsSvc)
+	// Engines keeping their connections encrypted can neither hand out nor revoke anything without the key
+	encryptionKey, errParseKey := encryption.ParseKey(apiconfig.Settings.Security.EncryptionKey)
+	if errParseKey != nil {
+		log.Printf("Database secrets engine leases are not handed out nor revoked: %s", errParseKey.Error())
+	} else {
+		apiconfig.Leases.Register(database.Engine, database.NewService(secretsSvc, apiconfig.Leases, encryptionKey))
+	}
+
+	if apiconfig.Settings.Leases.ReaperEnabled && len(apiconfig.Leases.Engines()) == 0 {
+		log.Println("Expired leases reaper is not started, as no engine handing out leases is registered")
+	} else if apiconfig.Settings.Leases.ReaperEnabled {
+		go apiconfig.Leases.Run(ctx, apiconfig.Settings.Leases.ReaperInterval)
+		log.Printf("Expired leases reaper started with interval of %s for engines %s", apiconfig.Settings.Leases.ReaperInterval,
+			strings.Join(apiconfig.Leases.Engines(), ", "))
+	}
+
 	/*
 		Localizer := i18n.NewLocalizer(apiconfig.Settings.Bundle, translations.DefaultLanguage)
 		rootFolder, errCreateRootFolder := secretsSvc.CreateFolder(ctx, folders.Folder{Name: ""})
@@ -0,0 +1,69 @@
+// Command store-migrate moves folders and secrets kept in two separate files (file repositories) into a single file
+// store. Files are taken from the same environment variables (and .env file) as the API uses, flags override them.
+// Source files are left untouched and the store must not exist yet.
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/joho/godotenv"
+	"hideout/config"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/extra"
+	"hideout/internal/secrets"
+	"hideout/internal/store"
+	"log"
+)
+
+func main() {
+	ctx := context.Background()
+	// Environment alone is enough, .env file is optional
+	_ = godotenv.Load(".env")
+
+	foldersFileName := flag.String("folders", config.GetEnv("FOLDERS_REPOSITORY_FILE_NAME", ""), "File of folders")
+	foldersEncoding := flag.String("folders-encoding", config.GetEnv("FOLDERS_REPOSITORY_FILE_ENCODING", extra.EncodingTypeMap[extra.Encoding_JSON]), "Encoding of file of folders")
+	secretsFileName := flag.String("secrets", config.GetEnv("SECRETS_REPOSITORY_FILE_NAME", ""), "File of secrets")
+	secretsEncoding := flag.String("secrets-encoding", config.GetEnv("SECRETS_REPOSITORY_FILE_ENCODING", extra.EncodingTypeMap[extra.Encoding_JSON]), "Encoding of file of secrets")
+	storeFileName := flag.String("store", config.GetEnv("STORE_FILE_NAME", "store.json"), "File of store, which must not exist")
+	storeEncoding := flag.String("store-encoding", config.GetEnv("STORE_FILE_ENCODING", extra.EncodingTypeMap[extra.Encoding_JSON]), "Encoding of store")
+	storeSyncPolicy := flag.String("sync", config.GetEnv("STORE_SYNC", store.SyncPoliciesMapInv[store.Sync_Always]), "Sync policy of store (always, file, never)")
+	flag.Parse()
+
+	if *foldersFileName == "" || *secretsFileName == "" {
+		log.Fatal("Both files of folders and secrets are required")
+	}
+	foldersEncodingType, foldersEncodingExists := extra.EncodingTypeMapInv[*foldersEncoding]
+	if !foldersEncodingExists {
+		log.Fatalf("Invalid encoding of folders: %s", *foldersEncoding)
+	}
+	secretsEncodingType, secretsEncodingExists := extra.EncodingTypeMapInv[*secretsEncoding]
+	if !secretsEncodingExists {
+		log.Fatalf("Invalid encoding of secrets: %s", *secretsEncoding)
+	}
+	storeEncodingType, storeEncodingExists := extra.EncodingTypeMapInv[*storeEncoding]
+	if !storeEncodingExists {
+		log.Fatalf("Invalid encoding of store: %s", *storeEncoding)
+	}
+	syncPolicy, syncPolicyExists := store.SyncPoliciesMap[*storeSyncPolicy]
+	if !syncPolicyExists {
+		log.Fatalf("Invalid sync policy of store: %s", *storeSyncPolicy)
+	}
+
+	fileStore, errCreateStore := store.NewStore(*storeFileName, storeEncodingType, syncPolicy)
+	if errCreateStore != nil {
+		log.Fatalf("Error creating store: %s", errCreateStore)
+	}
+	result, errMigrate := store.Migrate(ctx, folders.NewFileRepository(*foldersFileName, foldersEncodingType, nil),
+		secrets.NewFileRepository(*secretsFileName, secretsEncodingType, nil), fileStore)
+	if errMigrate != nil {
+		log.Fatalf("Error migrating into store: %s", errMigrate)
+	}
+
+	if len(result.OrphanFolders) != 0 {
+		log.Printf("Folders with missing parent (kept as they are): %v", result.OrphanFolders)
+	}
+	if len(result.OrphanSecrets) != 0 {
+		log.Printf("Secrets with missing folder (kept as they are): %v", result.OrphanSecrets)
+	}
+	log.Printf("Moved %d folders and %d secrets into store %s", result.Folders, result.Secrets, *storeFileName)
+}
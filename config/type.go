@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type (
 	ServerConfig struct {
 		Host   string
@@ -12,6 +14,19 @@ type (
 		PreloadInMemory bool
 		FileName        string
 		FileEncoding    uint
+		Watch           bool // Whether preloaded records are reloaded once file (or store) is changed on disk
+	}
+
+	// StoreConfig Single file keeping both folders and secrets
+	StoreConfig struct {
+		FileName     string
+		FileEncoding uint
+		SyncPolicy   uint // How thoroughly written file is flushed to disk
+	}
+
+	// EmbeddedConfig Embedded key-value store file keeping folders, secrets or both
+	EmbeddedConfig struct {
+		FileName string
 	}
 
 	EnvironmentConfig struct {
@@ -37,4 +52,39 @@ type (
 		Proto   string
 		SSLMode bool
 	}
+
+	ExpiryConfig struct {
+		Enabled       bool          // Whether background expiration checker is running
+		CheckInterval time.Duration // How often secrets are checked for expiration
+		Window        time.Duration // Secrets expiring within this window are reported
+	}
+
+	NotificationsConfig struct {
+		WebhookURL     string
+		WebhookTimeout time.Duration
+		SMTP           SMTPConfig
+	}
+
+	SMTPConfig struct {
+		Host     string
+		Port     int
+		Username string
+		Password string
+		From     string
+		To       []string
+	}
+
+	SecurityConfig struct {
+		EncryptionKey string // Base64-encoded AES-256 key used to encrypt sensitive secret values at rest
+		SigningKey    string // Base64-encoded Ed25519 seed used to sign manifests of exports
+	}
+
+	LeasesConfig struct {
+		ReaperEnabled  bool          // Whether expired leases are revoked in background
+		ReaperInterval time.Duration // How often leases are checked for expiration
+	}
+
+	AuditConfig struct {
+		FileName string // File audit events are appended to, application log is used if empty
+	}
 )
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS secrets_expires_at_idx;
+
+ALTER TABLE public.secrets
+    DROP COLUMN IF EXISTS expires_at;
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE public.secrets
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP NULL DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS secrets_expires_at_idx ON public.secrets (expires_at);
+
+COMMIT;
\ No newline at end of file
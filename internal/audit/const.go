@@ -0,0 +1,16 @@
+package audit
+
+const (
+	Action_TOTPCode   = "totp.code"
+	Action_TOTPReveal = "totp.reveal"
+
+	Action_TransitCreateKey    = "transit.create-key"
+	Action_TransitRotateKey    = "transit.rotate-key"
+	Action_TransitConfigureKey = "transit.configure-key"
+	Action_TransitEncrypt      = "transit.encrypt"
+	Action_TransitDecrypt      = "transit.decrypt"
+	Action_TransitRewrap       = "transit.rewrap"
+	Action_TransitSign         = "transit.sign"
+	Action_TransitVerify       = "transit.verify"
+	Action_TransitHMAC         = "transit.hmac"
+)
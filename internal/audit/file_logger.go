@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileLogger Appends audit events to a file, one JSON document per line
+type FileLogger struct {
+	Filename string
+}
+
+// Writes from concurrent requests must not interleave
+var fileMutex sync.Mutex
+
+func NewFileLogger(filename string) FileLogger {
+	return FileLogger{Filename: filename}
+}
+
+func (m FileLogger) Log(ctx context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Actor == (Actor{}) {
+		event.Actor = ActorFromContext(ctx)
+	}
+	eventData, errMarshal := json.Marshal(event)
+	if errMarshal != nil {
+		return errMarshal
+	}
+
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+	fileWriter, errOpenFile := os.OpenFile(m.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if errOpenFile != nil {
+		return errors.Wrapf(errOpenFile, "Failed to open audit log %s", m.Filename)
+	}
+	defer fileWriter.Close()
+	_, errWrite := fileWriter.Write(append(eventData, '\n'))
+	return errWrite
+}
@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"context"
+	"hideout/config"
+)
+
+// WithActor Stores client information in the context, so that events logged further down are attributed to it
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext Client information stored by WithActor
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorContextKey{}).(Actor)
+	return actor
+}
+
+// NewLogger Audit logger writing into the configured file, or into the application log if none is configured
+func NewLogger(auditConfig config.AuditConfig) Logger {
+	if auditConfig.FileName != "" {
+		return NewFileLogger(auditConfig.FileName)
+	}
+	return NewLogLogger()
+}
@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// LogLogger Writes audit events into the application log
+type LogLogger struct{}
+
+func NewLogLogger() LogLogger {
+	return LogLogger{}
+}
+
+func (m LogLogger) Log(ctx context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Actor == (Actor{}) {
+		event.Actor = ActorFromContext(ctx)
+	}
+	eventData, errMarshal := json.Marshal(event)
+	if errMarshal != nil {
+		return errMarshal
+	}
+	log.Printf("[AUDIT] %s", eventData)
+	return nil
+}
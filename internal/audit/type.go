@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Event Single audited action performed on a secret
+	Event struct {
+		Time       time.Time         `json:"Time"`
+		Action     string            `json:"Action"`
+		SecretUID  string            `json:"SecretUID,omitempty"`
+		SecretName string            `json:"SecretName,omitempty"`
+		FolderUID  string            `json:"FolderUID,omitempty"`
+		Actor      Actor             `json:"Actor"`
+		Success    bool              `json:"Success"`
+		Error      string            `json:"Error,omitempty"`
+		Details    map[string]string `json:"Details,omitempty"`
+	}
+
+	// Actor Information about the client performing the action
+	Actor struct {
+		RemoteAddr string `json:"RemoteAddr,omitempty"`
+		UserAgent  string `json:"UserAgent,omitempty"`
+	}
+
+	Logger interface {
+		Log(ctx context.Context, event Event) error
+	}
+
+	actorContextKey struct{}
+)
@@ -0,0 +1,22 @@
+package generics
+
+import "encoding/json"
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+
+	var value T
+	if errUnmarshal := json.Unmarshal(data, &value); errUnmarshal != nil {
+		return errUnmarshal
+	}
+	o.Value = &value
+	return nil
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Value)
+}
@@ -7,6 +7,12 @@ import (
 )
 
 type (
+	// Optional Value of request body, which tells omitted value (Set is false) apart from explicit null (Value is nil)
+	Optional[T any] struct {
+		Set   bool
+		Value *T
+	}
+
 	FromTo[T any] struct {
 		From T `json:"From"`
 		To   T `json:"To"`
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"database/sql"
+	"hideout/internal/pkg/binfile"
+	"time"
+)
+
+// EncodeBinary Writes fields of the model in binary file layout, fields of embedding record follow
+func (m Model) EncodeBinary(encoder *binfile.Encoder) {
+	encoder.Uint(uint64(m.ID))
+	encoder.Time(m.CreatedAt)
+	encoder.Time(m.UpdatedAt)
+	encoder.NullTime(m.DeletedAt)
+}
+
+// DecodeBinary Reads fields of the model in binary file layout
+func DecodeBinary(decoder *binfile.Decoder) Model {
+	return Model{
+		ID:        uint(decoder.Uint()),
+		CreatedAt: decoder.Time(),
+		UpdatedAt: decoder.Time(),
+		DeletedAt: decoder.NullTime(),
+	}
+}
+
+// NullTimeToPtr Time which is left out of human-editable files when not set
+func NullTimeToPtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+// PtrToNullTime Time read from human-editable files, which is not set when left out
+func PtrToNullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *value, Valid: true}
+}
@@ -2,12 +2,20 @@ package model
 
 import (
 	"database/sql"
+	"sync"
 	"time"
 )
 
 type Model struct {
-	ID        uint         `struc:"uint64" json:"ID" bson:"ID" csv:"ID" xml:"ID" yaml:"ID" db:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	ID        uint         `json:"ID" bson:"ID" csv:"ID" xml:"ID" yaml:"ID" db:"id" gorm:"column:id;primaryKey;autoIncrement"`
 	CreatedAt time.Time    `json:"CreatedAt" bson:"CreatedAt" csv:"CreatedAt" xml:"CreatedAt" yaml:"CreatedAt" db:"created_at" gorm:"column:created_at"`
 	UpdatedAt time.Time    `json:"UpdatedAt" bson:"UpdatedAt" csv:"UpdatedAt" xml:"UpdatedAt" yaml:"UpdatedAt" db:"updated_at" gorm:"column:updated_at"`
 	DeletedAt sql.NullTime `json:"DeletedAt" bson:"DeletedAt" csv:"DeletedAt" xml:"DeletedAt" yaml:"DeletedAt" db:"deleted_at" gorm:"column:deleted_at"`
 }
+
+// Preload Lock of preloaded records shared by their in-memory repositories, along with the generation counting local
+// writes, so that records reloaded from disk never replace newer ones
+type Preload struct {
+	sync.RWMutex
+	Generation uint64
+}
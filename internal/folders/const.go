@@ -3,3 +3,19 @@ package folders
 const (
 	TableName = "folders"
 )
+
+const (
+	// BinaryKind Kind of records in binary file of folders
+	BinaryKind = 1
+	// ArchiveDirectory Directory holding one JSON file per folder in archive file of folders
+	ArchiveDirectory = TableName
+)
+
+const (
+	// EmbeddedIndexUID Bucket indexing folders by UID in embedded key-value store
+	EmbeddedIndexUID = TableName + "_uid"
+	// EmbeddedIndexParent Bucket indexing folders by parent folder in embedded key-value store
+	EmbeddedIndexParent = TableName + "_parent"
+	// EmbeddedIndexName Bucket indexing folders by name in embedded key-value store
+	EmbeddedIndexName = TableName + "_name"
+)
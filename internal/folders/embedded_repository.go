@@ -0,0 +1,322 @@
+package folders
+
+import (
+	"context"
+	"database/sql"
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+	"hideout/internal/common/apperror"
+	"hideout/internal/common/generics"
+	"hideout/internal/common/model"
+	"hideout/internal/common/ordering"
+	"hideout/internal/common/pagination"
+	"hideout/internal/pkg/embedded"
+	"time"
+)
+
+type EmbeddedRepository struct {
+	db                 *bbolt.DB
+	inMemoryRepository *InMemoryRepository
+}
+
+func NewEmbeddedRepository(db *bbolt.DB, inMemoryRep *InMemoryRepository) EmbeddedRepository {
+	return EmbeddedRepository{db: db, inMemoryRepository: inMemoryRep}
+}
+
+func (m EmbeddedRepository) GetID(ctx context.Context) (uint, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetID(ctx)
+	}
+
+	var id uint
+	errView := m.db.View(func(tx *bbolt.Tx) error {
+		id = EmbeddedTable.NextID(tx)
+		return nil
+	})
+	if errView != nil {
+		return 0, errors.Wrap(errView, "Failed to retrieve next folder ID from embedded store")
+	}
+
+	return id, nil
+}
+
+func (m EmbeddedRepository) Load(ctx context.Context) ([]Folder, error) {
+	var results []Folder
+	errView := m.db.View(func(tx *bbolt.Tx) error {
+		var errAll error
+		results, errAll = EmbeddedTable.All(tx)
+		return errAll
+	})
+	if errView != nil {
+		return nil, errors.Wrap(errView, "Failed to load folders from embedded store")
+	}
+
+	return results, nil
+}
+
+func (m EmbeddedRepository) GetMapByID(ctx context.Context, params ListFolderParams) (map[uint]*Folder, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetMapByID(ctx, params)
+	}
+
+	results, errGetResults := m.Get(ctx, params)
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	resultsMap := make(map[uint]*Folder)
+	for _, result := range results {
+		resultsMap[result.ID] = result
+	}
+
+	return resultsMap, nil
+}
+
+func (m EmbeddedRepository) GetMapByUID(ctx context.Context, params ListFolderParams) (map[string]*Folder, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetMapByUID(ctx, params)
+	}
+
+	results, errGetResults := m.Get(ctx, params)
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	resultsMap := make(map[string]*Folder)
+	for _, result := range results {
+		resultsMap[result.UID] = result
+	}
+
+	return resultsMap, nil
+}
+
+func (m EmbeddedRepository) Get(ctx context.Context, params ListFolderParams) ([]*Folder, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.Get(ctx, params)
+	}
+
+	var results []Folder
+	errView := m.db.View(func(tx *bbolt.Tx) error {
+		var errFind error
+		results, errFind = m.candidates(tx, params)
+		return errFind
+	})
+	if errView != nil {
+		return nil, errors.Wrap(errView, "Failed to retrieve folders from embedded store")
+	}
+
+	// Candidates are narrowed down by a single index, the rest of parameters is applied the same way as in memory
+	inMemoryRepository := NewInMemoryRepository(&results)
+	return inMemoryRepository.Get(ctx, params)
+}
+
+func (m EmbeddedRepository) GetByID(ctx context.Context, id uint) (*Folder, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetByID(ctx, id)
+	}
+
+	resultsMap, errGetResults := m.GetMapByID(ctx, ListFolderParams{ListParams: generics.ListParams{IDs: []uint{id}, Deleted: model.No}})
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	result, exists := resultsMap[id]
+	if !exists {
+		return nil, apperror.ErrRecordNotFound
+	}
+
+	return result, nil
+}
+
+func (m EmbeddedRepository) GetByUID(ctx context.Context, uid string) (*Folder, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetByUID(ctx, uid)
+	}
+
+	resultsMap, errGetResults := m.GetMapByUID(ctx, ListFolderParams{ListParams: generics.ListParams{UIDs: []string{uid}, Deleted: model.No}})
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	result, exists := resultsMap[uid]
+	if !exists {
+		return nil, apperror.ErrRecordNotFound
+	}
+
+	return result, nil
+}
+
+func (m EmbeddedRepository) Update(ctx context.Context, folder Folder) (*Folder, error) {
+	var updatedFolderEntry *Folder
+	errUpdate := m.db.Update(func(tx *bbolt.Tx) error {
+		existingFolder, errGetFolder := EmbeddedTable.Get(tx, folder.ID)
+		if errGetFolder != nil {
+			return errGetFolder
+		}
+		if existingFolder == nil {
+			return apperror.ErrRecordNotFound
+		}
+
+		var errUpdateFolder error
+		updatedFolderEntry, errUpdateFolder = NewInMemoryRepository(&[]Folder{*existingFolder}).Update(ctx, folder)
+		if errUpdateFolder != nil {
+			return errUpdateFolder
+		}
+		return m.put(tx, *updatedFolderEntry)
+	})
+	if errUpdate != nil {
+		return nil, errors.Wrapf(errUpdate, "Error updating folder with ID of %d in embedded store", folder.ID)
+	}
+
+	if m.inMemoryRepository != nil {
+		updatedFolder, errUpdateFolder := m.inMemoryRepository.Update(ctx, *updatedFolderEntry)
+		if errUpdateFolder != nil {
+			return nil, errors.Wrapf(errUpdateFolder, "Error updating folder with ID of %d and name %s in memory", folder.ID, folder.Name)
+		}
+
+		updatedFolderEntry = updatedFolder
+	}
+
+	return updatedFolderEntry, nil
+}
+
+func (m EmbeddedRepository) Create(ctx context.Context, folder Folder) (*Folder, error) {
+	var createdFolderEntry *Folder
+	errCreate := m.db.Update(func(tx *bbolt.Tx) error {
+		existingFolder, errGetFolder := EmbeddedTable.Get(tx, folder.ID)
+		if errGetFolder != nil {
+			return errGetFolder
+		}
+		if existingFolder != nil {
+			return apperror.ErrAlreadyExists
+		}
+
+		// Folders of the same parent are enough to check whether the name is already taken
+		siblings, errFindSiblings := EmbeddedTable.Find(tx, EmbeddedIndexParent, embedded.Key(folder.ParentID))
+		if errFindSiblings != nil {
+			return errFindSiblings
+		}
+		var errCreateFolder error
+		createdFolderEntry, errCreateFolder = NewInMemoryRepository(&siblings).Create(ctx, folder)
+		if errCreateFolder != nil {
+			return errCreateFolder
+		}
+		return m.put(tx, *createdFolderEntry)
+	})
+	if errCreate != nil {
+		return nil, errors.Wrapf(errCreate, "Error creating folder with ID of %d in embedded store", folder.ID)
+	}
+
+	if m.inMemoryRepository != nil {
+		newFolder, errCreateFolder := m.inMemoryRepository.Create(ctx, *createdFolderEntry)
+		if errCreateFolder != nil {
+			return nil, errors.Wrapf(errCreateFolder, "Error creating folder with parent ID of %d and name %s in memory", folder.ParentID, folder.Name)
+		}
+		createdFolderEntry = newFolder
+	}
+
+	return createdFolderEntry, nil
+}
+
+func (m EmbeddedRepository) Count(ctx context.Context, params ListFolderParams) (uint, error) {
+	// These are not needed when performing filtering and counting
+	params.Pagination = pagination.Pagination{PerPage: 0, Page: 0}
+	params.Order = []ordering.Order{}
+
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.Count(ctx, params)
+	}
+
+	results, errGetResults := m.Get(ctx, params)
+	if errGetResults != nil {
+		return 0, errGetResults
+	}
+
+	return uint(len(results)), nil
+}
+
+func (m EmbeddedRepository) Delete(ctx context.Context, id uint, forceDelete bool) error {
+	errDelete := m.db.Update(func(tx *bbolt.Tx) error {
+		existingFolder, errGetFolder := EmbeddedTable.Get(tx, id)
+		if errGetFolder != nil {
+			return errGetFolder
+		}
+		if existingFolder == nil {
+			return apperror.ErrRecordNotFound
+		}
+
+		if forceDelete {
+			return EmbeddedTable.Delete(tx, id)
+		}
+		existingFolder.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		return m.put(tx, *existingFolder)
+	})
+	if errDelete != nil {
+		return errors.Wrapf(errDelete, "Error deleting folder with ID of %d in embedded store", id)
+	}
+
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.Delete(ctx, id, forceDelete)
+	}
+
+	return nil
+}
+
+// put Writes the folder, taken UID is reported the same way as taken name is
+func (m EmbeddedRepository) put(tx *bbolt.Tx, folder Folder) error {
+	errPut := EmbeddedTable.Put(tx, folder)
+	if errors.Is(errPut, embedded.ErrUniqueViolation) {
+		return errors.Wrapf(apperror.ErrAlreadyExists, "Folder with UID of %s", folder.UID)
+	}
+	return errPut
+}
+
+// candidates Folders which may match the parameters, narrowed down by the first index the parameters allow to use
+func (m EmbeddedRepository) candidates(tx *bbolt.Tx, params ListFolderParams) ([]Folder, error) {
+	var results []Folder
+	var found = make(map[uint]bool)
+	switch {
+	case len(params.IDs) > 0:
+		{
+			for _, id := range params.IDs {
+				folder, errGetFolder := EmbeddedTable.Get(tx, id)
+				if errGetFolder != nil {
+					return nil, errGetFolder
+				}
+				if folder != nil && !found[folder.ID] {
+					found[folder.ID] = true
+					results = append(results, *folder)
+				}
+			}
+			return results, nil
+		}
+	case len(params.UIDs) > 0:
+		{
+			for _, uid := range params.UIDs {
+				folders, errFindFolders := EmbeddedTable.Find(tx, EmbeddedIndexUID, []byte(uid))
+				if errFindFolders != nil {
+					return nil, errFindFolders
+				}
+				for _, folder := range folders {
+					if !found[folder.ID] {
+						found[folder.ID] = true
+						results = append(results, folder)
+					}
+				}
+			}
+			return results, nil
+		}
+	case params.ParentFolderID > 0:
+		{
+			return EmbeddedTable.Find(tx, EmbeddedIndexParent, embedded.Key(params.ParentFolderID))
+		}
+	case len(embedded.PatternPrefix(params.Name)) > 0:
+		{
+			return EmbeddedTable.Find(tx, EmbeddedIndexName, embedded.PatternPrefix(params.Name))
+		}
+	default:
+		{
+			return EmbeddedTable.All(tx)
+		}
+	}
+}
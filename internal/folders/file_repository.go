@@ -1,7 +1,6 @@
 package folders
 
 import (
-	"bytes"
 	"context"
 	"encoding/gob"
 	"encoding/json"
@@ -14,6 +13,10 @@ import (
 	"hideout/internal/common/ordering"
 	"hideout/internal/common/pagination"
 	"hideout/internal/pkg/extra"
+	"hideout/internal/pkg/journal"
+	"hideout/internal/pkg/safefile"
+	"hideout/internal/pkg/watcher"
+	"io"
 	"os"
 )
 
@@ -48,6 +51,12 @@ func (m FileRepository) GetID(ctx context.Context) (uint, error) {
 }
 
 func (m FileRepository) Load(ctx context.Context) ([]Folder, error) {
+	lock, errLock := safefile.LockShared(m.Filename)
+	if errLock != nil {
+		return nil, errLock
+	}
+	defer lock.Unlock()
+
 	var folders []Folder
 	errDecode := m.decode(&folders)
 	return folders, errDecode
@@ -144,11 +153,18 @@ func (m FileRepository) GetByUID(ctx context.Context, uid string) (*Folder, erro
 }
 
 func (m FileRepository) Update(ctx context.Context, folder Folder) (*Folder, error) {
+	lock, errLock := safefile.LockExclusive(m.Filename)
+	if errLock != nil {
+		return nil, errLock
+	}
+	defer lock.Unlock()
+
 	var inMemoryRepository = m.inMemoryRepository
 	if inMemoryRepository == nil {
-		folders, errLoadFolders := m.Load(ctx)
-		if errLoadFolders != nil {
-			return nil, errLoadFolders
+		var folders []Folder
+		errDecode := m.decode(&folders)
+		if errDecode != nil {
+			return nil, errDecode
 		}
 		inMemoryRepository = NewInMemoryRepository(&folders)
 	}
@@ -157,53 +173,36 @@ func (m FileRepository) Update(ctx context.Context, folder Folder) (*Folder, err
 	if errUpdateFolder != nil {
 		return nil, errUpdateFolder
 	}
-	folderPtrs, errGetFolders := inMemoryRepository.Get(ctx, ListFolderParams{ListParams: generics.ListParams{Deleted: model.YesOrNo}})
-	if errGetFolders != nil {
-		return nil, errGetFolders
-	}
-	var folders []Folder
-	for _, folderPtr := range folderPtrs {
-		folders = append(folders, *folderPtr)
-	}
-	return updatedFolder, m.encode(&folders)
+	return updatedFolder, m.write(journal.Put(*updatedFolder))
 }
 
 func (m FileRepository) Create(ctx context.Context, folder Folder) (*Folder, error) {
-	if m.inMemoryRepository != nil {
-		createdFolder, errCreateFolder := m.inMemoryRepository.Create(ctx, folder)
-		if errCreateFolder != nil {
-			return nil, errors.Wrapf(errCreateFolder, "Error creating folder with ID of %d in memory", folder.ID)
-		}
+	lock, errLock := safefile.LockExclusive(m.Filename)
+	if errLock != nil {
+		return nil, errLock
+	}
+	defer lock.Unlock()
 
-		folderPtrs, errGetFolders := m.inMemoryRepository.Get(ctx, ListFolderParams{ListParams: generics.ListParams{Deleted: model.YesOrNo}})
-		if errGetFolders != nil {
-			return nil, errGetFolders
-		}
+	var inMemoryRepository = m.inMemoryRepository
+	if inMemoryRepository == nil {
+		// Done this way because file may have a duplicate entry and needs to be
+		// loaded to check
 		var folders []Folder
-		for _, folderPtr := range folderPtrs {
-			folders = append(folders, *folderPtr)
+		errDecode := m.decode(&folders)
+		if errDecode != nil {
+			return nil, errDecode
 		}
-		errencode := m.encode(&folders)
-		return createdFolder, errencode
-	}
-
-	// Done this way because file may have a duplicate entry and needs to be
-	// loaded to check
-	var folders []Folder
-	errDecode := m.decode(&folders)
-	if errDecode != nil {
-		return nil, errDecode
+		inMemoryRepository = NewInMemoryRepository(&folders)
 	}
 
-	inMemoryRepository := NewInMemoryRepository(&folders)
 	createdFolder, errCreateFolder := inMemoryRepository.Create(ctx, folder)
 	if errCreateFolder != nil {
 		return nil, errors.Wrapf(errCreateFolder, "Error creating folder with ID of %d in memory", folder.ID)
 	}
 
-	errEncode := m.encode(&folders)
-	if errEncode != nil {
-		return nil, errEncode
+	errWrite := m.write(journal.Put(*createdFolder))
+	if errWrite != nil {
+		return nil, errWrite
 	}
 
 	return createdFolder, nil
@@ -227,11 +226,18 @@ func (m FileRepository) Count(ctx context.Context, params ListFolderParams) (uin
 }
 
 func (m FileRepository) Delete(ctx context.Context, id uint, forceDelete bool) error {
+	lock, errLock := safefile.LockExclusive(m.Filename)
+	if errLock != nil {
+		return errLock
+	}
+	defer lock.Unlock()
+
 	var inMemoryRepository = m.inMemoryRepository
 	if inMemoryRepository == nil {
-		folders, errLoadFolders := m.Load(ctx)
-		if errLoadFolders != nil {
-			return errLoadFolders
+		var folders []Folder
+		errDecode := m.decode(&folders)
+		if errDecode != nil {
+			return errDecode
 		}
 		inMemoryRepository = NewInMemoryRepository(&folders)
 	}
@@ -240,25 +246,112 @@ func (m FileRepository) Delete(ctx context.Context, id uint, forceDelete bool) e
 	if errDelete != nil {
 		return errDelete
 	}
+	if forceDelete {
+		return m.write(journal.Remove[Folder](id))
+	}
+
+	// Soft deleted folder is kept along with its deletion date
+	results, errGetResults := inMemoryRepository.GetMapByID(ctx, ListFolderParams{
+		ListParams: generics.ListParams{IDs: []uint{id}, Deleted: model.YesOrNo},
+	})
+	if errGetResults != nil {
+		return errGetResults
+	}
+	deletedFolder, exists := results[id]
+	if !exists {
+		return apperror.ErrRecordNotFound
+	}
+	return m.write(journal.Put(*deletedFolder))
+}
+
+// Recover Brings the file into consistent state after a crash: removes temporary files of interrupted writes and
+// merges journal (dropping its incomplete entry) into the file. Meant to be called on startup
+func (m FileRepository) Recover(ctx context.Context) error {
+	lock, errLock := safefile.LockExclusive(m.Filename)
+	if errLock != nil {
+		return errLock
+	}
+	defer lock.Unlock()
+
+	errClean := safefile.CleanTemp(m.Filename)
+	if errClean != nil {
+		return errClean
+	}
+	journalSize, errSize := m.journal().Size()
+	if errSize != nil {
+		return errSize
+	}
+	if journalSize == 0 {
+		return nil
+	}
+	return m.compact()
+}
+
+// Watch Reloads preloaded folders whenever the file (or its journal) is changed on disk, e.g. by another tool, while the
+// returned watcher runs. Invalid folders are handed to onError instead, reloads loaded before the latest local write
+// are dropped, as that write changes the file once again
+func (m FileRepository) Watch(ctx context.Context, onError func(errLoad error)) (*watcher.Watcher, error) {
+	fileNames := []string{m.Filename, m.journal().FileName}
+	return watcher.New(fileNames, func() {
+		generation := m.inMemoryRepository.Generation()
+		folders, errLoad := m.Load(ctx)
+		if errLoad == nil {
+			errLoad = Validate(folders)
+		}
+		if errLoad != nil {
+			onError(errLoad)
+			return
+		}
+		m.inMemoryRepository.Replace(folders, generation)
+	}, onError)
+}
+
+func (m FileRepository) journal() journal.Journal[Folder] {
+	return journal.New[Folder](m.Filename)
+}
+
+// write Appends changes to journal instead of rewriting the file, journal is merged into the file once it grows
+// large. Must only be called while holding exclusive lock of the file
+func (m FileRepository) write(entries ...journal.Entry[Folder]) error {
+	fileJournal := m.journal()
+	errAppend := fileJournal.Append(entries...)
+	if errAppend != nil {
+		return errAppend
+	}
 
-	folderPtrs, errGetFolders := inMemoryRepository.Get(ctx, ListFolderParams{ListParams: generics.ListParams{Deleted: model.YesOrNo}})
-	if errGetFolders != nil {
-		return errGetFolders
+	journalSize, errSize := fileJournal.Size()
+	if errSize != nil {
+		return errSize
 	}
+	if journalSize < journal.CompactSize {
+		return nil
+	}
+	return m.compact()
+}
+
+// compact Merges journal into the file. Journal is reset only once the file is replaced, as replaying it again is
+// harmless. Must only be called while holding exclusive lock of the file
+func (m FileRepository) compact() error {
 	var folders []Folder
-	for _, folderPtr := range folderPtrs {
-		folders = append(folders, *folderPtr)
+	errDecode := m.decode(&folders)
+	if errDecode != nil {
+		return errDecode
 	}
-	return m.encode(&folders)
+	errEncode := m.encode(&folders)
+	if errEncode != nil {
+		return errEncode
+	}
+	return m.journal().Reset()
 }
 
+// encode Replaces the file atomically, so that a crash never leaves it partially written
 func (m FileRepository) encode(data *[]Folder) error {
-	fileWriter, errOpenFile := os.OpenFile(m.Filename, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0644)
-	if errOpenFile != nil {
-		return errOpenFile
-	}
-	defer fileWriter.Close()
+	return safefile.WriteAtomic(m.Filename, safefile.Sync_Always, func(fileWriter io.Writer) error {
+		return m.encodeTo(fileWriter, data)
+	})
+}
 
+func (m FileRepository) encodeTo(fileWriter io.Writer, data *[]Folder) error {
 	switch m.EncodingType {
 	case extra.Encoding_JSON:
 		{
@@ -268,11 +361,23 @@ func (m FileRepository) encode(data *[]Folder) error {
 		}
 	case extra.Encoding_CSV:
 		{
-			return gocsv.UnmarshalFile(fileWriter, data)
+			return gocsv.Marshal(data, fileWriter)
 		}
 	case extra.Encoding_Binary:
 		{
-			return apperror.ErrNotImplemented
+			return encodeBinary(fileWriter, *data)
+		}
+	case extra.Encoding_Archive:
+		{
+			return encodeArchive(fileWriter, *data)
+		}
+	case extra.Encoding_YAML:
+		{
+			return encodeYAML(fileWriter, *data)
+		}
+	case extra.Encoding_TOML:
+		{
+			return encodeTOML(fileWriter, *data)
 		}
 	case extra.Encoding_GOB:
 		{
@@ -293,7 +398,25 @@ func (m FileRepository) encode(data *[]Folder) error {
 	return apperror.ErrNotImplemented
 }
 
+// decode Reads the file along with changes journaled since it was written. Must only be called while holding lock of
+// the file
 func (m FileRepository) decode(data *[]Folder) error {
+	errDecodeFile := m.decodeFile(data)
+	if errDecodeFile != nil {
+		return errDecodeFile
+	}
+
+	entries, errRead := m.journal().Read()
+	if errRead != nil {
+		return errRead
+	}
+	*data = journal.Replay(*data, entries, func(folder Folder) uint {
+		return folder.ID
+	})
+	return nil
+}
+
+func (m FileRepository) decodeFile(data *[]Folder) error {
 	_, errFileExists := os.Stat(m.Filename)
 	if errors.Is(errFileExists, os.ErrNotExist) {
 		return nil
@@ -313,26 +436,52 @@ func (m FileRepository) decode(data *[]Folder) error {
 		}
 	case extra.Encoding_CSV:
 		{
-			return gocsv.MarshalFile(data, fileReader)
+			return gocsv.Unmarshal(fileReader, data)
+		}
+	case extra.Encoding_Binary:
+		{
+			decoded, errDecode := decodeBinary(fileReader)
+			*data = decoded
+			return errDecode
+		}
+	case extra.Encoding_Archive:
+		{
+			decoded, errDecode := decodeArchive(fileReader)
+			*data = decoded
+			return errDecode
+		}
+	case extra.Encoding_YAML:
+		{
+			decoded, errDecode := decodeYAML(fileReader)
+			*data = decoded
+			return errDecode
+		}
+	case extra.Encoding_TOML:
+		{
+			decoded, errDecode := decodeTOML(fileReader)
+			*data = decoded
+			return errDecode
 		}
 	case extra.Encoding_GOB:
 		{
 			decoder := gob.NewDecoder(fileReader)
 			return decoder.Decode(data)
 		}
-	case extra.Encoding_Binary:
-		{
-			return apperror.ErrNotImplemented
-		}
 	case extra.Encoding_XML:
 		{
-			buf := new(bytes.Buffer)
-			_, errRead := buf.ReadFrom(fileReader)
-			if errRead != nil {
-				return errRead
+			// Records are written as sibling elements, each of which is decoded separately
+			decoder := xml.NewDecoder(fileReader)
+			for {
+				var folder Folder
+				errDecode := decoder.Decode(&folder)
+				if errors.Is(errDecode, io.EOF) {
+					return nil
+				}
+				if errDecode != nil {
+					return errDecode
+				}
+				*data = append(*data, folder)
 			}
-
-			return xml.Unmarshal(buf.Bytes(), data)
 		}
 	}
 
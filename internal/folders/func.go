@@ -1,9 +1,21 @@
 package folders
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
+	"hideout/internal/common/apperror"
 	"hideout/internal/common/model"
+	"hideout/internal/pkg/archive"
+	"hideout/internal/pkg/binfile"
+	"hideout/internal/pkg/extra"
+	"io"
+	"path"
 	"slices"
 	"sort"
 	"strings"
@@ -16,6 +28,12 @@ func (params ListFolderParams) DatabaseFilter(TableName string, Query *gorm.DB)
 	if len(params.UIDs) != 0 {
 		Query = Query.Where(TableName+".uid IN (?)", params.UIDs)
 	}
+	if params.ParentFolderID != 0 {
+		Query = Query.Where(TableName+".parent_id = ?", params.ParentFolderID)
+	}
+	if params.Name != "" {
+		Query = Query.Where(TableName+".name LIKE ?", extra.GlobToLike(params.Name))
+	}
 
 	if params.Pagination.Page != 0 {
 		Query = Query.Offset(int(params.Pagination.Offset()))
@@ -144,3 +162,227 @@ func (ms *multiSorter) Less(i, j int) bool {
 	// the final comparison reports.
 	return ms.less[k](p, q)
 }
+
+// encodeBinary Writes folders in compact binary file layout
+func encodeBinary(writer io.Writer, folders []Folder) error {
+	encoder := binfile.NewEncoder()
+	for _, folder := range folders {
+		folder.Model.EncodeBinary(encoder)
+		encoder.Uint(uint64(folder.ParentID))
+		encoder.String(folder.UID)
+		encoder.String(folder.Name)
+	}
+	return binfile.Write(writer, BinaryKind, len(folders), encoder)
+}
+
+// decodeBinary Reads folders written in compact binary file layout
+func decodeBinary(reader io.Reader) ([]Folder, error) {
+	decoder, count, errRead := binfile.Read(reader, BinaryKind)
+	if errRead != nil {
+		return nil, errRead
+	}
+
+	folders := make([]Folder, 0, decoder.Capacity(count))
+	for i := 0; i < count && decoder.Err() == nil; i++ {
+		folders = append(folders, Folder{
+			Model:    model.DecodeBinary(decoder),
+			ParentID: uint(decoder.Uint()),
+			UID:      decoder.String(),
+			Name:     decoder.String(),
+		})
+	}
+	if errDecode := decoder.Finish(); errDecode != nil {
+		return nil, errDecode
+	}
+	return folders, nil
+}
+
+// encodeArchive Writes folders into compressed tarball as one JSON file per folder
+func encodeArchive(writer io.Writer, folders []Folder) error {
+	files := make([]archive.File, 0, len(folders))
+	for _, folder := range folders {
+		data, errMarshal := json.MarshalIndent(folder, "", " ")
+		if errMarshal != nil {
+			return errMarshal
+		}
+		files = append(files, archive.Bytes(path.Join(ArchiveDirectory, fmt.Sprintf("%d.json", folder.ID)), data))
+	}
+	return archive.Pack(context.Background(), writer, files, ArchiveFormat)
+}
+
+// decodeArchive Reads folders from compressed tarball, ordered by their identifiers. Any other file means that the
+// archive is not a file of folders, only the archive with no files at all is empty (as written for no folders)
+func decodeArchive(reader io.Reader) ([]Folder, error) {
+	files, errExtract := archive.ExtractFormat(context.Background(), reader, ArchiveFormat, archive.DefaultSizeLimit)
+	if errExtract != nil {
+		return nil, errExtract
+	}
+
+	folders := make([]Folder, 0, len(files))
+	for name, data := range files {
+		if path.Dir(name) != ArchiveDirectory || path.Ext(name) != ".json" {
+			return nil, errors.Wrapf(ErrArchiveUnexpectedFile, "File %s", name)
+		}
+		var folder Folder
+		if errUnmarshal := json.Unmarshal(data, &folder); errUnmarshal != nil {
+			return nil, errors.Wrapf(errUnmarshal, "Failed to decode %s", name)
+		}
+		folders = append(folders, folder)
+	}
+	slices.SortFunc(folders, func(a, b Folder) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return folders, nil
+}
+
+// encodeYAML Writes folders in human-editable YAML layout
+func encodeYAML(writer io.Writer, folders []Folder) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if errEncode := encoder.Encode(nestFolders(folders)); errEncode != nil {
+		return errEncode
+	}
+	return encoder.Close()
+}
+
+// decodeYAML Reads folders written in human-editable YAML layout
+func decodeYAML(reader io.Reader) ([]Folder, error) {
+	var file fileFolders
+	// Empty file holds no folders
+	if errDecode := yaml.NewDecoder(reader).Decode(&file); errDecode != nil && !errors.Is(errDecode, io.EOF) {
+		return nil, errDecode
+	}
+	return flattenFolders(file), nil
+}
+
+// encodeTOML Writes folders in human-editable TOML layout
+func encodeTOML(writer io.Writer, folders []Folder) error {
+	return toml.NewEncoder(writer).Encode(nestFolders(folders))
+}
+
+// decodeTOML Reads folders written in human-editable TOML layout
+func decodeTOML(reader io.Reader) ([]Folder, error) {
+	var file fileFolders
+	if _, errDecode := toml.NewDecoder(reader).Decode(&file); errDecode != nil {
+		return nil, errDecode
+	}
+	return flattenFolders(file), nil
+}
+
+// nestFolders Nests folders into their parents, folders which parent is not in the file (or which are in a cycle) are
+// kept on top-level along with identifier of their parent. Folders are ordered by their identifiers so that the file
+// only changes where folders do
+func nestFolders(folders []Folder) fileFolders {
+	folders = slices.Clone(folders)
+	slices.SortStableFunc(folders, func(a, b Folder) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	var present = make(map[uint]bool, len(folders))
+	for _, folder := range folders {
+		present[folder.ID] = true
+	}
+	var children = make(map[uint][]int)
+	for index, folder := range folders {
+		if folder.ParentID != 0 && folder.ParentID != folder.ID && present[folder.ParentID] {
+			children[folder.ParentID] = append(children[folder.ParentID], index)
+		}
+	}
+
+	var visited = make([]bool, len(folders))
+	var nest func(index int) fileFolder
+	nest = func(index int) fileFolder {
+		visited[index] = true
+		folder := folders[index]
+		nested := fileFolder{ID: folder.ID, UID: folder.UID, Name: folder.Name, CreatedAt: folder.CreatedAt,
+			UpdatedAt: folder.UpdatedAt, DeletedAt: model.NullTimeToPtr(folder.DeletedAt)}
+		for _, childIndex := range children[folder.ID] {
+			if !visited[childIndex] {
+				nested.Folders = append(nested.Folders, nest(childIndex))
+			}
+		}
+		return nested
+	}
+
+	var file = fileFolders{Folders: []fileFolder{}}
+	for index, folder := range folders {
+		if folder.ParentID == 0 || folder.ParentID == folder.ID || !present[folder.ParentID] {
+			topLevel := nest(index)
+			topLevel.ParentID = folder.ParentID
+			file.Folders = append(file.Folders, topLevel)
+		}
+	}
+	// Folders in a cycle are not reachable from any top-level folder
+	for index, folder := range folders {
+		if !visited[index] {
+			topLevel := nest(index)
+			topLevel.ParentID = folder.ParentID
+			file.Folders = append(file.Folders, topLevel)
+		}
+	}
+	return file
+}
+
+// flattenFolders Folders nested into their parents, ordered by their identifiers
+func flattenFolders(file fileFolders) []Folder {
+	var folders []Folder
+	var flatten func(nested fileFolder, parentID uint)
+	flatten = func(nested fileFolder, parentID uint) {
+		folders = append(folders, Folder{
+			Model: model.Model{ID: nested.ID, CreatedAt: nested.CreatedAt, UpdatedAt: nested.UpdatedAt,
+				DeletedAt: model.PtrToNullTime(nested.DeletedAt)},
+			ParentID: parentID,
+			UID:      nested.UID,
+			Name:     nested.Name,
+		})
+		for _, child := range nested.Folders {
+			flatten(child, nested.ID)
+		}
+	}
+	for _, topLevel := range file.Folders {
+		flatten(topLevel, topLevel.ParentID)
+	}
+
+	slices.SortStableFunc(folders, func(a, b Folder) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return folders
+}
+
+// Validate Checks folders read from outside (e.g. from a file edited by hand) hold together the way repositories keep
+// them: identifiers are set and unique and names are unique within their parent (deleted folders aside)
+func Validate(folders []Folder) error {
+	var ids = make(map[uint]bool, len(folders))
+	var uids = make(map[string]bool, len(folders))
+	var names = make(map[uint]map[string]bool)
+	for _, folder := range folders {
+		if folder.ID == 0 {
+			return errors.Wrapf(apperror.ErrInvalidParameter, "Folder %q has no ID", folder.Name)
+		}
+		if folder.UID == "" {
+			return errors.Wrapf(apperror.ErrInvalidParameter, "Folder with ID of %d has no UID", folder.ID)
+		}
+		if ids[folder.ID] {
+			return errors.Wrapf(apperror.ErrAlreadyExists, "Folder with ID of %d is duplicated", folder.ID)
+		}
+		if uids[folder.UID] {
+			return errors.Wrapf(apperror.ErrAlreadyExists, "Folder with UID of %s is duplicated", folder.UID)
+		}
+		ids[folder.ID] = true
+		uids[folder.UID] = true
+
+		if folder.DeletedAt.Valid {
+			continue
+		}
+		if names[folder.ParentID] == nil {
+			names[folder.ParentID] = make(map[string]bool)
+		}
+		if names[folder.ParentID][folder.Name] {
+			return errors.Wrapf(apperror.ErrAlreadyExists, "Folder %q is duplicated in folder with ID of %d", folder.Name,
+				folder.ParentID)
+		}
+		names[folder.ParentID][folder.Name] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package folders
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"hash/crc32"
+	"hideout/internal/common/model"
+	"hideout/internal/pkg/binfile"
+	"maps"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testFolders() []Folder {
+	createdAt := time.Date(2030, 1, 2, 3, 4, 5, 6, time.UTC)
+	return []Folder{
+		{
+			Model: model.Model{ID: 1, CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour)},
+			UID:   "abc-def-ghi",
+			Name:  "zażółć 日本語",
+		},
+		{
+			Model:    model.Model{ID: 2, CreatedAt: createdAt, UpdatedAt: createdAt, DeletedAt: sql.NullTime{Time: createdAt, Valid: true}},
+			ParentID: 1,
+			UID:      "jkl-mno-pqr",
+			Name:     "nested",
+		},
+	}
+}
+
+// resignBinary Sets record count of binary file and recomputes its checksum, so that only the records are damaged
+func resignBinary(data []byte, count uint32) []byte {
+	data = bytes.Clone(data)
+	binary.BigEndian.PutUint32(data[8:], count)
+	checksumOffset := len(data) - binfile.ChecksumSize
+	binary.BigEndian.PutUint32(data[checksumOffset:], crc32.Checksum(data[:checksumOffset], crc32.MakeTable(crc32.Castagnoli)))
+	return data
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, folders := range [][]Folder{nil, testFolders()} {
+		var file bytes.Buffer
+		if errEncode := encodeBinary(&file, folders); errEncode != nil {
+			t.Fatalf("encodeBinary failed: %s", errEncode)
+		}
+		decoded, errDecode := decodeBinary(&file)
+		if errDecode != nil {
+			t.Fatalf("decodeBinary failed: %s", errDecode)
+		}
+		if len(folders) == 0 && len(decoded) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(decoded, folders) {
+			t.Errorf("Expected %+v, got %+v", folders, decoded)
+		}
+	}
+}
+
+func TestBinaryCorrupted(t *testing.T) {
+	var file bytes.Buffer
+	if errEncode := encodeBinary(&file, testFolders()); errEncode != nil {
+		t.Fatalf("encodeBinary failed: %s", errEncode)
+	}
+	data := file.Bytes()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{name: "bad magic", data: append([]byte("XXXX"), data[len(binfile.Magic):]...), expected: binfile.ErrInvalidMagic},
+		{name: "kind mismatch", data: append(append(bytes.Clone(data[:6]), BinaryKind+1), data[7:]...), expected: binfile.ErrKindMismatch},
+		{name: "bad checksum", data: append(bytes.Clone(data[:len(data)-1]), data[len(data)-1]^0xff), expected: binfile.ErrChecksumMismatch},
+		{name: "truncated header", data: data[:binfile.HeaderSize], expected: binfile.ErrTruncated},
+		{name: "truncated", data: data[:len(data)-binfile.ChecksumSize-1], expected: binfile.ErrChecksumMismatch},
+		{name: "trailing data", data: append(bytes.Clone(data), 0), expected: binfile.ErrChecksumMismatch},
+		{name: "missing records", data: resignBinary(data, uint32(len(testFolders())+1)), expected: binfile.ErrTruncated},
+		{name: "trailing records", data: resignBinary(data, uint32(len(testFolders())-1)), expected: binfile.ErrTrailingData},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoded, errDecode := decodeBinary(bytes.NewReader(test.data))
+			if !errors.Is(errDecode, test.expected) {
+				t.Errorf("Expected %s, got %v", test.expected, errDecode)
+			}
+			if decoded != nil {
+				t.Errorf("Expected no folders, got %+v", decoded)
+			}
+		})
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	for _, folders := range [][]Folder{nil, testFolders()} {
+		var file bytes.Buffer
+		if errEncode := encodeArchive(&file, folders); errEncode != nil {
+			t.Fatalf("encodeArchive failed: %s", errEncode)
+		}
+		decoded, errDecode := decodeArchive(&file)
+		if errDecode != nil {
+			t.Fatalf("decodeArchive failed: %s", errDecode)
+		}
+		if len(folders) == 0 && len(decoded) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(decoded, folders) {
+			t.Errorf("Expected %+v, got %+v", folders, decoded)
+		}
+	}
+}
+
+// tarball Compressed tarball of given files, so that archives not written by encodeArchive can be checked
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var file bytes.Buffer
+	compressor := gzip.NewWriter(&file)
+	writer := tar.NewWriter(compressor)
+	for name, content := range files {
+		if errHeader := writer.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}); errHeader != nil {
+			t.Fatalf("Failed to write header: %s", errHeader)
+		}
+		if _, errWrite := writer.Write([]byte(content)); errWrite != nil {
+			t.Fatalf("Failed to write file: %s", errWrite)
+		}
+	}
+	if errClose := writer.Close(); errClose != nil {
+		t.Fatalf("Failed to close tarball: %s", errClose)
+	}
+	if errClose := compressor.Close(); errClose != nil {
+		t.Fatalf("Failed to close compressor: %s", errClose)
+	}
+	return file.Bytes()
+}
+
+func TestArchiveCorrupted(t *testing.T) {
+	var file bytes.Buffer
+	if errEncode := encodeArchive(&file, testFolders()); errEncode != nil {
+		t.Fatalf("encodeArchive failed: %s", errEncode)
+	}
+	data := file.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "bad magic", data: append([]byte("XXXX"), data[4:]...)},
+		{name: "truncated", data: data[:len(data)/2]},
+		{name: "bad checksum", data: append(bytes.Clone(data[:len(data)-8]), append([]byte{data[len(data)-8] ^ 0xff}, data[len(data)-7:]...)...)},
+		{name: "invalid record", data: tarball(t, map[string]string{ArchiveDirectory + "/1.json": "{"})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoded, errDecode := decodeArchive(bytes.NewReader(test.data))
+			if errDecode == nil {
+				t.Errorf("Expected error, got %+v", decoded)
+			}
+		})
+	}
+}
+
+func TestArchiveForeignFiles(t *testing.T) {
+	records := map[string]string{
+		ArchiveDirectory + "/2.json": `{"ID":2,"Name":"SECOND"}`,
+		ArchiveDirectory + "/1.json": `{"ID":1,"Name":"FIRST"}`,
+	}
+	tests := []struct {
+		name    string
+		file    string
+		records bool
+	}{
+		{name: "other extension", file: ArchiveDirectory + "/1.txt", records: true},
+		{name: "other directory", file: "other/3.json", records: true},
+		{name: "top level", file: "README", records: true},
+		{name: "no records", file: "README", records: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files := map[string]string{test.file: "{}"}
+			if test.records {
+				maps.Copy(files, records)
+			}
+			decoded, errDecode := decodeArchive(bytes.NewReader(tarball(t, files)))
+			if !errors.Is(errDecode, ErrArchiveUnexpectedFile) {
+				t.Errorf("Expected %s, got %v", ErrArchiveUnexpectedFile, errDecode)
+			}
+			if decoded != nil {
+				t.Errorf("Expected no folders, got %+v", decoded)
+			}
+		})
+	}
+}
@@ -15,11 +15,43 @@ import (
 )
 
 type InMemoryRepository struct {
-	conn *[]Folder
+	conn    *[]Folder
+	preload *model.Preload
 }
 
 func NewInMemoryRepository(conn *[]Folder) *InMemoryRepository {
-	return &InMemoryRepository{conn: conn}
+	return &InMemoryRepository{conn: conn, preload: &model.Preload{}}
+}
+
+// NewPreloadedInMemoryRepository Repository of preloaded folders, guarded by the preload lock shared with others
+func NewPreloadedInMemoryRepository(conn *[]Folder, preload *model.Preload) *InMemoryRepository {
+	return &InMemoryRepository{conn: conn, preload: preload}
+}
+
+// Generation Number of local writes made to preloaded folders so far
+func (m InMemoryRepository) Generation() uint64 {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	return m.preload.Generation
+}
+
+// Replace Replaces preloaded folders with reloaded ones, unless a local write was made since their generation
+func (m InMemoryRepository) Replace(folders []Folder, generation uint64) bool {
+	m.preload.Lock()
+	defer m.preload.Unlock()
+	if m.preload.Generation != generation {
+		return false
+	}
+	*m.conn = folders
+	return true
+}
+
+// Swap Replaces preloaded folders with the ones written locally, which counts as a local write
+func (m InMemoryRepository) Swap(folders []Folder) {
+	m.preload.Lock()
+	defer m.preload.Unlock()
+	m.preload.Generation++
+	*m.conn = folders
 }
 
 func (m InMemoryRepository) Load(ctx context.Context) ([]Folder, error) {
@@ -27,6 +59,8 @@ func (m InMemoryRepository) Load(ctx context.Context) ([]Folder, error) {
 }
 
 func (m InMemoryRepository) GetID(ctx context.Context) (uint, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
 	id := uint(0)
 	for _, folderEntry := range *m.conn {
 		if folderEntry.ID > id {
@@ -38,7 +72,9 @@ func (m InMemoryRepository) GetID(ctx context.Context) (uint, error) {
 }
 
 func (m InMemoryRepository) GetMapByID(ctx context.Context, params ListFolderParams) (map[uint]*Folder, error) {
-	folders, errGetFolders := m.Get(ctx, params)
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	folders, errGetFolders := m.get(ctx, params)
 	if errGetFolders != nil {
 		return nil, errGetFolders
 	}
@@ -51,7 +87,9 @@ func (m InMemoryRepository) GetMapByID(ctx context.Context, params ListFolderPar
 }
 
 func (m InMemoryRepository) GetMapByUID(ctx context.Context, params ListFolderParams) (map[string]*Folder, error) {
-	folders, errGetFolders := m.Get(ctx, params)
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	folders, errGetFolders := m.get(ctx, params)
 	if errGetFolders != nil {
 		return nil, errGetFolders
 	}
@@ -64,6 +102,13 @@ func (m InMemoryRepository) GetMapByUID(ctx context.Context, params ListFolderPa
 }
 
 func (m InMemoryRepository) Get(ctx context.Context, params ListFolderParams) ([]*Folder, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	return m.get(ctx, params)
+}
+
+// get Filtered folders, must only be called while holding the preload lock
+func (m InMemoryRepository) get(ctx context.Context, params ListFolderParams) ([]*Folder, error) {
 	var parentFolderResults []*Folder
 	for _, folderEntry := range *m.conn {
 		if params.ParentFolderID > 0 {
@@ -100,6 +145,8 @@ func (m InMemoryRepository) Get(ctx context.Context, params ListFolderParams) ([
 }
 
 func (m InMemoryRepository) GetByID(ctx context.Context, id uint) (*Folder, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
 	for _, folderEntry := range *m.conn {
 		if folderEntry.ID == id && !folderEntry.DeletedAt.Valid {
 			return &folderEntry, nil
@@ -110,6 +157,8 @@ func (m InMemoryRepository) GetByID(ctx context.Context, id uint) (*Folder, erro
 }
 
 func (m InMemoryRepository) GetByUID(ctx context.Context, uid string) (*Folder, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
 	for _, folderEntry := range *m.conn {
 		if folderEntry.UID == uid && !folderEntry.DeletedAt.Valid {
 			return &folderEntry, nil
@@ -120,12 +169,18 @@ func (m InMemoryRepository) GetByUID(ctx context.Context, uid string) (*Folder,
 }
 
 func (m InMemoryRepository) Update(ctx context.Context, folder Folder) (*Folder, error) {
-	for _, folderEntry := range *m.conn {
+	m.preload.Lock()
+	defer m.preload.Unlock()
+	for folderIndex, folderEntry := range *m.conn {
 		if folderEntry.ID == folder.ID && !folderEntry.DeletedAt.Valid {
+			m.preload.Generation++
+			// Entry is modified in the slice itself, not in a copy made by range
+			folderEntry := &(*m.conn)[folderIndex]
 			folderEntry.ParentID = folder.ParentID
 			folderEntry.Name = folder.Name
 			folderEntry.UpdatedAt = time.Now()
-			return &folderEntry, nil
+			updatedFolder := *folderEntry
+			return &updatedFolder, nil
 		}
 	}
 
@@ -133,6 +188,8 @@ func (m InMemoryRepository) Update(ctx context.Context, folder Folder) (*Folder,
 }
 
 func (m InMemoryRepository) Create(ctx context.Context, folder Folder) (*Folder, error) {
+	m.preload.Lock()
+	defer m.preload.Unlock()
 	for _, folderEntry := range *m.conn {
 		if !folderEntry.DeletedAt.Valid && folderEntry.Name == folder.Name && folderEntry.ParentID == folder.ParentID {
 			return nil, apperror.ErrAlreadyExists
@@ -144,16 +201,19 @@ func (m InMemoryRepository) Create(ctx context.Context, folder Folder) (*Folder,
 	if folder.UID == "" {
 		folder.UID = gofakeit.UUID()
 	}
+	m.preload.Generation++
 	*m.conn = append(*m.conn, folder)
 	return &folder, nil
 }
 
 func (m InMemoryRepository) Count(ctx context.Context, params ListFolderParams) (uint, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
 	// These are not needed when performing filtering and counting
 	params.Pagination = pagination.Pagination{PerPage: 0, Page: 0}
 	params.Order = []ordering.Order{}
 
-	foldersList, errGetFolders := m.Get(ctx, params)
+	foldersList, errGetFolders := m.get(ctx, params)
 	if errGetFolders != nil {
 		return 0, errGetFolders
 	}
@@ -162,8 +222,11 @@ func (m InMemoryRepository) Count(ctx context.Context, params ListFolderParams)
 }
 
 func (m InMemoryRepository) Delete(ctx context.Context, id uint, forceDelete bool) error {
+	m.preload.Lock()
+	defer m.preload.Unlock()
 	for folderIndex, folderEntry := range *m.conn {
 		if folderEntry.ID == id {
+			m.preload.Generation++
 			if forceDelete {
 				*m.conn = slices.Delete(*m.conn, folderIndex, folderIndex+1)
 			} else {
@@ -4,14 +4,15 @@ import (
 	"context"
 	"hideout/internal/common/generics"
 	"hideout/internal/common/model"
+	"time"
 )
 
 type (
 	Folder struct {
 		model.Model
-		ParentID uint   `struc:"uint64" json:"ParentID" bson:"ParentID" csv:"ParentID" xml:"ParentID" yaml:"ParentID" db:"parent_id" gorm:"column:parent_id" description:"Parent value identifier (link)" example:"0"`
-		UID      string `struc:"[]byte" json:"UID" bson:"UID" csv:"UID" xml:"UID" yaml:"UID" db:"uid" gorm:"column:uid;unique" description:"Secondary unique identifier" example:"abc-def-ghi"`
-		Name     string `struc:"[]byte" json:"Name" bson:"Name" csv:"Name" xml:"Name" yaml:"Name" db:"name" gorm:"column:name" description:"Folder folder name" example:"/"`
+		ParentID uint   `json:"ParentID" bson:"ParentID" csv:"ParentID" xml:"ParentID" yaml:"ParentID" db:"parent_id" gorm:"column:parent_id" description:"Parent value identifier (link)" example:"0"`
+		UID      string `json:"UID" bson:"UID" csv:"UID" xml:"UID" yaml:"UID" db:"uid" gorm:"column:uid;unique" description:"Secondary unique identifier" example:"abc-def-ghi"`
+		Name     string `json:"Name" bson:"Name" csv:"Name" xml:"Name" yaml:"Name" db:"name" gorm:"column:name" description:"Folder folder name" example:"/"`
 	}
 
 	Repository interface {
@@ -40,8 +41,20 @@ type (
 		less    []lessFunc
 	}
 
-	FoldersList struct {
-		Length uint8 `struc:"sizeof=Data"`
-		Data   []Folder
+	// fileFolders Human-editable layout of YAML and TOML files of folders, folders are nested into their parents
+	fileFolders struct {
+		Folders []fileFolder `yaml:"Folders" toml:"Folders"`
+	}
+
+	// fileFolder Folder nested into its parent, identifier of parent is only kept by top-level folders
+	fileFolder struct {
+		ID        uint         `yaml:"ID" toml:"ID"`
+		ParentID  uint         `yaml:"ParentID,omitempty" toml:"ParentID,omitzero"`
+		UID       string       `yaml:"UID" toml:"UID"`
+		Name      string       `yaml:"Name" toml:"Name"`
+		CreatedAt time.Time    `yaml:"CreatedAt" toml:"CreatedAt"`
+		UpdatedAt time.Time    `yaml:"UpdatedAt" toml:"UpdatedAt"`
+		DeletedAt *time.Time   `yaml:"DeletedAt,omitempty" toml:"DeletedAt,omitempty"`
+		Folders   []fileFolder `yaml:"Folders,omitempty" toml:"Folders,omitempty"`
 	}
 )
@@ -1,6 +1,33 @@
 package folders
 
+import (
+	"github.com/mholt/archives"
+	"github.com/pkg/errors"
+	"hideout/internal/pkg/embedded"
+)
+
 var (
 	OrderMap = map[string]string{"ID": "id", "ParentID": "parent_id", "UID": "uid", "Name": "name", "CreatedAt": "created_at",
 		"UpdatedAt": "updated_at", "DeletedAt": "deleted_at"}
 )
+
+var (
+	// ArchiveFormat Format of archive file of folders
+	ArchiveFormat = archives.CompressedArchive{Archival: archives.Tar{}, Extraction: archives.Tar{}, Compression: archives.Gz{}}
+
+	// ErrArchiveUnexpectedFile File of archive file of folders which is not one of them, the archive was not written as one
+	ErrArchiveUnexpectedFile = errors.New("Unexpected file in archive of folders")
+)
+
+var (
+	// EmbeddedTable Folders in embedded key-value store, indexed by UID, parent folder and name
+	EmbeddedTable = embedded.Table[Folder]{
+		Bucket: TableName,
+		ID:     func(folder Folder) uint { return folder.ID },
+		Indexes: []embedded.Index[Folder]{
+			{Bucket: EmbeddedIndexUID, Unique: true, Value: func(folder Folder) []byte { return []byte(folder.UID) }},
+			{Bucket: EmbeddedIndexParent, Value: func(folder Folder) []byte { return embedded.Key(folder.ParentID) }},
+			{Bucket: EmbeddedIndexName, Value: func(folder Folder) []byte { return []byte(folder.Name) }},
+		},
+	}
+)
@@ -0,0 +1,6 @@
+package leases
+
+const (
+	// Separator Separates parts of lease identifiers
+	Separator = "/"
+)
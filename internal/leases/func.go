@@ -0,0 +1,29 @@
+package leases
+
+import (
+	"strings"
+	"time"
+)
+
+// IsExpired Whether lease has expired at the moment
+func (l Lease) IsExpired(now time.Time) bool {
+	return !l.ExpiresAt.After(now)
+}
+
+// TTL Time left until the lease expires
+func (l Lease) TTL(now time.Time) time.Duration {
+	if l.IsExpired(now) {
+		return 0
+	}
+	return l.ExpiresAt.Sub(now).Truncate(time.Second)
+}
+
+// CanRenew Whether lease can still be extended
+func (l Lease) CanRenew(now time.Time) bool {
+	return l.Renewable && !l.IsExpired(now) && l.ExpiresAt.Before(l.MaxExpiresAt)
+}
+
+// Nonce Last part of the lease identifier, unique across all leases
+func Nonce(leaseID string) string {
+	return leaseID[strings.LastIndex(leaseID, Separator)+1:]
+}
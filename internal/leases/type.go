@@ -0,0 +1,35 @@
+package leases
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Lease Short-lived secret handed out by an engine, revoked by the engine once it expires
+	Lease struct {
+		// LeaseID has the form of <engine>/<folder UID>/<path>/<nonce>
+		LeaseID      string    `json:"LeaseID"`
+		Engine       string    `json:"Engine"`
+		FolderUID    string    `json:"FolderUID"`
+		Path         string    `json:"Path"`
+		IssuedAt     time.Time `json:"IssuedAt"`
+		ExpiresAt    time.Time `json:"ExpiresAt"`
+		MaxExpiresAt time.Time `json:"MaxExpiresAt"`
+		// Increment Lease is extended by it on renewal unless other increment is requested
+		Increment time.Duration `json:"Increment"`
+		Renewable bool          `json:"Renewable"`
+		// Data Engine-specific details needed to revoke the lease (e.g. database user name)
+		Data map[string]string `json:"Data,omitempty"`
+	}
+
+	// Revoker Engine callback invoked when its lease is revoked or expires, lease is kept if it fails
+	Revoker interface {
+		Revoke(ctx context.Context, lease Lease) error
+	}
+
+	// Renewer Optional engine callback invoked with the extended lease before it is saved, renewal is rejected if it fails
+	Renewer interface {
+		Renew(ctx context.Context, lease Lease) error
+	}
+)
@@ -0,0 +1,6 @@
+package notifications
+
+const (
+	Type_Expiring = "expiring"
+	Type_Expired  = "expired"
+)
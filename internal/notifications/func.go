@@ -0,0 +1,21 @@
+package notifications
+
+import (
+	"fmt"
+	"time"
+)
+
+// Subject Short human-readable summary of the notification
+func (n Notification) Subject() string {
+	if n.Type == Type_Expired {
+		return fmt.Sprintf("Secret %s has expired", n.SecretName)
+	}
+
+	return fmt.Sprintf("Secret %s expires at %s", n.SecretName, n.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+// Body Human-readable description of the notification
+func (n Notification) Body() string {
+	return fmt.Sprintf("%s\n\nSecret: %s (UID %s)\nFolder: %s (UID %s)\nExpires at: %s\n", n.Subject(),
+		n.SecretName, n.SecretUID, n.FolderName, n.FolderUID, n.ExpiresAt.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,24 @@
+package notifications
+
+import (
+	"context"
+	"log"
+)
+
+// LogSink writes notifications into the application log, useful as a local stand-in for real sinks
+type LogSink struct {
+}
+
+func NewLogSink() LogSink {
+	return LogSink{}
+}
+
+func (m LogSink) Name() string {
+	return "log"
+}
+
+func (m LogSink) Send(ctx context.Context, notification Notification) error {
+	log.Printf("[NOTIFICATION] %s (secret UID %s, folder UID %s)", notification.Subject(), notification.SecretUID,
+		notification.FolderUID)
+	return nil
+}
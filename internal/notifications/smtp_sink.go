@@ -0,0 +1,60 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSink e-mails notifications to the configured recipients
+type SMTPSink struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func NewSMTPSink(host string, port int, username string, password string, from string, to []string) SMTPSink {
+	return SMTPSink{Host: host, Port: port, Username: username, Password: password, From: from, To: to}
+}
+
+func (m SMTPSink) Name() string {
+	return "smtp"
+}
+
+func (m SMTPSink) Send(ctx context.Context, notification Notification) error {
+	if len(m.To) == 0 {
+		return errors.New("No recipients configured for SMTP notifications")
+	}
+
+	var auth smtp.Auth = nil
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	message := strings.Join([]string{
+		fmt.Sprintf("From: %s", m.From),
+		fmt.Sprintf("To: %s", strings.Join(m.To, ", ")),
+		fmt.Sprintf("Subject: %s", notification.Subject()),
+		fmt.Sprintf("Date: %s", time.Now().Format(time.RFC1123Z)),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		strings.ReplaceAll(notification.Body(), "\n", "\r\n"),
+	}, "\r\n")
+
+	address := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	errSend := smtp.SendMail(address, auth, m.From, m.To, []byte(message))
+	if errSend != nil {
+		return errors.Wrapf(errSend, "Error sending e-mail notification through %s", address)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package notifications
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Notification is a single message about a secret, which is delivered through every configured sink
+	Notification struct {
+		Type       string    `json:"Type" example:"expiring"`
+		SecretID   uint      `json:"SecretID" example:"1"`
+		SecretUID  string    `json:"SecretUID" example:"abc-def-ghi"`
+		SecretName string    `json:"SecretName" example:"API_KEY"`
+		FolderUID  string    `json:"FolderUID" example:"abc-def-ghi"`
+		FolderName string    `json:"FolderName" example:"production"`
+		ExpiresAt  time.Time `json:"ExpiresAt" example:"2030-01-01T00:00:00Z"`
+		CreatedAt  time.Time `json:"CreatedAt" example:"2029-12-01T00:00:00Z"`
+	}
+
+	Sink interface {
+		Name() string
+		Send(ctx context.Context, notification Notification) error
+	}
+)
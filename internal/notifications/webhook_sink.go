@@ -0,0 +1,50 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+// WebhookSink posts notifications as JSON to the configured URL
+type WebhookSink struct {
+	URL    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) WebhookSink {
+	return WebhookSink{URL: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (m WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (m WebhookSink) Send(ctx context.Context, notification Notification) error {
+	payload, errMarshal := json.Marshal(notification)
+	if errMarshal != nil {
+		return errors.Wrapf(errMarshal, "Error serializing notification for secret with UID of %s", notification.SecretUID)
+	}
+
+	request, errCreateRequest := http.NewRequestWithContext(ctx, http.MethodPost, m.URL, bytes.NewReader(payload))
+	if errCreateRequest != nil {
+		return errors.Wrapf(errCreateRequest, "Error creating webhook request to %s", m.URL)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, errSend := m.client.Do(request)
+	if errSend != nil {
+		return errors.Wrapf(errSend, "Error sending webhook request to %s", m.URL)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("Webhook %s responded with status code %d", m.URL, response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,6 @@
+package archive
+
+const (
+	// DefaultSizeLimit Maximum size of the archive (once decompressed) read into memory by default
+	DefaultSizeLimit = 256 << 20
+)
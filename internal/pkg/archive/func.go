@@ -0,0 +1,177 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"github.com/mholt/archives"
+	"github.com/pkg/errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// Pack Streams files packed into a (compressed) archive into the writer as they are written, files are neither kept in
+// memory nor written to disk
+func Pack(ctx context.Context, writer io.Writer, files []File, format archives.CompressedArchive) error {
+	var modTime = time.Now()
+	archiveFiles := make([]archives.FileInfo, 0, len(files))
+	for _, file := range files {
+		info := fileInfo{name: path.Base(file.Name), size: file.Size, modTime: modTime}
+		write := file.Write
+		archiveFiles = append(archiveFiles, archives.FileInfo{FileInfo: info, NameInArchive: file.Name,
+			Open: func() (fs.File, error) {
+				pipeReader, pipeWriter := io.Pipe()
+				go func() {
+					pipeWriter.CloseWithError(write(pipeWriter))
+				}()
+				return fileReader{PipeReader: pipeReader, fileInfo: info}, nil
+			},
+		})
+	}
+
+	return format.Archive(ctx, writer, archiveFiles)
+}
+
+// Bytes File of data already kept in memory
+func Bytes(name string, data []byte) File {
+	return File{Name: name, Size: int64(len(data)), Write: func(writer io.Writer) error {
+		_, errWrite := writer.Write(data)
+		return errWrite
+	}}
+}
+
+// Extract Reads regular files of the archive (of any supported archive and compression type) into memory, keyed by
+// their cleaned paths in archive, size limit applies to the archive both before and after decompression. A compressed
+// file which is not an archive is read as a single file named after the given file name without compression extension
+func Extract(ctx context.Context, fileName string, reader io.Reader, sizeLimit int64) (map[string][]byte, error) {
+	archiveData, errRead := ReadLimited(reader, sizeLimit)
+	if errRead != nil {
+		return nil, errRead
+	}
+	format, _, errIdentify := archives.Identify(ctx, "", bytes.NewReader(archiveData))
+	if errIdentify != nil {
+		return nil, errors.Wrap(ErrNotArchive, errIdentify.Error())
+	}
+
+	var extraction archives.Extraction
+	switch archiveFormat := format.(type) {
+	case archives.CompressedArchive:
+		return extractArchive(ctx, archiveData, archiveFormat, sizeLimit)
+	case archives.Extraction:
+		extraction = archiveFormat
+	case archives.Compression:
+		return decompressFile(fileName, archiveData, archiveFormat, sizeLimit)
+	}
+	if extraction == nil {
+		return nil, ErrNotArchive
+	}
+
+	return extractFiles(ctx, archiveData, extraction, sizeLimit)
+}
+
+// ExtractFormat Reads regular files of the archive of known format the same way as Extract does, so that archive with
+// no files at all (which cannot be identified) is read as empty one
+func ExtractFormat(ctx context.Context, reader io.Reader, format archives.CompressedArchive, sizeLimit int64) (map[string][]byte, error) {
+	archiveData, errRead := ReadLimited(reader, sizeLimit)
+	if errRead != nil {
+		return nil, errRead
+	}
+	return extractArchive(ctx, archiveData, format, sizeLimit)
+}
+
+// extractArchive Regular files of the (compressed) archive, which is decompressed as a whole first
+func extractArchive(ctx context.Context, archiveData []byte, format archives.CompressedArchive, sizeLimit int64) (map[string][]byte, error) {
+	if format.Compression != nil {
+		// Zip is only readable with random access, and checksum of compressed data is only verified once it is read to
+		// the end, so archive is decompressed in memory first
+		decompressor, errOpen := format.Compression.OpenReader(bytes.NewReader(archiveData))
+		if errOpen != nil {
+			return nil, errors.Wrap(errOpen, "Failed to decompress archive")
+		}
+		var errRead error
+		archiveData, errRead = ReadLimited(decompressor, sizeLimit)
+		_ = decompressor.Close()
+		if errRead != nil {
+			return nil, errRead
+		}
+	}
+	if format.Extraction == nil {
+		return nil, ErrNotArchive
+	}
+	return extractFiles(ctx, archiveData, format.Extraction, sizeLimit)
+}
+
+// extractFiles Regular files of the archive keyed by their cleaned paths, up to the size limit in total
+func extractFiles(ctx context.Context, archiveData []byte, extraction archives.Extraction, sizeLimit int64) (map[string][]byte, error) {
+	var files = make(map[string][]byte)
+	var extractedSize int64
+	errExtract := extraction.Extract(ctx, bytes.NewReader(archiveData), func(ctx context.Context, file archives.FileInfo) error {
+		if !file.Mode().IsRegular() {
+			return nil
+		}
+		fileReader, errOpen := file.Open()
+		if errOpen != nil {
+			return errOpen
+		}
+		defer fileReader.Close()
+		// Entries may be compressed individually (zip, 7z), so the limit is enforced on the total of extracted entries
+		data, errReadFile := ReadLimited(fileReader, sizeLimit-extractedSize)
+		if errReadFile != nil {
+			return errReadFile
+		}
+		extractedSize += int64(len(data))
+		// Archives repacked by other tools often prefix paths with ./
+		files[strings.TrimPrefix(path.Clean("/"+file.NameInArchive), "/")] = data
+		return nil
+	})
+	if errExtract != nil {
+		return nil, errors.Wrap(errExtract, "Failed to extract archive")
+	}
+
+	return files, nil
+}
+
+// decompressFile Single compressed file, which can only be named if the name of the compressed file is known
+func decompressFile(fileName string, data []byte, compression archives.Compression, sizeLimit int64) (map[string][]byte, error) {
+	name := path.Base(fileName)
+	if extensioner, hasExtension := compression.(archives.Format); hasExtension {
+		name = strings.TrimSuffix(name, extensioner.Extension())
+	}
+	if fileName == "" || name == "" || name == "." || name == "/" {
+		return nil, ErrNotArchive
+	}
+
+	decompressor, errOpen := compression.OpenReader(bytes.NewReader(data))
+	if errOpen != nil {
+		return nil, errors.Wrap(errOpen, "Failed to decompress file")
+	}
+	defer decompressor.Close()
+	fileData, errRead := ReadLimited(decompressor, sizeLimit)
+	if errRead != nil {
+		return nil, errRead
+	}
+	return map[string][]byte{name: fileData}, nil
+}
+
+// ReadLimited Reads everything from the reader unless it exceeds the size limit
+func ReadLimited(reader io.Reader, sizeLimit int64) ([]byte, error) {
+	data, errRead := io.ReadAll(io.LimitReader(reader, sizeLimit+1))
+	if errRead != nil {
+		return nil, errors.Wrap(errRead, "Failed to read archive")
+	}
+	if int64(len(data)) > sizeLimit {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) Mode() fs.FileMode  { return 0600 }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return false }
+func (i fileInfo) Sys() any           { return nil }
+
+func (r fileReader) Stat() (fs.FileInfo, error) { return r.fileInfo, nil }
@@ -0,0 +1,28 @@
+package archive
+
+import (
+	"io"
+	"time"
+)
+
+type (
+	// File File written into archive as it is packed, along with its path in archive
+	File struct {
+		Name  string                       // Path of the file in archive
+		Size  int64                        // Exact amount of bytes written, tar headers go before contents
+		Write func(writer io.Writer) error // Writes contents of the file, called once while packing
+	}
+
+	// fileInfo Description of file packed into archive
+	fileInfo struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+
+	// fileReader File opened for archiving, reading contents as they are written
+	fileReader struct {
+		*io.PipeReader
+		fileInfo fileInfo
+	}
+)
@@ -0,0 +1,8 @@
+package archive
+
+import "github.com/pkg/errors"
+
+var (
+	ErrNotArchive = errors.New("Not a supported archive")
+	ErrTooLarge   = errors.New("Archive exceeds the size limit")
+)
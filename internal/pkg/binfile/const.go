@@ -0,0 +1,15 @@
+package binfile
+
+const (
+	// Magic Signature every binary file starts with
+	Magic = "HDRF"
+
+	// Version Version of the layout written, files of other versions are rejected
+	Version = 1
+
+	// HeaderSize Length of the header: magic (4), version (2), record kind (1), reserved (1) and record count (4)
+	HeaderSize = 12
+
+	// ChecksumSize Length of CRC-32C checksum of header and records which ends the file
+	ChecksumSize = 4
+)
@@ -0,0 +1,189 @@
+package binfile
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// Write Writes header, encoded records and checksum into the writer
+func Write(writer io.Writer, kind uint8, count int, encoder *Encoder) error {
+	data := make([]byte, HeaderSize, HeaderSize+len(encoder.payload)+ChecksumSize)
+	copy(data, Magic)
+	binary.BigEndian.PutUint16(data[4:], Version)
+	data[6] = kind
+	binary.BigEndian.PutUint32(data[8:], uint32(count))
+	data = append(data, encoder.payload...)
+	data = binary.BigEndian.AppendUint32(data, crc32.Checksum(data, crcTable))
+
+	_, errWrite := writer.Write(data)
+	return errWrite
+}
+
+// Read Reads the whole file, checks its header and checksum and returns decoder of its records along with their count
+func Read(reader io.Reader, kind uint8) (*Decoder, int, error) {
+	data, errRead := io.ReadAll(reader)
+	if errRead != nil {
+		return nil, 0, errors.Wrap(errRead, "Failed to read binary file")
+	}
+	if len(data) < len(Magic) || string(data[:len(Magic)]) != Magic {
+		return nil, 0, ErrInvalidMagic
+	}
+	if len(data) < HeaderSize+ChecksumSize {
+		return nil, 0, ErrTruncated
+	}
+	if version := binary.BigEndian.Uint16(data[4:]); version != Version {
+		return nil, 0, errors.Wrapf(ErrUnsupportedVersion, "Version %d", version)
+	}
+	if data[6] != kind {
+		return nil, 0, errors.Wrapf(ErrKindMismatch, "Kind %d instead of %d", data[6], kind)
+	}
+
+	checksumOffset := len(data) - ChecksumSize
+	if crc32.Checksum(data[:checksumOffset], crcTable) != binary.BigEndian.Uint32(data[checksumOffset:]) {
+		return nil, 0, ErrChecksumMismatch
+	}
+
+	count := int(binary.BigEndian.Uint32(data[8:]))
+	return &Decoder{payload: data[HeaderSize:checksumOffset]}, count, nil
+}
+
+// NewEncoder Encoder with no records yet
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+func (e *Encoder) Uint(value uint64) {
+	e.payload = binary.AppendUvarint(e.payload, value)
+}
+
+func (e *Encoder) Int(value int64) {
+	e.payload = binary.AppendVarint(e.payload, value)
+}
+
+func (e *Encoder) Bool(value bool) {
+	if value {
+		e.payload = append(e.payload, 1)
+	} else {
+		e.payload = append(e.payload, 0)
+	}
+}
+
+// String Length of the string followed by its bytes
+func (e *Encoder) String(value string) {
+	e.Uint(uint64(len(value)))
+	e.payload = append(e.payload, value...)
+}
+
+// Time Whether time is set followed by seconds and nanoseconds since Unix epoch, location is not kept
+func (e *Encoder) Time(value time.Time) {
+	e.Bool(!value.IsZero())
+	if !value.IsZero() {
+		e.Int(value.Unix())
+		e.Uint(uint64(value.Nanosecond()))
+	}
+}
+
+func (e *Encoder) NullTime(value sql.NullTime) {
+	e.Bool(value.Valid)
+	if value.Valid {
+		e.Time(value.Time)
+	}
+}
+
+// Capacity Amount of records worth preallocating, count of a damaged file must not cause huge allocations
+func (d *Decoder) Capacity(count int) int {
+	return min(count, len(d.payload))
+}
+
+func (d *Decoder) Uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	value, size := binary.Uvarint(d.payload[d.offset:])
+	if size <= 0 {
+		d.err = ErrTruncated
+		return 0
+	}
+	d.offset += size
+	return value
+}
+
+func (d *Decoder) Int() int64 {
+	if d.err != nil {
+		return 0
+	}
+	value, size := binary.Varint(d.payload[d.offset:])
+	if size <= 0 {
+		d.err = ErrTruncated
+		return 0
+	}
+	d.offset += size
+	return value
+}
+
+func (d *Decoder) Bool() bool {
+	if d.err != nil {
+		return false
+	}
+	if d.offset >= len(d.payload) {
+		d.err = ErrTruncated
+		return false
+	}
+	value := d.payload[d.offset] != 0
+	d.offset++
+	return value
+}
+
+func (d *Decoder) String() string {
+	length := d.Uint()
+	if d.err != nil {
+		return ""
+	}
+	if length > uint64(len(d.payload)-d.offset) {
+		d.err = ErrTruncated
+		return ""
+	}
+	value := string(d.payload[d.offset : d.offset+int(length)])
+	d.offset += int(length)
+	return value
+}
+
+// Time Time in UTC, as location is not kept
+func (d *Decoder) Time() time.Time {
+	if !d.Bool() {
+		return time.Time{}
+	}
+	seconds := d.Int()
+	nanoseconds := d.Uint()
+	if d.err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, int64(nanoseconds)).UTC()
+}
+
+func (d *Decoder) NullTime() sql.NullTime {
+	if !d.Bool() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: d.Time(), Valid: true}
+}
+
+// Err Error of reading records so far
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Finish Error of reading records, all of which must have been read
+func (d *Decoder) Finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.offset != len(d.payload) {
+		return ErrTrailingData
+	}
+	return nil
+}
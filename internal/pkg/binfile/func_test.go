@@ -0,0 +1,159 @@
+package binfile
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+const testKind = 7
+
+// writeTestFile File with two records holding every kind of field
+func writeTestFile(t *testing.T) []byte {
+	t.Helper()
+	encoder := NewEncoder()
+	for _, record := range testRecords() {
+		encoder.Uint(record.uint)
+		encoder.Int(record.int)
+		encoder.Bool(record.bool)
+		encoder.String(record.string)
+		encoder.Time(record.time)
+		encoder.NullTime(record.nullTime)
+	}
+	var file bytes.Buffer
+	if errWrite := Write(&file, testKind, len(testRecords()), encoder); errWrite != nil {
+		t.Fatalf("Write failed: %s", errWrite)
+	}
+	return file.Bytes()
+}
+
+type testRecord struct {
+	uint     uint64
+	int      int64
+	bool     bool
+	string   string
+	time     time.Time
+	nullTime sql.NullTime
+}
+
+func testRecords() []testRecord {
+	return []testRecord{
+		{
+			uint:     1<<64 - 1,
+			int:      -1 << 63,
+			bool:     true,
+			string:   "zażółć 日本語",
+			time:     time.Date(2030, 1, 2, 3, 4, 5, 6, time.UTC),
+			nullTime: sql.NullTime{Time: time.Date(1969, 12, 31, 23, 59, 59, 999999999, time.UTC), Valid: true},
+		},
+		{},
+	}
+}
+
+func readTestRecord(decoder *Decoder) testRecord {
+	return testRecord{
+		uint:     decoder.Uint(),
+		int:      decoder.Int(),
+		bool:     decoder.Bool(),
+		string:   decoder.String(),
+		time:     decoder.Time(),
+		nullTime: decoder.NullTime(),
+	}
+}
+
+// resign Sets record count of the file and recomputes its checksum, so that only the records are damaged
+func resign(data []byte, count uint32) []byte {
+	data = bytes.Clone(data)
+	binary.BigEndian.PutUint32(data[8:], count)
+	checksumOffset := len(data) - ChecksumSize
+	binary.BigEndian.PutUint32(data[checksumOffset:], crc32.Checksum(data[:checksumOffset], crcTable))
+	return data
+}
+
+func TestRoundTrip(t *testing.T) {
+	decoder, count, errRead := Read(bytes.NewReader(writeTestFile(t)), testKind)
+	if errRead != nil {
+		t.Fatalf("Read failed: %s", errRead)
+	}
+	if count != len(testRecords()) {
+		t.Fatalf("Expected %d records, got %d", len(testRecords()), count)
+	}
+	for i, expected := range testRecords() {
+		if record := readTestRecord(decoder); record != expected {
+			t.Errorf("Record %d: expected %+v, got %+v", i, expected, record)
+		}
+	}
+	if errFinish := decoder.Finish(); errFinish != nil {
+		t.Errorf("Finish failed: %s", errFinish)
+	}
+}
+
+func TestReadCorrupted(t *testing.T) {
+	file := writeTestFile(t)
+	tests := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{name: "empty", data: nil, expected: ErrInvalidMagic},
+		{name: "bad magic", data: append([]byte("XXXX"), file[len(Magic):]...), expected: ErrInvalidMagic},
+		{name: "header only", data: file[:HeaderSize], expected: ErrTruncated},
+		{name: "unsupported version", data: append(append([]byte(Magic), 0, 2), file[6:]...), expected: ErrUnsupportedVersion},
+		{name: "kind mismatch", data: append(append([]byte(Magic), file[4:6]...), append([]byte{testKind + 1}, file[7:]...)...), expected: ErrKindMismatch},
+		{name: "bad checksum", data: append(bytes.Clone(file[:len(file)-1]), file[len(file)-1]^0xff), expected: ErrChecksumMismatch},
+		{name: "flipped payload", data: append(append(bytes.Clone(file[:HeaderSize]), file[HeaderSize]^0xff), file[HeaderSize+1:]...), expected: ErrChecksumMismatch},
+		{name: "truncated", data: file[:len(file)/2], expected: ErrChecksumMismatch},
+		{name: "trailing data", data: append(bytes.Clone(file), 0), expected: ErrChecksumMismatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, errRead := Read(bytes.NewReader(test.data), testKind)
+			if !errors.Is(errRead, test.expected) {
+				t.Errorf("Expected %s, got %v", test.expected, errRead)
+			}
+		})
+	}
+}
+
+func TestDecodeCorrupted(t *testing.T) {
+	file := writeTestFile(t)
+	tests := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{name: "missing records", data: resign(file, uint32(len(testRecords())+1)), expected: ErrTruncated},
+		{name: "trailing records", data: resign(file, uint32(len(testRecords())-1)), expected: ErrTrailingData},
+		{name: "cut payload", data: resign(append(bytes.Clone(file[:HeaderSize+3]), file[len(file)-ChecksumSize:]...), uint32(len(testRecords()))), expected: ErrTruncated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoder, count, errRead := Read(bytes.NewReader(test.data), testKind)
+			if errRead != nil {
+				t.Fatalf("Read failed: %s", errRead)
+			}
+			for i := 0; i < count && decoder.Err() == nil; i++ {
+				readTestRecord(decoder)
+			}
+			if errFinish := decoder.Finish(); !errors.Is(errFinish, test.expected) {
+				t.Errorf("Expected %s, got %v", test.expected, errFinish)
+			}
+		})
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	decoder, _, errRead := Read(bytes.NewReader(resign(writeTestFile(t), 1<<32-1)), testKind)
+	if errRead != nil {
+		t.Fatalf("Read failed: %s", errRead)
+	}
+	if capacity := decoder.Capacity(1<<32 - 1); capacity > len(decoder.payload) {
+		t.Errorf("Capacity %d exceeds payload of %d bytes", capacity, len(decoder.payload))
+	}
+}
@@ -0,0 +1,15 @@
+package binfile
+
+type (
+	// Encoder Records encoded into memory, fields are written one after another without any names
+	Encoder struct {
+		payload []byte
+	}
+
+	// Decoder Records read from the checked file, the first error sticks so that fields can be read without checks
+	Decoder struct {
+		payload []byte
+		offset  int
+		err     error
+	}
+)
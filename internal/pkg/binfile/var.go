@@ -0,0 +1,17 @@
+package binfile
+
+import (
+	"github.com/pkg/errors"
+	"hash/crc32"
+)
+
+var (
+	ErrInvalidMagic       = errors.New("Not a binary file of records")
+	ErrUnsupportedVersion = errors.New("Unsupported version of binary file")
+	ErrKindMismatch       = errors.New("Binary file holds records of another kind")
+	ErrChecksumMismatch   = errors.New("Checksum of binary file does not match, file is corrupted")
+	ErrTruncated          = errors.New("Binary file is truncated")
+	ErrTrailingData       = errors.New("Binary file has data after the last record")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
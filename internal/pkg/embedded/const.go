@@ -0,0 +1,11 @@
+package embedded
+
+import "time"
+
+const (
+	// OpenTimeout How long opening waits for another process to release the file
+	OpenTimeout = 5 * time.Second
+
+	// KeySize Size of keys made of identifiers
+	KeySize = 8
+)
@@ -0,0 +1,232 @@
+package embedded
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+	"slices"
+	"strings"
+)
+
+// Open Opens embedded key-value store file (creating it if missing), waiting for another process to release it
+func Open(fileName string) (*bbolt.DB, error) {
+	db, errOpen := bbolt.Open(fileName, 0600, &bbolt.Options{Timeout: OpenTimeout})
+	if errOpen != nil {
+		return nil, errors.Wrapf(errOpen, "Failed to open embedded store %s", fileName)
+	}
+	return db, nil
+}
+
+// Key Key of the identifier, big-endian so that keys are iterated in order of identifiers
+func Key(id uint) []byte {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, KeySize), uint64(id))
+}
+
+// ID Identifier of the key
+func ID(key []byte) uint {
+	return uint(binary.BigEndian.Uint64(key))
+}
+
+// PatternPrefix Literal beginning of path.Match pattern, every value matching the pattern starts with it
+func PatternPrefix(pattern string) []byte {
+	if metaIndex := strings.IndexAny(pattern, `*?[\`); metaIndex >= 0 {
+		return []byte(pattern[:metaIndex])
+	}
+	return []byte(pattern)
+}
+
+// NextID Identifier following the greatest one in the table
+func (t Table[T]) NextID(tx *bbolt.Tx) uint {
+	records := tx.Bucket([]byte(t.Bucket))
+	if records == nil {
+		return 1
+	}
+	lastKey, _ := records.Cursor().Last()
+	if lastKey == nil {
+		return 1
+	}
+	return ID(lastKey) + 1
+}
+
+// Get Record by its identifier, nil if there is none
+func (t Table[T]) Get(tx *bbolt.Tx, id uint) (*T, error) {
+	records := tx.Bucket([]byte(t.Bucket))
+	if records == nil {
+		return nil, nil
+	}
+	data := records.Get(Key(id))
+	if data == nil {
+		return nil, nil
+	}
+
+	var record T
+	if errUnmarshal := json.Unmarshal(data, &record); errUnmarshal != nil {
+		return nil, errors.Wrapf(errUnmarshal, "Failed to decode record %d of %s", id, t.Bucket)
+	}
+	return &record, nil
+}
+
+// All Every record of the table in order of identifiers
+func (t Table[T]) All(tx *bbolt.Tx) ([]T, error) {
+	records := tx.Bucket([]byte(t.Bucket))
+	if records == nil {
+		return nil, nil
+	}
+
+	var results []T
+	errEach := records.ForEach(func(key []byte, data []byte) error {
+		var record T
+		if errUnmarshal := json.Unmarshal(data, &record); errUnmarshal != nil {
+			return errors.Wrapf(errUnmarshal, "Failed to decode record %d of %s", ID(key), t.Bucket)
+		}
+		results = append(results, record)
+		return nil
+	})
+	if errEach != nil {
+		return nil, errEach
+	}
+	return results, nil
+}
+
+// Find Records with values in the index starting with the prefix (i.e. the value itself, or longer values), in order
+// of identifiers
+func (t Table[T]) Find(tx *bbolt.Tx, indexBucket string, prefix []byte) ([]T, error) {
+	index, errIndex := t.index(indexBucket)
+	if errIndex != nil {
+		return nil, errIndex
+	}
+	entries := tx.Bucket([]byte(index.Bucket))
+	if entries == nil {
+		return nil, nil
+	}
+
+	var results []T
+	cursor := entries.Cursor()
+	for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+		var id uint
+		if index.Unique {
+			id = ID(value)
+		} else {
+			id = ID(key[len(key)-KeySize:])
+		}
+
+		record, errGet := t.Get(tx, id)
+		if errGet != nil {
+			return nil, errGet
+		}
+		if record != nil {
+			results = append(results, *record)
+		}
+	}
+
+	slices.SortFunc(results, func(a T, b T) int {
+		return cmp.Compare(t.ID(a), t.ID(b))
+	})
+	return results, nil
+}
+
+// Put Creates or replaces the record along with its index entries. Empty values are not kept in unique indexes, the
+// same way NULL values do not violate unique constraints of databases
+func (t Table[T]) Put(tx *bbolt.Tx, record T) error {
+	records, errCreate := tx.CreateBucketIfNotExists([]byte(t.Bucket))
+	if errCreate != nil {
+		return errors.Wrapf(errCreate, "Failed to create bucket %s", t.Bucket)
+	}
+
+	id := t.ID(record)
+	previous, errGet := t.Get(tx, id)
+	if errGet != nil {
+		return errGet
+	}
+	if previous != nil {
+		if errUnindex := t.unindex(tx, *previous); errUnindex != nil {
+			return errUnindex
+		}
+	}
+
+	for _, index := range t.Indexes {
+		entries, errCreateIndex := tx.CreateBucketIfNotExists([]byte(index.Bucket))
+		if errCreateIndex != nil {
+			return errors.Wrapf(errCreateIndex, "Failed to create bucket %s", index.Bucket)
+		}
+
+		var errPut error
+		value := index.Value(record)
+		if !index.Unique {
+			errPut = entries.Put(indexKey(value, id), []byte{})
+		} else if len(value) > 0 {
+			if owner := entries.Get(value); owner != nil && ID(owner) != id {
+				return errors.Wrapf(ErrUniqueViolation, "Record %d of %s is not indexed in %s", id, t.Bucket, index.Bucket)
+			}
+			errPut = entries.Put(value, Key(id))
+		}
+		if errPut != nil {
+			return errors.Wrapf(errPut, "Failed to index record %d of %s in %s", id, t.Bucket, index.Bucket)
+		}
+	}
+
+	data, errMarshal := json.Marshal(record)
+	if errMarshal != nil {
+		return errors.Wrapf(errMarshal, "Failed to encode record %d of %s", id, t.Bucket)
+	}
+	if errPut := records.Put(Key(id), data); errPut != nil {
+		return errors.Wrapf(errPut, "Failed to write record %d of %s", id, t.Bucket)
+	}
+	return nil
+}
+
+// Delete Removes the record along with its index entries, missing record is not an error
+func (t Table[T]) Delete(tx *bbolt.Tx, id uint) error {
+	previous, errGet := t.Get(tx, id)
+	if errGet != nil || previous == nil {
+		return errGet
+	}
+	if errUnindex := t.unindex(tx, *previous); errUnindex != nil {
+		return errUnindex
+	}
+	if errDelete := tx.Bucket([]byte(t.Bucket)).Delete(Key(id)); errDelete != nil {
+		return errors.Wrapf(errDelete, "Failed to delete record %d of %s", id, t.Bucket)
+	}
+	return nil
+}
+
+// unindex Removes index entries of the record as it is currently written
+func (t Table[T]) unindex(tx *bbolt.Tx, record T) error {
+	id := t.ID(record)
+	for _, index := range t.Indexes {
+		entries := tx.Bucket([]byte(index.Bucket))
+		if entries == nil {
+			continue
+		}
+
+		var errDelete error
+		value := index.Value(record)
+		if !index.Unique {
+			errDelete = entries.Delete(indexKey(value, id))
+		} else if owner := entries.Get(value); owner != nil && ID(owner) == id {
+			errDelete = entries.Delete(value)
+		}
+		if errDelete != nil {
+			return errors.Wrapf(errDelete, "Failed to unindex record %d of %s in %s", id, t.Bucket, index.Bucket)
+		}
+	}
+	return nil
+}
+
+// index Index of the table kept in the bucket
+func (t Table[T]) index(bucket string) (Index[T], error) {
+	for _, index := range t.Indexes {
+		if index.Bucket == bucket {
+			return index, nil
+		}
+	}
+	return Index[T]{}, errors.Wrapf(ErrUnknownIndex, "Index %s of %s", bucket, t.Bucket)
+}
+
+// indexKey Key of entry of non-unique index, the value followed by identifier of the record
+func indexKey(value []byte, id uint) []byte {
+	return append(append(make([]byte, 0, len(value)+KeySize), value...), Key(id)...)
+}
@@ -0,0 +1,20 @@
+package embedded
+
+type (
+	// Table Records of a kind kept in a bucket of embedded key-value store by their identifiers, along with buckets of
+	// their secondary indexes. Indexes are only changed by the table, in the same transaction as records are
+	Table[T any] struct {
+		Bucket  string
+		ID      func(record T) uint
+		Indexes []Index[T]
+	}
+
+	// Index Secondary index of records by a value of theirs. Entries of unique index are keyed by the value alone,
+	// other entries are keyed by the value followed by identifier of the record, so that records sharing the value
+	// are found by scanning keys starting with the value
+	Index[T any] struct {
+		Bucket string
+		Unique bool
+		Value  func(record T) []byte
+	}
+)
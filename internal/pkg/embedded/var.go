@@ -0,0 +1,8 @@
+package embedded
+
+import "github.com/pkg/errors"
+
+var (
+	ErrUnknownIndex    = errors.New("Unknown index of embedded table")
+	ErrUniqueViolation = errors.New("Value of unique index is already taken by another record")
+)
@@ -0,0 +1,8 @@
+package encryption
+
+const (
+	// Prefix Marks values encrypted by Seal, includes format version for future changes
+	Prefix = "enc:v1:"
+
+	KeySize = 32
+)
@@ -0,0 +1,72 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// ParseKey Decodes base64-encoded AES-256 key
+func ParseKey(encodedKey string) ([]byte, error) {
+	if encodedKey == "" {
+		return nil, ErrKeyNotConfigured
+	}
+	key, errDecode := base64.StdEncoding.DecodeString(encodedKey)
+	if errDecode != nil || len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// Seal Encrypts plaintext with AES-256-GCM, result is prefixed and base64-encoded so it can be stored as secret value
+func Seal(key []byte, plaintext []byte) (string, error) {
+	aead, errCipher := newAEAD(key)
+	if errCipher != nil {
+		return "", errCipher
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, errRead := rand.Read(nonce)
+	if errRead != nil {
+		return "", errors.Wrap(errRead, "Failed to generate nonce")
+	}
+	return Prefix + base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Open Decrypts value produced by Seal
+func Open(key []byte, sealed string) ([]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, ErrNotEncrypted
+	}
+	aead, errCipher := newAEAD(key)
+	if errCipher != nil {
+		return nil, errCipher
+	}
+	data, errDecode := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, Prefix))
+	if errDecode != nil || len(data) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	plaintext, errOpen := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if errOpen != nil {
+		return nil, errors.Wrap(errOpen, "Failed to decrypt value")
+	}
+	return plaintext, nil
+}
+
+// IsSealed Whether value was produced by Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, errCipher := aes.NewCipher(key)
+	if errCipher != nil {
+		return nil, errors.Wrap(errCipher, "Failed to create cipher")
+	}
+	return cipher.NewGCM(block)
+}
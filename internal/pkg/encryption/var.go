@@ -0,0 +1,10 @@
+package encryption
+
+import "github.com/pkg/errors"
+
+var (
+	ErrKeyNotConfigured = errors.New("Encryption key is not configured")
+	ErrInvalidKey       = errors.New("Encryption key must be 32 bytes long, base64-encoded")
+	ErrNotEncrypted     = errors.New("Value is not encrypted")
+	ErrMalformed        = errors.New("Encrypted value is malformed")
+)
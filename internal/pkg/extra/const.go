@@ -7,4 +7,6 @@ const (
 	Encoding_JSON    = 3
 	Encoding_XML     = 4
 	Encoding_Archive = 5
+	Encoding_YAML    = 6
+	Encoding_TOML    = 7
 )
@@ -1,5 +1,7 @@
 package extra
 
+import "strings"
+
 func UniqueString(slice []string) []string {
 	// create a map with all the values as key
 	uniqMap := make(map[string]struct{})
@@ -49,3 +51,22 @@ func RemoveZeros(s []uint) []uint {
 	}
 	return r
 }
+
+// GlobToLike converts shell file name pattern (as used by path.Match) into SQL LIKE pattern
+func GlobToLike(pattern string) string {
+	var result strings.Builder
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			result.WriteRune('%')
+		case '?':
+			result.WriteRune('_')
+		case '%', '_', '\\':
+			result.WriteRune('\\')
+			result.WriteRune(c)
+		default:
+			result.WriteRune(c)
+		}
+	}
+	return result.String()
+}
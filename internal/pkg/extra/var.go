@@ -8,6 +8,8 @@ var (
 		Encoding_JSON:    "json",
 		Encoding_XML:     "xml",
 		Encoding_Archive: "archive",
+		Encoding_YAML:    "yaml",
+		Encoding_TOML:    "toml",
 	}
 
 	EncodingTypeMapInv = map[string]uint{
@@ -17,5 +19,7 @@ var (
 		"json":    Encoding_JSON,
 		"xml":     Encoding_XML,
 		"archive": Encoding_Archive,
+		"yaml":    Encoding_YAML,
+		"toml":    Encoding_TOML,
 	}
 )
@@ -0,0 +1,16 @@
+package journal
+
+const (
+	// Op_Put Record is created or replaced
+	Op_Put = "put"
+	// Op_Remove Record is removed for good
+	Op_Remove = "remove"
+)
+
+const (
+	// Suffix Suffix of the journal kept next to the file it journals
+	Suffix = ".journal"
+
+	// CompactSize Size of the journal after which it is merged into the file it journals
+	CompactSize = 1 << 20
+)
@@ -0,0 +1,180 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+)
+
+// New Journal of the file
+func New[T any](fileName string) Journal[T] {
+	return Journal[T]{FileName: fileName + Suffix}
+}
+
+// Put Entry creating or replacing the record
+func Put[T any](record T) Entry[T] {
+	return Entry[T]{Op: Op_Put, Record: &record}
+}
+
+// Remove Entry removing the record for good
+func Remove[T any](id uint) Entry[T] {
+	return Entry[T]{Op: Op_Remove, ID: id}
+}
+
+// Append Appends entries and flushes them to disk. Entry left incomplete by a crash is dropped first, so that it does
+// not merge with appended ones
+func (j Journal[T]) Append(entries ...Entry[T]) error {
+	var data []byte
+	for _, entry := range entries {
+		line, errMarshal := json.Marshal(entry)
+		if errMarshal != nil {
+			return errMarshal
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	file, errOpen := os.OpenFile(j.FileName, os.O_RDWR|os.O_CREATE, 0600)
+	if errOpen != nil {
+		return errors.Wrap(errOpen, "Failed to open journal")
+	}
+	defer file.Close()
+
+	size, errComplete := completeSize(file)
+	if errComplete != nil {
+		return errComplete
+	}
+	if errTruncate := file.Truncate(size); errTruncate != nil {
+		return errors.Wrap(errTruncate, "Failed to drop incomplete journal entry")
+	}
+	if _, errWrite := file.WriteAt(data, size); errWrite != nil {
+		return errors.Wrap(errWrite, "Failed to write journal")
+	}
+	if errSync := file.Sync(); errSync != nil {
+		return errors.Wrap(errSync, "Failed to flush journal to disk")
+	}
+	return nil
+}
+
+// completeSize Size of the journal up to the end of its last complete entry
+func completeSize(file *os.File) (int64, error) {
+	info, errStat := file.Stat()
+	if errStat != nil {
+		return 0, errors.Wrap(errStat, "Failed to read journal")
+	}
+	if info.Size() == 0 {
+		return 0, nil
+	}
+
+	var lastByte = make([]byte, 1)
+	if _, errRead := file.ReadAt(lastByte, info.Size()-1); errRead != nil {
+		return 0, errors.Wrap(errRead, "Failed to read journal")
+	}
+	if lastByte[0] == '\n' {
+		return info.Size(), nil
+	}
+
+	data, errRead := io.ReadAll(io.NewSectionReader(file, 0, info.Size()))
+	if errRead != nil {
+		return 0, errors.Wrap(errRead, "Failed to read journal")
+	}
+	return int64(bytes.LastIndexByte(data, '\n') + 1), nil
+}
+
+// Read Complete entries of the journal, missing journal has no entries
+func (j Journal[T]) Read() ([]Entry[T], error) {
+	file, errOpen := os.Open(j.FileName)
+	if errors.Is(errOpen, os.ErrNotExist) {
+		return nil, nil
+	}
+	if errOpen != nil {
+		return nil, errors.Wrap(errOpen, "Failed to open journal")
+	}
+	defer file.Close()
+
+	var entries []Entry[T]
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, errRead := reader.ReadBytes('\n')
+		if errors.Is(errRead, io.EOF) {
+			// Entry without line end was not completely written before a crash and was never acknowledged
+			return entries, nil
+		}
+		if errRead != nil {
+			return nil, errors.Wrap(errRead, "Failed to read journal")
+		}
+
+		var entry Entry[T]
+		if errUnmarshal := json.Unmarshal(line, &entry); errUnmarshal != nil {
+			return nil, errors.Wrapf(ErrCorrupted, "Line %d: %s", lineNumber, errUnmarshal)
+		}
+		if (entry.Op != Op_Put || entry.Record == nil) && entry.Op != Op_Remove {
+			return nil, errors.Wrapf(ErrCorrupted, "Line %d: unknown operation %q", lineNumber, entry.Op)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// Size Size of the journal, missing journal is empty
+func (j Journal[T]) Size() (int64, error) {
+	info, errStat := os.Stat(j.FileName)
+	if errors.Is(errStat, os.ErrNotExist) {
+		return 0, nil
+	}
+	if errStat != nil {
+		return 0, errors.Wrap(errStat, "Failed to read journal")
+	}
+	return info.Size(), nil
+}
+
+// Reset Drops all the entries once they are merged into the journaled file
+func (j Journal[T]) Reset() error {
+	if errRemove := os.Remove(j.FileName); errRemove != nil && !errors.Is(errRemove, os.ErrNotExist) {
+		return errors.Wrap(errRemove, "Failed to reset journal")
+	}
+	return nil
+}
+
+// Replay Applies entries to the records, records are identified by the given function
+func Replay[T any](records []T, entries []Entry[T], id func(record T) uint) []T {
+	var positions = make(map[uint]int, len(records))
+	for position, record := range records {
+		positions[id(record)] = position
+	}
+
+	var removed = make(map[int]bool)
+	for _, entry := range entries {
+		switch entry.Op {
+		case Op_Put:
+			{
+				recordID := id(*entry.Record)
+				if position, exists := positions[recordID]; exists {
+					records[position] = *entry.Record
+				} else {
+					positions[recordID] = len(records)
+					records = append(records, *entry.Record)
+				}
+			}
+		case Op_Remove:
+			{
+				if position, exists := positions[entry.ID]; exists {
+					removed[position] = true
+					delete(positions, entry.ID)
+				}
+			}
+		}
+	}
+
+	if len(removed) == 0 {
+		return records
+	}
+	var results = make([]T, 0, len(records)-len(removed))
+	for position, record := range records {
+		if !removed[position] {
+			results = append(results, record)
+		}
+	}
+	return results
+}
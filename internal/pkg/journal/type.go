@@ -0,0 +1,16 @@
+package journal
+
+type (
+	// Journal Changes of records appended to the file next to the file holding all the records, so that a change is
+	// written without rewriting all the records. Journal must only be used while holding lock of the journaled file
+	Journal[T any] struct {
+		FileName string
+	}
+
+	// Entry Single change, replaying entries is idempotent
+	Entry[T any] struct {
+		Op     string `json:"Op"`
+		ID     uint   `json:"ID,omitempty"`
+		Record *T     `json:"Record,omitempty"`
+	}
+)
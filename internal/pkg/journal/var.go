@@ -0,0 +1,7 @@
+package journal
+
+import "github.com/pkg/errors"
+
+var (
+	ErrCorrupted = errors.New("Journal is corrupted")
+)
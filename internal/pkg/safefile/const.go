@@ -0,0 +1,17 @@
+package safefile
+
+const (
+	// Sync_Always Written file and its directory are flushed to disk before write is reported as done
+	Sync_Always = 0
+	// Sync_File Only written file is flushed, rename may be lost on power failure (but never leaves a partial file)
+	Sync_File = 1
+	// Sync_Never Flushing is left to operating system
+	Sync_Never = 2
+)
+
+const (
+	// LockSuffix Suffix of the file locked on behalf of the file, which itself is replaced on every write
+	LockSuffix = ".lock"
+	// TempSuffix Suffix of temporary files written next to the file
+	TempSuffix = ".tmp"
+)
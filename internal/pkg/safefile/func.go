@@ -0,0 +1,107 @@
+package safefile
+
+import (
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteAtomic Writes the file into temporary file next to it and renames it over the file, so that readers (and the
+// file after a crash) see either old or new contents, but never partially written ones
+func WriteAtomic(fileName string, syncPolicy uint, write func(writer io.Writer) error) error {
+	directory := filepath.Dir(fileName)
+	tempFile, errCreate := os.CreateTemp(directory, tempPattern(fileName))
+	if errCreate != nil {
+		return errors.Wrap(errCreate, "Failed to create temporary file")
+	}
+	// Removal fails once the file is renamed
+	defer os.Remove(tempFile.Name())
+
+	if errWrite := write(tempFile); errWrite != nil {
+		_ = tempFile.Close()
+		return errWrite
+	}
+	if syncPolicy != Sync_Never {
+		if errSync := tempFile.Sync(); errSync != nil {
+			_ = tempFile.Close()
+			return errors.Wrap(errSync, "Failed to flush file to disk")
+		}
+	}
+	if errClose := tempFile.Close(); errClose != nil {
+		return errors.Wrap(errClose, "Failed to write file")
+	}
+	if errRename := os.Rename(tempFile.Name(), fileName); errRename != nil {
+		return errors.Wrap(errRename, "Failed to replace file")
+	}
+
+	if syncPolicy == Sync_Always {
+		return SyncDirectory(directory)
+	}
+	return nil
+}
+
+// SyncDirectory Flushes entries of the directory to disk, so that renamed or created files survive power failure
+func SyncDirectory(directory string) error {
+	directoryFile, errOpen := os.Open(directory)
+	if errOpen != nil {
+		return errors.Wrap(errOpen, "Failed to open directory")
+	}
+	defer directoryFile.Close()
+
+	if errSync := directoryFile.Sync(); errSync != nil {
+		return errors.Wrap(errSync, "Failed to flush directory to disk")
+	}
+	return nil
+}
+
+// CleanTemp Removes temporary files of the file left behind by writers which crashed, must only be called while
+// holding the lock of the file
+func CleanTemp(fileName string) error {
+	tempFiles, errGlob := filepath.Glob(filepath.Join(filepath.Dir(fileName), tempPattern(fileName)))
+	if errGlob != nil {
+		return errGlob
+	}
+	for _, tempFile := range tempFiles {
+		if errRemove := os.Remove(tempFile); errRemove != nil && !errors.Is(errRemove, os.ErrNotExist) {
+			return errors.Wrap(errRemove, "Failed to remove temporary file")
+		}
+	}
+	return nil
+}
+
+func tempPattern(fileName string) string {
+	return "." + filepath.Base(fileName) + ".*" + TempSuffix
+}
+
+// LockExclusive Waits until no other process (nor goroutine) holds the lock of the file and takes it for writing
+func LockExclusive(fileName string) (*Lock, error) {
+	return lock(fileName, true)
+}
+
+// LockShared Waits until nobody writes the file and takes the lock for reading, which others may read along
+func LockShared(fileName string) (*Lock, error) {
+	return lock(fileName, false)
+}
+
+func lock(fileName string, exclusive bool) (*Lock, error) {
+	file, errOpen := os.OpenFile(fileName+LockSuffix, os.O_RDWR|os.O_CREATE, 0600)
+	if errOpen != nil {
+		return nil, errors.Wrap(errOpen, "Failed to open lock file")
+	}
+	if errLock := lockFile(file, exclusive); errLock != nil {
+		_ = file.Close()
+		return nil, errors.Wrap(errLock, "Failed to lock file")
+	}
+	return &Lock{file: file, exclusive: exclusive}, nil
+}
+
+// Unlock Releases the lock, lock is released by operating system too once the process exits
+func (l *Lock) Unlock() error {
+	errUnlock := unlockFile(l.file, l.exclusive)
+	errClose := l.file.Close()
+	if errUnlock != nil {
+		return errors.Wrap(errUnlock, "Failed to unlock file")
+	}
+	return errClose
+}
@@ -0,0 +1,40 @@
+//go:build !unix
+
+package safefile
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// locks Locks of files by their absolute paths, only processes on Unix-like systems are excluded from each other
+var locks sync.Map
+
+func fileMutex(file *os.File) *sync.RWMutex {
+	fileName, errAbs := filepath.Abs(file.Name())
+	if errAbs != nil {
+		fileName = file.Name()
+	}
+	mutex, _ := locks.LoadOrStore(fileName, &sync.RWMutex{})
+	return mutex.(*sync.RWMutex)
+}
+
+// lockFile Only excludes goroutines of this process from each other
+func lockFile(file *os.File, exclusive bool) error {
+	if exclusive {
+		fileMutex(file).Lock()
+	} else {
+		fileMutex(file).RLock()
+	}
+	return nil
+}
+
+func unlockFile(file *os.File, exclusive bool) error {
+	if exclusive {
+		fileMutex(file).Unlock()
+	} else {
+		fileMutex(file).RUnlock()
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+package safefile
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile Takes flock(2) lock, which is held per open file, so it excludes goroutines of the same process as well
+func lockFile(file *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		errLock := syscall.Flock(int(file.Fd()), how)
+		if !errors.Is(errLock, syscall.EINTR) {
+			return errLock
+		}
+	}
+}
+
+func unlockFile(file *os.File, exclusive bool) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
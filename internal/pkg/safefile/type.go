@@ -0,0 +1,11 @@
+package safefile
+
+import "os"
+
+type (
+	// Lock Advisory lock held on behalf of the file until unlocked
+	Lock struct {
+		file      *os.File
+		exclusive bool
+	}
+)
@@ -0,0 +1,12 @@
+package signing
+
+const (
+	// SeedSize Length of the Ed25519 seed the signing key is derived from
+	SeedSize = 32
+
+	// KeyIDSize Amount of bytes of the public key SHA-256 hash used as key identifier
+	KeyIDSize = 8
+
+	ManifestFile          = "manifest.json"     // Name of the manifest in the root of exported archives
+	ManifestSignatureFile = "manifest.json.sig" // Name of the detached base64-encoded Ed25519 signature of the manifest
+)
@@ -0,0 +1,43 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"github.com/pkg/errors"
+)
+
+// ParseKey Decodes base64-encoded Ed25519 seed into the signing key
+func ParseKey(encodedKey string) (ed25519.PrivateKey, error) {
+	if encodedKey == "" {
+		return nil, ErrKeyNotConfigured
+	}
+	seed, errDecode := base64.StdEncoding.DecodeString(encodedKey)
+	if errDecode != nil || len(seed) != SeedSize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// KeyID Short identifier of the public key, lets verifiers pick the right key after rotation
+func KeyID(publicKey ed25519.PublicKey) string {
+	checksum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(checksum[:KeyIDSize])
+}
+
+// EncodePublicKey Encodes public key as PKIX PEM block, understood by openssl and most crypto libraries
+func EncodePublicKey(publicKey ed25519.PublicKey) (string, error) {
+	der, errMarshal := x509.MarshalPKIXPublicKey(publicKey)
+	if errMarshal != nil {
+		return "", errors.Wrap(errMarshal, "Failed to marshal public key")
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// Sign Detached signature of the data, base64-encoded so that it can be stored as a text file
+func Sign(privateKey ed25519.PrivateKey, data []byte) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data)) + "\n")
+}
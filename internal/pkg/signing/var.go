@@ -0,0 +1,8 @@
+package signing
+
+import "github.com/pkg/errors"
+
+var (
+	ErrKeyNotConfigured = errors.New("Signing key is not configured")
+	ErrInvalidKey       = errors.New("Signing key must be a 32 bytes long Ed25519 seed, base64-encoded")
+)
@@ -0,0 +1,8 @@
+package watcher
+
+import "time"
+
+const (
+	// DebounceDelay Changes made within the delay are reported once, so that a file being written is not read halfway
+	DebounceDelay = 200 * time.Millisecond
+)
@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"context"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"path/filepath"
+	"time"
+)
+
+// New Watcher of the files, which calls onChange once the files stop changing and onError when watching fails.
+// Directories of the files are watched, as files replaced by rename lose watches of their own
+func New(fileNames []string, onChange func(), onError func(err error)) (*Watcher, error) {
+	fsWatcher, errCreate := fsnotify.NewWatcher()
+	if errCreate != nil {
+		return nil, errors.Wrap(errCreate, "Failed to create file watcher")
+	}
+
+	var watcher = &Watcher{watcher: fsWatcher, fileNames: make(map[string]bool), onChange: onChange, onError: onError}
+	var directories = make(map[string]bool)
+	for _, fileName := range fileNames {
+		absFileName, errAbs := filepath.Abs(fileName)
+		if errAbs != nil {
+			_ = fsWatcher.Close()
+			return nil, errors.Wrapf(errAbs, "Failed to watch %s", fileName)
+		}
+		watcher.fileNames[absFileName] = true
+		directories[filepath.Dir(absFileName)] = true
+	}
+	for directory := range directories {
+		if errAdd := fsWatcher.Add(directory); errAdd != nil {
+			_ = fsWatcher.Close()
+			return nil, errors.Wrapf(errAdd, "Failed to watch %s", directory)
+		}
+	}
+
+	return watcher, nil
+}
+
+// Run Watches the files until the context is done
+func (w *Watcher) Run(ctx context.Context) {
+	defer w.watcher.Close()
+
+	debounce := time.NewTimer(DebounceDelay)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			debounce.Stop()
+			return
+		case event, isOpen := <-w.watcher.Events:
+			if !isOpen {
+				return
+			}
+			// Changes of permissions alone do not change contents
+			if w.fileNames[filepath.Clean(event.Name)] && event.Op != fsnotify.Chmod {
+				debounce.Reset(DebounceDelay)
+			}
+		case errWatch, isOpen := <-w.watcher.Errors:
+			if !isOpen {
+				return
+			}
+			w.onError(errWatch)
+		case <-debounce.C:
+			w.onChange()
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package watcher
+
+import "github.com/fsnotify/fsnotify"
+
+type (
+	// Watcher Reports changes of files made on disk by anybody, including other processes
+	Watcher struct {
+		watcher   *fsnotify.Watcher
+		fileNames map[string]bool
+		onChange  func()
+		onError   func(err error)
+	}
+)
@@ -3,3 +3,46 @@ package secrets
 const (
 	TableName = "secrets"
 )
+
+const (
+	Type_Plain       = ""
+	Type_Certificate = "certificate"
+	Type_PrivateKey  = "private-key"
+	Type_PKIRole     = "pki-role"
+	Type_PKISerial   = "pki-serial"
+	// Type_PKICAPrivateKey Sealed type, value never leaves the storage
+	Type_PKICAPrivateKey = "pki-ca-private-key"
+	// Type_SSHCAPrivateKey Sealed type, value never leaves the storage
+	Type_SSHCAPrivateKey = "ssh-ca-private-key"
+	Type_SSHPrivateKey   = "ssh-private-key"
+	Type_SSHPublicKey    = "ssh-public-key"
+	// Type_TOTP Hidden type, value is an encrypted otpauth URI
+	Type_TOTP = "totp"
+	// Type_DatabaseConnection Hidden type, value is an encrypted connection URL with administrative credentials
+	Type_DatabaseConnection = "database-connection"
+	Type_DatabaseRole       = "database-role"
+	// Type_TransitKey Sealed type, value is an encrypted set of key versions used by transit engine
+	Type_TransitKey = "transit-key"
+	// Type_AgeRecipient Public key of age recipient of encrypted exports
+	Type_AgeRecipient = "age-recipient"
+	// Type_OpenPGPPublicKey Armored OpenPGP public key of recipient of encrypted exports
+	Type_OpenPGPPublicKey = "openpgp-public-key"
+	// Type_Lease Lease of a short-lived secret handed out by an engine
+	Type_Lease = "lease"
+)
+
+const (
+	// BinaryKind Kind of records in binary file of secrets
+	BinaryKind = 2
+	// ArchiveDirectory Directory holding one JSON file per secret in archive file of secrets
+	ArchiveDirectory = TableName
+)
+
+const (
+	// EmbeddedIndexUID Bucket indexing secrets by UID in embedded key-value store
+	EmbeddedIndexUID = TableName + "_uid"
+	// EmbeddedIndexFolder Bucket indexing secrets by folder in embedded key-value store
+	EmbeddedIndexFolder = TableName + "_folder"
+	// EmbeddedIndexName Bucket indexing secrets by name in embedded key-value store
+	EmbeddedIndexName = TableName + "_name"
+)
@@ -157,7 +157,12 @@ func (m DatabaseRepository) GetByUID(ctx context.Context, uid string) (*Secret,
 
 func (m DatabaseRepository) Update(ctx context.Context, secret Secret) (*Secret, error) {
 	var updatedSecretEntry = &secret
-	errUpdate := m.conn.Table(TableName).Model(&secret).Updates(updatedSecretEntry).Error
+	secret.UpdatedAt = time.Now()
+	// Columns are listed, as updating from struct skips zero values and expiration date could never be cleared
+	errUpdate := m.conn.Table(TableName).Model(&secret).Updates(map[string]interface{}{
+		"folder_id": secret.FolderID, "name": secret.Name, "value": secret.Value, "script": secret.Script,
+		"type": secret.Type, "expires_at": secret.ExpiresAt, "updated_at": secret.UpdatedAt,
+	}).Error
 	if errUpdate != nil {
 		return nil, errors.Wrapf(errUpdate, "Error updating secret with ID of %d in database", secret.ID)
 	}
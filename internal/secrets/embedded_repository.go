@@ -0,0 +1,352 @@
+package secrets
+
+import (
+	"context"
+	"database/sql"
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+	"hideout/internal/common/apperror"
+	"hideout/internal/common/generics"
+	"hideout/internal/common/model"
+	"hideout/internal/common/ordering"
+	"hideout/internal/common/pagination"
+	"hideout/internal/pkg/embedded"
+	"time"
+)
+
+type EmbeddedRepository struct {
+	db                 *bbolt.DB
+	inMemoryRepository *InMemoryRepository
+}
+
+func NewEmbeddedRepository(db *bbolt.DB, inMemoryRep *InMemoryRepository) EmbeddedRepository {
+	return EmbeddedRepository{db: db, inMemoryRepository: inMemoryRep}
+}
+
+func (m EmbeddedRepository) GetID(ctx context.Context) (uint, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetID(ctx)
+	}
+
+	var id uint
+	errView := m.db.View(func(tx *bbolt.Tx) error {
+		id = EmbeddedTable.NextID(tx)
+		return nil
+	})
+	if errView != nil {
+		return 0, errors.Wrap(errView, "Failed to retrieve next secret ID from embedded store")
+	}
+
+	return id, nil
+}
+
+func (m EmbeddedRepository) Load(ctx context.Context) ([]Secret, error) {
+	var results []Secret
+	errView := m.db.View(func(tx *bbolt.Tx) error {
+		var errAll error
+		results, errAll = EmbeddedTable.All(tx)
+		return errAll
+	})
+	if errView != nil {
+		return nil, errors.Wrap(errView, "Failed to load secrets from embedded store")
+	}
+
+	return results, nil
+}
+
+func (m EmbeddedRepository) GetMapByID(ctx context.Context, params ListSecretParams) (map[uint]*Secret, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetMapByID(ctx, params)
+	}
+
+	results, errGetResults := m.Get(ctx, params)
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	resultsMap := make(map[uint]*Secret)
+	for _, result := range results {
+		resultsMap[result.ID] = result
+	}
+
+	return resultsMap, nil
+}
+
+func (m EmbeddedRepository) GetMapByUID(ctx context.Context, params ListSecretParams) (map[string]*Secret, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetMapByUID(ctx, params)
+	}
+
+	results, errGetResults := m.Get(ctx, params)
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	resultsMap := make(map[string]*Secret)
+	for _, result := range results {
+		resultsMap[result.UID] = result
+	}
+
+	return resultsMap, nil
+}
+
+func (m EmbeddedRepository) GetMapByFolder(ctx context.Context, params ListSecretParams) (map[uint][]*Secret, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetMapByFolder(ctx, params)
+	}
+
+	results, errGetResults := m.Get(ctx, params)
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	resultsMap := make(map[uint][]*Secret)
+	for _, result := range results {
+		resultsMap[result.FolderID] = append(resultsMap[result.FolderID], result)
+	}
+
+	return resultsMap, nil
+}
+
+func (m EmbeddedRepository) Get(ctx context.Context, params ListSecretParams) ([]*Secret, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.Get(ctx, params)
+	}
+
+	var results []Secret
+	errView := m.db.View(func(tx *bbolt.Tx) error {
+		var errFind error
+		results, errFind = m.candidates(tx, params)
+		return errFind
+	})
+	if errView != nil {
+		return nil, errors.Wrap(errView, "Failed to retrieve secrets from embedded store")
+	}
+
+	// Candidates are narrowed down by a single index, the rest of parameters is applied the same way as in memory
+	inMemoryRepository := NewInMemoryRepository(&results)
+	return inMemoryRepository.Get(ctx, params)
+}
+
+func (m EmbeddedRepository) GetByID(ctx context.Context, id uint) (*Secret, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetByID(ctx, id)
+	}
+
+	resultsMap, errGetResults := m.GetMapByID(ctx, ListSecretParams{ListParams: generics.ListParams{IDs: []uint{id}, Deleted: model.No}})
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	result, exists := resultsMap[id]
+	if !exists {
+		return nil, apperror.ErrRecordNotFound
+	}
+
+	return result, nil
+}
+
+func (m EmbeddedRepository) GetByUID(ctx context.Context, uid string) (*Secret, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.GetByUID(ctx, uid)
+	}
+
+	resultsMap, errGetResults := m.GetMapByUID(ctx, ListSecretParams{ListParams: generics.ListParams{UIDs: []string{uid}, Deleted: model.No}})
+	if errGetResults != nil {
+		return nil, errGetResults
+	}
+
+	result, exists := resultsMap[uid]
+	if !exists {
+		return nil, apperror.ErrRecordNotFound
+	}
+
+	return result, nil
+}
+
+func (m EmbeddedRepository) Update(ctx context.Context, secret Secret) (*Secret, error) {
+	var updatedSecretEntry *Secret
+	errUpdate := m.db.Update(func(tx *bbolt.Tx) error {
+		existingSecret, errGetSecret := EmbeddedTable.Get(tx, secret.ID)
+		if errGetSecret != nil {
+			return errGetSecret
+		}
+		if existingSecret == nil {
+			return apperror.ErrRecordNotFound
+		}
+
+		var errUpdateSecret error
+		updatedSecretEntry, errUpdateSecret = NewInMemoryRepository(&[]Secret{*existingSecret}).Update(ctx, secret)
+		if errUpdateSecret != nil {
+			return errUpdateSecret
+		}
+		return m.put(tx, *updatedSecretEntry)
+	})
+	if errUpdate != nil {
+		return nil, errors.Wrapf(errUpdate, "Error updating secret with ID of %d in embedded store", secret.ID)
+	}
+
+	if m.inMemoryRepository != nil {
+		updatedSecret, errUpdateSecret := m.inMemoryRepository.Update(ctx, *updatedSecretEntry)
+		if errUpdateSecret != nil {
+			return nil, errors.Wrapf(errUpdateSecret, "Error updating secret with ID of %d and name %s in memory", secret.ID, secret.Name)
+		}
+
+		updatedSecretEntry = updatedSecret
+	}
+
+	return updatedSecretEntry, nil
+}
+
+func (m EmbeddedRepository) Create(ctx context.Context, secret Secret) (*Secret, error) {
+	var createdSecretEntry *Secret
+	errCreate := m.db.Update(func(tx *bbolt.Tx) error {
+		existingSecret, errGetSecret := EmbeddedTable.Get(tx, secret.ID)
+		if errGetSecret != nil {
+			return errGetSecret
+		}
+		if existingSecret != nil {
+			return apperror.ErrAlreadyExists
+		}
+
+		// Secrets of the same folder are enough to check whether the name is already taken
+		siblings, errFindSiblings := EmbeddedTable.Find(tx, EmbeddedIndexFolder, embedded.Key(secret.FolderID))
+		if errFindSiblings != nil {
+			return errFindSiblings
+		}
+		var errCreateSecret error
+		createdSecretEntry, errCreateSecret = NewInMemoryRepository(&siblings).Create(ctx, secret)
+		if errCreateSecret != nil {
+			return errCreateSecret
+		}
+		return m.put(tx, *createdSecretEntry)
+	})
+	if errCreate != nil {
+		return nil, errors.Wrapf(errCreate, "Error creating secret with ID of %d in embedded store", secret.ID)
+	}
+
+	if m.inMemoryRepository != nil {
+		newSecret, errCreateSecret := m.inMemoryRepository.Create(ctx, *createdSecretEntry)
+		if errCreateSecret != nil {
+			return nil, errors.Wrapf(errCreateSecret, "Error creating secret with folder ID of %d and name %s in memory", secret.FolderID, secret.Name)
+		}
+		createdSecretEntry = newSecret
+	}
+
+	return createdSecretEntry, nil
+}
+
+func (m EmbeddedRepository) Count(ctx context.Context, params ListSecretParams) (uint, error) {
+	// These are not needed when performing filtering and counting
+	params.Pagination = pagination.Pagination{PerPage: 0, Page: 0}
+	params.Order = []ordering.Order{}
+
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.Count(ctx, params)
+	}
+
+	results, errGetResults := m.Get(ctx, params)
+	if errGetResults != nil {
+		return 0, errGetResults
+	}
+
+	return uint(len(results)), nil
+}
+
+func (m EmbeddedRepository) Delete(ctx context.Context, id uint, forceDelete bool) error {
+	errDelete := m.db.Update(func(tx *bbolt.Tx) error {
+		existingSecret, errGetSecret := EmbeddedTable.Get(tx, id)
+		if errGetSecret != nil {
+			return errGetSecret
+		}
+		if existingSecret == nil {
+			return apperror.ErrRecordNotFound
+		}
+
+		if forceDelete {
+			return EmbeddedTable.Delete(tx, id)
+		}
+		existingSecret.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		return m.put(tx, *existingSecret)
+	})
+	if errDelete != nil {
+		return errors.Wrapf(errDelete, "Error deleting secret with ID of %d in embedded store", id)
+	}
+
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository.Delete(ctx, id, forceDelete)
+	}
+
+	return nil
+}
+
+// put Writes the secret, taken UID is reported the same way as taken name is
+func (m EmbeddedRepository) put(tx *bbolt.Tx, secret Secret) error {
+	errPut := EmbeddedTable.Put(tx, secret)
+	if errors.Is(errPut, embedded.ErrUniqueViolation) {
+		return errors.Wrapf(apperror.ErrAlreadyExists, "Secret with UID of %s", secret.UID)
+	}
+	return errPut
+}
+
+// candidates Secrets which may match the parameters, narrowed down by the first index the parameters allow to use
+func (m EmbeddedRepository) candidates(tx *bbolt.Tx, params ListSecretParams) ([]Secret, error) {
+	var results []Secret
+	var found = make(map[uint]bool)
+	switch {
+	case len(params.IDs) > 0:
+		{
+			for _, id := range params.IDs {
+				secret, errGetSecret := EmbeddedTable.Get(tx, id)
+				if errGetSecret != nil {
+					return nil, errGetSecret
+				}
+				if secret != nil && !found[secret.ID] {
+					found[secret.ID] = true
+					results = append(results, *secret)
+				}
+			}
+			return results, nil
+		}
+	case len(params.UIDs) > 0:
+		{
+			for _, uid := range params.UIDs {
+				secrets, errFindSecrets := EmbeddedTable.Find(tx, EmbeddedIndexUID, []byte(uid))
+				if errFindSecrets != nil {
+					return nil, errFindSecrets
+				}
+				for _, secret := range secrets {
+					if !found[secret.ID] {
+						found[secret.ID] = true
+						results = append(results, secret)
+					}
+				}
+			}
+			return results, nil
+		}
+	case len(params.FolderIDs) > 0:
+		{
+			for _, folderID := range params.FolderIDs {
+				secrets, errFindSecrets := EmbeddedTable.Find(tx, EmbeddedIndexFolder, embedded.Key(folderID))
+				if errFindSecrets != nil {
+					return nil, errFindSecrets
+				}
+				for _, secret := range secrets {
+					if !found[secret.ID] {
+						found[secret.ID] = true
+						results = append(results, secret)
+					}
+				}
+			}
+			return results, nil
+		}
+	case len(embedded.PatternPrefix(params.Name)) > 0:
+		{
+			return EmbeddedTable.Find(tx, EmbeddedIndexName, embedded.PatternPrefix(params.Name))
+		}
+	default:
+		{
+			return EmbeddedTable.All(tx)
+		}
+	}
+}
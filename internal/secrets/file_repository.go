@@ -1,7 +1,6 @@
 package secrets
 
 import (
-	"bytes"
 	"context"
 	"encoding/gob"
 	"encoding/json"
@@ -14,6 +13,10 @@ import (
 	"hideout/internal/common/ordering"
 	"hideout/internal/common/pagination"
 	"hideout/internal/pkg/extra"
+	"hideout/internal/pkg/journal"
+	"hideout/internal/pkg/safefile"
+	"hideout/internal/pkg/watcher"
+	"io"
 	"os"
 )
 
@@ -48,6 +51,12 @@ func (m FileRepository) GetID(ctx context.Context) (uint, error) {
 }
 
 func (m FileRepository) Load(ctx context.Context) ([]Secret, error) {
+	lock, errLock := safefile.LockShared(m.Filename)
+	if errLock != nil {
+		return nil, errLock
+	}
+	defer lock.Unlock()
+
 	var secrets []Secret
 	errDecode := m.decode(&secrets)
 	return secrets, errDecode
@@ -152,11 +161,18 @@ func (m FileRepository) GetByUID(ctx context.Context, uid string) (*Secret, erro
 }
 
 func (m FileRepository) Update(ctx context.Context, secret Secret) (*Secret, error) {
+	lock, errLock := safefile.LockExclusive(m.Filename)
+	if errLock != nil {
+		return nil, errLock
+	}
+	defer lock.Unlock()
+
 	var inMemoryRepository = m.inMemoryRepository
 	if inMemoryRepository == nil {
-		secrets, errLoadSecrets := m.Load(ctx)
-		if errLoadSecrets != nil {
-			return nil, errLoadSecrets
+		var secrets []Secret
+		errDecode := m.decode(&secrets)
+		if errDecode != nil {
+			return nil, errDecode
 		}
 		inMemoryRepository = NewInMemoryRepository(&secrets)
 	}
@@ -165,53 +181,36 @@ func (m FileRepository) Update(ctx context.Context, secret Secret) (*Secret, err
 	if errUpdateSecret != nil {
 		return nil, errUpdateSecret
 	}
-	secretPtrs, errGetSecrets := inMemoryRepository.Get(ctx, ListSecretParams{ListParams: generics.ListParams{Deleted: model.YesOrNo}})
-	if errGetSecrets != nil {
-		return nil, errGetSecrets
-	}
-	var secrets []Secret
-	for _, secretPtr := range secretPtrs {
-		secrets = append(secrets, *secretPtr)
-	}
-	return updatedSecret, m.encode(&secrets)
+	return updatedSecret, m.write(journal.Put(*updatedSecret))
 }
 
 func (m FileRepository) Create(ctx context.Context, secret Secret) (*Secret, error) {
-	if m.inMemoryRepository != nil {
-		createdSecret, errCreateSecret := m.inMemoryRepository.Create(ctx, secret)
-		if errCreateSecret != nil {
-			return nil, errors.Wrapf(errCreateSecret, "Error creating secret with ID of %d in memory", secret.ID)
-		}
+	lock, errLock := safefile.LockExclusive(m.Filename)
+	if errLock != nil {
+		return nil, errLock
+	}
+	defer lock.Unlock()
 
-		secretPtrs, errGetSecrets := m.inMemoryRepository.Get(ctx, ListSecretParams{ListParams: generics.ListParams{Deleted: model.YesOrNo}})
-		if errGetSecrets != nil {
-			return nil, errGetSecrets
-		}
+	var inMemoryRepository = m.inMemoryRepository
+	if inMemoryRepository == nil {
+		// Done this way because file may have a duplicate entry and needs to be
+		// loaded to check
 		var secrets []Secret
-		for _, secretPtr := range secretPtrs {
-			secrets = append(secrets, *secretPtr)
+		errDecode := m.decode(&secrets)
+		if errDecode != nil {
+			return nil, errDecode
 		}
-		errEncode := m.encode(&secrets)
-		return createdSecret, errEncode
-	}
-
-	// Done this way because file may have a duplicate entry and needs to be
-	// loaded to check
-	var secrets []Secret
-	errDecode := m.decode(&secrets)
-	if errDecode != nil {
-		return nil, errDecode
+		inMemoryRepository = NewInMemoryRepository(&secrets)
 	}
 
-	inMemoryRepository := NewInMemoryRepository(&secrets)
 	createdSecret, errCreateSecret := inMemoryRepository.Create(ctx, secret)
 	if errCreateSecret != nil {
 		return nil, errors.Wrapf(errCreateSecret, "Error creating secret with ID of %d in memory", secret.ID)
 	}
 
-	errEncode := m.encode(&secrets)
-	if errEncode != nil {
-		return nil, errEncode
+	errWrite := m.write(journal.Put(*createdSecret))
+	if errWrite != nil {
+		return nil, errWrite
 	}
 
 	return createdSecret, nil
@@ -235,11 +234,18 @@ func (m FileRepository) Count(ctx context.Context, params ListSecretParams) (uin
 }
 
 func (m FileRepository) Delete(ctx context.Context, id uint, forceDelete bool) error {
+	lock, errLock := safefile.LockExclusive(m.Filename)
+	if errLock != nil {
+		return errLock
+	}
+	defer lock.Unlock()
+
 	var inMemoryRepository = m.inMemoryRepository
 	if inMemoryRepository == nil {
-		secrets, errLoadSecrets := m.Load(ctx)
-		if errLoadSecrets != nil {
-			return errLoadSecrets
+		var secrets []Secret
+		errDecode := m.decode(&secrets)
+		if errDecode != nil {
+			return errDecode
 		}
 		inMemoryRepository = NewInMemoryRepository(&secrets)
 	}
@@ -248,24 +254,112 @@ func (m FileRepository) Delete(ctx context.Context, id uint, forceDelete bool) e
 	if errDelete != nil {
 		return errDelete
 	}
+	if forceDelete {
+		return m.write(journal.Remove[Secret](id))
+	}
+
+	// Soft deleted secret is kept along with its deletion date
+	results, errGetResults := inMemoryRepository.GetMapByID(ctx, ListSecretParams{
+		ListParams: generics.ListParams{IDs: []uint{id}, Deleted: model.YesOrNo},
+	})
+	if errGetResults != nil {
+		return errGetResults
+	}
+	deletedSecret, exists := results[id]
+	if !exists {
+		return apperror.ErrRecordNotFound
+	}
+	return m.write(journal.Put(*deletedSecret))
+}
+
+// Recover Brings the file into consistent state after a crash: removes temporary files of interrupted writes and
+// merges journal (dropping its incomplete entry) into the file. Meant to be called on startup
+func (m FileRepository) Recover(ctx context.Context) error {
+	lock, errLock := safefile.LockExclusive(m.Filename)
+	if errLock != nil {
+		return errLock
+	}
+	defer lock.Unlock()
 
-	secretPtrs, errGetSecrets := inMemoryRepository.Get(ctx, ListSecretParams{ListParams: generics.ListParams{Deleted: model.YesOrNo}})
-	if errGetSecrets != nil {
-		return errGetSecrets
+	errClean := safefile.CleanTemp(m.Filename)
+	if errClean != nil {
+		return errClean
+	}
+	journalSize, errSize := m.journal().Size()
+	if errSize != nil {
+		return errSize
 	}
+	if journalSize == 0 {
+		return nil
+	}
+	return m.compact()
+}
+
+// Watch Reloads preloaded secrets whenever the file (or its journal) is changed on disk, e.g. by another tool, while the
+// returned watcher runs. Invalid secrets are handed to onError instead, reloads loaded before the latest local write
+// are dropped, as that write changes the file once again
+func (m FileRepository) Watch(ctx context.Context, onError func(errLoad error)) (*watcher.Watcher, error) {
+	fileNames := []string{m.Filename, m.journal().FileName}
+	return watcher.New(fileNames, func() {
+		generation := m.inMemoryRepository.Generation()
+		secrets, errLoad := m.Load(ctx)
+		if errLoad == nil {
+			errLoad = Validate(secrets)
+		}
+		if errLoad != nil {
+			onError(errLoad)
+			return
+		}
+		m.inMemoryRepository.Replace(secrets, generation)
+	}, onError)
+}
+
+func (m FileRepository) journal() journal.Journal[Secret] {
+	return journal.New[Secret](m.Filename)
+}
+
+// write Appends changes to journal instead of rewriting the file, journal is merged into the file once it grows
+// large. Must only be called while holding exclusive lock of the file
+func (m FileRepository) write(entries ...journal.Entry[Secret]) error {
+	fileJournal := m.journal()
+	errAppend := fileJournal.Append(entries...)
+	if errAppend != nil {
+		return errAppend
+	}
+
+	journalSize, errSize := fileJournal.Size()
+	if errSize != nil {
+		return errSize
+	}
+	if journalSize < journal.CompactSize {
+		return nil
+	}
+	return m.compact()
+}
+
+// compact Merges journal into the file. Journal is reset only once the file is replaced, as replaying it again is
+// harmless. Must only be called while holding exclusive lock of the file
+func (m FileRepository) compact() error {
 	var secrets []Secret
-	for _, secretPtr := range secretPtrs {
-		secrets = append(secrets, *secretPtr)
+	errDecode := m.decode(&secrets)
+	if errDecode != nil {
+		return errDecode
 	}
-	return m.encode(&secrets)
+	errEncode := m.encode(&secrets)
+	if errEncode != nil {
+		return errEncode
+	}
+	return m.journal().Reset()
 }
 
+// encode Replaces the file atomically, so that a crash never leaves it partially written
 func (m FileRepository) encode(data *[]Secret) error {
-	fileWriter, errOpenFile := os.OpenFile(m.Filename, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0644)
-	if errOpenFile != nil {
-		return errOpenFile
-	}
-	defer fileWriter.Close()
+	return safefile.WriteAtomic(m.Filename, safefile.Sync_Always, func(fileWriter io.Writer) error {
+		return m.encodeTo(fileWriter, data)
+	})
+}
+
+func (m FileRepository) encodeTo(fileWriter io.Writer, data *[]Secret) error {
 	switch m.EncodingType {
 	case extra.Encoding_JSON:
 		{
@@ -275,7 +369,23 @@ func (m FileRepository) encode(data *[]Secret) error {
 		}
 	case extra.Encoding_CSV:
 		{
-			return gocsv.UnmarshalFile(fileWriter, data)
+			return gocsv.Marshal(data, fileWriter)
+		}
+	case extra.Encoding_Binary:
+		{
+			return encodeBinary(fileWriter, *data)
+		}
+	case extra.Encoding_Archive:
+		{
+			return encodeArchive(fileWriter, *data)
+		}
+	case extra.Encoding_YAML:
+		{
+			return encodeYAML(fileWriter, *data)
+		}
+	case extra.Encoding_TOML:
+		{
+			return encodeTOML(fileWriter, *data)
 		}
 	case extra.Encoding_GOB:
 		{
@@ -296,7 +406,25 @@ func (m FileRepository) encode(data *[]Secret) error {
 	return apperror.ErrNotImplemented
 }
 
+// decode Reads the file along with changes journaled since it was written. Must only be called while holding lock of
+// the file
 func (m FileRepository) decode(data *[]Secret) error {
+	errDecodeFile := m.decodeFile(data)
+	if errDecodeFile != nil {
+		return errDecodeFile
+	}
+
+	entries, errRead := m.journal().Read()
+	if errRead != nil {
+		return errRead
+	}
+	*data = journal.Replay(*data, entries, func(secret Secret) uint {
+		return secret.ID
+	})
+	return nil
+}
+
+func (m FileRepository) decodeFile(data *[]Secret) error {
 	_, errFileExists := os.Stat(m.Filename)
 	if errors.Is(errFileExists, os.ErrNotExist) {
 		return nil
@@ -316,7 +444,31 @@ func (m FileRepository) decode(data *[]Secret) error {
 		}
 	case extra.Encoding_CSV:
 		{
-			return gocsv.MarshalFile(data, fileReader)
+			return gocsv.Unmarshal(fileReader, data)
+		}
+	case extra.Encoding_Binary:
+		{
+			decoded, errDecode := decodeBinary(fileReader)
+			*data = decoded
+			return errDecode
+		}
+	case extra.Encoding_Archive:
+		{
+			decoded, errDecode := decodeArchive(fileReader)
+			*data = decoded
+			return errDecode
+		}
+	case extra.Encoding_YAML:
+		{
+			decoded, errDecode := decodeYAML(fileReader)
+			*data = decoded
+			return errDecode
+		}
+	case extra.Encoding_TOML:
+		{
+			decoded, errDecode := decodeTOML(fileReader)
+			*data = decoded
+			return errDecode
 		}
 	case extra.Encoding_GOB:
 		{
@@ -325,13 +477,19 @@ func (m FileRepository) decode(data *[]Secret) error {
 		}
 	case extra.Encoding_XML:
 		{
-			buf := new(bytes.Buffer)
-			_, errRead := buf.ReadFrom(fileReader)
-			if errRead != nil {
-				return errRead
+			// Records are written as sibling elements, each of which is decoded separately
+			decoder := xml.NewDecoder(fileReader)
+			for {
+				var secret Secret
+				errDecode := decoder.Decode(&secret)
+				if errors.Is(errDecode, io.EOF) {
+					return nil
+				}
+				if errDecode != nil {
+					return errDecode
+				}
+				*data = append(*data, secret)
 			}
-
-			return xml.Unmarshal(buf.Bytes(), data)
 		}
 	}
 
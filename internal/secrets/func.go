@@ -1,12 +1,27 @@
 package secrets
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
+	"hideout/internal/common/apperror"
+	"hideout/internal/common/generics"
 	"hideout/internal/common/model"
+	"hideout/internal/pkg/archive"
+	"hideout/internal/pkg/binfile"
+	"hideout/internal/pkg/extra"
+	"io"
+	"maps"
+	"path"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 )
 
 func (params ListSecretParams) DatabaseFilter(TableName string, Query *gorm.DB) *gorm.DB {
@@ -16,6 +31,15 @@ func (params ListSecretParams) DatabaseFilter(TableName string, Query *gorm.DB)
 	if len(params.UIDs) != 0 {
 		Query = Query.Where(TableName+".uid IN (?)", params.UIDs)
 	}
+	if len(params.FolderIDs) != 0 {
+		Query = Query.Where(TableName+".folder_id IN (?)", params.FolderIDs)
+	}
+	if params.Name != "" {
+		Query = Query.Where(TableName+".name LIKE ?", extra.GlobToLike(params.Name))
+	}
+	if len(params.Types) != 0 {
+		Query = Query.Where(TableName+".type IN (?)", params.Types)
+	}
 
 	if params.Pagination.Page != 0 {
 		Query = Query.Offset(int(params.Pagination.Offset()))
@@ -32,6 +56,16 @@ func (params ListSecretParams) DatabaseFilter(TableName string, Query *gorm.DB)
 		Query = Query.Where(TableName+".updated_at BETWEEN ? AND ?", params.UpdatedAt.From.UTC(), params.UpdatedAt.To.UTC())
 	}
 
+	if !params.ExpiresAt.IsZero() {
+		Query = Query.Where(TableName + ".expires_at IS NOT NULL")
+		if !params.ExpiresAt.From.IsZero() {
+			Query = Query.Where(TableName+".expires_at >= ?", params.ExpiresAt.From.UTC())
+		}
+		if !params.ExpiresAt.To.IsZero() {
+			Query = Query.Where(TableName+".expires_at <= ?", params.ExpiresAt.To.UTC())
+		}
+	}
+
 	if params.Deleted == model.Yes {
 		Query = Query.Unscoped().Where(TableName + ".deleted_at IS NOT NULL")
 		if !params.DeletedAt.IsZero() {
@@ -54,10 +88,17 @@ func (params ListSecretParams) DatabaseOrder(TableName string, Query *gorm.DB, O
 			orderDirectionVal = "asc"
 		}
 		orderColumn, orderColumnExists := OrderMap[order.OrderBy]
+		// Secrets which never expire come last in both directions
+		if orderColumn == "expires_at" {
+			orderDirectionVal += " nulls last"
+		}
 		if orderColumnExists {
 			results = append(results, fmt.Sprintf("%s.%s %s", TableName, orderColumn, orderDirectionVal))
 		}
 	}
+	if len(results) != 0 {
+		results = append(results, fmt.Sprintf("%s.id asc", TableName))
+	}
 
 	return Query.Order(strings.Join(results, ", "))
 }
@@ -92,6 +133,26 @@ func (params ListSecretParams) Apply(data map[string][]*Secret) (results map[str
 	return results
 }
 
+// IsExpired tells whether secret has an expiration date set which has already passed
+func (s Secret) IsExpired(now time.Time) bool {
+	return s.ExpiresAt.Valid && !s.ExpiresAt.Time.After(now)
+}
+
+// ExpiresWithin tells whether secret has an expiration date set which falls into the window
+func (s Secret) ExpiresWithin(window generics.FromTo[time.Time]) bool {
+	if !s.ExpiresAt.Valid {
+		return false
+	}
+	if !window.From.IsZero() && s.ExpiresAt.Time.Before(window.From) {
+		return false
+	}
+	if !window.To.IsZero() && s.ExpiresAt.Time.After(window.To) {
+		return false
+	}
+
+	return true
+}
+
 type lessFunc func(p1, p2 *Secret) bool
 
 // Sort sorts the argument slice according to the less functions passed to OrderedBy.
@@ -142,3 +203,219 @@ func (ms *multiSorter) Less(i, j int) bool {
 	// the final comparison reports.
 	return ms.less[k](p, q)
 }
+
+// IsSealed tells whether secret value must never leave the storage
+func (s Secret) IsSealed() bool {
+	return slices.Contains(SealedTypes, s.Type)
+}
+
+// IsHidden tells whether secret value must not be returned by generic secrets endpoints and exports
+func (s Secret) IsHidden() bool {
+	return s.IsSealed() || slices.Contains(HiddenTypes, s.Type)
+}
+
+// VisibleValue Value of the secret safe to be returned to the client (empty for sealed and hidden secrets)
+func (s Secret) VisibleValue() string {
+	if s.IsHidden() {
+		return ""
+	}
+	return s.Value
+}
+
+// encodeBinary Writes secrets in compact binary file layout
+func encodeBinary(writer io.Writer, secrets []Secret) error {
+	encoder := binfile.NewEncoder()
+	for _, secret := range secrets {
+		secret.Model.EncodeBinary(encoder)
+		encoder.Uint(uint64(secret.FolderID))
+		encoder.String(secret.UID)
+		encoder.String(secret.Name)
+		encoder.String(secret.Value)
+		encoder.String(secret.Script)
+		encoder.String(secret.Type)
+		encoder.NullTime(secret.ExpiresAt)
+	}
+	return binfile.Write(writer, BinaryKind, len(secrets), encoder)
+}
+
+// decodeBinary Reads secrets written in compact binary file layout
+func decodeBinary(reader io.Reader) ([]Secret, error) {
+	decoder, count, errRead := binfile.Read(reader, BinaryKind)
+	if errRead != nil {
+		return nil, errRead
+	}
+
+	secrets := make([]Secret, 0, decoder.Capacity(count))
+	for i := 0; i < count && decoder.Err() == nil; i++ {
+		secrets = append(secrets, Secret{
+			Model:     model.DecodeBinary(decoder),
+			FolderID:  uint(decoder.Uint()),
+			UID:       decoder.String(),
+			Name:      decoder.String(),
+			Value:     decoder.String(),
+			Script:    decoder.String(),
+			Type:      decoder.String(),
+			ExpiresAt: decoder.NullTime(),
+		})
+	}
+	if errDecode := decoder.Finish(); errDecode != nil {
+		return nil, errDecode
+	}
+	return secrets, nil
+}
+
+// encodeArchive Writes secrets into compressed tarball as one JSON file per secret
+func encodeArchive(writer io.Writer, secrets []Secret) error {
+	files := make([]archive.File, 0, len(secrets))
+	for _, secret := range secrets {
+		data, errMarshal := json.MarshalIndent(secret, "", " ")
+		if errMarshal != nil {
+			return errMarshal
+		}
+		files = append(files, archive.Bytes(path.Join(ArchiveDirectory, fmt.Sprintf("%d.json", secret.ID)), data))
+	}
+	return archive.Pack(context.Background(), writer, files, ArchiveFormat)
+}
+
+// decodeArchive Reads secrets from compressed tarball, ordered by their identifiers. Any other file means that the
+// archive is not a file of secrets, only the archive with no files at all is empty (as written for no secrets)
+func decodeArchive(reader io.Reader) ([]Secret, error) {
+	files, errExtract := archive.ExtractFormat(context.Background(), reader, ArchiveFormat, archive.DefaultSizeLimit)
+	if errExtract != nil {
+		return nil, errExtract
+	}
+
+	secrets := make([]Secret, 0, len(files))
+	for name, data := range files {
+		if path.Dir(name) != ArchiveDirectory || path.Ext(name) != ".json" {
+			return nil, errors.Wrapf(ErrArchiveUnexpectedFile, "File %s", name)
+		}
+		var secret Secret
+		if errUnmarshal := json.Unmarshal(data, &secret); errUnmarshal != nil {
+			return nil, errors.Wrapf(errUnmarshal, "Failed to decode %s", name)
+		}
+		secrets = append(secrets, secret)
+	}
+	slices.SortFunc(secrets, func(a, b Secret) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return secrets, nil
+}
+
+// encodeYAML Writes secrets in human-editable YAML layout
+func encodeYAML(writer io.Writer, secrets []Secret) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if errEncode := encoder.Encode(nestSecrets(secrets)); errEncode != nil {
+		return errEncode
+	}
+	return encoder.Close()
+}
+
+// decodeYAML Reads secrets written in human-editable YAML layout
+func decodeYAML(reader io.Reader) ([]Secret, error) {
+	var file fileSecrets
+	// Empty file holds no secrets
+	if errDecode := yaml.NewDecoder(reader).Decode(&file); errDecode != nil && !errors.Is(errDecode, io.EOF) {
+		return nil, errDecode
+	}
+	return flattenSecrets(file), nil
+}
+
+// encodeTOML Writes secrets in human-editable TOML layout
+func encodeTOML(writer io.Writer, secrets []Secret) error {
+	return toml.NewEncoder(writer).Encode(nestSecrets(secrets))
+}
+
+// decodeTOML Reads secrets written in human-editable TOML layout
+func decodeTOML(reader io.Reader) ([]Secret, error) {
+	var file fileSecrets
+	if _, errDecode := toml.NewDecoder(reader).Decode(&file); errDecode != nil {
+		return nil, errDecode
+	}
+	return flattenSecrets(file), nil
+}
+
+// nestSecrets Groups secrets by their folders, both ordered by their identifiers
+func nestSecrets(secrets []Secret) fileSecrets {
+	var groups = make(map[uint][]fileSecret)
+	for _, secret := range secrets {
+		groups[secret.FolderID] = append(groups[secret.FolderID], fileSecret{ID: secret.ID, UID: secret.UID,
+			Name: secret.Name, Value: secret.Value, Script: secret.Script, Type: secret.Type, CreatedAt: secret.CreatedAt,
+			UpdatedAt: secret.UpdatedAt, DeletedAt: model.NullTimeToPtr(secret.DeletedAt),
+			ExpiresAt: model.NullTimeToPtr(secret.ExpiresAt)})
+	}
+
+	var file = fileSecrets{Folders: make([]fileSecretsFolder, 0, len(groups))}
+	for _, folderID := range slices.Sorted(maps.Keys(groups)) {
+		group := groups[folderID]
+		slices.SortStableFunc(group, func(a, b fileSecret) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+		file.Folders = append(file.Folders, fileSecretsFolder{FolderID: folderID, Secrets: group})
+	}
+	return file
+}
+
+// flattenSecrets Secrets grouped by their folders, ordered by their identifiers
+func flattenSecrets(file fileSecrets) []Secret {
+	var secrets []Secret
+	for _, folder := range file.Folders {
+		for _, secret := range folder.Secrets {
+			secrets = append(secrets, Secret{
+				Model: model.Model{ID: secret.ID, CreatedAt: secret.CreatedAt, UpdatedAt: secret.UpdatedAt,
+					DeletedAt: model.PtrToNullTime(secret.DeletedAt)},
+				FolderID:  folder.FolderID,
+				UID:       secret.UID,
+				Name:      secret.Name,
+				Value:     secret.Value,
+				Script:    secret.Script,
+				Type:      secret.Type,
+				ExpiresAt: model.PtrToNullTime(secret.ExpiresAt),
+			})
+		}
+	}
+
+	slices.SortStableFunc(secrets, func(a, b Secret) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return secrets
+}
+
+// Validate Checks secrets read from outside (e.g. from a file edited by hand) hold together the way repositories keep
+// them: identifiers are set and unique and names are unique within their folder (deleted secrets aside)
+func Validate(secrets []Secret) error {
+	var ids = make(map[uint]bool, len(secrets))
+	var uids = make(map[string]bool, len(secrets))
+	var names = make(map[uint]map[string]bool)
+	for _, secret := range secrets {
+		if secret.ID == 0 {
+			return errors.Wrapf(apperror.ErrInvalidParameter, "Secret %q has no ID", secret.Name)
+		}
+		if secret.UID == "" {
+			return errors.Wrapf(apperror.ErrInvalidParameter, "Secret with ID of %d has no UID", secret.ID)
+		}
+		if ids[secret.ID] {
+			return errors.Wrapf(apperror.ErrAlreadyExists, "Secret with ID of %d is duplicated", secret.ID)
+		}
+		if uids[secret.UID] {
+			return errors.Wrapf(apperror.ErrAlreadyExists, "Secret with UID of %s is duplicated", secret.UID)
+		}
+		ids[secret.ID] = true
+		uids[secret.UID] = true
+
+		if secret.DeletedAt.Valid {
+			continue
+		}
+		if names[secret.FolderID] == nil {
+			names[secret.FolderID] = make(map[string]bool)
+		}
+		if names[secret.FolderID][secret.Name] {
+			return errors.Wrapf(apperror.ErrAlreadyExists, "Secret %q is duplicated in folder with ID of %d", secret.Name,
+				secret.FolderID)
+		}
+		names[secret.FolderID][secret.Name] = true
+	}
+
+	return nil
+}
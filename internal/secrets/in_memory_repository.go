@@ -15,11 +15,43 @@ import (
 )
 
 type InMemoryRepository struct {
-	conn *[]Secret
+	conn    *[]Secret
+	preload *model.Preload
 }
 
 func NewInMemoryRepository(conn *[]Secret) *InMemoryRepository {
-	return &InMemoryRepository{conn: conn}
+	return &InMemoryRepository{conn: conn, preload: &model.Preload{}}
+}
+
+// NewPreloadedInMemoryRepository Repository of preloaded secrets, guarded by the preload lock shared with others
+func NewPreloadedInMemoryRepository(conn *[]Secret, preload *model.Preload) *InMemoryRepository {
+	return &InMemoryRepository{conn: conn, preload: preload}
+}
+
+// Generation Number of local writes made to preloaded secrets so far
+func (m InMemoryRepository) Generation() uint64 {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	return m.preload.Generation
+}
+
+// Replace Replaces preloaded secrets with reloaded ones, unless a local write was made since their generation
+func (m InMemoryRepository) Replace(secrets []Secret, generation uint64) bool {
+	m.preload.Lock()
+	defer m.preload.Unlock()
+	if m.preload.Generation != generation {
+		return false
+	}
+	*m.conn = secrets
+	return true
+}
+
+// Swap Replaces preloaded secrets with the ones written locally, which counts as a local write
+func (m InMemoryRepository) Swap(secrets []Secret) {
+	m.preload.Lock()
+	defer m.preload.Unlock()
+	m.preload.Generation++
+	*m.conn = secrets
 }
 
 func (m InMemoryRepository) Load(ctx context.Context) ([]Secret, error) {
@@ -27,6 +59,8 @@ func (m InMemoryRepository) Load(ctx context.Context) ([]Secret, error) {
 }
 
 func (m InMemoryRepository) GetID(ctx context.Context) (uint, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
 	id := uint(0)
 	for _, secretEntry := range *m.conn {
 		if secretEntry.ID > id {
@@ -38,7 +72,9 @@ func (m InMemoryRepository) GetID(ctx context.Context) (uint, error) {
 }
 
 func (m InMemoryRepository) GetMapByID(ctx context.Context, params ListSecretParams) (map[uint]*Secret, error) {
-	secrets, errGetSecrets := m.Get(ctx, params)
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	secrets, errGetSecrets := m.get(ctx, params)
 	if errGetSecrets != nil {
 		return nil, errGetSecrets
 	}
@@ -51,7 +87,9 @@ func (m InMemoryRepository) GetMapByID(ctx context.Context, params ListSecretPar
 }
 
 func (m InMemoryRepository) GetMapByUID(ctx context.Context, params ListSecretParams) (map[string]*Secret, error) {
-	secrets, errGetSecrets := m.Get(ctx, params)
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	secrets, errGetSecrets := m.get(ctx, params)
 	if errGetSecrets != nil {
 		return nil, errGetSecrets
 	}
@@ -64,10 +102,17 @@ func (m InMemoryRepository) GetMapByUID(ctx context.Context, params ListSecretPa
 }
 
 func (m InMemoryRepository) Get(ctx context.Context, params ListSecretParams) ([]*Secret, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	return m.get(ctx, params)
+}
+
+// get Filtered secrets, must only be called while holding the preload lock
+func (m InMemoryRepository) get(ctx context.Context, params ListSecretParams) ([]*Secret, error) {
 	var folderResults []*Secret
 	for _, secretEntry := range *m.conn {
 		if len(params.FolderIDs) > 0 {
-			if slices.Contains(params.FolderIDs, secretEntry.ID) {
+			if slices.Contains(params.FolderIDs, secretEntry.FolderID) {
 				folderResults = append(folderResults, &secretEntry)
 			}
 		} else {
@@ -91,7 +136,7 @@ func (m InMemoryRepository) Get(ctx context.Context, params ListSecretParams) ([
 	}
 
 	var scriptableResults []*Secret
-	for _, folderEntry := range folderResults {
+	for _, folderEntry := range nameResults {
 		if params.Scriptable == model.Yes {
 			if folderEntry.Script != "" {
 				scriptableResults = append(scriptableResults, folderEntry)
@@ -105,7 +150,29 @@ func (m InMemoryRepository) Get(ctx context.Context, params ListSecretParams) ([
 		}
 	}
 
-	filteredResults := m.Filter(ctx, scriptableResults, params.ListParams)
+	var typeResults []*Secret
+	for _, secretEntry := range scriptableResults {
+		if len(params.Types) > 0 {
+			if slices.Contains(params.Types, secretEntry.Type) {
+				typeResults = append(typeResults, secretEntry)
+			}
+		} else {
+			typeResults = append(typeResults, secretEntry)
+		}
+	}
+
+	var expiringResults []*Secret
+	for _, secretEntry := range typeResults {
+		if !params.ExpiresAt.IsZero() {
+			if secretEntry.ExpiresWithin(params.ExpiresAt) {
+				expiringResults = append(expiringResults, secretEntry)
+			}
+		} else {
+			expiringResults = append(expiringResults, secretEntry)
+		}
+	}
+
+	filteredResults := m.Filter(ctx, expiringResults, params.ListParams)
 	if params.Page == 0 && params.PerPage == 0 {
 		return filteredResults, nil
 	}
@@ -115,7 +182,9 @@ func (m InMemoryRepository) Get(ctx context.Context, params ListSecretParams) ([
 }
 
 func (m InMemoryRepository) GetMapByFolder(ctx context.Context, params ListSecretParams) (map[uint][]*Secret, error) {
-	secrets, errGetSecrets := m.Get(ctx, params)
+	m.preload.RLock()
+	defer m.preload.RUnlock()
+	secrets, errGetSecrets := m.get(ctx, params)
 	if errGetSecrets != nil {
 		return nil, errGetSecrets
 	}
@@ -133,6 +202,8 @@ func (m InMemoryRepository) GetMapByFolder(ctx context.Context, params ListSecre
 }
 
 func (m InMemoryRepository) GetByID(ctx context.Context, id uint) (*Secret, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
 	for _, secretEntry := range *m.conn {
 		if secretEntry.ID == id && !secretEntry.DeletedAt.Valid {
 			return &secretEntry, nil
@@ -143,6 +214,8 @@ func (m InMemoryRepository) GetByID(ctx context.Context, id uint) (*Secret, erro
 }
 
 func (m InMemoryRepository) GetByUID(ctx context.Context, uid string) (*Secret, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
 	for _, secretEntry := range *m.conn {
 		if secretEntry.UID == uid && !secretEntry.DeletedAt.Valid {
 			return &secretEntry, nil
@@ -153,14 +226,22 @@ func (m InMemoryRepository) GetByUID(ctx context.Context, uid string) (*Secret,
 }
 
 func (m InMemoryRepository) Update(ctx context.Context, secret Secret) (*Secret, error) {
-	for _, secretEntry := range *m.conn {
+	m.preload.Lock()
+	defer m.preload.Unlock()
+	for secretIndex, secretEntry := range *m.conn {
 		if !secretEntry.DeletedAt.Valid && secretEntry.ID == secret.ID {
+			m.preload.Generation++
+			// Entry is modified in the slice itself, not in a copy made by range
+			secretEntry := &(*m.conn)[secretIndex]
 			secretEntry.FolderID = secret.FolderID
 			secretEntry.Name = secret.Name
 			secretEntry.Value = secret.Value
 			secretEntry.Script = secret.Script
+			secretEntry.Type = secret.Type
+			secretEntry.ExpiresAt = secret.ExpiresAt
 			secretEntry.UpdatedAt = time.Now()
-			return &secretEntry, nil
+			updatedSecret := *secretEntry
+			return &updatedSecret, nil
 		}
 	}
 
@@ -168,6 +249,8 @@ func (m InMemoryRepository) Update(ctx context.Context, secret Secret) (*Secret,
 }
 
 func (m InMemoryRepository) Create(ctx context.Context, secret Secret) (*Secret, error) {
+	m.preload.Lock()
+	defer m.preload.Unlock()
 	for _, secretEntry := range *m.conn {
 		if !secretEntry.DeletedAt.Valid && secretEntry.Name == secret.Name && secretEntry.FolderID == secret.FolderID {
 			return nil, apperror.ErrAlreadyExists
@@ -179,16 +262,19 @@ func (m InMemoryRepository) Create(ctx context.Context, secret Secret) (*Secret,
 	if secret.UID == "" {
 		secret.UID = gofakeit.UUID()
 	}
+	m.preload.Generation++
 	*m.conn = append(*m.conn, secret)
 	return &secret, nil
 }
 
 func (m InMemoryRepository) Count(ctx context.Context, params ListSecretParams) (uint, error) {
+	m.preload.RLock()
+	defer m.preload.RUnlock()
 	// These are not needed when performing filtering and counting
 	params.Pagination = pagination.Pagination{PerPage: 0, Page: 0}
 	params.Order = []ordering.Order{}
 
-	secretsList, errGetSecrets := m.Get(ctx, params)
+	secretsList, errGetSecrets := m.get(ctx, params)
 	if errGetSecrets != nil {
 		return 0, errGetSecrets
 	}
@@ -197,8 +283,11 @@ func (m InMemoryRepository) Count(ctx context.Context, params ListSecretParams)
 }
 
 func (m InMemoryRepository) Delete(ctx context.Context, id uint, forceDelete bool) error {
+	m.preload.Lock()
+	defer m.preload.Unlock()
 	for secretIndex, secretEntry := range *m.conn {
 		if secretEntry.ID == id {
+			m.preload.Generation++
 			if forceDelete {
 				*m.conn = slices.Delete(*m.conn, secretIndex, secretIndex+1)
 			} else {
@@ -327,6 +416,20 @@ func (m InMemoryRepository) Sort(ctx context.Context, data []*Secret, ordering [
 					}
 				})
 			}
+		case "expires_at":
+			{
+				orderParams = append(orderParams, func(p1, p2 *Secret) bool {
+					// Secrets which never expire come last in both directions, like NULLS LAST of the database
+					if p1.ExpiresAt.Valid != p2.ExpiresAt.Valid {
+						return p1.ExpiresAt.Valid
+					}
+					if order.Order {
+						return p1.ExpiresAt.Valid && p1.ExpiresAt.Time.Before(p2.ExpiresAt.Time)
+					} else {
+						return p1.ExpiresAt.Valid && p1.ExpiresAt.Time.After(p2.ExpiresAt.Time)
+					}
+				})
+			}
 		}
 	}
 
@@ -334,6 +437,11 @@ func (m InMemoryRepository) Sort(ctx context.Context, data []*Secret, ordering [
 		orderParams = append(orderParams, func(p1, p2 *Secret) bool {
 			return p1.ID > p2.ID
 		})
+	} else {
+		// Ties are broken by ID, so that order does not depend on the sorting algorithm
+		orderParams = append(orderParams, func(p1, p2 *Secret) bool {
+			return p1.ID < p2.ID
+		})
 	}
 
 	OrderedBy(orderParams...).Sort(data)
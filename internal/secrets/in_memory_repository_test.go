@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"context"
+	"database/sql"
+	"hideout/internal/common/model"
+	"hideout/internal/common/ordering"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortExpiresAt(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	secret := func(id uint, name string, expiresAt time.Time) *Secret {
+		return &Secret{Model: model.Model{ID: id}, Name: name, ExpiresAt: sql.NullTime{Time: expiresAt, Valid: !expiresAt.IsZero()}}
+	}
+	tests := []struct {
+		name     string
+		orders   []ordering.Order
+		expected []uint
+	}{
+		{name: "ascending", orders: []ordering.Order{{OrderBy: "ExpiresAt", Order: true}}, expected: []uint{4, 2, 3, 1, 5, 6}},
+		{name: "descending", orders: []ordering.Order{{OrderBy: "ExpiresAt", Order: false}}, expected: []uint{2, 3, 4, 1, 5, 6}},
+		{name: "then by name", orders: []ordering.Order{{OrderBy: "ExpiresAt", Order: true}, {OrderBy: "Name", Order: true}},
+			expected: []uint{4, 3, 2, 6, 1, 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := []*Secret{secret(5, "C", time.Time{}), secret(3, "A", expiresAt.Add(time.Hour)), secret(1, "B", time.Time{}),
+				secret(2, "B", expiresAt.Add(time.Hour)), secret(6, "A", time.Time{}), secret(4, "D", expiresAt)}
+			var ids []uint
+			for _, sorted := range (InMemoryRepository{}).Sort(context.Background(), data, test.orders) {
+				ids = append(ids, sorted.ID)
+			}
+			if !reflect.DeepEqual(ids, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, ids)
+			}
+		})
+	}
+}
@@ -2,18 +2,22 @@ package secrets
 
 import (
 	"context"
+	"database/sql"
 	"hideout/internal/common/generics"
 	"hideout/internal/common/model"
+	"time"
 )
 
 type (
 	Secret struct {
 		model.Model
-		FolderID uint   `json:"FolderID" bson:"FolderID" xml:"FolderID" yaml:"FolderID" csv:"FolderID" db:"folder_id" gorm:"column:folder_id" description:"Folder unique identifier (link)" example:"0"`
-		UID      string `json:"UID" bson:"UID" xml:"UID" csv:"UID" yaml:"UID" db:"uid" gorm:"column:uid;unique" description:"Secondary unique identifier" example:"abc-def-ghi"`
-		Name     string `json:"Name" bson:"Name" xml:"Name" csv:"Name" yaml:"Name" db:"name" gorm:"column:name" description:"Secret name" example:"DEBUG"`
-		Value    string `json:"Value" bson:"Value" xml:"Value" csv:"Value" yaml:"Value" db:"value" gorm:"column:value" description:"Secret value" example:"Test"`
-		Script   string `json:"Script" bson:"Script" xml:"Script" csv:"Script" yaml:"Script" db:"script" description:"Script for dynamic value" example:"time.RFC3339"`
+		FolderID  uint         `json:"FolderID" bson:"FolderID" xml:"FolderID" yaml:"FolderID" csv:"FolderID" db:"folder_id" gorm:"column:folder_id" description:"Folder unique identifier (link)" example:"0"`
+		UID       string       `json:"UID" bson:"UID" xml:"UID" csv:"UID" yaml:"UID" db:"uid" gorm:"column:uid;unique" description:"Secondary unique identifier" example:"abc-def-ghi"`
+		Name      string       `json:"Name" bson:"Name" xml:"Name" csv:"Name" yaml:"Name" db:"name" gorm:"column:name" description:"Secret name" example:"DEBUG"`
+		Value     string       `json:"Value" bson:"Value" xml:"Value" csv:"Value" yaml:"Value" db:"value" gorm:"column:value" description:"Secret value" example:"Test"`
+		Script    string       `json:"Script" bson:"Script" xml:"Script" csv:"Script" yaml:"Script" db:"script" description:"Script for dynamic value" example:"time.RFC3339"`
+		Type      string       `json:"Type" bson:"Type" xml:"Type" csv:"Type" yaml:"Type" db:"type" gorm:"column:type" description:"Secret type (plain, certificate, private key etc.)" example:""`
+		ExpiresAt sql.NullTime `json:"ExpiresAt" bson:"ExpiresAt" xml:"ExpiresAt" csv:"ExpiresAt" yaml:"ExpiresAt" db:"expires_at" gorm:"column:expires_at" description:"External expiration date of the secret (optional)"`
 	}
 
 	Repository interface {
@@ -36,6 +40,10 @@ type (
 		FolderIDs  []uint
 		Name       string
 		Scriptable uint
+		Types      []string
+		// ExpiresAt limits results to secrets with expiration date set within the window, either
+		// of the bounds may be left empty (e.g. only To is set to find already expired secrets)
+		ExpiresAt generics.FromTo[time.Time]
 	}
 
 	// multiSorter implements the Sort interface, sorting the secrets within.
@@ -43,4 +51,28 @@ type (
 		secrets []*Secret
 		less    []lessFunc
 	}
+
+	// fileSecrets Human-editable layout of YAML and TOML files of secrets, secrets are grouped by their folders
+	fileSecrets struct {
+		Folders []fileSecretsFolder `yaml:"Folders" toml:"Folders"`
+	}
+
+	fileSecretsFolder struct {
+		FolderID uint         `yaml:"FolderID" toml:"FolderID"`
+		Secrets  []fileSecret `yaml:"Secrets" toml:"Secrets"`
+	}
+
+	// fileSecret Secret grouped by its folder, empty optional fields are left out
+	fileSecret struct {
+		ID        uint       `yaml:"ID" toml:"ID"`
+		UID       string     `yaml:"UID" toml:"UID"`
+		Name      string     `yaml:"Name" toml:"Name"`
+		Value     string     `yaml:"Value" toml:"Value"`
+		Script    string     `yaml:"Script,omitempty" toml:"Script,omitempty"`
+		Type      string     `yaml:"Type,omitempty" toml:"Type,omitempty"`
+		CreatedAt time.Time  `yaml:"CreatedAt" toml:"CreatedAt"`
+		UpdatedAt time.Time  `yaml:"UpdatedAt" toml:"UpdatedAt"`
+		DeletedAt *time.Time `yaml:"DeletedAt,omitempty" toml:"DeletedAt,omitempty"`
+		ExpiresAt *time.Time `yaml:"ExpiresAt,omitempty" toml:"ExpiresAt,omitempty"`
+	}
 )
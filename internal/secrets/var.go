@@ -1,6 +1,38 @@
 package secrets
 
+import (
+	"github.com/mholt/archives"
+	"github.com/pkg/errors"
+	"hideout/internal/pkg/embedded"
+)
+
 var (
 	OrderMap = map[string]string{"ID": "id", "FolderID": "folder_id", "UID": "uid", "Name": "name", "Type": "type", "CreatedAt": "created_at",
-		"UpdatedAt": "updated_at", "DeletedAt": "deleted_at"}
+		"UpdatedAt": "updated_at", "DeletedAt": "deleted_at", "ExpiresAt": "expires_at"}
+
+	// SealedTypes Types of secrets which values are only used internally and are never returned nor exported
+	SealedTypes = []string{Type_PKICAPrivateKey, Type_SSHCAPrivateKey, Type_TransitKey}
+	// HiddenTypes Types of secrets which values are not returned nor exported, but can be revealed by their engines
+	HiddenTypes = []string{Type_TOTP, Type_DatabaseConnection, Type_Lease}
+)
+
+var (
+	// ArchiveFormat Format of archive file of secrets
+	ArchiveFormat = archives.CompressedArchive{Archival: archives.Tar{}, Extraction: archives.Tar{}, Compression: archives.Gz{}}
+
+	// ErrArchiveUnexpectedFile File of archive file of secrets which is not one of them, the archive was not written as one
+	ErrArchiveUnexpectedFile = errors.New("Unexpected file in archive of secrets")
+)
+
+var (
+	// EmbeddedTable Secrets in embedded key-value store, indexed by UID, folder and name
+	EmbeddedTable = embedded.Table[Secret]{
+		Bucket: TableName,
+		ID:     func(secret Secret) uint { return secret.ID },
+		Indexes: []embedded.Index[Secret]{
+			{Bucket: EmbeddedIndexUID, Unique: true, Value: func(secret Secret) []byte { return []byte(secret.UID) }},
+			{Bucket: EmbeddedIndexFolder, Value: func(secret Secret) []byte { return embedded.Key(secret.FolderID) }},
+			{Bucket: EmbeddedIndexName, Value: func(secret Secret) []byte { return []byte(secret.Name) }},
+		},
+	}
 )
@@ -0,0 +1,14 @@
+package store
+
+import "hideout/internal/pkg/safefile"
+
+const (
+	// SchemaVersion Version of the document written, documents of newer versions are rejected
+	SchemaVersion = 1
+)
+
+const (
+	Sync_Always = safefile.Sync_Always
+	Sync_File   = safefile.Sync_File
+	Sync_Never  = safefile.Sync_Never
+)
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"hideout/internal/folders"
+)
+
+func NewFoldersRepository(store *Store, inMemoryRep *folders.InMemoryRepository) FoldersRepository {
+	return FoldersRepository{store: store, inMemoryRepository: inMemoryRep}
+}
+
+// repository Folders to read from, either preloaded ones or the ones currently in store
+func (m FoldersRepository) repository() (*folders.InMemoryRepository, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository, nil
+	}
+
+	document, errLoad := m.store.Load()
+	if errLoad != nil {
+		return nil, errors.Wrap(errLoad, "Failed to load folders from store")
+	}
+	return folders.NewInMemoryRepository(&document.Folders), nil
+}
+
+// update Applies changes to folders and writes them into store along with secrets currently in store. Changes
+// are applied to a copy of preloaded folders, which replaces them only once the store is written
+func (m FoldersRepository) update(ctx context.Context, apply func(repository *folders.InMemoryRepository) error) error {
+	if m.inMemoryRepository == nil {
+		return m.store.Update(func(document *Document) error {
+			return apply(folders.NewInMemoryRepository(&document.Folders))
+		})
+	}
+
+	return m.store.update(func(document *Document) error {
+		preloaded, errGetFolders := allFolders(ctx, m.inMemoryRepository)
+		if errGetFolders != nil {
+			return errGetFolders
+		}
+		if errApply := apply(folders.NewInMemoryRepository(&preloaded)); errApply != nil {
+			return errApply
+		}
+		document.Folders = preloaded
+		return nil
+	}, func(document Document) {
+		m.inMemoryRepository.Swap(document.Folders)
+	})
+}
+
+func (m FoldersRepository) Load(ctx context.Context) ([]folders.Folder, error) {
+	document, errLoad := m.store.Load()
+	if errLoad != nil {
+		return nil, errLoad
+	}
+	return document.Folders, nil
+}
+
+func (m FoldersRepository) GetID(ctx context.Context) (uint, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return 0, errRepository
+	}
+	return repository.GetID(ctx)
+}
+
+func (m FoldersRepository) Get(ctx context.Context, params folders.ListFolderParams) ([]*folders.Folder, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.Get(ctx, params)
+}
+
+func (m FoldersRepository) GetMapByID(ctx context.Context, params folders.ListFolderParams) (map[uint]*folders.Folder, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetMapByID(ctx, params)
+}
+
+func (m FoldersRepository) GetMapByUID(ctx context.Context, params folders.ListFolderParams) (map[string]*folders.Folder, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetMapByUID(ctx, params)
+}
+
+func (m FoldersRepository) GetByID(ctx context.Context, id uint) (*folders.Folder, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetByID(ctx, id)
+}
+
+func (m FoldersRepository) GetByUID(ctx context.Context, uid string) (*folders.Folder, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetByUID(ctx, uid)
+}
+
+func (m FoldersRepository) Count(ctx context.Context, params folders.ListFolderParams) (uint, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return 0, errRepository
+	}
+	return repository.Count(ctx, params)
+}
+
+func (m FoldersRepository) Update(ctx context.Context, folder folders.Folder) (*folders.Folder, error) {
+	var updatedFolder *folders.Folder
+	errUpdate := m.update(ctx, func(repository *folders.InMemoryRepository) error {
+		var errUpdateFolder error
+		updatedFolder, errUpdateFolder = repository.Update(ctx, folder)
+		return errUpdateFolder
+	})
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+	return updatedFolder, nil
+}
+
+func (m FoldersRepository) Create(ctx context.Context, folder folders.Folder) (*folders.Folder, error) {
+	var createdFolder *folders.Folder
+	errUpdate := m.update(ctx, func(repository *folders.InMemoryRepository) error {
+		var errCreateFolder error
+		createdFolder, errCreateFolder = repository.Create(ctx, folder)
+		return errors.Wrapf(errCreateFolder, "Error creating folder with ID of %d in memory", folder.ID)
+	})
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+	return createdFolder, nil
+}
+
+func (m FoldersRepository) Delete(ctx context.Context, id uint, forceDelete bool) error {
+	return m.update(ctx, func(repository *folders.InMemoryRepository) error {
+		return repository.Delete(ctx, id, forceDelete)
+	})
+}
@@ -0,0 +1,264 @@
+package store
+
+import (
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/pkg/errors"
+	"hideout/internal/common/generics"
+	"hideout/internal/common/model"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/extra"
+	"hideout/internal/pkg/safefile"
+	"hideout/internal/pkg/watcher"
+	"hideout/internal/secrets"
+	"io"
+	"os"
+	"slices"
+)
+
+// NewStore Store kept in the file, which is created on the first change
+func NewStore(fileName string, encodingType uint, syncPolicy uint) (*Store, error) {
+	if !slices.Contains(Encodings, encodingType) {
+		return nil, ErrUnsupportedEncoding
+	}
+	return &Store{FileName: fileName, EncodingType: encodingType, SyncPolicy: syncPolicy}, nil
+}
+
+// Load Reads the whole document, missing file is an empty store
+func (s *Store) Load() (Document, error) {
+	lock, errLock := safefile.LockShared(s.FileName)
+	if errLock != nil {
+		return Document{}, errLock
+	}
+	defer lock.Unlock()
+
+	return s.load()
+}
+
+func (s *Store) load() (Document, error) {
+	fileReader, errOpenFile := os.Open(s.FileName)
+	if errors.Is(errOpenFile, os.ErrNotExist) {
+		return Document{SchemaVersion: SchemaVersion}, nil
+	}
+	if errOpenFile != nil {
+		return Document{}, errOpenFile
+	}
+	defer fileReader.Close()
+
+	document, errDecode := s.decode(fileReader)
+	if errDecode != nil {
+		return Document{}, errors.Wrapf(errDecode, "Failed to read store %s", s.FileName)
+	}
+	if document.SchemaVersion == 0 || document.SchemaVersion > SchemaVersion {
+		return Document{}, errors.Wrapf(ErrUnsupportedSchema, "Version %d of store %s", document.SchemaVersion, s.FileName)
+	}
+	return document, nil
+}
+
+// Update Reads the document, applies changes to it and writes it back, changes of one update are never interleaved
+// with changes of another one (of any process) and nothing is written if changes fail
+func (s *Store) Update(apply func(document *Document) error) error {
+	return s.update(apply, func(document Document) {})
+}
+
+// update Same as Update, but once the document is written commit is called with it before changes of others may start
+func (s *Store) update(apply func(document *Document) error, commit func(document Document)) error {
+	lock, errLock := safefile.LockExclusive(s.FileName)
+	if errLock != nil {
+		return errLock
+	}
+	defer lock.Unlock()
+
+	document, errLoad := s.load()
+	if errLoad != nil {
+		return errLoad
+	}
+	if errApply := apply(&document); errApply != nil {
+		return errApply
+	}
+	if errSave := s.save(document); errSave != nil {
+		return errSave
+	}
+	commit(document)
+	return nil
+}
+
+// Save Writes the whole document, replacing contents of the store
+func (s *Store) Save(document Document) error {
+	lock, errLock := safefile.LockExclusive(s.FileName)
+	if errLock != nil {
+		return errLock
+	}
+	defer lock.Unlock()
+
+	return s.save(document)
+}
+
+// save Writes the document atomically, so that readers (and the store after a crash) see either old or new document,
+// but never a partially written one
+func (s *Store) save(document Document) error {
+	document.SchemaVersion = SchemaVersion
+	errWrite := safefile.WriteAtomic(s.FileName, s.SyncPolicy, func(writer io.Writer) error {
+		return s.encode(writer, document)
+	})
+	return errors.Wrap(errWrite, "Failed to write store")
+}
+
+// Recover Removes temporary files left behind by writes interrupted by a crash, store itself is never left partially
+// written. Meant to be called on startup
+func (s *Store) Recover() error {
+	lock, errLock := safefile.LockExclusive(s.FileName)
+	if errLock != nil {
+		return errLock
+	}
+	defer lock.Unlock()
+
+	return safefile.CleanTemp(s.FileName)
+}
+
+// Watch Reloads preloaded folders and/or secrets (those with repository given) whenever the store is changed on disk,
+// e.g. by another tool. Invalid document is handed to onError instead, reloads loaded before the latest local write are
+// dropped, as that write changes the store once again
+func (s *Store) Watch(foldersRep *folders.InMemoryRepository, secretsRep *secrets.InMemoryRepository, onError func(errLoad error)) (*watcher.Watcher, error) {
+	return watcher.New([]string{s.FileName}, func() {
+		var foldersGeneration, secretsGeneration uint64
+		if foldersRep != nil {
+			foldersGeneration = foldersRep.Generation()
+		}
+		if secretsRep != nil {
+			secretsGeneration = secretsRep.Generation()
+		}
+		document, errLoad := s.Load()
+		if errLoad == nil {
+			errLoad = folders.Validate(document.Folders)
+		}
+		if errLoad == nil {
+			errLoad = secrets.Validate(document.Secrets)
+		}
+		if errLoad != nil {
+			onError(errLoad)
+			return
+		}
+
+		if foldersRep != nil {
+			foldersRep.Replace(document.Folders, foldersGeneration)
+		}
+		if secretsRep != nil {
+			secretsRep.Replace(document.Secrets, secretsGeneration)
+		}
+	}, onError)
+}
+
+func (s *Store) encode(writer io.Writer, document Document) error {
+	switch s.EncodingType {
+	case extra.Encoding_JSON:
+		{
+			encoder := json.NewEncoder(writer)
+			encoder.SetIndent("", " ")
+			return encoder.Encode(document)
+		}
+	case extra.Encoding_GOB:
+		{
+			return gob.NewEncoder(writer).Encode(document)
+		}
+	case extra.Encoding_XML:
+		{
+			encoder := xml.NewEncoder(writer)
+			encoder.Indent("", " ")
+			return encoder.Encode(document)
+		}
+	}
+
+	return ErrUnsupportedEncoding
+}
+
+func (s *Store) decode(reader io.Reader) (Document, error) {
+	var document Document
+	switch s.EncodingType {
+	case extra.Encoding_JSON:
+		{
+			return document, json.NewDecoder(reader).Decode(&document)
+		}
+	case extra.Encoding_GOB:
+		{
+			return document, gob.NewDecoder(reader).Decode(&document)
+		}
+	case extra.Encoding_XML:
+		{
+			return document, xml.NewDecoder(reader).Decode(&document)
+		}
+	}
+
+	return document, ErrUnsupportedEncoding
+}
+
+// Migrate Moves folders and secrets kept in two separate files into the store, which must not exist yet. References
+// broken between the files are kept as they are and reported. Store is locked for the whole migration, so that it
+// cannot be created by anyone else in between
+func Migrate(ctx context.Context, foldersRepository folders.Repository, secretsRepository secrets.Repository, store *Store) (MigrateResult, error) {
+	lock, errLock := safefile.LockExclusive(store.FileName)
+	if errLock != nil {
+		return MigrateResult{}, errLock
+	}
+	defer lock.Unlock()
+
+	if _, errStat := os.Stat(store.FileName); !errors.Is(errStat, os.ErrNotExist) {
+		return MigrateResult{}, errors.Wrap(ErrStoreExists, store.FileName)
+	}
+
+	loadedFolders, errLoadFolders := foldersRepository.Load(ctx)
+	if errLoadFolders != nil {
+		return MigrateResult{}, errors.Wrap(errLoadFolders, "Failed to load folders")
+	}
+	loadedSecrets, errLoadSecrets := secretsRepository.Load(ctx)
+	if errLoadSecrets != nil {
+		return MigrateResult{}, errors.Wrap(errLoadSecrets, "Failed to load secrets")
+	}
+
+	var result = MigrateResult{Folders: len(loadedFolders), Secrets: len(loadedSecrets)}
+	var folderIDs = make(map[uint]bool, len(loadedFolders))
+	for _, folder := range loadedFolders {
+		folderIDs[folder.ID] = true
+	}
+	for _, folder := range loadedFolders {
+		if folder.ParentID != 0 && !folderIDs[folder.ParentID] {
+			result.OrphanFolders = append(result.OrphanFolders, folder.ID)
+		}
+	}
+	for _, secret := range loadedSecrets {
+		if secret.FolderID != 0 && !folderIDs[secret.FolderID] {
+			result.OrphanSecrets = append(result.OrphanSecrets, secret.ID)
+		}
+	}
+
+	errSave := store.save(Document{Folders: loadedFolders, Secrets: loadedSecrets})
+	return result, errSave
+}
+
+// allFolders Folders including deleted ones, copied out of the repository
+func allFolders(ctx context.Context, repository *folders.InMemoryRepository) ([]folders.Folder, error) {
+	folderPtrs, errGetFolders := repository.Get(ctx, folders.ListFolderParams{ListParams: generics.ListParams{Deleted: model.YesOrNo}})
+	if errGetFolders != nil {
+		return nil, errGetFolders
+	}
+	var results = make([]folders.Folder, 0, len(folderPtrs))
+	for _, folderPtr := range folderPtrs {
+		results = append(results, *folderPtr)
+	}
+	return results, nil
+}
+
+// allSecrets Secrets including deleted ones, copied out of the repository
+func allSecrets(ctx context.Context, repository *secrets.InMemoryRepository) ([]secrets.Secret, error) {
+	secretPtrs, errGetSecrets := repository.Get(ctx, secrets.ListSecretParams{ListParams: generics.ListParams{Deleted: model.YesOrNo}})
+	if errGetSecrets != nil {
+		return nil, errGetSecrets
+	}
+	var results = make([]secrets.Secret, 0, len(secretPtrs))
+	for _, secretPtr := range secretPtrs {
+		results = append(results, *secretPtr)
+	}
+	return results, nil
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"hideout/internal/secrets"
+)
+
+func NewSecretsRepository(store *Store, inMemoryRep *secrets.InMemoryRepository) SecretsRepository {
+	return SecretsRepository{store: store, inMemoryRepository: inMemoryRep}
+}
+
+// repository Secrets to read from, either preloaded ones or the ones currently in store
+func (m SecretsRepository) repository() (*secrets.InMemoryRepository, error) {
+	if m.inMemoryRepository != nil {
+		return m.inMemoryRepository, nil
+	}
+
+	document, errLoad := m.store.Load()
+	if errLoad != nil {
+		return nil, errors.Wrap(errLoad, "Failed to load secrets from store")
+	}
+	return secrets.NewInMemoryRepository(&document.Secrets), nil
+}
+
+// update Applies changes to secrets and writes them into store along with folders currently in store. Changes
+// are applied to a copy of preloaded secrets, which replaces them only once the store is written
+func (m SecretsRepository) update(ctx context.Context, apply func(repository *secrets.InMemoryRepository) error) error {
+	if m.inMemoryRepository == nil {
+		return m.store.Update(func(document *Document) error {
+			return apply(secrets.NewInMemoryRepository(&document.Secrets))
+		})
+	}
+
+	return m.store.update(func(document *Document) error {
+		preloaded, errGetSecrets := allSecrets(ctx, m.inMemoryRepository)
+		if errGetSecrets != nil {
+			return errGetSecrets
+		}
+		if errApply := apply(secrets.NewInMemoryRepository(&preloaded)); errApply != nil {
+			return errApply
+		}
+		document.Secrets = preloaded
+		return nil
+	}, func(document Document) {
+		m.inMemoryRepository.Swap(document.Secrets)
+	})
+}
+
+func (m SecretsRepository) Load(ctx context.Context) ([]secrets.Secret, error) {
+	document, errLoad := m.store.Load()
+	if errLoad != nil {
+		return nil, errLoad
+	}
+	return document.Secrets, nil
+}
+
+func (m SecretsRepository) GetID(ctx context.Context) (uint, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return 0, errRepository
+	}
+	return repository.GetID(ctx)
+}
+
+func (m SecretsRepository) Get(ctx context.Context, params secrets.ListSecretParams) ([]*secrets.Secret, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.Get(ctx, params)
+}
+
+func (m SecretsRepository) GetMapByID(ctx context.Context, params secrets.ListSecretParams) (map[uint]*secrets.Secret, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetMapByID(ctx, params)
+}
+
+func (m SecretsRepository) GetMapByUID(ctx context.Context, params secrets.ListSecretParams) (map[string]*secrets.Secret, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetMapByUID(ctx, params)
+}
+
+func (m SecretsRepository) GetMapByFolder(ctx context.Context, params secrets.ListSecretParams) (map[uint][]*secrets.Secret, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetMapByFolder(ctx, params)
+}
+
+func (m SecretsRepository) GetByID(ctx context.Context, id uint) (*secrets.Secret, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetByID(ctx, id)
+}
+
+func (m SecretsRepository) GetByUID(ctx context.Context, uid string) (*secrets.Secret, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return nil, errRepository
+	}
+	return repository.GetByUID(ctx, uid)
+}
+
+func (m SecretsRepository) Count(ctx context.Context, params secrets.ListSecretParams) (uint, error) {
+	repository, errRepository := m.repository()
+	if errRepository != nil {
+		return 0, errRepository
+	}
+	return repository.Count(ctx, params)
+}
+
+func (m SecretsRepository) Update(ctx context.Context, secret secrets.Secret) (*secrets.Secret, error) {
+	var updatedSecret *secrets.Secret
+	errUpdate := m.update(ctx, func(repository *secrets.InMemoryRepository) error {
+		var errUpdateSecret error
+		updatedSecret, errUpdateSecret = repository.Update(ctx, secret)
+		return errUpdateSecret
+	})
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+	return updatedSecret, nil
+}
+
+func (m SecretsRepository) Create(ctx context.Context, secret secrets.Secret) (*secrets.Secret, error) {
+	var createdSecret *secrets.Secret
+	errUpdate := m.update(ctx, func(repository *secrets.InMemoryRepository) error {
+		var errCreateSecret error
+		createdSecret, errCreateSecret = repository.Create(ctx, secret)
+		return errors.Wrapf(errCreateSecret, "Error creating secret with ID of %d in memory", secret.ID)
+	})
+	if errUpdate != nil {
+		return nil, errUpdate
+	}
+	return createdSecret, nil
+}
+
+func (m SecretsRepository) Delete(ctx context.Context, id uint, forceDelete bool) error {
+	return m.update(ctx, func(repository *secrets.InMemoryRepository) error {
+		return repository.Delete(ctx, id, forceDelete)
+	})
+}
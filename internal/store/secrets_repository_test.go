@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"hideout/internal/common/model"
+	"hideout/internal/pkg/extra"
+	"hideout/internal/secrets"
+	"path/filepath"
+	"testing"
+)
+
+// newTestSecretsRepository Repository of single preloaded secret kept in store of given encoding
+func newTestSecretsRepository(t *testing.T, encodingType uint) (SecretsRepository, *[]secrets.Secret) {
+	t.Helper()
+	preloaded := []secrets.Secret{{Model: model.Model{ID: 1}, UID: "abc-def-ghi", Name: "KEY", Value: "old"}}
+	store := &Store{FileName: filepath.Join(t.TempDir(), "store.json"), EncodingType: encodingType, SyncPolicy: Sync_Never}
+	return NewSecretsRepository(store, secrets.NewPreloadedInMemoryRepository(&preloaded, &model.Preload{})), &preloaded
+}
+
+func TestSecretsUpdate(t *testing.T) {
+	repository, preloaded := newTestSecretsRepository(t, extra.Encoding_JSON)
+	generation := repository.inMemoryRepository.Generation()
+	if _, errUpdate := repository.Update(context.Background(), secrets.Secret{Model: model.Model{ID: 1}, Name: "KEY", Value: "new"}); errUpdate != nil {
+		t.Fatalf("Update failed: %s", errUpdate)
+	}
+	if _, errCreate := repository.Create(context.Background(), secrets.Secret{Model: model.Model{ID: 2}, Name: "OTHER"}); errCreate != nil {
+		t.Fatalf("Create failed: %s", errCreate)
+	}
+
+	document, errLoad := repository.store.Load()
+	if errLoad != nil {
+		t.Fatalf("Load failed: %s", errLoad)
+	}
+	for name, stored := range map[string][]secrets.Secret{"preloaded": *preloaded, "stored": document.Secrets} {
+		if len(stored) != 2 || (stored[0].Value != "new" && stored[1].Value != "new") {
+			t.Errorf("Expected updated and created secrets to be %s, got %+v", name, stored)
+		}
+	}
+	if repository.inMemoryRepository.Generation() == generation {
+		t.Error("Writes were not counted as local ones")
+	}
+}
+
+func TestSecretsUpdateNotSaved(t *testing.T) {
+	// Document cannot be encoded, so that nothing can be saved
+	repository, preloaded := newTestSecretsRepository(t, extra.Encoding_JSON+1000)
+
+	_, errUpdate := repository.Update(context.Background(), secrets.Secret{Model: model.Model{ID: 1}, Name: "KEY", Value: "new"})
+	if !errors.Is(errUpdate, ErrUnsupportedEncoding) {
+		t.Errorf("Expected %s, got %v", ErrUnsupportedEncoding, errUpdate)
+	}
+	_, errCreate := repository.Create(context.Background(), secrets.Secret{Model: model.Model{ID: 2}, Name: "OTHER"})
+	if !errors.Is(errCreate, ErrUnsupportedEncoding) {
+		t.Errorf("Expected %s, got %v", ErrUnsupportedEncoding, errCreate)
+	}
+	errDelete := repository.Delete(context.Background(), 1, true)
+	if !errors.Is(errDelete, ErrUnsupportedEncoding) {
+		t.Errorf("Expected %s, got %v", ErrUnsupportedEncoding, errDelete)
+	}
+
+	if len(*preloaded) != 1 || (*preloaded)[0].Value != "old" {
+		t.Errorf("Preloaded secrets were changed by writes which were not saved: %+v", *preloaded)
+	}
+}
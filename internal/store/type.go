@@ -0,0 +1,46 @@
+package store
+
+import (
+	"encoding/xml"
+	"hideout/internal/folders"
+	"hideout/internal/secrets"
+)
+
+type (
+	// Store Single file holding both folders and secrets, which is rewritten as a whole on every change. Changes are
+	// serialized by advisory lock of the file, so that several processes may share the store
+	Store struct {
+		FileName     string
+		EncodingType uint
+		SyncPolicy   uint
+	}
+
+	// Document Contents of store file
+	Document struct {
+		XMLName       xml.Name         `json:"-" xml:"Store"`
+		SchemaVersion uint             `json:"SchemaVersion" xml:"SchemaVersion"`
+		Folders       []folders.Folder `json:"Folders" xml:"Folder"`
+		Secrets       []secrets.Secret `json:"Secrets" xml:"Secret"`
+	}
+
+	// FoldersRepository Folders kept in store along with secrets
+	FoldersRepository struct {
+		store              *Store
+		inMemoryRepository *folders.InMemoryRepository
+	}
+
+	// SecretsRepository Secrets kept in store along with folders
+	SecretsRepository struct {
+		store              *Store
+		inMemoryRepository *secrets.InMemoryRepository
+	}
+
+	// MigrateResult Amount of records moved from two-file layout into store along with references which were
+	// already broken between the files
+	MigrateResult struct {
+		Folders       int
+		Secrets       int
+		OrphanFolders []uint // Folders which parent is missing
+		OrphanSecrets []uint // Secrets which folder is missing
+	}
+)
@@ -0,0 +1,27 @@
+package store
+
+import (
+	"github.com/pkg/errors"
+	"hideout/internal/pkg/extra"
+)
+
+var (
+	ErrUnsupportedEncoding = errors.New("Encoding is not supported by store")
+	ErrUnsupportedSchema   = errors.New("Unsupported schema version of store")
+	ErrStoreExists         = errors.New("Store file already exists")
+
+	// Encodings Encodings of the whole document supported by store
+	Encodings = []uint{extra.Encoding_JSON, extra.Encoding_GOB, extra.Encoding_XML}
+
+	SyncPoliciesMap = map[string]uint{
+		"always": Sync_Always,
+		"file":   Sync_File,
+		"never":  Sync_Never,
+	}
+
+	SyncPoliciesMapInv = map[uint]string{
+		Sync_Always: "always",
+		Sync_File:   "file",
+		Sync_Never:  "never",
+	}
+)
@@ -0,0 +1,28 @@
+package database
+
+const (
+	// Names of the secrets and sub-folders making up a database secrets engine folder
+	SecretName_Connection = "CONNECTION"
+	FolderName_Roles      = "roles"
+
+	// Placeholders substituted in role statements
+	Placeholder_Name       = "{{name}}"
+	Placeholder_Password   = "{{password}}"
+	Placeholder_Expiration = "{{expiration}}"
+
+	// Engine Prefix of the leases handed out by the engine
+	Engine = "database"
+
+	// LeaseData_Username Key of the lease data holding name of the database user
+	LeaseData_Username = "Username"
+
+	DriverName = "postgres"
+
+	// ExpirationLayout Format of the expiration date accepted by VALID UNTIL clause
+	ExpirationLayout = "2006-01-02 15:04:05-07:00"
+
+	// Postgres limits identifiers to 63 bytes
+	maxUsernameLength = 63
+	passwordLength    = 32
+	passwordAlphabet  = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Validate Checks role for consistency, filling in the defaults
+func (r *Role) Validate() error {
+	if !roleNameRegexp.MatchString(r.Name) {
+		return ErrInvalidRoleName
+	}
+	r.CreationStatements = nonEmptyStatements(r.CreationStatements)
+	if len(r.CreationStatements) == 0 {
+		return ErrNoCreationStatements
+	}
+	r.RevocationStatements = nonEmptyStatements(r.RevocationStatements)
+	r.RenewStatements = nonEmptyStatements(r.RenewStatements)
+	if r.MaxTTL <= 0 {
+		r.MaxTTL = DefaultMaxTTL
+	}
+	if r.TTL <= 0 {
+		r.TTL = DefaultTTL
+	}
+	if r.TTL > r.MaxTTL {
+		r.TTL = r.MaxTTL
+	}
+	return nil
+}
+
+// Revocation Statements dropping the user, defaults are used if role has none
+func (r Role) Revocation() []string {
+	if len(r.RevocationStatements) == 0 {
+		return DefaultRevocationStatements
+	}
+	return r.RevocationStatements
+}
+
+// Renewal Statements extending validity of the user, defaults are used if role has none
+func (r Role) Renewal() []string {
+	if len(r.RenewStatements) == 0 {
+		return DefaultRenewStatements
+	}
+	return r.RenewStatements
+}
+
+// ValidateConnectionURL Checks that connection URL is a postgres:// URL or a key=value connection string
+func ValidateConnectionURL(connectionURL string) error {
+	if strings.Contains(connectionURL, "://") {
+		parsedURL, errParse := url.Parse(connectionURL)
+		if errParse != nil {
+			return errors.Wrap(ErrInvalidConnectionURL, errParse.Error())
+		}
+		if parsedURL.Scheme != "postgres" && parsedURL.Scheme != "postgresql" {
+			return errors.Wrapf(ErrInvalidConnectionURL, "Unsupported scheme %s", parsedURL.Scheme)
+		}
+		if parsedURL.Host == "" {
+			return errors.Wrap(ErrInvalidConnectionURL, "Host is missing")
+		}
+		return nil
+	}
+	if !strings.Contains(connectionURL, "=") {
+		return ErrInvalidConnectionURL
+	}
+	return nil
+}
+
+// GenerateUsername Unique user name mentioning the role and creation time, e.g. v-readonly-1700000000-1a2b3c4d
+func GenerateUsername(roleName string, now time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	_, errRead := rand.Read(suffix)
+	if errRead != nil {
+		return "", errors.Wrap(errRead, "Failed to generate user name")
+	}
+	tail := fmt.Sprintf("-%d-%s", now.Unix(), hex.EncodeToString(suffix))
+	rolePart := strings.ToLower(strings.ReplaceAll(roleName, "_", "-"))
+	if maxRoleLength := maxUsernameLength - len("v-") - len(tail); len(rolePart) > maxRoleLength {
+		rolePart = rolePart[:maxRoleLength]
+	}
+	return "v-" + rolePart + tail, nil
+}
+
+// GeneratePassword Random alphanumeric password, safe to be substituted into quoted SQL literals
+func GeneratePassword() (string, error) {
+	var builder strings.Builder
+	alphabetLength := big.NewInt(int64(len(passwordAlphabet)))
+	for i := 0; i < passwordLength; i++ {
+		index, errRand := rand.Int(rand.Reader, alphabetLength)
+		if errRand != nil {
+			return "", errors.Wrap(errRand, "Failed to generate password")
+		}
+		builder.WriteByte(passwordAlphabet[index.Int64()])
+	}
+	return builder.String(), nil
+}
+
+// RenderStatements Substitutes placeholders in the statements
+func RenderStatements(statements []string, username string, password string, expiration time.Time) []string {
+	replacer := strings.NewReplacer(Placeholder_Name, username, Placeholder_Password, password,
+		Placeholder_Expiration, expiration.UTC().Format(ExpirationLayout))
+	var results []string
+	for _, statement := range statements {
+		results = append(results, replacer.Replace(statement))
+	}
+	return results
+}
+
+func nonEmptyStatements(statements []string) []string {
+	var results []string
+	for _, statement := range statements {
+		if strings.TrimSpace(statement) != "" {
+			results = append(results, statement)
+		}
+	}
+	return results
+}
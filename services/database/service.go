@@ -0,0 +1,294 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	_ "github.com/lib/pq"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
+	"hideout/internal/folders"
+	leases2 "hideout/internal/leases"
+	"hideout/internal/pkg/encryption"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/leases"
+	"hideout/services/secrets"
+	"log"
+	"time"
+)
+
+// DatabaseService Dynamic Postgres credentials, users are created on demand and dropped once their lease expires
+type DatabaseService struct {
+	secretsSvc    *secrets.SecretsService
+	leaseSvc      *leases.LeaseService
+	encryptionKey []byte
+}
+
+// NewService Creation of the service, registering it as the engine revoking its leases
+func NewService(secretsSvc *secrets.SecretsService, leaseSvc *leases.LeaseService, encryptionKey []byte) *DatabaseService {
+	return &DatabaseService{secretsSvc: secretsSvc, leaseSvc: leaseSvc, encryptionKey: encryptionKey}
+}
+
+// Configure Creates or replaces administrative connection of the folder, connection URL is stored encrypted
+func (m *DatabaseService) Configure(ctx context.Context, Localizer *i18n.Localizer, params ConfigureParams) error {
+	folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, params.FolderUID)
+	if errGetFolder != nil {
+		return errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", params.FolderUID)
+	}
+
+	errValidate := ValidateConnectionURL(params.ConnectionURL)
+	if errValidate != nil {
+		return errValidate
+	}
+	connection := Connection{ConnectionURL: params.ConnectionURL}
+	if params.Verify {
+		errExecute := m.execute(ctx, connection, nil)
+		if errExecute != nil {
+			return errExecute
+		}
+	}
+
+	connectionData, errMarshal := json.Marshal(connection)
+	if errMarshal != nil {
+		return errors.Wrap(errMarshal, "Failed to serialize connection")
+	}
+	sealedConnection, errSeal := encryption.Seal(m.encryptionKey, connectionData)
+	if errSeal != nil {
+		return errSeal
+	}
+	_, errSaveSecret := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: folder.ID, Name: SecretName_Connection,
+		Value: sealedConnection, Type: secrets2.Type_DatabaseConnection})
+	if errSaveSecret != nil {
+		return errSaveSecret
+	}
+
+	_, errGetFolder = m.getChildFolder(ctx, folder.ID, FolderName_Roles, true)
+	return errGetFolder
+}
+
+// SaveRole Creates or replaces role of the folder
+func (m *DatabaseService) SaveRole(ctx context.Context, Localizer *i18n.Localizer, folderUID string, role Role) (*Role, error) {
+	errValidate := role.Validate()
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	_, folder, errLoadConnection := m.loadConnection(ctx, folderUID)
+	if errLoadConnection != nil {
+		return nil, errLoadConnection
+	}
+	rolesFolder, errGetFolder := m.getChildFolder(ctx, folder.ID, FolderName_Roles, true)
+	if errGetFolder != nil {
+		return nil, errGetFolder
+	}
+
+	roleData, errMarshal := json.Marshal(role)
+	if errMarshal != nil {
+		return nil, errors.Wrapf(errMarshal, "Failed to serialize role %s", role.Name)
+	}
+	_, errSaveSecret := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: rolesFolder.ID, Name: role.Name,
+		Value: string(roleData), Type: secrets2.Type_DatabaseRole})
+	if errSaveSecret != nil {
+		return nil, errSaveSecret
+	}
+
+	return &role, nil
+}
+
+// GetRole Retrieves role stored in the folder
+func (m *DatabaseService) GetRole(ctx context.Context, folder *folders.Folder, roleName string) (*Role, error) {
+	if !roleNameRegexp.MatchString(roleName) {
+		return nil, ErrInvalidRoleName
+	}
+	rolesFolder, errGetFolder := m.getChildFolder(ctx, folder.ID, FolderName_Roles, false)
+	if errGetFolder != nil {
+		if errors.Is(errGetFolder, apperror.ErrRecordNotFound) {
+			return nil, errors.Wrapf(ErrRoleNotFound, "Role %s does not exist", roleName)
+		}
+		return nil, errGetFolder
+	}
+	roleSecret, errGetSecret := m.secretsSvc.GetSecretByName(ctx, rolesFolder.ID, roleName)
+	if errGetSecret != nil {
+		if errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+			return nil, errors.Wrapf(ErrRoleNotFound, "Role %s does not exist", roleName)
+		}
+		return nil, errGetSecret
+	}
+
+	var role Role
+	errUnmarshal := json.Unmarshal([]byte(roleSecret.Value), &role)
+	if errUnmarshal != nil {
+		return nil, errors.Wrapf(errUnmarshal, "Failed to deserialize role %s", roleName)
+	}
+	return &role, nil
+}
+
+// Credentials Creates database user of the role and a lease tracking it, password is only returned once
+func (m *DatabaseService) Credentials(ctx context.Context, Localizer *i18n.Localizer, params CredentialsParams, now time.Time) (*Credentials, error) {
+	connection, folder, errLoadConnection := m.loadConnection(ctx, params.FolderUID)
+	if errLoadConnection != nil {
+		return nil, errLoadConnection
+	}
+	role, errGetRole := m.GetRole(ctx, folder, params.Role)
+	if errGetRole != nil {
+		return nil, errGetRole
+	}
+
+	ttl := params.TTL
+	if ttl <= 0 {
+		ttl = role.TTL
+	}
+	if ttl > role.MaxTTL {
+		ttl = role.MaxTTL
+	}
+
+	username, errUsername := GenerateUsername(role.Name, now)
+	if errUsername != nil {
+		return nil, errUsername
+	}
+	password, errPassword := GeneratePassword()
+	if errPassword != nil {
+		return nil, errPassword
+	}
+	expiresAt := now.Add(ttl)
+
+	errExecute := m.execute(ctx, *connection, RenderStatements(role.CreationStatements, username, password, expiresAt))
+	if errExecute != nil {
+		return nil, errors.Wrapf(errExecute, "Failed to create user for role %s", role.Name)
+	}
+
+	lease, errCreateLease := m.leaseSvc.Create(ctx, Localizer, folder, leases2.Lease{Engine: Engine, Path: role.Name, IssuedAt: now,
+		ExpiresAt: expiresAt, MaxExpiresAt: now.Add(role.MaxTTL), Increment: role.TTL, Renewable: true,
+		Data: map[string]string{LeaseData_Username: username}})
+	if errCreateLease != nil {
+		// User which is not tracked by a lease would never be dropped
+		errRevoke := m.execute(ctx, *connection, RenderStatements(role.Revocation(), username, "", expiresAt))
+		if errRevoke != nil {
+			log.Printf("Error dropping user %s without lease: %s", username, errRevoke.Error())
+		}
+		return nil, errCreateLease
+	}
+
+	return &Credentials{Lease: *lease, Username: username, Password: password}, nil
+}
+
+// Renew Extends validity of the database user to the new expiration date of the lease
+func (m *DatabaseService) Renew(ctx context.Context, lease leases2.Lease) error {
+	connection, role, username, errLoad := m.loadLease(ctx, lease)
+	if errLoad != nil {
+		return errLoad
+	}
+	errExecute := m.execute(ctx, *connection, RenderStatements(role.Renewal(), username, "", lease.ExpiresAt))
+	if errExecute != nil {
+		return errors.Wrapf(errExecute, "Failed to renew user %s", username)
+	}
+	return nil
+}
+
+// Revoke Drops database user of the lease
+func (m *DatabaseService) Revoke(ctx context.Context, lease leases2.Lease) error {
+	connection, role, username, errLoad := m.loadLease(ctx, lease)
+	if errLoad != nil {
+		return errLoad
+	}
+	errExecute := m.execute(ctx, *connection, RenderStatements(role.Revocation(), username, "", lease.ExpiresAt))
+	if errExecute != nil {
+		return errors.Wrapf(errExecute, "Failed to drop user %s", username)
+	}
+	return nil
+}
+
+// loadLease Connection, role (defaults are used if role was removed since) and user name of the lease
+func (m *DatabaseService) loadLease(ctx context.Context, lease leases2.Lease) (*Connection, Role, string, error) {
+	username := lease.Data[LeaseData_Username]
+	if lease.Engine != Engine || username == "" {
+		return nil, Role{}, "", errors.Wrapf(ErrInvalidLease, "Lease %s", lease.LeaseID)
+	}
+	connection, folder, errLoadConnection := m.loadConnection(ctx, lease.FolderUID)
+	if errLoadConnection != nil {
+		return nil, Role{}, "", errLoadConnection
+	}
+
+	role, errGetRole := m.GetRole(ctx, folder, lease.Path)
+	if errGetRole != nil {
+		log.Printf("Error retrieving role %s of lease %s, using defaults: %s", lease.Path, lease.LeaseID, errGetRole.Error())
+		role = &Role{Name: lease.Path, TTL: DefaultTTL, MaxTTL: DefaultMaxTTL}
+	}
+	return connection, *role, username, nil
+}
+
+func (m *DatabaseService) loadConnection(ctx context.Context, folderUID string) (*Connection, *folders.Folder, error) {
+	folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+	if errGetFolder != nil {
+		return nil, nil, errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+	}
+	connectionSecret, errGetSecret := m.secretsSvc.GetSecretByName(ctx, folder.ID, SecretName_Connection)
+	if errGetSecret != nil {
+		if errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+			return nil, nil, errors.Wrapf(ErrNotConfigured, "Secret %s is missing", SecretName_Connection)
+		}
+		return nil, nil, errGetSecret
+	}
+	if connectionSecret.Type != secrets2.Type_DatabaseConnection {
+		return nil, nil, errors.Wrapf(ErrNotConfigured, "Secret %s is not a database connection", SecretName_Connection)
+	}
+
+	connectionData, errOpen := encryption.Open(m.encryptionKey, connectionSecret.Value)
+	if errOpen != nil {
+		return nil, nil, errOpen
+	}
+	var connection Connection
+	errUnmarshal := json.Unmarshal(connectionData, &connection)
+	if errUnmarshal != nil {
+		return nil, nil, errors.Wrap(errUnmarshal, "Failed to deserialize connection")
+	}
+	return &connection, folder, nil
+}
+
+// execute Runs statements within a single transaction, only checks the connection if there are none
+func (m *DatabaseService) execute(ctx context.Context, connection Connection, statements []string) error {
+	conn, errOpen := sql.Open(DriverName, connection.ConnectionURL)
+	if errOpen != nil {
+		return errors.Wrap(ErrInvalidConnectionURL, errOpen.Error())
+	}
+	defer conn.Close()
+
+	errPing := conn.PingContext(ctx)
+	if errPing != nil {
+		return errors.Wrap(ErrStatementExecution, errPing.Error())
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+
+	tx, errBegin := conn.BeginTx(ctx, nil)
+	if errBegin != nil {
+		return errors.Wrap(ErrStatementExecution, errBegin.Error())
+	}
+	for _, statement := range statements {
+		_, errExec := tx.ExecContext(ctx, statement)
+		if errExec != nil {
+			_ = tx.Rollback()
+			return errors.Wrap(ErrStatementExecution, errExec.Error())
+		}
+	}
+	errCommit := tx.Commit()
+	if errCommit != nil {
+		return errors.Wrap(ErrStatementExecution, errCommit.Error())
+	}
+	return nil
+}
+
+func (m *DatabaseService) getChildFolder(ctx context.Context, parentID uint, name string, create bool) (*folders.Folder, error) {
+	childFolder, errGetFolder := m.secretsSvc.GetFolderByName(ctx, parentID, name)
+	if errGetFolder == nil || !create || !errors.Is(errGetFolder, apperror.ErrRecordNotFound) {
+		return childFolder, errGetFolder
+	}
+
+	newFolder, errCreateFolder := m.secretsSvc.CreateFolder(ctx, folders.Folder{ParentID: parentID, Name: name})
+	if errCreateFolder != nil {
+		return nil, errors.Wrapf(errCreateFolder, "Failed to create folder %s", name)
+	}
+	return newFolder, nil
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"hideout/internal/leases"
+	"time"
+)
+
+type (
+	// Connection Administrative connection used to manage users, stored encrypted
+	Connection struct {
+		ConnectionURL string `json:"ConnectionURL"`
+	}
+
+	// Role Statements executed to create, renew and revoke users of the role ({{name}}, {{password}} and {{expiration}}
+	// placeholders are substituted)
+	Role struct {
+		Name                 string        `json:"Name"`
+		CreationStatements   []string      `json:"CreationStatements"`
+		RevocationStatements []string      `json:"RevocationStatements"`
+		RenewStatements      []string      `json:"RenewStatements"`
+		TTL                  time.Duration `json:"TTL"`
+		MaxTTL               time.Duration `json:"MaxTTL"`
+	}
+
+	ConfigureParams struct {
+		FolderUID     string
+		ConnectionURL string
+		// Verify Check that connection can be established before saving
+		Verify bool
+	}
+
+	CredentialsParams struct {
+		FolderUID string
+		Role      string
+		TTL       time.Duration
+	}
+
+	Credentials struct {
+		Lease    leases.Lease
+		Username string
+		Password string
+	}
+)
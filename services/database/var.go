@@ -0,0 +1,29 @@
+package database
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrNotConfigured        = errors.New("Folder does not contain a database connection")
+	ErrInvalidConnectionURL = errors.New("Invalid connection URL")
+	ErrRoleNotFound         = errors.New("Role not found")
+	ErrInvalidRoleName      = errors.New("Role name may only contain letters, digits, dashes and underscores")
+	ErrNoCreationStatements = errors.New("At least one creation statement is required")
+	ErrInvalidLease         = errors.New("Lease was not issued by the database secrets engine")
+	ErrStatementExecution   = errors.New("Failed to execute statement in database")
+
+	DefaultTTL    = time.Hour
+	DefaultMaxTTL = 24 * time.Hour
+
+	// DefaultRevocationStatements Drop objects owned by the user and its privileges, then the user itself
+	DefaultRevocationStatements = []string{
+		`DO $$ BEGIN IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = '{{name}}') THEN EXECUTE 'DROP OWNED BY "{{name}}"'; END IF; END $$;`,
+		`DROP ROLE IF EXISTS "{{name}}";`,
+	}
+	DefaultRenewStatements = []string{`ALTER ROLE "{{name}}" VALID UNTIL '{{expiration}}';`}
+
+	roleNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
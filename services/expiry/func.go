@@ -0,0 +1,21 @@
+package expiry
+
+import (
+	"hideout/config"
+	"hideout/internal/notifications"
+)
+
+// SinksFromConfig Creates notification sinks based on the configuration, log sink is always present
+func SinksFromConfig(notificationsConfig config.NotificationsConfig) []notifications.Sink {
+	sinks := []notifications.Sink{notifications.NewLogSink()}
+	if notificationsConfig.WebhookURL != "" {
+		sinks = append(sinks, notifications.NewWebhookSink(notificationsConfig.WebhookURL, notificationsConfig.WebhookTimeout))
+	}
+	if notificationsConfig.SMTP.Host != "" {
+		sinks = append(sinks, notifications.NewSMTPSink(notificationsConfig.SMTP.Host, notificationsConfig.SMTP.Port,
+			notificationsConfig.SMTP.Username, notificationsConfig.SMTP.Password, notificationsConfig.SMTP.From,
+			notificationsConfig.SMTP.To))
+	}
+
+	return sinks
+}
@@ -0,0 +1,108 @@
+package expiry
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"hideout/config"
+	"hideout/internal/common/generics"
+	"hideout/internal/common/model"
+	"hideout/internal/notifications"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"log"
+	"sync"
+	"time"
+)
+
+type ExpiryService struct {
+	config     config.ExpiryConfig
+	secretsSvc *secrets.SecretsService
+	sinks      []notifications.Sink
+
+	// Secrets already reported, so that the same notification is not sent on every check
+	notified map[uint]notifiedEntry
+	mutex    sync.Mutex
+}
+
+// NewService Creation of the service
+func NewService(expiryConfig config.ExpiryConfig, secretsSvc *secrets.SecretsService, sinks []notifications.Sink) *ExpiryService {
+	return &ExpiryService{config: expiryConfig, secretsSvc: secretsSvc, sinks: sinks, notified: make(map[uint]notifiedEntry)}
+}
+
+// Run Periodically checks secrets for expiration until context is cancelled
+func (m *ExpiryService) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		_, errCheck := m.Check(ctx, time.Now())
+		if errCheck != nil {
+			log.Printf("Error checking secrets for expiration: %s", errCheck.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check Sends notifications for secrets that expire within the configured window or have already expired
+func (m *ExpiryService) Check(ctx context.Context, now time.Time) ([]notifications.Notification, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	expiringSecrets, errGetSecrets := m.secretsSvc.GetSecrets(ctx, secrets2.ListSecretParams{
+		ListParams: generics.ListParams{Deleted: model.No},
+		ExpiresAt:  generics.FromTo[time.Time]{To: now.Add(m.config.Window)},
+	})
+	if errGetSecrets != nil {
+		return nil, errors.Wrap(errGetSecrets, "Error retrieving expiring secrets")
+	}
+
+	var results []notifications.Notification
+	for _, expiringSecret := range expiringSecrets {
+		notificationType := notifications.Type_Expiring
+		if expiringSecret.IsExpired(now) {
+			notificationType = notifications.Type_Expired
+		}
+
+		entry := notifiedEntry{Type: notificationType, ExpiresAt: expiringSecret.ExpiresAt.Time}
+		if previousEntry, isNotified := m.notified[expiringSecret.ID]; isNotified && previousEntry.Type == entry.Type &&
+			previousEntry.ExpiresAt.Equal(entry.ExpiresAt) {
+			continue
+		}
+
+		notification := notifications.Notification{
+			Type: notificationType, SecretID: expiringSecret.ID, SecretUID: expiringSecret.UID, SecretName: expiringSecret.Name,
+			ExpiresAt: expiringSecret.ExpiresAt.Time, CreatedAt: now,
+		}
+		secretFolder, errGetFolder := m.secretsSvc.GetFolderByID(ctx, expiringSecret.FolderID)
+		if errGetFolder != nil {
+			log.Printf("Error retrieving folder with ID of %d for secret with UID of %s: %s", expiringSecret.FolderID,
+				expiringSecret.UID, errGetFolder.Error())
+		} else {
+			notification.FolderUID = secretFolder.UID
+			notification.FolderName = secretFolder.Name
+		}
+
+		isDelivered := true
+		for _, sink := range m.sinks {
+			errSend := sink.Send(ctx, notification)
+			if errSend != nil {
+				log.Printf("Error sending notification about secret with UID of %s through %s sink: %s",
+					expiringSecret.UID, sink.Name(), errSend.Error())
+				isDelivered = false
+			}
+		}
+
+		// Undelivered notifications are retried on the next check
+		if isDelivered {
+			m.notified[expiringSecret.ID] = entry
+		}
+		results = append(results, notification)
+	}
+
+	return results, nil
+}
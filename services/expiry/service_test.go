@@ -0,0 +1,136 @@
+package expiry
+
+import (
+	"context"
+	"database/sql"
+	"github.com/pkg/errors"
+	"hideout/config"
+	"hideout/internal/folders"
+	"hideout/internal/notifications"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"hideout/structs"
+	"testing"
+	"time"
+)
+
+// stubSink Records delivered notifications, failing while err is set
+type stubSink struct {
+	notifications []notifications.Notification
+	err           error
+}
+
+func (m *stubSink) Name() string {
+	return "stub"
+}
+
+func (m *stubSink) Send(ctx context.Context, notification notifications.Notification) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.notifications = append(m.notifications, notification)
+	return nil
+}
+
+// notified Types of notifications sent since the last call, indexed by secret names
+func (m *stubSink) notified() map[string]string {
+	results := make(map[string]string)
+	for _, notification := range m.notifications {
+		results[notification.SecretName] = notification.Type
+	}
+	m.notifications = nil
+	return results
+}
+
+// newTestService Service checking secrets of empty in-memory repositories with a day long window, along with a folder
+func newTestService(t *testing.T, sink *stubSink) (*ExpiryService, *secrets.SecretsService, *folders.Folder) {
+	t.Helper()
+	structs.Folders, structs.Secrets = nil, nil
+	secretsSvc, errCreateService := secrets.NewService(context.Background(), config.RepositoryConfig{Type: secrets.RepositoryType_InMemory},
+		config.RepositoryConfig{Type: secrets.RepositoryType_InMemory}, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		t.Fatalf("Failed to create secrets service: %s", errCreateService)
+	}
+	folder, errCreateFolder := secretsSvc.CreateFolder(context.Background(), folders.Folder{Name: "production"})
+	if errCreateFolder != nil {
+		t.Fatalf("Failed to create folder: %s", errCreateFolder)
+	}
+	return NewService(config.ExpiryConfig{Window: 24 * time.Hour}, secretsSvc, []notifications.Sink{sink}), secretsSvc, folder
+}
+
+func createTestSecret(t *testing.T, secretsSvc *secrets.SecretsService, folder *folders.Folder, name string, expiresAt sql.NullTime) {
+	t.Helper()
+	_, errSave := secretsSvc.SaveSecret(context.Background(), nil, secrets2.Secret{FolderID: folder.ID, Name: name, Value: "value",
+		ExpiresAt: expiresAt})
+	if errSave != nil {
+		t.Fatalf("Failed to create secret %s: %s", name, errSave)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	sink := &stubSink{}
+	expirySvc, secretsSvc, folder := newTestService(t, sink)
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	createTestSecret(t, secretsSvc, folder, "EXPIRED", sql.NullTime{Time: now.Add(-time.Hour), Valid: true})
+	createTestSecret(t, secretsSvc, folder, "EXPIRING", sql.NullTime{Time: now.Add(time.Hour), Valid: true})
+	createTestSecret(t, secretsSvc, folder, "VALID", sql.NullTime{Time: now.Add(48 * time.Hour), Valid: true})
+	createTestSecret(t, secretsSvc, folder, "PERMANENT", sql.NullTime{})
+
+	results, errCheck := expirySvc.Check(context.Background(), now)
+	if errCheck != nil {
+		t.Fatalf("Check failed: %s", errCheck)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 notifications, got %+v", results)
+	}
+	notified := sink.notified()
+	if len(notified) != 2 || notified["EXPIRED"] != notifications.Type_Expired || notified["EXPIRING"] != notifications.Type_Expiring {
+		t.Errorf("Expected EXPIRED to be expired and EXPIRING to be expiring, got %v", notified)
+	}
+	for _, notification := range results {
+		if notification.FolderUID != folder.UID || notification.FolderName != folder.Name {
+			t.Errorf("Notification about %s refers to folder %s", notification.SecretName, notification.FolderUID)
+		}
+	}
+
+	// Nothing changed since the last check
+	if _, errCheck := expirySvc.Check(context.Background(), now.Add(time.Minute)); errCheck != nil {
+		t.Fatalf("Check failed: %s", errCheck)
+	}
+	if notified := sink.notified(); len(notified) != 0 {
+		t.Errorf("Expected no notifications, got %v", notified)
+	}
+
+	// Expiring secret is reported again once it expires
+	if _, errCheck := expirySvc.Check(context.Background(), now.Add(2*time.Hour)); errCheck != nil {
+		t.Fatalf("Check failed: %s", errCheck)
+	}
+	if notified := sink.notified(); len(notified) != 1 || notified["EXPIRING"] != notifications.Type_Expired {
+		t.Errorf("Expected EXPIRING to be expired, got %v", notified)
+	}
+}
+
+func TestCheckUndelivered(t *testing.T) {
+	sink := &stubSink{err: errors.New("unavailable")}
+	expirySvc, secretsSvc, folder := newTestService(t, sink)
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	createTestSecret(t, secretsSvc, folder, "EXPIRING", sql.NullTime{Time: now.Add(time.Hour), Valid: true})
+
+	if _, errCheck := expirySvc.Check(context.Background(), now); errCheck != nil {
+		t.Fatalf("Check failed: %s", errCheck)
+	}
+	if notified := sink.notified(); len(notified) != 0 {
+		t.Errorf("Expected no notifications, got %v", notified)
+	}
+
+	// Notification is retried once the sink recovers, and only then
+	sink.err = nil
+	for i := 0; i < 2; i++ {
+		if _, errCheck := expirySvc.Check(context.Background(), now); errCheck != nil {
+			t.Fatalf("Check failed: %s", errCheck)
+		}
+	}
+	if len(sink.notifications) != 1 || sink.notifications[0].Type != notifications.Type_Expiring {
+		t.Errorf("Expected single expiring notification, got %+v", sink.notifications)
+	}
+}
@@ -0,0 +1,10 @@
+package expiry
+
+import "time"
+
+type (
+	notifiedEntry struct {
+		Type      string
+		ExpiresAt time.Time
+	}
+)
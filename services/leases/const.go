@@ -0,0 +1,6 @@
+package leases
+
+const (
+	// FolderName Sub-folder of the engine folder leases are stored in
+	FolderName = "leases"
+)
@@ -0,0 +1,302 @@
+package leases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
+	"hideout/internal/common/generics"
+	"hideout/internal/common/model"
+	"hideout/internal/folders"
+	"hideout/internal/leases"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LeaseService Keeps track of leases handed out by engines, persisted as secrets next to the engine data
+type LeaseService struct {
+	secretsSvc *secrets.SecretsService
+	revokers   map[string]leases.Revoker
+}
+
+// NewService Creation of the service
+func NewService(secretsSvc *secrets.SecretsService) *LeaseService {
+	return &LeaseService{secretsSvc: secretsSvc, revokers: make(map[string]leases.Revoker)}
+}
+
+// Register Registers engine callbacks revoking (and optionally renewing) leases with the engine prefix
+func (m *LeaseService) Register(engine string, revoker leases.Revoker) {
+	m.revokers[engine] = revoker
+}
+
+// Engines Prefixes of registered engines, in alphabetical order
+func (m *LeaseService) Engines() []string {
+	engines := make([]string, 0, len(m.revokers))
+	for engine := range m.revokers {
+		engines = append(engines, engine)
+	}
+	slices.Sort(engines)
+	return engines
+}
+
+// Create Assigns identifier to the lease and stores it in the leases sub-folder of the engine folder
+func (m *LeaseService) Create(ctx context.Context, Localizer *i18n.Localizer, folder *folders.Folder, lease leases.Lease) (*leases.Lease, error) {
+	if !lease.ExpiresAt.After(lease.IssuedAt) || lease.ExpiresAt.After(lease.MaxExpiresAt) {
+		return nil, ErrInvalidLifetime
+	}
+	if _, engineExists := m.revokers[lease.Engine]; !engineExists {
+		return nil, errors.Wrapf(ErrUnknownEngine, "Engine %s", lease.Engine)
+	}
+
+	nonce := make([]byte, 12)
+	_, errRead := rand.Read(nonce)
+	if errRead != nil {
+		return nil, errors.Wrap(errRead, "Failed to generate lease identifier")
+	}
+	lease.FolderUID = folder.UID
+	lease.LeaseID = strings.Join([]string{lease.Engine, folder.UID, lease.Path, hex.EncodeToString(nonce)}, leases.Separator)
+
+	leasesFolder, errGetFolder := m.secretsSvc.GetFolderByName(ctx, folder.ID, FolderName)
+	if errors.Is(errGetFolder, apperror.ErrRecordNotFound) {
+		leasesFolder, errGetFolder = m.secretsSvc.CreateFolder(ctx, folders.Folder{ParentID: folder.ID, Name: FolderName})
+	}
+	if errGetFolder != nil {
+		return nil, errors.Wrapf(errGetFolder, "Failed to retrieve folder %s", FolderName)
+	}
+
+	errSave := m.save(ctx, Localizer, &secrets2.Secret{FolderID: leasesFolder.ID, Name: leases.Nonce(lease.LeaseID)}, lease)
+	if errSave != nil {
+		return nil, errSave
+	}
+	return &lease, nil
+}
+
+// Get Retrieves lease by its identifier
+func (m *LeaseService) Get(ctx context.Context, leaseID string) (*leases.Lease, error) {
+	stored, errGetLease := m.get(ctx, leaseID)
+	if errGetLease != nil {
+		return nil, errGetLease
+	}
+	return stored.lease, nil
+}
+
+// List Retrieves leases which identifiers start with the prefix (all leases if prefix is empty)
+func (m *LeaseService) List(ctx context.Context, prefix string) ([]*leases.Lease, error) {
+	storedLeases, errList := m.list(ctx, prefix)
+	if errList != nil {
+		return nil, errList
+	}
+	var results []*leases.Lease
+	for _, stored := range storedLeases {
+		results = append(results, stored.lease)
+	}
+	return results, nil
+}
+
+// Renew Extends lease by the increment (lease increment by default), but not past the maximum lifetime of the lease
+func (m *LeaseService) Renew(ctx context.Context, Localizer *i18n.Localizer, leaseID string, increment time.Duration, now time.Time) (*leases.Lease, error) {
+	stored, errGetLease := m.get(ctx, leaseID)
+	if errGetLease != nil {
+		return nil, errGetLease
+	}
+	lease := *stored.lease
+	if lease.IsExpired(now) {
+		return nil, errors.Wrapf(ErrLeaseExpired, "Lease %s expired at %s", leaseID, lease.ExpiresAt.Format(time.RFC3339))
+	}
+	if !lease.Renewable {
+		return nil, errors.Wrapf(ErrNotRenewable, "Lease %s", leaseID)
+	}
+	if !lease.ExpiresAt.Before(lease.MaxExpiresAt) {
+		return nil, errors.Wrapf(ErrMaxTTLReached, "Lease %s cannot be extended past %s", leaseID,
+			lease.MaxExpiresAt.Format(time.RFC3339))
+	}
+
+	if increment <= 0 {
+		increment = lease.Increment
+	}
+	lease.ExpiresAt = now.Add(increment)
+	if lease.ExpiresAt.After(lease.MaxExpiresAt) {
+		lease.ExpiresAt = lease.MaxExpiresAt
+	}
+
+	revoker, engineExists := m.revokers[lease.Engine]
+	if !engineExists {
+		return nil, errors.Wrapf(ErrUnknownEngine, "Engine %s", lease.Engine)
+	}
+	if renewer, isRenewer := revoker.(leases.Renewer); isRenewer {
+		errRenew := renewer.Renew(ctx, lease)
+		if errRenew != nil {
+			return nil, errors.Wrapf(errRenew, "Engine %s failed to renew lease %s", lease.Engine, leaseID)
+		}
+	}
+
+	errSave := m.save(ctx, Localizer, stored.secret, lease)
+	if errSave != nil {
+		return nil, errSave
+	}
+	return &lease, nil
+}
+
+// Revoke Revokes lease through its engine and removes it
+func (m *LeaseService) Revoke(ctx context.Context, leaseID string) (*leases.Lease, error) {
+	stored, errGetLease := m.get(ctx, leaseID)
+	if errGetLease != nil {
+		return nil, errGetLease
+	}
+	return stored.lease, m.revoke(ctx, stored)
+}
+
+// RevokePrefix Revokes all leases which identifiers start with the prefix, leases failed to be revoked are kept and
+// reported with the first error
+func (m *LeaseService) RevokePrefix(ctx context.Context, prefix string) ([]*leases.Lease, error) {
+	if prefix == "" {
+		return nil, ErrInvalidPrefix
+	}
+	storedLeases, errList := m.list(ctx, prefix)
+	if errList != nil {
+		return nil, errList
+	}
+
+	var results []*leases.Lease
+	var errFirst error
+	for _, stored := range storedLeases {
+		errRevoke := m.revoke(ctx, stored)
+		if errRevoke != nil {
+			log.Printf("Error revoking lease %s: %s", stored.lease.LeaseID, errRevoke.Error())
+			if errFirst == nil {
+				errFirst = errRevoke
+			}
+			continue
+		}
+		results = append(results, stored.lease)
+	}
+	return results, errFirst
+}
+
+// RevokeExpired Revokes all expired leases, failed revocations are retried on the next call
+func (m *LeaseService) RevokeExpired(ctx context.Context, now time.Time) ([]*leases.Lease, error) {
+	storedLeases, errList := m.list(ctx, "")
+	if errList != nil {
+		return nil, errList
+	}
+
+	var results []*leases.Lease
+	for _, stored := range storedLeases {
+		if !stored.lease.IsExpired(now) {
+			continue
+		}
+		errRevoke := m.revoke(ctx, stored)
+		if errRevoke != nil {
+			log.Printf("Error revoking expired lease %s: %s", stored.lease.LeaseID, errRevoke.Error())
+			continue
+		}
+		results = append(results, stored.lease)
+	}
+	return results, nil
+}
+
+// Run Periodically revokes expired leases until context is cancelled
+func (m *LeaseService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		revokedLeases, errRevoke := m.RevokeExpired(ctx, time.Now())
+		if errRevoke != nil {
+			log.Printf("Error revoking expired leases: %s", errRevoke.Error())
+		}
+		for _, revokedLease := range revokedLeases {
+			log.Printf("Expired lease %s was revoked", revokedLease.LeaseID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *LeaseService) revoke(ctx context.Context, stored storedLease) error {
+	revoker, engineExists := m.revokers[stored.lease.Engine]
+	if !engineExists {
+		return errors.Wrapf(ErrUnknownEngine, "Engine %s", stored.lease.Engine)
+	}
+	errRevoke := revoker.Revoke(ctx, *stored.lease)
+	if errRevoke != nil {
+		return errors.Wrapf(errRevoke, "Engine %s failed to revoke lease %s", stored.lease.Engine, stored.lease.LeaseID)
+	}
+
+	errDelete := m.secretsSvc.DeleteSecret(ctx, stored.secret.ID, true)
+	if errDelete != nil {
+		return errors.Wrapf(errDelete, "Failed to delete lease %s", stored.lease.LeaseID)
+	}
+	return nil
+}
+
+func (m *LeaseService) get(ctx context.Context, leaseID string) (storedLease, error) {
+	nonce := leases.Nonce(leaseID)
+	if !nonceRegexp.MatchString(nonce) {
+		return storedLease{}, errors.Wrapf(ErrInvalidLeaseID, "Lease identifier %s", leaseID)
+	}
+	storedLeases, errList := m.load(ctx, secrets2.ListSecretParams{
+		ListParams: generics.ListParams{Deleted: model.No},
+		Name:       nonce,
+		Types:      []string{secrets2.Type_Lease},
+	}, leaseID)
+	if errList != nil {
+		return storedLease{}, errList
+	}
+	for _, stored := range storedLeases {
+		if stored.lease.LeaseID == leaseID {
+			return stored, nil
+		}
+	}
+	return storedLease{}, errors.Wrapf(ErrLeaseNotFound, "Lease %s", leaseID)
+}
+
+func (m *LeaseService) list(ctx context.Context, prefix string) ([]storedLease, error) {
+	return m.load(ctx, secrets2.ListSecretParams{
+		ListParams: generics.ListParams{Deleted: model.No},
+		Types:      []string{secrets2.Type_Lease},
+	}, prefix)
+}
+
+// load Deserializes leases stored in the secrets, keeping the ones which identifiers start with the prefix
+func (m *LeaseService) load(ctx context.Context, params secrets2.ListSecretParams, prefix string) ([]storedLease, error) {
+	leaseSecrets, errGetSecrets := m.secretsSvc.GetSecrets(ctx, params)
+	if errGetSecrets != nil {
+		return nil, errors.Wrap(errGetSecrets, "Error retrieving leases")
+	}
+
+	var results []storedLease
+	for _, leaseSecret := range leaseSecrets {
+		var lease leases.Lease
+		errUnmarshal := json.Unmarshal([]byte(leaseSecret.Value), &lease)
+		if errUnmarshal != nil {
+			log.Printf("Error deserializing lease stored in secret with UID of %s: %s", leaseSecret.UID, errUnmarshal.Error())
+			continue
+		}
+		if strings.HasPrefix(lease.LeaseID, prefix) {
+			results = append(results, storedLease{lease: &lease, secret: leaseSecret})
+		}
+	}
+	return results, nil
+}
+
+func (m *LeaseService) save(ctx context.Context, Localizer *i18n.Localizer, leaseSecret *secrets2.Secret, lease leases.Lease) error {
+	leaseData, errMarshal := json.Marshal(lease)
+	if errMarshal != nil {
+		return errors.Wrapf(errMarshal, "Failed to serialize lease %s", lease.LeaseID)
+	}
+	_, errSaveSecret := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: leaseSecret.FolderID, Name: leaseSecret.Name,
+		Value: string(leaseData), Type: secrets2.Type_Lease})
+	return errSaveSecret
+}
@@ -0,0 +1,14 @@
+package leases
+
+import (
+	"hideout/internal/leases"
+	secrets2 "hideout/internal/secrets"
+)
+
+type (
+	// storedLease Lease along with the secret it is persisted in
+	storedLease struct {
+		lease  *leases.Lease
+		secret *secrets2.Secret
+	}
+)
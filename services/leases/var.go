@@ -0,0 +1,19 @@
+package leases
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+)
+
+var (
+	ErrInvalidLeaseID  = errors.New("Invalid lease identifier")
+	ErrInvalidPrefix   = errors.New("Lease prefix must not be empty")
+	ErrLeaseNotFound   = errors.New("Lease not found")
+	ErrLeaseExpired    = errors.New("Lease has expired")
+	ErrNotRenewable    = errors.New("Lease is not renewable")
+	ErrMaxTTLReached   = errors.New("Lease has reached its maximum lifetime")
+	ErrUnknownEngine   = errors.New("Engine of the lease is not registered")
+	ErrInvalidLifetime = errors.New("Lease must expire in the future and not past its maximum lifetime")
+
+	nonceRegexp = regexp.MustCompile(`^[a-f0-9]{24}$`)
+)
@@ -0,0 +1,26 @@
+package pki
+
+const (
+	KeyType_Any     = "any"
+	KeyType_RSA     = "rsa"
+	KeyType_EC      = "ec"
+	KeyType_Ed25519 = "ed25519"
+
+	// Names of the secrets and sub-folders making up a certificate authority folder
+	SecretName_CACertificate = "CA_CERTIFICATE"
+	SecretName_CAPrivateKey  = "CA_PRIVATE_KEY"
+	SecretName_CAChain       = "CA_CHAIN"
+	FolderName_Roles         = "roles"
+	FolderName_Certificates  = "certificates"
+
+	// Suffixes of the secrets stored for issued certificates (prefixed with IssueParams.Prefix)
+	SecretSuffix_Certificate = "_CERTIFICATE"
+	SecretSuffix_PrivateKey  = "_PRIVATE_KEY"
+	SecretSuffix_CAChain     = "_CA_CHAIN"
+
+	DefaultPrefix = "TLS"
+
+	PEMType_Certificate = "CERTIFICATE"
+	PEMType_PrivateKey  = "PRIVATE KEY"
+	PEMType_CRL         = "X509 CRL"
+)
@@ -0,0 +1,212 @@
+package pki
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/pkg/errors"
+	"math/big"
+	"net"
+	"path"
+	"strings"
+)
+
+// Validate Checks role for consistency, filling in the defaults
+func (r *Role) Validate() error {
+	if !roleNameRegexp.MatchString(r.Name) {
+		return ErrInvalidRoleName
+	}
+	if r.KeyType == "" {
+		r.KeyType = KeyType_Any
+	}
+	if !KeyTypesMap[r.KeyType] {
+		return errors.Wrapf(ErrInvalidKeyType, "Key type %s is not supported", r.KeyType)
+	}
+	for _, domain := range r.AllowedDomains {
+		if _, errMatch := path.Match(domain, ""); errMatch != nil {
+			return errors.Wrapf(errMatch, "Invalid allowed domain pattern %s", domain)
+		}
+	}
+	if r.MaxTTL > 0 && r.TTL > r.MaxTTL {
+		r.TTL = r.MaxTTL
+	}
+	return nil
+}
+
+// AllowsDomain Whether certificate may be issued for the domain name under the role
+func (r Role) AllowsDomain(domain string) bool {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for _, allowedDomain := range r.AllowedDomains {
+		allowedDomain = strings.ToLower(strings.TrimSuffix(allowedDomain, "."))
+		if strings.ContainsAny(allowedDomain, "*?[") {
+			if matched, _ := path.Match(allowedDomain, domain); matched {
+				return true
+			}
+			continue
+		}
+		if r.AllowBareDomains && domain == allowedDomain {
+			return true
+		}
+		if r.AllowSubdomains && strings.HasSuffix(domain, "."+allowedDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPublicKey Whether public key satisfies the key type and size constraints of the role
+func (r Role) CheckPublicKey(publicKey any) error {
+	keyType, keyBits, errKeyType := publicKeyType(publicKey)
+	if errKeyType != nil {
+		return errKeyType
+	}
+	if r.KeyType != KeyType_Any && r.KeyType != keyType {
+		return errors.Wrapf(ErrKeyTypeNotAllowed, "Expected %s key, got %s", r.KeyType, keyType)
+	}
+	if r.KeyBits > 0 && keyType != KeyType_Ed25519 && keyBits < r.KeyBits {
+		return errors.Wrapf(ErrKeyTooWeak, "Expected at least %d bits, got %d", r.KeyBits, keyBits)
+	}
+	return nil
+}
+
+func publicKeyType(publicKey any) (string, int, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return KeyType_RSA, key.N.BitLen(), nil
+	case *ecdsa.PublicKey:
+		return KeyType_EC, key.Curve.Params().BitSize, nil
+	case ed25519.PublicKey:
+		return KeyType_Ed25519, 0, nil
+	}
+	return "", 0, errors.Wrapf(ErrInvalidKeyType, "Unsupported public key of type %T", publicKey)
+}
+
+// GenerateKey Generates private key of given type, default size is used if bits are not specified
+func GenerateKey(keyType string, bits int) (crypto.Signer, error) {
+	if bits == 0 {
+		bits = DefaultKeyBits[keyType]
+	}
+	switch keyType {
+	case KeyType_RSA:
+		return rsa.GenerateKey(rand.Reader, bits)
+	case KeyType_EC:
+		var curve elliptic.Curve
+		switch bits {
+		case 224:
+			curve = elliptic.P224()
+		case 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Wrapf(ErrInvalidKeyType, "Unsupported elliptic curve size %d", bits)
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	case KeyType_Ed25519:
+		_, privateKey, errGenerate := ed25519.GenerateKey(rand.Reader)
+		return privateKey, errGenerate
+	}
+	return nil, errors.Wrapf(ErrInvalidKeyType, "Key type %s is not supported", keyType)
+}
+
+// EncodePrivateKey Encodes private key into PKCS #8 PEM block
+func EncodePrivateKey(privateKey any) (string, error) {
+	keyBytes, errMarshal := x509.MarshalPKCS8PrivateKey(privateKey)
+	if errMarshal != nil {
+		return "", errors.Wrap(errMarshal, "Failed to marshal private key")
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: PEMType_PrivateKey, Bytes: keyBytes})), nil
+}
+
+// DecodePrivateKey Decodes private key from PEM block (PKCS #8, PKCS #1 or SEC 1)
+func DecodePrivateKey(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("No PEM block found in private key")
+	}
+
+	var privateKey any
+	var errParse error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, errParse = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		privateKey, errParse = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		privateKey, errParse = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if errParse != nil {
+		return nil, errors.Wrap(errParse, "Failed to parse private key")
+	}
+
+	signer, isSigner := privateKey.(crypto.Signer)
+	if !isSigner {
+		return nil, errors.Wrapf(ErrInvalidKeyType, "Private key of type %T cannot sign", privateKey)
+	}
+	return signer, nil
+}
+
+// EncodeCertificate Encodes DER certificate into PEM block
+func EncodeCertificate(der []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: PEMType_Certificate, Bytes: der}))
+}
+
+// DecodeCertificate Decodes first certificate found in PEM data
+func DecodeCertificate(data string) (*x509.Certificate, error) {
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("No certificate PEM block found")
+		}
+		if block.Type == PEMType_Certificate {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// DecodeCSR Decodes and verifies signature of the certificate signing request
+func DecodeCSR(data string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil || !strings.HasSuffix(block.Type, "CERTIFICATE REQUEST") {
+		return nil, errors.Wrap(ErrInvalidCSR, "No certificate request PEM block found")
+	}
+	csr, errParse := x509.ParseCertificateRequest(block.Bytes)
+	if errParse != nil {
+		return nil, errors.Wrap(ErrInvalidCSR, errParse.Error())
+	}
+	if errCheck := csr.CheckSignature(); errCheck != nil {
+		return nil, errors.Wrap(ErrInvalidCSR, errCheck.Error())
+	}
+	return csr, nil
+}
+
+// SerialToString Hex representation of the serial number, used as name of the registry entry
+func SerialToString(serial *big.Int) string {
+	return fmt.Sprintf("%x", serial)
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func parseIPs(values []string) ([]net.IP, error) {
+	var ips []net.IP
+	for _, value := range values {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, errors.Wrapf(ErrDomainNotAllowed, "Invalid IP address %s", value)
+		}
+		ips = append(ips, ip)
+	}
+	return ips, nil
+}
@@ -0,0 +1,307 @@
+package pki
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/json"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
+	"hideout/internal/common/generics"
+	"hideout/internal/common/model"
+	secrets2 "hideout/internal/secrets"
+	"math/big"
+	"net"
+	"slices"
+	"time"
+)
+
+// Issue Issues leaf certificate for either provided signing request or generated key pair, constrained by the role
+func (m *PKIService) Issue(ctx context.Context, Localizer *i18n.Localizer, params IssueParams) (*CertificateBundle, error) {
+	ca, caFolder, errLoadCA := m.LoadCA(ctx, params.FolderUID)
+	if errLoadCA != nil {
+		return nil, errLoadCA
+	}
+	role, errGetRole := m.GetRole(ctx, caFolder, params.Role)
+	if errGetRole != nil {
+		return nil, errGetRole
+	}
+
+	var publicKey any
+	var privateKey crypto.Signer
+	commonName, altNames, ipSANs := params.CommonName, params.AltNames, params.IPSANs
+	if params.CSR != "" {
+		csr, errDecodeCSR := DecodeCSR(params.CSR)
+		if errDecodeCSR != nil {
+			return nil, errDecodeCSR
+		}
+		publicKey = csr.PublicKey
+		if commonName == "" {
+			commonName = csr.Subject.CommonName
+		}
+		altNames = append(altNames, csr.DNSNames...)
+		for _, ip := range csr.IPAddresses {
+			ipSANs = append(ipSANs, ip.String())
+		}
+	} else {
+		keyType := role.KeyType
+		if keyType == KeyType_Any {
+			keyType = KeyType_EC
+		}
+		generatedKey, errGenerateKey := GenerateKey(keyType, role.KeyBits)
+		if errGenerateKey != nil {
+			return nil, errGenerateKey
+		}
+		privateKey, publicKey = generatedKey, generatedKey.Public()
+	}
+
+	errCheckKey := role.CheckPublicKey(publicKey)
+	if errCheckKey != nil {
+		return nil, errCheckKey
+	}
+
+	// Common name is also included as a subject alternative name, since clients ignore the former
+	var dnsNames []string
+	for _, name := range append([]string{commonName}, altNames...) {
+		if name == "" || slices.Contains(dnsNames, name) {
+			continue
+		}
+		if net.ParseIP(name) != nil {
+			ipSANs = append(ipSANs, name)
+			continue
+		}
+		if !role.AllowsDomain(name) {
+			return nil, errors.Wrapf(ErrDomainNotAllowed, "Domain %s is not allowed by role %s", name, role.Name)
+		}
+		dnsNames = append(dnsNames, name)
+	}
+	ipAddresses, errParseIPs := parseIPs(ipSANs)
+	if errParseIPs != nil {
+		return nil, errParseIPs
+	}
+	if len(ipAddresses) > 0 && !role.AllowIPSANs {
+		return nil, errors.Wrapf(ErrDomainNotAllowed, "IP subject alternative names are not allowed by role %s", role.Name)
+	}
+	if len(dnsNames) == 0 && len(ipAddresses) == 0 {
+		return nil, errors.Wrap(ErrDomainNotAllowed, "Certificate must have common name or alternative names")
+	}
+
+	ttl := params.TTL
+	if ttl <= 0 {
+		ttl = role.TTL
+	}
+	if ttl <= 0 {
+		ttl = DefaultLeafTTL
+	}
+	if role.MaxTTL > 0 && ttl > role.MaxTTL {
+		ttl = role.MaxTTL
+	}
+	now := time.Now()
+	notAfter := now.Add(ttl)
+	if notAfter.After(ca.Certificate.NotAfter) {
+		notAfter = ca.Certificate.NotAfter
+	}
+
+	serial, errSerial := randomSerial()
+	if errSerial != nil {
+		return nil, errors.Wrap(errSerial, "Failed to generate serial number")
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              dnsNames,
+		IPAddresses:           ipAddresses,
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	if keyType, _, _ := publicKeyType(publicKey); keyType == KeyType_RSA {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	if role.ServerFlag {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+	}
+	if role.ClientFlag {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+
+	signer, errSigner := m.signer(ca)
+	if errSigner != nil {
+		return nil, errSigner
+	}
+	certificateDER, errCreateCertificate := x509.CreateCertificate(rand.Reader, template, ca.Certificate, publicKey, signer)
+	if errCreateCertificate != nil {
+		return nil, errors.Wrap(errCreateCertificate, "Failed to create certificate")
+	}
+
+	bundle := &CertificateBundle{SerialNumber: SerialToString(serial), Certificate: EncodeCertificate(certificateDER),
+		CAChain: ca.ChainPEM, NotAfter: notAfter}
+	if privateKey != nil {
+		privateKeyPEM, errEncodeKey := EncodePrivateKey(privateKey)
+		if errEncodeKey != nil {
+			return nil, errEncodeKey
+		}
+		bundle.PrivateKey = privateKeyPEM
+	}
+
+	errRecord := m.saveRecord(ctx, Localizer, caFolder.ID, CertificateRecord{SerialNumber: bundle.SerialNumber,
+		CommonName: commonName, Role: role.Name, NotBefore: template.NotBefore, NotAfter: notAfter, Certificate: bundle.Certificate})
+	if errRecord != nil {
+		return nil, errRecord
+	}
+
+	if params.TargetFolderUID != "" {
+		errStore := m.storeBundle(ctx, Localizer, params.TargetFolderUID, params.Prefix, bundle)
+		if errStore != nil {
+			return nil, errStore
+		}
+	}
+
+	return bundle, nil
+}
+
+// Revoke Marks certificate with given serial number as revoked, so that it is included into the revocation list
+func (m *PKIService) Revoke(ctx context.Context, Localizer *i18n.Localizer, folderUID string, serialNumber string) (*CertificateRecord, error) {
+	_, caFolder, errLoadCA := m.LoadCA(ctx, folderUID)
+	if errLoadCA != nil {
+		return nil, errLoadCA
+	}
+
+	records, errGetRecords := m.getRecords(ctx, caFolder.ID, serialNumber)
+	if errGetRecords != nil {
+		return nil, errGetRecords
+	}
+	if len(records) == 0 {
+		return nil, errors.Wrapf(ErrCertificateNotFound, "Serial number %s", serialNumber)
+	}
+
+	record := records[0]
+	if record.RevokedAt == nil {
+		revokedAt := time.Now()
+		record.RevokedAt = &revokedAt
+		errRecord := m.saveRecord(ctx, Localizer, caFolder.ID, record)
+		if errRecord != nil {
+			return nil, errRecord
+		}
+	}
+	return &record, nil
+}
+
+// CRL Generates DER-encoded certificate revocation list of the authority
+func (m *PKIService) CRL(ctx context.Context, folderUID string) ([]byte, error) {
+	ca, caFolder, errLoadCA := m.LoadCA(ctx, folderUID)
+	if errLoadCA != nil {
+		return nil, errLoadCA
+	}
+
+	records, errGetRecords := m.getRecords(ctx, caFolder.ID, "")
+	if errGetRecords != nil {
+		return nil, errGetRecords
+	}
+
+	now := time.Now()
+	var revokedCertificates []x509.RevocationListEntry
+	for _, record := range records {
+		// Expired certificates are of no interest to anyone anymore
+		if record.RevokedAt == nil || record.NotAfter.Before(now) {
+			continue
+		}
+		serial, isValid := new(big.Int).SetString(record.SerialNumber, 16)
+		if !isValid {
+			return nil, errors.Errorf("Invalid serial number %s in registry", record.SerialNumber)
+		}
+		revokedCertificates = append(revokedCertificates, x509.RevocationListEntry{SerialNumber: serial, RevocationTime: *record.RevokedAt})
+	}
+
+	signer, errSigner := m.signer(ca)
+	if errSigner != nil {
+		return nil, errSigner
+	}
+	// Revocation list number has to increase monotonically, time is good enough for that
+	return x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(now.UnixNano()),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(CRLLifetime),
+		RevokedCertificateEntries: revokedCertificates,
+	}, ca.Certificate, signer)
+}
+
+func (m *PKIService) getRecords(ctx context.Context, caFolderID uint, serialNumber string) ([]CertificateRecord, error) {
+	certificatesFolder, errGetFolder := m.getChildFolder(ctx, caFolderID, FolderName_Certificates, false)
+	if errGetFolder != nil {
+		if errors.Is(errGetFolder, apperror.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errGetFolder
+	}
+
+	recordSecrets, errGetSecrets := m.secretsSvc.GetSecrets(ctx, secrets2.ListSecretParams{
+		ListParams: generics.ListParams{Deleted: model.No}, FolderIDs: []uint{certificatesFolder.ID},
+		Types: []string{secrets2.Type_PKISerial}, Scriptable: model.YesOrNo,
+	})
+	if errGetSecrets != nil {
+		return nil, errors.Wrap(errGetSecrets, "Failed to retrieve serial registry")
+	}
+
+	var records []CertificateRecord
+	for _, recordSecret := range recordSecrets {
+		if serialNumber != "" && recordSecret.Name != serialNumber {
+			continue
+		}
+		var record CertificateRecord
+		errUnmarshal := json.Unmarshal([]byte(recordSecret.Value), &record)
+		if errUnmarshal != nil {
+			return nil, errors.Wrapf(errUnmarshal, "Failed to deserialize registry entry %s", recordSecret.Name)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (m *PKIService) saveRecord(ctx context.Context, Localizer *i18n.Localizer, caFolderID uint, record CertificateRecord) error {
+	certificatesFolder, errGetFolder := m.getChildFolder(ctx, caFolderID, FolderName_Certificates, true)
+	if errGetFolder != nil {
+		return errGetFolder
+	}
+	recordData, errMarshal := json.Marshal(record)
+	if errMarshal != nil {
+		return errors.Wrapf(errMarshal, "Failed to serialize registry entry %s", record.SerialNumber)
+	}
+	_, errSaveSecret := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: certificatesFolder.ID, Name: record.SerialNumber,
+		Value: string(recordData), Type: secrets2.Type_PKISerial})
+	return errSaveSecret
+}
+
+func (m *PKIService) storeBundle(ctx context.Context, Localizer *i18n.Localizer, folderUID string, prefix string, bundle *CertificateBundle) error {
+	targetFolder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+	if errGetFolder != nil {
+		return errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+	}
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	bundleSecrets := []secrets2.Secret{
+		{Name: prefix + SecretSuffix_Certificate, Value: bundle.Certificate, Type: secrets2.Type_Certificate,
+			ExpiresAt: sql.NullTime{Time: bundle.NotAfter, Valid: true}},
+		{Name: prefix + SecretSuffix_CAChain, Value: bundle.CAChain, Type: secrets2.Type_Certificate},
+	}
+	if bundle.PrivateKey != "" {
+		bundleSecrets = append(bundleSecrets, secrets2.Secret{Name: prefix + SecretSuffix_PrivateKey, Value: bundle.PrivateKey,
+			Type: secrets2.Type_PrivateKey})
+	}
+	for _, secret := range bundleSecrets {
+		secret.FolderID = targetFolder.ID
+		_, errSaveSecret := m.secretsSvc.SaveSecret(ctx, Localizer, secret)
+		if errSaveSecret != nil {
+			return errSaveSecret
+		}
+	}
+	return nil
+}
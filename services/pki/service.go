@@ -0,0 +1,247 @@
+package pki
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/json"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/encryption"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"time"
+)
+
+// PKIService Certificate authority keeping its keys, roles and serial registry as secrets inside a folder, private key
+// of the authority is encrypted at rest
+type PKIService struct {
+	secretsSvc    *secrets.SecretsService
+	encryptionKey []byte
+}
+
+// NewService Creation of the service
+func NewService(secretsSvc *secrets.SecretsService, encryptionKey []byte) *PKIService {
+	return &PKIService{secretsSvc: secretsSvc, encryptionKey: encryptionKey}
+}
+
+// CreateCA Creates root (self-signed) or intermediate (signed by parent authority) certificate authority in the folder
+func (m *PKIService) CreateCA(ctx context.Context, Localizer *i18n.Localizer, params CreateCAParams) (*CertificateBundle, error) {
+	caFolder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, params.FolderUID)
+	if errGetFolder != nil {
+		return nil, errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", params.FolderUID)
+	}
+
+	_, errGetCertificate := m.secretsSvc.GetSecretByName(ctx, caFolder.ID, SecretName_CACertificate)
+	if errGetCertificate == nil {
+		return nil, ErrAlreadyCA
+	} else if !errors.Is(errGetCertificate, apperror.ErrRecordNotFound) {
+		return nil, errGetCertificate
+	}
+
+	if params.KeyType == "" || params.KeyType == KeyType_Any {
+		params.KeyType = KeyType_EC
+	}
+	privateKey, errGenerateKey := GenerateKey(params.KeyType, params.KeyBits)
+	if errGenerateKey != nil {
+		return nil, errGenerateKey
+	}
+
+	serial, errSerial := randomSerial()
+	if errSerial != nil {
+		return nil, errors.Wrap(errSerial, "Failed to generate serial number")
+	}
+
+	ttl := params.TTL
+	if ttl <= 0 {
+		ttl = DefaultCATTL
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: params.CommonName},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(ttl),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            params.MaxPathLen,
+		MaxPathLenZero:        params.MaxPathLen == 0,
+	}
+	if params.Organization != "" {
+		template.Subject.Organization = []string{params.Organization}
+	}
+	if params.Country != "" {
+		template.Subject.Country = []string{params.Country}
+	}
+
+	// Self-signed unless parent authority is specified
+	issuerCertificate, issuerKey, chainPEM := template, privateKey, ""
+	if params.ParentFolderUID != "" {
+		parentCA, _, errLoadParent := m.LoadCA(ctx, params.ParentFolderUID)
+		if errLoadParent != nil {
+			return nil, errors.Wrapf(errLoadParent, "Failed to load parent certificate authority in folder with UID of %s", params.ParentFolderUID)
+		}
+		if template.NotAfter.After(parentCA.Certificate.NotAfter) {
+			template.NotAfter = parentCA.Certificate.NotAfter
+		}
+		parentKey, errOpenParentKey := m.signer(parentCA)
+		if errOpenParentKey != nil {
+			return nil, errOpenParentKey
+		}
+		issuerCertificate, issuerKey, chainPEM = parentCA.Certificate, parentKey, parentCA.ChainPEM
+	}
+
+	certificateDER, errCreateCertificate := x509.CreateCertificate(rand.Reader, template, issuerCertificate, privateKey.Public(), issuerKey)
+	if errCreateCertificate != nil {
+		return nil, errors.Wrap(errCreateCertificate, "Failed to create certificate authority certificate")
+	}
+	certificatePEM := EncodeCertificate(certificateDER)
+	privateKeyPEM, errEncodeKey := EncodePrivateKey(privateKey)
+	if errEncodeKey != nil {
+		return nil, errEncodeKey
+	}
+	sealedPrivateKey, errSeal := encryption.Seal(m.encryptionKey, []byte(privateKeyPEM))
+	if errSeal != nil {
+		return nil, errSeal
+	}
+
+	expiresAt := sql.NullTime{Time: template.NotAfter, Valid: true}
+	for _, secret := range []secrets2.Secret{
+		{Name: SecretName_CACertificate, Value: certificatePEM, Type: secrets2.Type_Certificate, ExpiresAt: expiresAt},
+		{Name: SecretName_CAPrivateKey, Value: sealedPrivateKey, Type: secrets2.Type_PKICAPrivateKey},
+		{Name: SecretName_CAChain, Value: certificatePEM + chainPEM, Type: secrets2.Type_Certificate},
+	} {
+		secret.FolderID = caFolder.ID
+		_, errSaveSecret := m.secretsSvc.SaveSecret(ctx, Localizer, secret)
+		if errSaveSecret != nil {
+			return nil, errSaveSecret
+		}
+	}
+
+	for _, folderName := range []string{FolderName_Roles, FolderName_Certificates} {
+		_, errGetFolder := m.getChildFolder(ctx, caFolder.ID, folderName, true)
+		if errGetFolder != nil {
+			return nil, errGetFolder
+		}
+	}
+
+	return &CertificateBundle{SerialNumber: SerialToString(serial), Certificate: certificatePEM,
+		CAChain: certificatePEM + chainPEM, NotAfter: template.NotAfter}, nil
+}
+
+// LoadCA Loads certificate authority stored in the folder, its private key stays sealed until something is signed
+func (m *PKIService) LoadCA(ctx context.Context, folderUID string) (*CertificateAuthority, *folders.Folder, error) {
+	caFolder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+	if errGetFolder != nil {
+		return nil, nil, errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+	}
+
+	var caSecrets = make(map[string]string)
+	for _, secretName := range []string{SecretName_CACertificate, SecretName_CAPrivateKey, SecretName_CAChain} {
+		secret, errGetSecret := m.secretsSvc.GetSecretByName(ctx, caFolder.ID, secretName)
+		if errGetSecret != nil {
+			if errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+				return nil, nil, errors.Wrapf(ErrNotCertificateAuthority, "Secret %s is missing", secretName)
+			}
+			return nil, nil, errGetSecret
+		}
+		caSecrets[secretName] = secret.Value
+	}
+
+	certificate, errDecodeCertificate := DecodeCertificate(caSecrets[SecretName_CACertificate])
+	if errDecodeCertificate != nil {
+		return nil, nil, errors.Wrap(errDecodeCertificate, "Failed to decode certificate authority certificate")
+	}
+
+	return &CertificateAuthority{Certificate: certificate, sealedPrivateKey: caSecrets[SecretName_CAPrivateKey],
+		CertificatePEM: caSecrets[SecretName_CACertificate], ChainPEM: caSecrets[SecretName_CAChain]}, caFolder, nil
+}
+
+// signer Decrypts and decodes private key of the authority right before it signs something
+func (m *PKIService) signer(ca *CertificateAuthority) (crypto.Signer, error) {
+	privateKeyPEM, errOpen := encryption.Open(m.encryptionKey, ca.sealedPrivateKey)
+	if errOpen != nil {
+		return nil, errors.Wrap(errOpen, "Failed to decrypt certificate authority private key")
+	}
+	privateKey, errDecodeKey := DecodePrivateKey(string(privateKeyPEM))
+	if errDecodeKey != nil {
+		return nil, errors.Wrap(errDecodeKey, "Failed to decode certificate authority private key")
+	}
+	return privateKey, nil
+}
+
+// SaveRole Creates or replaces role of the certificate authority
+func (m *PKIService) SaveRole(ctx context.Context, Localizer *i18n.Localizer, folderUID string, role Role) (*Role, error) {
+	errValidate := role.Validate()
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	_, caFolder, errLoadCA := m.LoadCA(ctx, folderUID)
+	if errLoadCA != nil {
+		return nil, errLoadCA
+	}
+	rolesFolder, errGetFolder := m.getChildFolder(ctx, caFolder.ID, FolderName_Roles, true)
+	if errGetFolder != nil {
+		return nil, errGetFolder
+	}
+
+	roleData, errMarshal := json.Marshal(role)
+	if errMarshal != nil {
+		return nil, errors.Wrapf(errMarshal, "Failed to serialize role %s", role.Name)
+	}
+	_, errSaveSecret := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: rolesFolder.ID, Name: role.Name,
+		Value: string(roleData), Type: secrets2.Type_PKIRole})
+	if errSaveSecret != nil {
+		return nil, errSaveSecret
+	}
+
+	return &role, nil
+}
+
+// GetRole Retrieves role of the certificate authority stored in the folder
+func (m *PKIService) GetRole(ctx context.Context, caFolder *folders.Folder, roleName string) (*Role, error) {
+	if !roleNameRegexp.MatchString(roleName) {
+		return nil, ErrInvalidRoleName
+	}
+	rolesFolder, errGetFolder := m.getChildFolder(ctx, caFolder.ID, FolderName_Roles, false)
+	if errGetFolder != nil {
+		if errors.Is(errGetFolder, apperror.ErrRecordNotFound) {
+			return nil, errors.Wrapf(ErrRoleNotFound, "Role %s does not exist", roleName)
+		}
+		return nil, errGetFolder
+	}
+	roleSecret, errGetSecret := m.secretsSvc.GetSecretByName(ctx, rolesFolder.ID, roleName)
+	if errGetSecret != nil {
+		if errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+			return nil, errors.Wrapf(ErrRoleNotFound, "Role %s does not exist", roleName)
+		}
+		return nil, errGetSecret
+	}
+
+	var role Role
+	errUnmarshal := json.Unmarshal([]byte(roleSecret.Value), &role)
+	if errUnmarshal != nil {
+		return nil, errors.Wrapf(errUnmarshal, "Failed to deserialize role %s", roleName)
+	}
+	return &role, nil
+}
+
+func (m *PKIService) getChildFolder(ctx context.Context, parentID uint, name string, create bool) (*folders.Folder, error) {
+	childFolder, errGetFolder := m.secretsSvc.GetFolderByName(ctx, parentID, name)
+	if errGetFolder == nil || !create || !errors.Is(errGetFolder, apperror.ErrRecordNotFound) {
+		return childFolder, errGetFolder
+	}
+
+	newFolder, errCreateFolder := m.secretsSvc.CreateFolder(ctx, folders.Folder{ParentID: parentID, Name: name})
+	if errCreateFolder != nil {
+		return nil, errors.Wrapf(errCreateFolder, "Failed to create folder %s", name)
+	}
+	return newFolder, nil
+}
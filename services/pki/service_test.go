@@ -0,0 +1,116 @@
+package pki
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"hideout/config"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/encryption"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"hideout/structs"
+	"testing"
+)
+
+// newTestService Service over empty in-memory repositories and a random encryption key
+func newTestService(t *testing.T) (*PKIService, *secrets.SecretsService) {
+	t.Helper()
+	structs.Folders, structs.Secrets = nil, nil
+	secretsSvc, errCreateService := secrets.NewService(context.Background(), config.RepositoryConfig{Type: secrets.RepositoryType_InMemory},
+		config.RepositoryConfig{Type: secrets.RepositoryType_InMemory}, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		t.Fatalf("Failed to create secrets service: %s", errCreateService)
+	}
+	encryptionKey := make([]byte, encryption.KeySize)
+	if _, errRead := rand.Read(encryptionKey); errRead != nil {
+		t.Fatalf("Failed to generate encryption key: %s", errRead)
+	}
+	return NewService(secretsSvc, encryptionKey), secretsSvc
+}
+
+func createTestFolder(t *testing.T, secretsSvc *secrets.SecretsService, name string) *folders.Folder {
+	t.Helper()
+	folder, errCreateFolder := secretsSvc.CreateFolder(context.Background(), folders.Folder{Name: name})
+	if errCreateFolder != nil {
+		t.Fatalf("Failed to create folder: %s", errCreateFolder)
+	}
+	return folder
+}
+
+func TestCAPrivateKeySealed(t *testing.T) {
+	pkiSvc, secretsSvc := newTestService(t)
+	rootFolder := createTestFolder(t, secretsSvc, "root")
+	intermediateFolder := createTestFolder(t, secretsSvc, "intermediate")
+
+	_, errCreateRoot := pkiSvc.CreateCA(context.Background(), nil, CreateCAParams{FolderUID: rootFolder.UID, CommonName: "Root", MaxPathLen: -1})
+	if errCreateRoot != nil {
+		t.Fatalf("Failed to create root authority: %s", errCreateRoot)
+	}
+	// Intermediate authority is signed by the unsealed key of the root one
+	intermediate, errCreateIntermediate := pkiSvc.CreateCA(context.Background(), nil, CreateCAParams{FolderUID: intermediateFolder.UID,
+		ParentFolderUID: rootFolder.UID, CommonName: "Intermediate"})
+	if errCreateIntermediate != nil {
+		t.Fatalf("Failed to create intermediate authority: %s", errCreateIntermediate)
+	}
+
+	for _, folder := range []*folders.Folder{rootFolder, intermediateFolder} {
+		stored, errGetSecret := secretsSvc.GetSecretByName(context.Background(), folder.ID, SecretName_CAPrivateKey)
+		if errGetSecret != nil {
+			t.Fatalf("Failed to read stored private key: %s", errGetSecret)
+		}
+		if stored.Type != secrets2.Type_PKICAPrivateKey || !stored.IsSealed() {
+			t.Errorf("Private key of %s is stored as %q type", folder.Name, stored.Type)
+		}
+		if !encryption.IsSealed(stored.Value) {
+			t.Errorf("Private key of %s is not encrypted", folder.Name)
+		}
+		if block, _ := pem.Decode([]byte(stored.Value)); block != nil {
+			t.Errorf("Private key of %s is stored as %s PEM block", folder.Name, block.Type)
+		}
+	}
+
+	root, _, errLoadRoot := pkiSvc.LoadCA(context.Background(), rootFolder.UID)
+	if errLoadRoot != nil {
+		t.Fatalf("Failed to load root authority: %s", errLoadRoot)
+	}
+	intermediateCertificate, errDecode := DecodeCertificate(intermediate.Certificate)
+	if errDecode != nil {
+		t.Fatalf("Failed to decode intermediate certificate: %s", errDecode)
+	}
+	if errCheck := intermediateCertificate.CheckSignatureFrom(root.Certificate); errCheck != nil {
+		t.Errorf("Intermediate certificate is not signed by root authority: %s", errCheck)
+	}
+}
+
+func TestCAPrivateKeyWrongEncryptionKey(t *testing.T) {
+	pkiSvc, secretsSvc := newTestService(t)
+	folder := createTestFolder(t, secretsSvc, "root")
+	_, errCreateCA := pkiSvc.CreateCA(context.Background(), nil, CreateCAParams{FolderUID: folder.UID, CommonName: "Root", MaxPathLen: -1})
+	if errCreateCA != nil {
+		t.Fatalf("Failed to create authority: %s", errCreateCA)
+	}
+
+	otherKey := make([]byte, encryption.KeySize)
+	_, errCRL := NewService(secretsSvc, otherKey).CRL(context.Background(), folder.UID)
+	if errCRL == nil {
+		t.Fatal("Revocation list was signed with key decrypted by wrong encryption key")
+	}
+
+	crlDER, errCRL := pkiSvc.CRL(context.Background(), folder.UID)
+	if errCRL != nil {
+		t.Fatalf("Failed to generate revocation list: %s", errCRL)
+	}
+	ca, _, errLoadCA := pkiSvc.LoadCA(context.Background(), folder.UID)
+	if errLoadCA != nil {
+		t.Fatalf("Failed to load authority: %s", errLoadCA)
+	}
+	crl, errParse := x509.ParseRevocationList(crlDER)
+	if errParse != nil {
+		t.Fatalf("Failed to parse revocation list: %s", errParse)
+	}
+	if errCheck := crl.CheckSignatureFrom(ca.Certificate); errCheck != nil {
+		t.Errorf("Revocation list is not signed by the authority: %s", errCheck)
+	}
+}
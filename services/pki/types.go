@@ -0,0 +1,80 @@
+package pki
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+type (
+	// Role Constraints applied to certificates issued by a certificate authority
+	Role struct {
+		Name string `json:"Name"`
+		// AllowedDomains may contain glob patterns (e.g. *.example.com)
+		AllowedDomains   []string      `json:"AllowedDomains"`
+		AllowBareDomains bool          `json:"AllowBareDomains"`
+		AllowSubdomains  bool          `json:"AllowSubdomains"`
+		AllowIPSANs      bool          `json:"AllowIPSANs"`
+		KeyType          string        `json:"KeyType"`
+		KeyBits          int           `json:"KeyBits"`
+		TTL              time.Duration `json:"TTL"`
+		MaxTTL           time.Duration `json:"MaxTTL"`
+		ServerFlag       bool          `json:"ServerFlag"`
+		ClientFlag       bool          `json:"ClientFlag"`
+	}
+
+	// CertificateRecord Entry of the serial registry of a certificate authority
+	CertificateRecord struct {
+		SerialNumber string     `json:"SerialNumber"`
+		CommonName   string     `json:"CommonName"`
+		Role         string     `json:"Role"`
+		NotBefore    time.Time  `json:"NotBefore"`
+		NotAfter     time.Time  `json:"NotAfter"`
+		RevokedAt    *time.Time `json:"RevokedAt,omitempty"`
+		Certificate  string     `json:"Certificate"`
+	}
+
+	CreateCAParams struct {
+		// FolderUID Folder the authority is stored in
+		FolderUID string
+		// ParentFolderUID Folder of the authority signing the new one, root authority is created if empty
+		ParentFolderUID string
+		CommonName      string
+		Organization    string
+		Country         string
+		KeyType         string
+		KeyBits         int
+		TTL             time.Duration
+		MaxPathLen      int
+	}
+
+	IssueParams struct {
+		FolderUID  string
+		Role       string
+		CommonName string
+		AltNames   []string
+		IPSANs     []string
+		TTL        time.Duration
+		// CSR PEM-encoded signing request, key pair is generated if empty
+		CSR string
+		// TargetFolderUID Folder to store issued certificate bundle in (optional)
+		TargetFolderUID string
+		Prefix          string
+	}
+
+	CertificateAuthority struct {
+		Certificate *x509.Certificate
+		// sealedPrivateKey Encrypted PEM-encoded private key, only opened by signer
+		sealedPrivateKey string
+		// CertificatePEM and ChainPEM are stored as-is to be returned with issued certificates
+		CertificatePEM string
+		ChainPEM       string
+	}
+
+	CertificateBundle struct {
+		SerialNumber string
+		Certificate  string
+		PrivateKey   string
+		CAChain      string
+		NotAfter     time.Time
+	}
+)
@@ -0,0 +1,30 @@
+package pki
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrNotCertificateAuthority = errors.New("Folder does not contain a certificate authority")
+	ErrAlreadyCA               = errors.New("Folder already contains a certificate authority")
+	ErrRoleNotFound            = errors.New("Role not found")
+	ErrInvalidRoleName         = errors.New("Role name may only contain letters, digits, dashes and underscores")
+	ErrDomainNotAllowed        = errors.New("Domain is not allowed by the role")
+	ErrKeyTypeNotAllowed       = errors.New("Key type is not allowed by the role")
+	ErrKeyTooWeak              = errors.New("Key size is below the minimum required by the role")
+	ErrInvalidCSR              = errors.New("Invalid certificate signing request")
+	ErrInvalidKeyType          = errors.New("Invalid key type")
+	ErrCertificateNotFound     = errors.New("Certificate with given serial number was not issued by the authority")
+
+	KeyTypesMap = map[string]bool{KeyType_Any: true, KeyType_RSA: true, KeyType_EC: true, KeyType_Ed25519: true}
+
+	DefaultKeyBits = map[string]int{KeyType_RSA: 2048, KeyType_EC: 256, KeyType_Ed25519: 0}
+
+	DefaultCATTL   = 10 * 365 * 24 * time.Hour
+	DefaultLeafTTL = 30 * 24 * time.Hour
+	CRLLifetime    = 24 * time.Hour
+
+	roleNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
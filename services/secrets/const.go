@@ -5,4 +5,8 @@ const (
 	RepositoryType_Redis    = 1
 	RepositoryType_Database = 2
 	RepositoryType_File     = 3
+	// RepositoryType_Store Folders and secrets kept together in a single file
+	RepositoryType_Store = 4
+	// RepositoryType_Embedded Embedded transactional key-value store file, may be shared by folders and secrets
+	RepositoryType_Embedded = 5
 )
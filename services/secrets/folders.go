@@ -2,6 +2,10 @@ package secrets
 
 import (
 	"context"
+	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
+	"hideout/internal/common/generics"
+	"hideout/internal/common/model"
 	"hideout/internal/folders"
 )
 
@@ -49,3 +53,20 @@ func (m *SecretsService) DeleteFolder(ctx context.Context, id uint, forceDelete
 func (m *SecretsService) CountFolders(ctx context.Context, params folders.ListFolderParams) (uint, error) {
 	return m.foldersRepository.Count(ctx, params)
 }
+
+// GetFolderByName Retrieves folder by exact name within the parent folder
+func (m *SecretsService) GetFolderByName(ctx context.Context, parentID uint, name string) (*folders.Folder, error) {
+	childFolders, errGetFolders := m.foldersRepository.Get(ctx, folders.ListFolderParams{
+		ListParams: generics.ListParams{Deleted: model.No}, ParentFolderID: parentID, Name: name,
+	})
+	if errGetFolders != nil {
+		return nil, errors.Wrapf(errGetFolders, "Failed to retrieve folder %s", name)
+	}
+	// Name filter is a pattern, hence the exact comparison
+	for _, childFolder := range childFolders {
+		if childFolder.ParentID == parentID && childFolder.Name == name {
+			return childFolder, nil
+		}
+	}
+	return nil, apperror.ErrRecordNotFound
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
+	"hideout/internal/common/apperror"
+	"hideout/internal/common/generics"
+	"hideout/internal/common/model"
 	"hideout/internal/secrets"
 	"regexp"
 )
@@ -64,3 +67,43 @@ func (m *SecretsService) DeleteSecret(ctx context.Context, id uint, forceDelete
 func (m *SecretsService) CountSecrets(ctx context.Context, params secrets.ListSecretParams) (uint, error) {
 	return m.secretsRepository.Count(ctx, params)
 }
+
+// GetSecretByName Retrieves secret by exact name within the folder
+func (m *SecretsService) GetSecretByName(ctx context.Context, folderID uint, name string) (*secrets.Secret, error) {
+	secretsList, errGetSecrets := m.secretsRepository.Get(ctx, secrets.ListSecretParams{
+		ListParams: generics.ListParams{Deleted: model.No}, FolderIDs: []uint{folderID}, Name: name, Scriptable: model.YesOrNo,
+	})
+	if errGetSecrets != nil {
+		return nil, errors.Wrapf(errGetSecrets, "Failed to retrieve secret %s", name)
+	}
+	// Name filter is a pattern, hence the exact comparison
+	for _, secret := range secretsList {
+		if secret.FolderID == folderID && secret.Name == name {
+			return secret, nil
+		}
+	}
+	return nil, apperror.ErrRecordNotFound
+}
+
+// SaveSecret Updates secret with the same name in the folder or creates a new one
+func (m *SecretsService) SaveSecret(ctx context.Context, Localizer *i18n.Localizer, secret secrets.Secret) (*secrets.Secret, error) {
+	existingSecret, errGetSecret := m.GetSecretByName(ctx, secret.FolderID, secret.Name)
+	if errGetSecret != nil && !errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+		return nil, errGetSecret
+	}
+	if existingSecret != nil {
+		secret.Model = existingSecret.Model
+		secret.UID = existingSecret.UID
+		updatedSecret, errUpdateSecret := m.UpdateSecret(ctx, Localizer, secret)
+		if errUpdateSecret != nil {
+			return nil, errors.Wrapf(errUpdateSecret, "Failed to update secret %s", secret.Name)
+		}
+		return updatedSecret, nil
+	}
+
+	newSecret, errCreateSecret := m.CreateSecret(ctx, Localizer, secret)
+	if errCreateSecret != nil {
+		return nil, errors.Wrapf(errCreateSecret, "Failed to create secret %s", secret.Name)
+	}
+	return newSecret, nil
+}
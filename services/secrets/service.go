@@ -8,8 +8,11 @@ import (
 	"hideout/internal/common/generics"
 	"hideout/internal/common/model"
 	"hideout/internal/folders"
+	"hideout/internal/pkg/watcher"
 	"hideout/internal/secrets"
+	"hideout/internal/store"
 	"hideout/structs"
+	"log"
 )
 
 type Config struct {
@@ -31,14 +34,14 @@ func NewService(ctx context.Context, secretsConfig config.RepositoryConfig, fold
 	switch secretsConfig.Type {
 	case RepositoryType_InMemory:
 		{
-			secretsService.secretsRepository = secrets.NewInMemoryRepository(&structs.Secrets)
+			secretsService.secretsRepository = secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload)
 			secretsService.secrets = secretsList
 		}
 	case RepositoryType_Redis:
 		{
 			var inMemorySecretsRep *secrets.InMemoryRepository = nil
 			if secretsConfig.PreloadInMemory {
-				inMemorySecretsRep = secrets.NewInMemoryRepository(&structs.Secrets)
+				inMemorySecretsRep = secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload)
 			}
 			redisSecretsRep := secrets.NewRedisRepository(structs.Redis, inMemorySecretsRep)
 			if secretsConfig.PreloadInMemory {
@@ -61,11 +64,27 @@ func NewService(ctx context.Context, secretsConfig config.RepositoryConfig, fold
 		{
 			var inMemorySecretsRep *secrets.InMemoryRepository = nil
 			if secretsConfig.PreloadInMemory {
-				inMemorySecretsRep = secrets.NewInMemoryRepository(&structs.Secrets)
+				inMemorySecretsRep = secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload)
 			}
 			databaseSecretsRep := secrets.NewDatabaseRepository(structs.Gorm, inMemorySecretsRep)
 			secretsService.secretsRepository = databaseSecretsRep
 
+			if secretsConfig.PreloadInMemory {
+				errLoad := secretsService.LoadSecrets(ctx)
+				if errLoad != nil {
+					return nil, errors.Wrap(errLoad, "Error loading data into memory")
+				}
+			}
+		}
+	case RepositoryType_Embedded:
+		{
+			var inMemorySecretsRep *secrets.InMemoryRepository = nil
+			if secretsConfig.PreloadInMemory {
+				inMemorySecretsRep = secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload)
+			}
+			embeddedSecretsRep := secrets.NewEmbeddedRepository(structs.Embedded, inMemorySecretsRep)
+			secretsService.secretsRepository = embeddedSecretsRep
+
 			if secretsConfig.PreloadInMemory {
 				errLoad := secretsService.LoadSecrets(ctx)
 				if errLoad != nil {
@@ -77,12 +96,29 @@ func NewService(ctx context.Context, secretsConfig config.RepositoryConfig, fold
 		{
 			var inMemorySecretsRep *secrets.InMemoryRepository = nil
 			if secretsConfig.PreloadInMemory {
-				inMemorySecretsRep = secrets.NewInMemoryRepository(&structs.Secrets)
+				inMemorySecretsRep = secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload)
 			}
 			fileSecretsRep := secrets.NewFileRepository(secretsConfig.FileName, secretsConfig.FileEncoding, inMemorySecretsRep)
 			secretsService.secretsRepository = fileSecretsRep
 
+			// Watched file is loaded once on startup and reloaded by its watcher whenever changed
+			if secretsConfig.PreloadInMemory && !secretsConfig.Watch {
+				errLoad := secretsService.LoadSecrets(ctx)
+				if errLoad != nil {
+					return nil, errors.Wrap(errLoad, "Error loading data into memory")
+				}
+			}
+		}
+	case RepositoryType_Store:
+		{
+			var inMemorySecretsRep *secrets.InMemoryRepository = nil
 			if secretsConfig.PreloadInMemory {
+				inMemorySecretsRep = secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload)
+			}
+			secretsService.secretsRepository = store.NewSecretsRepository(structs.Store, inMemorySecretsRep)
+
+			// Watched file is loaded once on startup and reloaded by its watcher whenever changed
+			if secretsConfig.PreloadInMemory && !secretsConfig.Watch {
 				errLoad := secretsService.LoadSecrets(ctx)
 				if errLoad != nil {
 					return nil, errors.Wrap(errLoad, "Error loading data into memory")
@@ -94,14 +130,14 @@ func NewService(ctx context.Context, secretsConfig config.RepositoryConfig, fold
 	switch foldersConfig.Type {
 	case RepositoryType_InMemory:
 		{
-			secretsService.foldersRepository = folders.NewInMemoryRepository(&structs.Folders)
+			secretsService.foldersRepository = folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload)
 			secretsService.folders = foldersList
 		}
 	case RepositoryType_Redis:
 		{
 			var inMemoryFoldersRep *folders.InMemoryRepository = nil
 			if foldersConfig.PreloadInMemory {
-				inMemoryFoldersRep = folders.NewInMemoryRepository(&structs.Folders)
+				inMemoryFoldersRep = folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload)
 			}
 			redisFoldersRep := folders.NewRedisRepository(structs.Redis, inMemoryFoldersRep)
 			if foldersConfig.PreloadInMemory {
@@ -124,11 +160,27 @@ func NewService(ctx context.Context, secretsConfig config.RepositoryConfig, fold
 		{
 			var inMemoryFoldersRep *folders.InMemoryRepository = nil
 			if foldersConfig.PreloadInMemory {
-				inMemoryFoldersRep = folders.NewInMemoryRepository(&structs.Folders)
+				inMemoryFoldersRep = folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload)
 			}
 			databaseFoldersRep := folders.NewDatabaseRepository(structs.Gorm, inMemoryFoldersRep)
 			secretsService.foldersRepository = databaseFoldersRep
 
+			if foldersConfig.PreloadInMemory {
+				errLoad := secretsService.LoadFolders(ctx)
+				if errLoad != nil {
+					return nil, errors.Wrap(errLoad, "Error loading data into memory")
+				}
+			}
+		}
+	case RepositoryType_Embedded:
+		{
+			var inMemoryFoldersRep *folders.InMemoryRepository = nil
+			if foldersConfig.PreloadInMemory {
+				inMemoryFoldersRep = folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload)
+			}
+			embeddedFoldersRep := folders.NewEmbeddedRepository(structs.Embedded, inMemoryFoldersRep)
+			secretsService.foldersRepository = embeddedFoldersRep
+
 			if foldersConfig.PreloadInMemory {
 				errLoad := secretsService.LoadFolders(ctx)
 				if errLoad != nil {
@@ -140,12 +192,29 @@ func NewService(ctx context.Context, secretsConfig config.RepositoryConfig, fold
 		{
 			var inMemoryFoldersRep *folders.InMemoryRepository = nil
 			if foldersConfig.PreloadInMemory {
-				inMemoryFoldersRep = folders.NewInMemoryRepository(&structs.Folders)
+				inMemoryFoldersRep = folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload)
 			}
 			fileFoldersRep := folders.NewFileRepository(foldersConfig.FileName, foldersConfig.FileEncoding, inMemoryFoldersRep)
 			secretsService.foldersRepository = fileFoldersRep
 
+			// Watched file is loaded once on startup and reloaded by its watcher whenever changed
+			if foldersConfig.PreloadInMemory && !foldersConfig.Watch {
+				errLoad := secretsService.LoadFolders(ctx)
+				if errLoad != nil {
+					return nil, errors.Wrap(errLoad, "Error loading data into memory")
+				}
+			}
+		}
+	case RepositoryType_Store:
+		{
+			var inMemoryFoldersRep *folders.InMemoryRepository = nil
 			if foldersConfig.PreloadInMemory {
+				inMemoryFoldersRep = folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload)
+			}
+			secretsService.foldersRepository = store.NewFoldersRepository(structs.Store, inMemoryFoldersRep)
+
+			// Watched file is loaded once on startup and reloaded by its watcher whenever changed
+			if foldersConfig.PreloadInMemory && !foldersConfig.Watch {
 				errLoad := secretsService.LoadFolders(ctx)
 				if errLoad != nil {
 					return nil, errors.Wrap(errLoad, "Error loading data into memory")
@@ -157,6 +226,85 @@ func NewService(ctx context.Context, secretsConfig config.RepositoryConfig, fold
 	return secretsService, nil
 }
 
+// Recover Brings files of file repositories (or of store) into consistent state after a crash, meant to be called on
+// startup before anything is read from them
+func Recover(ctx context.Context, secretsConfig config.RepositoryConfig, foldersConfig config.RepositoryConfig) error {
+	if secretsConfig.Type == RepositoryType_File {
+		errRecover := secrets.NewFileRepository(secretsConfig.FileName, secretsConfig.FileEncoding, nil).Recover(ctx)
+		if errRecover != nil {
+			return errors.Wrap(errRecover, "Error recovering secrets file")
+		}
+	}
+	if foldersConfig.Type == RepositoryType_File {
+		errRecover := folders.NewFileRepository(foldersConfig.FileName, foldersConfig.FileEncoding, nil).Recover(ctx)
+		if errRecover != nil {
+			return errors.Wrap(errRecover, "Error recovering folders file")
+		}
+	}
+	if secretsConfig.Type == RepositoryType_Store || foldersConfig.Type == RepositoryType_Store {
+		errRecover := structs.Store.Recover()
+		if errRecover != nil {
+			return errors.Wrap(errRecover, "Error recovering store")
+		}
+	}
+
+	return nil
+}
+
+// Watch Keeps preloaded folders and secrets in sync with files of file repositories (or of store) changed on disk by
+// other tools. Changed files are validated first, preloaded records are replaced as a whole by valid ones only, unless
+// loaded before the latest local write
+func Watch(ctx context.Context, secretsConfig config.RepositoryConfig, foldersConfig config.RepositoryConfig) error {
+	var watchers []*watcher.Watcher
+	watchSecrets := secretsConfig.PreloadInMemory && secretsConfig.Watch
+	watchFolders := foldersConfig.PreloadInMemory && foldersConfig.Watch
+
+	if watchSecrets && secretsConfig.Type == RepositoryType_File {
+		secretsRep := secrets.NewFileRepository(secretsConfig.FileName, secretsConfig.FileEncoding,
+			secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload))
+		secretsWatcher, errWatch := secretsRep.Watch(ctx, func(errLoad error) {
+			log.Printf("Secrets file %s was changed, but is not reloaded: %s", secretsConfig.FileName, errLoad)
+		})
+		if errWatch != nil {
+			return errors.Wrap(errWatch, "Error watching secrets file")
+		}
+		watchers = append(watchers, secretsWatcher)
+	}
+	if watchFolders && foldersConfig.Type == RepositoryType_File {
+		foldersRep := folders.NewFileRepository(foldersConfig.FileName, foldersConfig.FileEncoding,
+			folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload))
+		foldersWatcher, errWatch := foldersRep.Watch(ctx, func(errLoad error) {
+			log.Printf("Folders file %s was changed, but is not reloaded: %s", foldersConfig.FileName, errLoad)
+		})
+		if errWatch != nil {
+			return errors.Wrap(errWatch, "Error watching folders file")
+		}
+		watchers = append(watchers, foldersWatcher)
+	}
+	if (watchSecrets || watchFolders) && secretsConfig.Type == RepositoryType_Store {
+		var foldersRep *folders.InMemoryRepository = nil
+		if watchFolders {
+			foldersRep = folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload)
+		}
+		var secretsRep *secrets.InMemoryRepository = nil
+		if watchSecrets {
+			secretsRep = secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload)
+		}
+		storeWatcher, errWatch := structs.Store.Watch(foldersRep, secretsRep, func(errLoad error) {
+			log.Printf("Store %s was changed, but is not reloaded: %s", structs.Store.FileName, errLoad)
+		})
+		if errWatch != nil {
+			return errors.Wrap(errWatch, "Error watching store")
+		}
+		watchers = append(watchers, storeWatcher)
+	}
+
+	for _, fileWatcher := range watchers {
+		go fileWatcher.Run(ctx)
+	}
+	return nil
+}
+
 func (m *SecretsService) Load(ctx context.Context) error {
 	errLoadSecrets := m.LoadSecrets(ctx)
 	if errLoadSecrets != nil {
@@ -173,11 +321,13 @@ func (m *SecretsService) Load(ctx context.Context) error {
 
 func (m *SecretsService) LoadSecrets(ctx context.Context) error {
 	if m.secretsConfig.PreloadInMemory {
+		preloadedSecrets := secrets.NewPreloadedInMemoryRepository(&structs.Secrets, &structs.SecretsPreload)
+		generation := preloadedSecrets.Generation()
 		loadedSecrets, errLoadSecrets := m.secretsRepository.Load(ctx)
 		if errLoadSecrets != nil {
 			return errors.Wrap(errLoadSecrets, "Error preloading secrets into in-memory storage")
 		}
-		structs.Secrets = loadedSecrets
+		preloadedSecrets.Replace(loadedSecrets, generation)
 	}
 
 	return nil
@@ -185,11 +335,13 @@ func (m *SecretsService) LoadSecrets(ctx context.Context) error {
 
 func (m *SecretsService) LoadFolders(ctx context.Context) error {
 	if m.foldersConfig.PreloadInMemory {
+		preloadedFolders := folders.NewPreloadedInMemoryRepository(&structs.Folders, &structs.FoldersPreload)
+		generation := preloadedFolders.Generation()
 		loadedFolders, errLoadFolders := m.foldersRepository.Load(ctx)
 		if errLoadFolders != nil {
 			return errors.Wrap(errLoadFolders, "Error preloading folders into in-memory storage in Redis")
 		}
-		structs.Folders = loadedFolders
+		preloadedFolders.Replace(loadedFolders, generation)
 	}
 
 	return nil
@@ -373,7 +525,7 @@ func (m *SecretsService) copySecrets(ctx context.Context, secretsList []*secrets
 		}
 		newSecret, errCreateSecret := m.secretsRepository.Create(ctx, secrets.Secret{
 			Model: model.Model{ID: id}, FolderID: toFolder.ID, UID: gofakeit.UUID(),
-			Name: secret.Name, Value: secret.Value, Script: secret.Script,
+			Name: secret.Name, Value: secret.Value, Script: secret.Script, Type: secret.Type, ExpiresAt: secret.ExpiresAt,
 		})
 		if errCreateSecret != nil {
 			return nil, errCreateSecret
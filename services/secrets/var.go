@@ -6,6 +6,8 @@ var (
 		"redis":    RepositoryType_Redis,
 		"database": RepositoryType_Database,
 		"file":     RepositoryType_File,
+		"store":    RepositoryType_Store,
+		"embedded": RepositoryType_Embedded,
 	}
 
 	TypeMapInv = map[uint]string{
@@ -13,5 +15,7 @@ var (
 		RepositoryType_Redis:    "redis",
 		RepositoryType_Database: "database",
 		RepositoryType_File:     "file",
+		RepositoryType_Store:    "store",
+		RepositoryType_Embedded: "embedded",
 	}
 )
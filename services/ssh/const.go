@@ -0,0 +1,20 @@
+package ssh
+
+const (
+	KeyType_Ed25519 = "ed25519"
+	KeyType_RSA     = "rsa"
+
+	CertType_User = "user"
+	CertType_Host = "host"
+
+	SecretSuffix_PrivateKey = "_PRIVATE_KEY"
+	SecretSuffix_PublicKey  = "_PUBLIC_KEY"
+	// UIDSuffix_PublicKey Suffix of public key secret UID, which is UID of its private key secret with it
+	UIDSuffix_PublicKey = "-public-key"
+
+	SecretName_CAPrivateKey = "SSH_CA_PRIVATE_KEY"
+	SecretName_CAPublicKey  = "SSH_CA_PUBLIC_KEY"
+
+	DefaultRSABits = 3072
+	MinRSABits     = 2048
+)
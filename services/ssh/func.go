@@ -0,0 +1,63 @@
+package ssh
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/pem"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"strings"
+)
+
+// GenerateKey Generates private key of given type, RSA size defaults to DefaultRSABits
+func GenerateKey(keyType string, bits int) (crypto.Signer, error) {
+	switch keyType {
+	case KeyType_Ed25519, "":
+		_, privateKey, errGenerate := ed25519.GenerateKey(rand.Reader)
+		return privateKey, errGenerate
+	case KeyType_RSA:
+		if bits == 0 {
+			bits = DefaultRSABits
+		}
+		if bits < MinRSABits {
+			return nil, errors.Wrapf(ErrInvalidKeyType, "RSA key must be at least %d bits long", MinRSABits)
+		}
+		return rsa.GenerateKey(rand.Reader, bits)
+	}
+	return nil, errors.Wrapf(ErrInvalidKeyType, "Key type %s is not supported", keyType)
+}
+
+// EncodePrivateKey Encodes private key in OpenSSH format
+func EncodePrivateKey(privateKey crypto.Signer, comment string) (string, error) {
+	block, errMarshal := ssh.MarshalPrivateKey(privateKey, comment)
+	if errMarshal != nil {
+		return "", errors.Wrap(errMarshal, "Failed to marshal private key")
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// EncodePublicKey Encodes public key in authorized_keys format (single line, optionally with comment)
+func EncodePublicKey(publicKey ssh.PublicKey, comment string) string {
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+	if comment != "" {
+		line += " " + comment
+	}
+	return line
+}
+
+func randomSerial() (uint64, error) {
+	var serialBytes [8]byte
+	_, errRead := rand.Read(serialBytes[:])
+	if errRead != nil {
+		return 0, errRead
+	}
+	return binary.BigEndian.Uint64(serialBytes[:]), nil
+}
+
+// PublicKeyUID UID of the public key secret linked to the private key secret of the pair
+func PublicKeyUID(privateKeyUID string) string {
+	return privateKeyUID + UIDSuffix_PublicKey
+}
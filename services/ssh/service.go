@@ -0,0 +1,240 @@
+package ssh
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"hideout/internal/common/apperror"
+	"hideout/internal/pkg/encryption"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"time"
+)
+
+// SSHService SSH key pairs and certificate authority, kept as secrets inside folders, private key of the authority is
+// encrypted at rest
+type SSHService struct {
+	secretsSvc    *secrets.SecretsService
+	encryptionKey []byte
+}
+
+// NewService Creation of the service
+func NewService(secretsSvc *secrets.SecretsService, encryptionKey []byte) *SSHService {
+	return &SSHService{secretsSvc: secretsSvc, encryptionKey: encryptionKey}
+}
+
+// GenerateKeyPair Generates key pair and stores it as private and public key secrets of the folder, public key secret
+// refers to its private key by UID. Existing pair of the same name is only replaced when rotation is asked for
+func (m *SSHService) GenerateKeyPair(ctx context.Context, Localizer *i18n.Localizer, params GenerateKeyPairParams) (*KeyPair, error) {
+	if !nameRegexp.MatchString(params.Name) {
+		return nil, ErrInvalidName
+	}
+	folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, params.FolderUID)
+	if errGetFolder != nil {
+		return nil, errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", params.FolderUID)
+	}
+
+	privateKeyUID, errGetPair := m.getKeyPairUID(ctx, folder.ID, params.Name, params.Rotate)
+	if errGetPair != nil {
+		return nil, errGetPair
+	}
+
+	privateKey, errGenerateKey := GenerateKey(params.KeyType, params.Bits)
+	if errGenerateKey != nil {
+		return nil, errGenerateKey
+	}
+	privateKeyPEM, errEncodeKey := EncodePrivateKey(privateKey, params.Comment)
+	if errEncodeKey != nil {
+		return nil, errEncodeKey
+	}
+	publicKey, errPublicKey := ssh.NewPublicKey(privateKey.Public())
+	if errPublicKey != nil {
+		return nil, errors.Wrap(errPublicKey, "Failed to convert public key")
+	}
+
+	privateKeySecret, errSavePrivateKey := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: folder.ID, UID: privateKeyUID,
+		Name: params.Name + SecretSuffix_PrivateKey, Value: privateKeyPEM, Type: secrets2.Type_SSHPrivateKey})
+	if errSavePrivateKey != nil {
+		return nil, errSavePrivateKey
+	}
+	publicKeySecret, errSavePublicKey := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: folder.ID,
+		UID: PublicKeyUID(privateKeySecret.UID), Name: params.Name + SecretSuffix_PublicKey, Value: EncodePublicKey(publicKey, params.Comment),
+		Type: secrets2.Type_SSHPublicKey})
+	if errSavePublicKey != nil {
+		return nil, errSavePublicKey
+	}
+
+	return &KeyPair{PrivateKey: privateKeySecret.Value, PublicKey: publicKeySecret.Value, Fingerprint: ssh.FingerprintSHA256(publicKey),
+		PrivateKeyUID: privateKeySecret.UID, PublicKeyUID: publicKeySecret.UID}, nil
+}
+
+// CreateCA Generates certificate authority key in the folder, its private key is sealed and never returned
+func (m *SSHService) CreateCA(ctx context.Context, Localizer *i18n.Localizer, folderUID string, keyType string, bits int) (string, error) {
+	folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+	if errGetFolder != nil {
+		return "", errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+	}
+	_, errGetCAKey := m.secretsSvc.GetSecretByName(ctx, folder.ID, SecretName_CAPrivateKey)
+	if errGetCAKey == nil {
+		return "", ErrAlreadyCA
+	} else if !errors.Is(errGetCAKey, apperror.ErrRecordNotFound) {
+		return "", errGetCAKey
+	}
+
+	privateKey, errGenerateKey := GenerateKey(keyType, bits)
+	if errGenerateKey != nil {
+		return "", errGenerateKey
+	}
+	privateKeyPEM, errEncodeKey := EncodePrivateKey(privateKey, "")
+	if errEncodeKey != nil {
+		return "", errEncodeKey
+	}
+	publicKey, errPublicKey := ssh.NewPublicKey(privateKey.Public())
+	if errPublicKey != nil {
+		return "", errors.Wrap(errPublicKey, "Failed to convert public key")
+	}
+	publicKeyLine := EncodePublicKey(publicKey, "")
+	sealedPrivateKey, errSeal := encryption.Seal(m.encryptionKey, []byte(privateKeyPEM))
+	if errSeal != nil {
+		return "", errSeal
+	}
+
+	_, errSavePrivateKey := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: folder.ID,
+		Name: SecretName_CAPrivateKey, Value: sealedPrivateKey, Type: secrets2.Type_SSHCAPrivateKey})
+	if errSavePrivateKey != nil {
+		return "", errSavePrivateKey
+	}
+	_, errSavePublicKey := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: folder.ID,
+		Name: SecretName_CAPublicKey, Value: publicKeyLine, Type: secrets2.Type_SSHPublicKey})
+	if errSavePublicKey != nil {
+		return "", errSavePublicKey
+	}
+
+	return publicKeyLine, nil
+}
+
+// GetCAPublicKey Public key of the certificate authority (for TrustedUserCAKeys or @cert-authority entries)
+func (m *SSHService) GetCAPublicKey(ctx context.Context, folderUID string) (string, error) {
+	folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+	if errGetFolder != nil {
+		return "", errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+	}
+	publicKeySecret, errGetPublicKey := m.secretsSvc.GetSecretByName(ctx, folder.ID, SecretName_CAPublicKey)
+	if errGetPublicKey != nil {
+		if errors.Is(errGetPublicKey, apperror.ErrRecordNotFound) {
+			return "", ErrNotCertificateAuthority
+		}
+		return "", errGetPublicKey
+	}
+	return publicKeySecret.Value, nil
+}
+
+// Sign Signs user or host certificate for the public key with the certificate authority of the folder
+func (m *SSHService) Sign(ctx context.Context, params SignParams) (*SignedCertificate, error) {
+	certType, certTypeExists := CertTypesMap[params.CertType]
+	if !certTypeExists {
+		return nil, errors.Wrapf(ErrInvalidCertType, "Certificate type %s is not supported", params.CertType)
+	}
+	if len(params.Principals) == 0 {
+		return nil, ErrNoPrincipals
+	}
+	publicKey, _, _, _, errParsePublicKey := ssh.ParseAuthorizedKey([]byte(params.PublicKey))
+	if errParsePublicKey != nil {
+		return nil, errors.Wrap(ErrInvalidPublicKey, errParsePublicKey.Error())
+	}
+
+	validAfter, validBefore := params.ValidAfter, params.ValidBefore
+	if validAfter.IsZero() {
+		// Small backdating tolerates clock skew between servers
+		validAfter = time.Now().Add(-time.Minute)
+	}
+	if validBefore.IsZero() {
+		validBefore = validAfter.Add(DefaultCertificateTTL)
+	}
+	if !validBefore.After(validAfter) {
+		return nil, ErrInvalidValidity
+	}
+
+	signer, errLoadSigner := m.loadSigner(ctx, params.FolderUID)
+	if errLoadSigner != nil {
+		return nil, errLoadSigner
+	}
+
+	serial, errSerial := randomSerial()
+	if errSerial != nil {
+		return nil, errors.Wrap(errSerial, "Failed to generate serial number")
+	}
+	extensions := params.Extensions
+	if extensions == nil && certType == ssh.UserCert {
+		extensions = DefaultUserExtensions
+	}
+	certificate := &ssh.Certificate{
+		Key:             publicKey,
+		Serial:          serial,
+		CertType:        certType,
+		KeyId:           params.KeyID,
+		ValidPrincipals: params.Principals,
+		ValidAfter:      uint64(validAfter.Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+		Permissions:     ssh.Permissions{CriticalOptions: params.CriticalOptions, Extensions: extensions},
+	}
+	errSign := certificate.SignCert(rand.Reader, signer)
+	if errSign != nil {
+		return nil, errors.Wrap(errSign, "Failed to sign certificate")
+	}
+
+	return &SignedCertificate{Certificate: EncodePublicKey(certificate, params.KeyID), Serial: serial,
+		ValidAfter: time.Unix(int64(certificate.ValidAfter), 0), ValidBefore: time.Unix(int64(certificate.ValidBefore), 0)}, nil
+}
+
+// getKeyPairUID UID of the private key secret of the pair, new one unless the existing pair is rotated. Secrets of the
+// pair names which are not a linked key pair are never replaced
+func (m *SSHService) getKeyPairUID(ctx context.Context, folderID uint, name string, rotate bool) (string, error) {
+	var existingSecrets [2]*secrets2.Secret
+	for i, secretName := range []string{name + SecretSuffix_PrivateKey, name + SecretSuffix_PublicKey} {
+		existingSecret, errGetSecret := m.secretsSvc.GetSecretByName(ctx, folderID, secretName)
+		if errGetSecret != nil && !errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+			return "", errGetSecret
+		}
+		existingSecrets[i] = existingSecret
+	}
+	privateKeySecret, publicKeySecret := existingSecrets[0], existingSecrets[1]
+	if privateKeySecret == nil && publicKeySecret == nil {
+		return gofakeit.UUID(), nil
+	}
+
+	if !rotate {
+		return "", errors.Wrapf(apperror.ErrAlreadyExists, "Key pair %s", name)
+	}
+	if privateKeySecret == nil || publicKeySecret == nil || privateKeySecret.Type != secrets2.Type_SSHPrivateKey ||
+		publicKeySecret.Type != secrets2.Type_SSHPublicKey || publicKeySecret.UID != PublicKeyUID(privateKeySecret.UID) {
+		return "", errors.Wrapf(ErrNotKeyPair, "Secrets of key pair %s", name)
+	}
+	return privateKeySecret.UID, nil
+}
+
+func (m *SSHService) loadSigner(ctx context.Context, folderUID string) (ssh.Signer, error) {
+	folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+	if errGetFolder != nil {
+		return nil, errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+	}
+	privateKeySecret, errGetPrivateKey := m.secretsSvc.GetSecretByName(ctx, folder.ID, SecretName_CAPrivateKey)
+	if errGetPrivateKey != nil {
+		if errors.Is(errGetPrivateKey, apperror.ErrRecordNotFound) {
+			return nil, ErrNotCertificateAuthority
+		}
+		return nil, errGetPrivateKey
+	}
+	privateKeyPEM, errOpen := encryption.Open(m.encryptionKey, privateKeySecret.Value)
+	if errOpen != nil {
+		return nil, errors.Wrap(errOpen, "Failed to decrypt certificate authority private key")
+	}
+	signer, errParse := ssh.ParsePrivateKey(privateKeyPEM)
+	if errParse != nil {
+		return nil, errors.Wrap(errParse, "Failed to parse certificate authority private key")
+	}
+	return signer, nil
+}
@@ -0,0 +1,173 @@
+package ssh
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/pem"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"hideout/config"
+	"hideout/internal/common/apperror"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/encryption"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"hideout/structs"
+	"testing"
+)
+
+// newTestService Service over empty in-memory repositories and a random encryption key, along with a folder
+func newTestService(t *testing.T) (*SSHService, *secrets.SecretsService, *folders.Folder) {
+	t.Helper()
+	structs.Folders, structs.Secrets = nil, nil
+	secretsSvc, errCreateService := secrets.NewService(context.Background(), config.RepositoryConfig{Type: secrets.RepositoryType_InMemory},
+		config.RepositoryConfig{Type: secrets.RepositoryType_InMemory}, &structs.Folders, &structs.Secrets)
+	if errCreateService != nil {
+		t.Fatalf("Failed to create secrets service: %s", errCreateService)
+	}
+	folder, errCreateFolder := secretsSvc.CreateFolder(context.Background(), folders.Folder{Name: "ssh"})
+	if errCreateFolder != nil {
+		t.Fatalf("Failed to create folder: %s", errCreateFolder)
+	}
+	encryptionKey := make([]byte, encryption.KeySize)
+	if _, errRead := rand.Read(encryptionKey); errRead != nil {
+		t.Fatalf("Failed to generate encryption key: %s", errRead)
+	}
+	return NewService(secretsSvc, encryptionKey), secretsSvc, folder
+}
+
+// testPublicKey Freshly generated public key in authorized_keys format
+func testPublicKey(t *testing.T) string {
+	t.Helper()
+	privateKey, errGenerateKey := GenerateKey(KeyType_Ed25519, 0)
+	if errGenerateKey != nil {
+		t.Fatalf("Failed to generate key: %s", errGenerateKey)
+	}
+	publicKey, errPublicKey := ssh.NewPublicKey(privateKey.Public())
+	if errPublicKey != nil {
+		t.Fatalf("Failed to convert public key: %s", errPublicKey)
+	}
+	return EncodePublicKey(publicKey, "")
+}
+
+func TestCAPrivateKeySealed(t *testing.T) {
+	sshSvc, secretsSvc, folder := newTestService(t)
+	caPublicKeyLine, errCreateCA := sshSvc.CreateCA(context.Background(), nil, folder.UID, KeyType_Ed25519, 0)
+	if errCreateCA != nil {
+		t.Fatalf("Failed to create certificate authority: %s", errCreateCA)
+	}
+
+	stored, errGetSecret := secretsSvc.GetSecretByName(context.Background(), folder.ID, SecretName_CAPrivateKey)
+	if errGetSecret != nil {
+		t.Fatalf("Failed to read stored private key: %s", errGetSecret)
+	}
+	if !encryption.IsSealed(stored.Value) {
+		t.Error("Private key is not encrypted")
+	}
+	if block, _ := pem.Decode([]byte(stored.Value)); block != nil {
+		t.Errorf("Private key is stored as %s PEM block", block.Type)
+	}
+	if _, errParse := ssh.ParsePrivateKey([]byte(stored.Value)); errParse == nil {
+		t.Error("Stored private key can be parsed without decryption")
+	}
+
+	signed, errSign := sshSvc.Sign(context.Background(), SignParams{FolderUID: folder.UID, PublicKey: testPublicKey(t),
+		CertType: CertType_User, Principals: []string{"user"}})
+	if errSign != nil {
+		t.Fatalf("Failed to sign certificate: %s", errSign)
+	}
+
+	parsedCertificate, _, _, _, errParseCertificate := ssh.ParseAuthorizedKey([]byte(signed.Certificate))
+	if errParseCertificate != nil {
+		t.Fatalf("Failed to parse certificate: %s", errParseCertificate)
+	}
+	caPublicKey, _, _, _, errParseCAKey := ssh.ParseAuthorizedKey([]byte(caPublicKeyLine))
+	if errParseCAKey != nil {
+		t.Fatalf("Failed to parse public key of the authority: %s", errParseCAKey)
+	}
+	certificate := parsedCertificate.(*ssh.Certificate)
+	if string(certificate.SignatureKey.Marshal()) != string(caPublicKey.Marshal()) {
+		t.Error("Certificate is not signed by the authority")
+	}
+	checker := ssh.CertChecker{IsUserAuthority: func(auth ssh.PublicKey) bool { return string(auth.Marshal()) == string(caPublicKey.Marshal()) }}
+	if errCheck := checker.CheckCert("user", certificate); errCheck != nil {
+		t.Errorf("Certificate is not valid: %s", errCheck)
+	}
+}
+
+func TestCAPrivateKeyWrongEncryptionKey(t *testing.T) {
+	sshSvc, secretsSvc, folder := newTestService(t)
+	if _, errCreateCA := sshSvc.CreateCA(context.Background(), nil, folder.UID, KeyType_Ed25519, 0); errCreateCA != nil {
+		t.Fatalf("Failed to create certificate authority: %s", errCreateCA)
+	}
+
+	otherKey := make([]byte, encryption.KeySize)
+	_, errSign := NewService(secretsSvc, otherKey).Sign(context.Background(), SignParams{FolderUID: folder.UID,
+		PublicKey: testPublicKey(t), CertType: CertType_User, Principals: []string{"user"}})
+	if errSign == nil {
+		t.Fatal("Certificate was signed with key decrypted by wrong encryption key")
+	}
+	if _, errSign := sshSvc.Sign(context.Background(), SignParams{FolderUID: folder.UID, PublicKey: testPublicKey(t),
+		CertType: CertType_User, Principals: []string{"user"}}); errSign != nil {
+		t.Errorf("Failed to sign certificate with the right encryption key: %s", errSign)
+	}
+}
+
+func TestGenerateKeyPairLinked(t *testing.T) {
+	sshSvc, secretsSvc, folder := newTestService(t)
+	params := GenerateKeyPairParams{FolderUID: folder.UID, Name: "DEPLOY", KeyType: KeyType_Ed25519}
+	keyPair, errGenerate := sshSvc.GenerateKeyPair(context.Background(), nil, params)
+	if errGenerate != nil {
+		t.Fatalf("Failed to generate key pair: %s", errGenerate)
+	}
+	if keyPair.PublicKeyUID != PublicKeyUID(keyPair.PrivateKeyUID) {
+		t.Errorf("Public key %s is not linked to private key %s", keyPair.PublicKeyUID, keyPair.PrivateKeyUID)
+	}
+	publicKeySecret, errGetSecret := secretsSvc.GetSecretByUID(context.Background(), PublicKeyUID(keyPair.PrivateKeyUID))
+	if errGetSecret != nil || publicKeySecret.Value != keyPair.PublicKey {
+		t.Fatalf("Public key is not found by UID of its private key: %v", errGetSecret)
+	}
+
+	_, errRegenerate := sshSvc.GenerateKeyPair(context.Background(), nil, params)
+	if !errors.Is(errRegenerate, apperror.ErrAlreadyExists) {
+		t.Errorf("Expected %s, got %v", apperror.ErrAlreadyExists, errRegenerate)
+	}
+
+	params.Rotate = true
+	rotatedKeyPair, errRotate := sshSvc.GenerateKeyPair(context.Background(), nil, params)
+	if errRotate != nil {
+		t.Fatalf("Failed to rotate key pair: %s", errRotate)
+	}
+	if rotatedKeyPair.PrivateKeyUID != keyPair.PrivateKeyUID || rotatedKeyPair.PublicKeyUID != keyPair.PublicKeyUID {
+		t.Errorf("Rotated key pair is stored in other secrets")
+	}
+	if rotatedKeyPair.PrivateKey == keyPair.PrivateKey || rotatedKeyPair.PublicKey == keyPair.PublicKey {
+		t.Errorf("Key pair was not rotated")
+	}
+}
+
+func TestGenerateKeyPairNotLinked(t *testing.T) {
+	sshSvc, secretsSvc, folder := newTestService(t)
+	// Secret which happens to have the name of public key of the pair
+	_, errCreateSecret := secretsSvc.SaveSecret(context.Background(), nil, secrets2.Secret{FolderID: folder.ID, UID: "unrelated",
+		Name: "DEPLOY" + SecretSuffix_PublicKey, Value: "value"})
+	if errCreateSecret != nil {
+		t.Fatalf("Failed to create secret: %s", errCreateSecret)
+	}
+
+	params := GenerateKeyPairParams{FolderUID: folder.UID, Name: "DEPLOY", KeyType: KeyType_Ed25519}
+	_, errGenerate := sshSvc.GenerateKeyPair(context.Background(), nil, params)
+	if !errors.Is(errGenerate, apperror.ErrAlreadyExists) {
+		t.Errorf("Expected %s, got %v", apperror.ErrAlreadyExists, errGenerate)
+	}
+	params.Rotate = true
+	_, errRotate := sshSvc.GenerateKeyPair(context.Background(), nil, params)
+	if !errors.Is(errRotate, ErrNotKeyPair) {
+		t.Errorf("Expected %s, got %v", ErrNotKeyPair, errRotate)
+	}
+
+	unrelated, errGetSecret := secretsSvc.GetSecretByUID(context.Background(), "unrelated")
+	if errGetSecret != nil || unrelated.Value != "value" {
+		t.Errorf("Unrelated secret was replaced: %v", errGetSecret)
+	}
+}
@@ -0,0 +1,44 @@
+package ssh
+
+import "time"
+
+type (
+	GenerateKeyPairParams struct {
+		FolderUID string
+		// Name Prefix of the private and public key secret names
+		Name    string
+		KeyType string
+		Bits    int
+		Comment string
+		// Rotate Replace existing key pair of the same name
+		Rotate bool
+	}
+
+	KeyPair struct {
+		PrivateKey    string
+		PublicKey     string
+		Fingerprint   string
+		PrivateKeyUID string
+		PublicKeyUID  string
+	}
+
+	SignParams struct {
+		FolderUID string
+		// PublicKey Key to be signed in authorized_keys format
+		PublicKey       string
+		CertType        string
+		KeyID           string
+		Principals      []string
+		ValidAfter      time.Time
+		ValidBefore     time.Time
+		CriticalOptions map[string]string
+		Extensions      map[string]string
+	}
+
+	SignedCertificate struct {
+		Certificate string
+		Serial      uint64
+		ValidAfter  time.Time
+		ValidBefore time.Time
+	}
+)
@@ -0,0 +1,29 @@
+package ssh
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrNotCertificateAuthority = errors.New("Folder does not contain an SSH certificate authority")
+	ErrAlreadyCA               = errors.New("Folder already contains an SSH certificate authority")
+	ErrInvalidKeyType          = errors.New("Invalid key type")
+	ErrInvalidCertType         = errors.New("Invalid certificate type")
+	ErrInvalidPublicKey        = errors.New("Invalid public key")
+	ErrInvalidName             = errors.New("Name may only contain letters, digits and underscores")
+	ErrInvalidValidity         = errors.New("Invalid certificate validity window")
+	ErrNoPrincipals            = errors.New("At least one principal is required")
+	ErrNotKeyPair              = errors.New("Secrets of the same names are not a linked key pair")
+
+	CertTypesMap = map[string]uint32{CertType_User: 1, CertType_Host: 2}
+
+	DefaultCertificateTTL = 24 * time.Hour
+
+	// DefaultUserExtensions Extensions OpenSSH grants to user certificates when none are requested
+	DefaultUserExtensions = map[string]string{"permit-X11-forwarding": "", "permit-agent-forwarding": "",
+		"permit-port-forwarding": "", "permit-pty": "", "permit-user-rc": ""}
+
+	nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
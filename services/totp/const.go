@@ -0,0 +1,10 @@
+package totp
+
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30
+
+	Algorithm_SHA1   = "SHA1"
+	Algorithm_SHA256 = "SHA256"
+	Algorithm_SHA512 = "SHA512"
+)
@@ -0,0 +1,81 @@
+package totp
+
+import (
+	"encoding/base32"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
+	"net/url"
+	"strings"
+)
+
+// ParseURI Parses otpauth URI, only time-based keys are accepted
+func ParseURI(uri string) (*otp.Key, error) {
+	key, errParse := otp.NewKeyFromURL(strings.TrimSpace(uri))
+	if errParse != nil {
+		return nil, errors.Wrap(ErrInvalidURI, errParse.Error())
+	}
+	if key.Type() != "totp" {
+		return nil, errors.Wrapf(ErrInvalidURI, "Expected totp key, got %s", key.Type())
+	}
+	if _, errDecode := decodeSeed(key.Secret()); errDecode != nil {
+		return nil, errDecode
+	}
+	return key, nil
+}
+
+// BuildURI Builds otpauth URI from base32 seed and parameters, defaults are used for empty ones
+func BuildURI(seed string, issuer string, accountName string, digits uint, period uint, algorithm string) (string, error) {
+	seed = normalizeSeed(seed)
+	if _, errDecode := decodeSeed(seed); errDecode != nil {
+		return "", errDecode
+	}
+	if digits == 0 {
+		digits = DefaultDigits
+	}
+	if digits != 6 && digits != 8 {
+		return "", ErrInvalidDigits
+	}
+	if period == 0 {
+		period = DefaultPeriod
+	}
+	if algorithm == "" {
+		algorithm = Algorithm_SHA1
+	}
+	if _, algorithmExists := AlgorithmsMap[strings.ToUpper(algorithm)]; !algorithmExists {
+		return "", errors.Wrapf(ErrInvalidAlgorithm, "Algorithm %s is not supported", algorithm)
+	}
+
+	label := accountName
+	if issuer != "" {
+		label = issuer + ":" + accountName
+	}
+	query := url.Values{}
+	query.Set("secret", seed)
+	query.Set("digits", fmt.Sprintf("%d", digits))
+	query.Set("period", fmt.Sprintf("%d", period))
+	query.Set("algorithm", strings.ToUpper(algorithm))
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	uri := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: query.Encode()}
+	return uri.String(), nil
+}
+
+func keyInfo(key *otp.Key) Info {
+	algorithm := strings.ToUpper(key.Algorithm().String())
+	return Info{Issuer: key.Issuer(), AccountName: key.AccountName(), Digits: uint(key.Digits().Length()),
+		Period: uint(key.Period()), Algorithm: algorithm}
+}
+
+func normalizeSeed(seed string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(seed))
+}
+
+func decodeSeed(seed string) ([]byte, error) {
+	seedBytes, errDecode := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(normalizeSeed(seed), "="))
+	if errDecode != nil || len(seedBytes) == 0 {
+		return nil, ErrInvalidSeed
+	}
+	return seedBytes, nil
+}
@@ -0,0 +1,137 @@
+package totp
+
+import (
+	"context"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp/totp"
+	"hideout/internal/audit"
+	"hideout/internal/common/apperror"
+	"hideout/internal/pkg/encryption"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"strconv"
+	"time"
+)
+
+// TOTPService Time-based one-time password secrets, seeds are encrypted at rest and never returned unless asked for
+type TOTPService struct {
+	secretsSvc    *secrets.SecretsService
+	encryptionKey []byte
+	auditLogger   audit.Logger
+}
+
+// NewService Creation of the service
+func NewService(secretsSvc *secrets.SecretsService, encryptionKey []byte, auditLogger audit.Logger) *TOTPService {
+	return &TOTPService{secretsSvc: secretsSvc, encryptionKey: encryptionKey, auditLogger: auditLogger}
+}
+
+// Create Creates or replaces TOTP secret in the folder from otpauth URI or base32 seed
+func (m *TOTPService) Create(ctx context.Context, Localizer *i18n.Localizer, params CreateParams) (*Info, error) {
+	folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, params.FolderUID)
+	if errGetFolder != nil {
+		return nil, errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", params.FolderUID)
+	}
+
+	uri := params.URI
+	if uri == "" {
+		builtURI, errBuildURI := BuildURI(params.Seed, params.Issuer, params.AccountName, params.Digits, params.Period, params.Algorithm)
+		if errBuildURI != nil {
+			return nil, errBuildURI
+		}
+		uri = builtURI
+	}
+	key, errParseURI := ParseURI(uri)
+	if errParseURI != nil {
+		return nil, errParseURI
+	}
+
+	// Plain secret of the same name is not silently replaced
+	existingSecret, errGetSecret := m.secretsSvc.GetSecretByName(ctx, folder.ID, params.Name)
+	if errGetSecret != nil && !errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+		return nil, errGetSecret
+	}
+	if existingSecret != nil && existingSecret.Type != secrets2.Type_TOTP {
+		return nil, errors.Wrapf(ErrNameTaken, "Secret %s", params.Name)
+	}
+
+	sealedURI, errSeal := encryption.Seal(m.encryptionKey, []byte(key.URL()))
+	if errSeal != nil {
+		return nil, errSeal
+	}
+	savedSecret, errSaveSecret := m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: folder.ID, Name: params.Name,
+		Value: sealedURI, Type: secrets2.Type_TOTP})
+	if errSaveSecret != nil {
+		return nil, errSaveSecret
+	}
+
+	info := keyInfo(key)
+	info.UID, info.Name = savedSecret.UID, savedSecret.Name
+	return &info, nil
+}
+
+// Code Generates current code of the TOTP secret, every call is audited
+func (m *TOTPService) Code(ctx context.Context, secretUID string, reveal bool, now time.Time) (*Code, error) {
+	event := audit.Event{Action: audit.Action_TOTPCode, SecretUID: secretUID, Details: map[string]string{"Reveal": strconv.FormatBool(reveal)}}
+	if reveal {
+		event.Action = audit.Action_TOTPReveal
+	}
+
+	code, secret, errGenerate := m.generate(ctx, secretUID, reveal, now)
+	if secret != nil {
+		event.SecretName = secret.Name
+		folder, errGetFolder := m.secretsSvc.GetFolderByID(ctx, secret.FolderID)
+		if errGetFolder == nil {
+			event.FolderUID = folder.UID
+		}
+	}
+	event.Success = errGenerate == nil
+	if errGenerate != nil {
+		event.Error = errGenerate.Error()
+	}
+	// Code must not be handed out if it cannot be accounted for
+	errAudit := m.auditLogger.Log(ctx, event)
+	if errAudit != nil {
+		return nil, errors.Wrap(errAudit, "Failed to write audit log")
+	}
+
+	return code, errGenerate
+}
+
+func (m *TOTPService) generate(ctx context.Context, secretUID string, reveal bool, now time.Time) (*Code, *secrets2.Secret, error) {
+	secret, errGetSecret := m.secretsSvc.GetSecretByUID(ctx, secretUID)
+	if errGetSecret != nil {
+		return nil, nil, errors.Wrapf(errGetSecret, "Failed to retrieve secret with UID of %s", secretUID)
+	}
+	if secret.Type != secrets2.Type_TOTP {
+		return nil, secret, ErrNotTOTP
+	}
+
+	uri, errOpen := encryption.Open(m.encryptionKey, secret.Value)
+	if errOpen != nil {
+		return nil, secret, errOpen
+	}
+	key, errParseURI := ParseURI(string(uri))
+	if errParseURI != nil {
+		return nil, secret, errParseURI
+	}
+
+	period := uint(key.Period())
+	if period == 0 {
+		period = DefaultPeriod
+	}
+	codeValue, errGenerate := totp.GenerateCodeCustom(key.Secret(), now, totp.ValidateOpts{
+		Period: period, Digits: key.Digits(), Algorithm: key.Algorithm(),
+	})
+	if errGenerate != nil {
+		return nil, secret, errors.Wrap(errGenerate, "Failed to generate code")
+	}
+
+	secondsRemaining := period - uint(now.Unix())%period
+	code := &Code{Code: codeValue, SecondsRemaining: secondsRemaining, Period: period,
+		ValidUntil: now.Truncate(time.Second).Add(time.Duration(secondsRemaining) * time.Second)}
+	if reveal {
+		code.URI = string(uri)
+	}
+	return code, secret, nil
+}
@@ -0,0 +1,38 @@
+package totp
+
+import "time"
+
+type (
+	CreateParams struct {
+		FolderUID string
+		Name      string
+		// URI otpauth:// URI, takes precedence over the rest of the parameters
+		URI         string
+		Seed        string
+		Issuer      string
+		AccountName string
+		Digits      uint
+		Period      uint
+		Algorithm   string
+	}
+
+	// Info Public information about TOTP secret, never includes the seed
+	Info struct {
+		UID         string
+		Name        string
+		Issuer      string
+		AccountName string
+		Digits      uint
+		Period      uint
+		Algorithm   string
+	}
+
+	Code struct {
+		Code             string
+		SecondsRemaining uint
+		Period           uint
+		ValidUntil       time.Time
+		// URI Only set when seed was explicitly requested to be revealed
+		URI string
+	}
+)
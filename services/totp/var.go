@@ -0,0 +1,18 @@
+package totp
+
+import (
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
+)
+
+var (
+	ErrInvalidURI       = errors.New("Invalid otpauth URI")
+	ErrInvalidSeed      = errors.New("Seed must be base32-encoded")
+	ErrInvalidAlgorithm = errors.New("Invalid algorithm")
+	ErrInvalidDigits    = errors.New("Number of digits must be 6 or 8")
+	ErrNotTOTP          = errors.New("Secret is not a TOTP secret")
+	ErrNameTaken        = errors.New("Secret with the same name already exists in the folder")
+
+	AlgorithmsMap = map[string]otp.Algorithm{Algorithm_SHA1: otp.AlgorithmSHA1, Algorithm_SHA256: otp.AlgorithmSHA256,
+		Algorithm_SHA512: otp.AlgorithmSHA512}
+)
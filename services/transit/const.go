@@ -0,0 +1,16 @@
+package transit
+
+const (
+	KeyType_AES256GCM        = "aes256-gcm96"
+	KeyType_ChaCha20Poly1305 = "chacha20-poly1305"
+	KeyType_Ed25519          = "ed25519"
+
+	HashAlgorithm_SHA256 = "sha2-256"
+	HashAlgorithm_SHA512 = "sha2-512"
+
+	// Prefix Ciphertexts, signatures and HMACs have the form of hideout:v<key version>:<base64 value>
+	Prefix = "hideout:v"
+
+	symmetricKeySize = 32
+	hmacKeySize      = 32
+)
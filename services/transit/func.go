@@ -0,0 +1,229 @@
+package transit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"hash"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewKey Creates key of the type with the first version
+func NewKey(name string, keyType string, now time.Time) (*Key, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, ErrInvalidName
+	}
+	if !KeyTypesMap[keyType] {
+		return nil, errors.Wrapf(ErrInvalidKeyType, "Key type %s is not supported", keyType)
+	}
+	key := &Key{Name: name, Type: keyType, MinDecryptionVersion: 1, Versions: make(map[int]KeyVersion)}
+	return key, key.Rotate(now)
+}
+
+// Rotate Adds new version of the key, new data is encrypted (signed) with it while older versions are kept for decryption
+func (k *Key) Rotate(now time.Time) error {
+	version := KeyVersion{CreatedAt: now}
+	switch k.Type {
+	case KeyType_AES256GCM, KeyType_ChaCha20Poly1305:
+		version.Key = make([]byte, symmetricKeySize)
+		if _, errRead := rand.Read(version.Key); errRead != nil {
+			return errors.Wrap(errRead, "Failed to generate key")
+		}
+	case KeyType_Ed25519:
+		_, privateKey, errGenerate := ed25519.GenerateKey(rand.Reader)
+		if errGenerate != nil {
+			return errors.Wrap(errGenerate, "Failed to generate key")
+		}
+		version.Key = privateKey.Seed()
+	default:
+		return errors.Wrapf(ErrInvalidKeyType, "Key type %s is not supported", k.Type)
+	}
+	version.HMACKey = make([]byte, hmacKeySize)
+	if _, errRead := rand.Read(version.HMACKey); errRead != nil {
+		return errors.Wrap(errRead, "Failed to generate HMAC key")
+	}
+
+	k.LatestVersion++
+	k.Versions[k.LatestVersion] = version
+	return nil
+}
+
+// SetMinDecryptionVersion Versions below the minimum can no longer be used to decrypt or verify data
+func (k *Key) SetMinDecryptionVersion(minVersion int) error {
+	if minVersion < 1 || minVersion > k.LatestVersion {
+		return errors.Wrapf(ErrInvalidMinVersion, "Latest version is %d", k.LatestVersion)
+	}
+	k.MinDecryptionVersion = minVersion
+	return nil
+}
+
+// Info Public information about the key
+func (k Key) Info() KeyInfo {
+	info := KeyInfo{Name: k.Name, Type: k.Type, LatestVersion: k.LatestVersion, MinDecryptionVersion: k.MinDecryptionVersion}
+	for versionNumber, version := range k.Versions {
+		versionInfo := KeyVersionInfo{Version: versionNumber, CreatedAt: version.CreatedAt}
+		if k.Type == KeyType_Ed25519 {
+			publicKey := ed25519.NewKeyFromSeed(version.Key).Public().(ed25519.PublicKey)
+			versionInfo.PublicKey = base64.StdEncoding.EncodeToString(publicKey)
+		}
+		info.Versions = append(info.Versions, versionInfo)
+	}
+	sort.Slice(info.Versions, func(i, j int) bool { return info.Versions[i].Version < info.Versions[j].Version })
+	return info
+}
+
+// Encrypt Encrypts plaintext with the latest version of the key, associated data (if any) is required for decryption
+func (k Key) Encrypt(plaintext []byte, associatedData []byte) (string, error) {
+	aead, errCipher := k.aead(k.LatestVersion)
+	if errCipher != nil {
+		return "", errCipher
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, errRead := rand.Read(nonce); errRead != nil {
+		return "", errors.Wrap(errRead, "Failed to generate nonce")
+	}
+	return EncodeValue(k.LatestVersion, aead.Seal(nonce, nonce, plaintext, associatedData)), nil
+}
+
+// Decrypt Decrypts ciphertext produced by Encrypt, returning the key version it was encrypted with
+func (k Key) Decrypt(ciphertext string, associatedData []byte) ([]byte, int, error) {
+	version, data, errDecode := k.decodeValue(ciphertext)
+	if errDecode != nil {
+		return nil, 0, errDecode
+	}
+	aead, errCipher := k.aead(version)
+	if errCipher != nil {
+		return nil, 0, errCipher
+	}
+	if len(data) < aead.NonceSize()+aead.Overhead() {
+		return nil, 0, ErrDecryptionFailed
+	}
+	plaintext, errOpen := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], associatedData)
+	if errOpen != nil {
+		return nil, 0, ErrDecryptionFailed
+	}
+	return plaintext, version, nil
+}
+
+// Sign Signs input with the latest version of ed25519 key
+func (k Key) Sign(input []byte) (string, error) {
+	if k.Type != KeyType_Ed25519 {
+		return "", errors.Wrapf(ErrUnsupportedOperation, "Key of type %s cannot sign", k.Type)
+	}
+	privateKey := ed25519.NewKeyFromSeed(k.Versions[k.LatestVersion].Key)
+	return EncodeValue(k.LatestVersion, ed25519.Sign(privateKey, input)), nil
+}
+
+// Verify Checks signature produced by Sign
+func (k Key) Verify(input []byte, signature string) (bool, error) {
+	if k.Type != KeyType_Ed25519 {
+		return false, errors.Wrapf(ErrUnsupportedOperation, "Key of type %s cannot verify signatures", k.Type)
+	}
+	version, data, errDecode := k.decodeValue(signature)
+	if errDecode != nil {
+		return false, errDecode
+	}
+	publicKey := ed25519.NewKeyFromSeed(k.Versions[version].Key).Public().(ed25519.PublicKey)
+	return ed25519.Verify(publicKey, input, data), nil
+}
+
+// HMAC Computes HMAC of the input with the latest version of the key
+func (k Key) HMAC(input []byte, algorithm string) (string, error) {
+	hashFunc, errHash := hashFunction(algorithm)
+	if errHash != nil {
+		return "", errHash
+	}
+	mac := hmac.New(hashFunc, k.Versions[k.LatestVersion].HMACKey)
+	mac.Write(input)
+	return EncodeValue(k.LatestVersion, mac.Sum(nil)), nil
+}
+
+// VerifyHMAC Checks HMAC produced by HMAC in constant time
+func (k Key) VerifyHMAC(input []byte, value string, algorithm string) (bool, error) {
+	hashFunc, errHash := hashFunction(algorithm)
+	if errHash != nil {
+		return false, errHash
+	}
+	version, data, errDecode := k.decodeValue(value)
+	if errDecode != nil {
+		return false, errDecode
+	}
+	mac := hmac.New(hashFunc, k.Versions[version].HMACKey)
+	mac.Write(input)
+	return hmac.Equal(mac.Sum(nil), data), nil
+}
+
+// EncodeValue Prefixes base64-encoded data with the key version
+func EncodeValue(version int, data []byte) string {
+	return Prefix + strconv.Itoa(version) + ":" + base64.StdEncoding.EncodeToString(data)
+}
+
+// DecodeValue Splits value produced by EncodeValue into key version and data
+func DecodeValue(value string) (int, []byte, error) {
+	if !strings.HasPrefix(value, Prefix) {
+		return 0, nil, ErrInvalidFormat
+	}
+	versionPart, dataPart, found := strings.Cut(strings.TrimPrefix(value, Prefix), ":")
+	if !found {
+		return 0, nil, ErrInvalidFormat
+	}
+	version, errVersion := strconv.Atoi(versionPart)
+	if errVersion != nil || version < 1 {
+		return 0, nil, ErrInvalidFormat
+	}
+	data, errDecode := base64.StdEncoding.DecodeString(dataPart)
+	if errDecode != nil {
+		return 0, nil, errors.Wrap(ErrInvalidFormat, errDecode.Error())
+	}
+	return version, data, nil
+}
+
+// decodeValue Decodes value checking that its key version exists and is allowed to be used for decryption
+func (k Key) decodeValue(value string) (int, []byte, error) {
+	version, data, errDecode := DecodeValue(value)
+	if errDecode != nil {
+		return 0, nil, errDecode
+	}
+	if _, versionExists := k.Versions[version]; !versionExists {
+		return 0, nil, errors.Wrapf(ErrVersionNotFound, "Version %d of key %s", version, k.Name)
+	}
+	if version < k.MinDecryptionVersion {
+		return 0, nil, errors.Wrapf(ErrVersionBelowMinimum, "Version %d of key %s, minimum is %d", version, k.Name,
+			k.MinDecryptionVersion)
+	}
+	return version, data, nil
+}
+
+func (k Key) aead(version int) (cipher.AEAD, error) {
+	switch k.Type {
+	case KeyType_AES256GCM:
+		block, errCipher := aes.NewCipher(k.Versions[version].Key)
+		if errCipher != nil {
+			return nil, errors.Wrap(errCipher, "Failed to create cipher")
+		}
+		return cipher.NewGCM(block)
+	case KeyType_ChaCha20Poly1305:
+		return chacha20poly1305.New(k.Versions[version].Key)
+	}
+	return nil, errors.Wrapf(ErrUnsupportedOperation, "Key of type %s cannot encrypt", k.Type)
+}
+
+func hashFunction(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "", HashAlgorithm_SHA256:
+		return sha256.New, nil
+	case HashAlgorithm_SHA512:
+		return sha512.New, nil
+	}
+	return nil, errors.Wrapf(ErrInvalidHashAlgorithm, "Hash algorithm %s is not supported", algorithm)
+}
@@ -0,0 +1,284 @@
+package transit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/pkg/errors"
+	"hideout/internal/audit"
+	"hideout/internal/common/apperror"
+	"hideout/internal/folders"
+	"hideout/internal/pkg/encryption"
+	secrets2 "hideout/internal/secrets"
+	"hideout/services/secrets"
+	"strconv"
+	"time"
+)
+
+// TransitService Encryption as a service, keys are stored encrypted in folders and never leave hideout
+type TransitService struct {
+	secretsSvc    *secrets.SecretsService
+	encryptionKey []byte
+	auditLogger   audit.Logger
+}
+
+// NewService Creation of the service
+func NewService(secretsSvc *secrets.SecretsService, encryptionKey []byte, auditLogger audit.Logger) *TransitService {
+	return &TransitService{secretsSvc: secretsSvc, encryptionKey: encryptionKey, auditLogger: auditLogger}
+}
+
+// CreateKey Creates named key of the type in the folder
+func (m *TransitService) CreateKey(ctx context.Context, Localizer *i18n.Localizer, folderUID string, name string, keyType string) (*KeyInfo, error) {
+	var info KeyInfo
+	errAudited := m.audited(ctx, audit.Action_TransitCreateKey, folderUID, name, map[string]string{"Type": keyType},
+		func(folder *folders.Folder) (*secrets2.Secret, error) {
+			existingSecret, errGetSecret := m.secretsSvc.GetSecretByName(ctx, folder.ID, name)
+			if errGetSecret != nil && !errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+				return nil, errGetSecret
+			}
+			if existingSecret != nil {
+				if existingSecret.Type == secrets2.Type_TransitKey {
+					return existingSecret, errors.Wrapf(ErrKeyExists, "Key %s", name)
+				}
+				return existingSecret, errors.Wrapf(ErrNameTaken, "Secret %s", name)
+			}
+
+			key, errNewKey := NewKey(name, keyType, time.Now())
+			if errNewKey != nil {
+				return nil, errNewKey
+			}
+			savedSecret, errSave := m.saveKey(ctx, Localizer, folder.ID, key)
+			if errSave != nil {
+				return nil, errSave
+			}
+			info = key.Info()
+			info.UID = savedSecret.UID
+			return savedSecret, nil
+		})
+	if errAudited != nil {
+		return nil, errAudited
+	}
+	return &info, nil
+}
+
+// GetKey Retrieves public information about the key
+func (m *TransitService) GetKey(ctx context.Context, folderUID string, name string) (*KeyInfo, error) {
+	folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+	if errGetFolder != nil {
+		return nil, errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+	}
+	key, keySecret, errLoadKey := m.loadKey(ctx, folder, name)
+	if errLoadKey != nil {
+		return nil, errLoadKey
+	}
+	info := key.Info()
+	info.UID = keySecret.UID
+	return &info, nil
+}
+
+// RotateKey Adds new version of the key
+func (m *TransitService) RotateKey(ctx context.Context, Localizer *i18n.Localizer, folderUID string, name string) (*KeyInfo, error) {
+	return m.updateKey(ctx, Localizer, audit.Action_TransitRotateKey, folderUID, name, nil, func(key *Key) error {
+		return key.Rotate(time.Now())
+	})
+}
+
+// ConfigureKey Sets minimum version of the key allowed to decrypt and verify data
+func (m *TransitService) ConfigureKey(ctx context.Context, Localizer *i18n.Localizer, folderUID string, name string, minDecryptionVersion int) (*KeyInfo, error) {
+	return m.updateKey(ctx, Localizer, audit.Action_TransitConfigureKey, folderUID, name,
+		map[string]string{"MinDecryptionVersion": strconv.Itoa(minDecryptionVersion)}, func(key *Key) error {
+			return key.SetMinDecryptionVersion(minDecryptionVersion)
+		})
+}
+
+// Encrypt Encrypts plaintext with the latest version of the key
+func (m *TransitService) Encrypt(ctx context.Context, folderUID string, name string, plaintext []byte, associatedData []byte) (string, error) {
+	var ciphertext string
+	errAudited := m.withKey(ctx, audit.Action_TransitEncrypt, folderUID, name, func(key *Key) (map[string]string, error) {
+		var errEncrypt error
+		ciphertext, errEncrypt = key.Encrypt(plaintext, associatedData)
+		return map[string]string{"Version": strconv.Itoa(key.LatestVersion)}, errEncrypt
+	})
+	return ciphertext, errAudited
+}
+
+// Decrypt Decrypts ciphertext produced by Encrypt
+func (m *TransitService) Decrypt(ctx context.Context, folderUID string, name string, ciphertext string, associatedData []byte) ([]byte, error) {
+	var plaintext []byte
+	errAudited := m.withKey(ctx, audit.Action_TransitDecrypt, folderUID, name, func(key *Key) (map[string]string, error) {
+		var version int
+		var errDecrypt error
+		plaintext, version, errDecrypt = key.Decrypt(ciphertext, associatedData)
+		return map[string]string{"Version": strconv.Itoa(version)}, errDecrypt
+	})
+	return plaintext, errAudited
+}
+
+// Rewrap Re-encrypts ciphertext with the latest version of the key without revealing the plaintext
+func (m *TransitService) Rewrap(ctx context.Context, folderUID string, name string, ciphertext string, associatedData []byte) (string, error) {
+	var newCiphertext string
+	errAudited := m.withKey(ctx, audit.Action_TransitRewrap, folderUID, name, func(key *Key) (map[string]string, error) {
+		plaintext, version, errDecrypt := key.Decrypt(ciphertext, associatedData)
+		if errDecrypt != nil {
+			return nil, errDecrypt
+		}
+		var errEncrypt error
+		newCiphertext, errEncrypt = key.Encrypt(plaintext, associatedData)
+		return map[string]string{"FromVersion": strconv.Itoa(version), "Version": strconv.Itoa(key.LatestVersion)}, errEncrypt
+	})
+	return newCiphertext, errAudited
+}
+
+// Sign Signs input with the latest version of ed25519 key
+func (m *TransitService) Sign(ctx context.Context, folderUID string, name string, input []byte) (string, error) {
+	var signature string
+	errAudited := m.withKey(ctx, audit.Action_TransitSign, folderUID, name, func(key *Key) (map[string]string, error) {
+		var errSign error
+		signature, errSign = key.Sign(input)
+		return map[string]string{"Version": strconv.Itoa(key.LatestVersion)}, errSign
+	})
+	return signature, errAudited
+}
+
+// Verify Checks either signature or HMAC of the input
+func (m *TransitService) Verify(ctx context.Context, folderUID string, name string, input []byte, signature string, hmacValue string, algorithm string) (bool, error) {
+	var valid bool
+	errAudited := m.withKey(ctx, audit.Action_TransitVerify, folderUID, name, func(key *Key) (map[string]string, error) {
+		var errVerify error
+		if hmacValue != "" {
+			valid, errVerify = key.VerifyHMAC(input, hmacValue, algorithm)
+		} else {
+			valid, errVerify = key.Verify(input, signature)
+		}
+		return map[string]string{"Valid": strconv.FormatBool(valid), "HMAC": strconv.FormatBool(hmacValue != "")}, errVerify
+	})
+	return valid, errAudited
+}
+
+// HMAC Computes HMAC of the input with the latest version of the key
+func (m *TransitService) HMAC(ctx context.Context, folderUID string, name string, input []byte, algorithm string) (string, error) {
+	var hmacValue string
+	errAudited := m.withKey(ctx, audit.Action_TransitHMAC, folderUID, name, func(key *Key) (map[string]string, error) {
+		var errHMAC error
+		hmacValue, errHMAC = key.HMAC(input, algorithm)
+		return map[string]string{"Version": strconv.Itoa(key.LatestVersion), "Algorithm": algorithm}, errHMAC
+	})
+	return hmacValue, errAudited
+}
+
+func (m *TransitService) updateKey(ctx context.Context, Localizer *i18n.Localizer, action string, folderUID string, name string,
+	details map[string]string, update func(key *Key) error) (*KeyInfo, error) {
+	var info KeyInfo
+	errAudited := m.audited(ctx, action, folderUID, name, details, func(folder *folders.Folder) (*secrets2.Secret, error) {
+		key, keySecret, errLoadKey := m.loadKey(ctx, folder, name)
+		if errLoadKey != nil {
+			return keySecret, errLoadKey
+		}
+		errUpdate := update(key)
+		if errUpdate != nil {
+			return keySecret, errUpdate
+		}
+		_, errSave := m.saveKey(ctx, Localizer, folder.ID, key)
+		if errSave != nil {
+			return keySecret, errSave
+		}
+		info = key.Info()
+		info.UID = keySecret.UID
+		return keySecret, nil
+	})
+	if errAudited != nil {
+		return nil, errAudited
+	}
+	return &info, nil
+}
+
+// withKey Runs cryptographic operation with the key, operation returns details to be audited
+func (m *TransitService) withKey(ctx context.Context, action string, folderUID string, name string,
+	operation func(key *Key) (map[string]string, error)) error {
+	event := audit.Event{Action: action, FolderUID: folderUID, SecretName: name}
+	errOperation := func() error {
+		folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+		if errGetFolder != nil {
+			return errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+		}
+		key, keySecret, errLoadKey := m.loadKey(ctx, folder, name)
+		if keySecret != nil {
+			event.SecretUID = keySecret.UID
+		}
+		if errLoadKey != nil {
+			return errLoadKey
+		}
+		operationDetails, errOperation := operation(key)
+		event.Details = operationDetails
+		return errOperation
+	}()
+	return m.audit(ctx, event, errOperation)
+}
+
+// audited Runs key management operation in the folder, operation returns the key secret it worked with
+func (m *TransitService) audited(ctx context.Context, action string, folderUID string, name string, details map[string]string,
+	operation func(folder *folders.Folder) (*secrets2.Secret, error)) error {
+	event := audit.Event{Action: action, FolderUID: folderUID, SecretName: name, Details: details}
+	errOperation := func() error {
+		folder, errGetFolder := m.secretsSvc.GetFolderByUID(ctx, folderUID)
+		if errGetFolder != nil {
+			return errors.Wrapf(errGetFolder, "Failed to retrieve folder with UID of %s", folderUID)
+		}
+		keySecret, errOperation := operation(folder)
+		if keySecret != nil {
+			event.SecretUID = keySecret.UID
+		}
+		return errOperation
+	}()
+	return m.audit(ctx, event, errOperation)
+}
+
+// audit Writes the event, result of the operation must not be handed out if it cannot be accounted for
+func (m *TransitService) audit(ctx context.Context, event audit.Event, errOperation error) error {
+	event.Success = errOperation == nil
+	if errOperation != nil {
+		event.Error = errOperation.Error()
+	}
+	errAudit := m.auditLogger.Log(ctx, event)
+	if errAudit != nil {
+		return errors.Wrap(errAudit, "Failed to write audit log")
+	}
+	return errOperation
+}
+
+func (m *TransitService) loadKey(ctx context.Context, folder *folders.Folder, name string) (*Key, *secrets2.Secret, error) {
+	keySecret, errGetSecret := m.secretsSvc.GetSecretByName(ctx, folder.ID, name)
+	if errGetSecret != nil {
+		if errors.Is(errGetSecret, apperror.ErrRecordNotFound) {
+			return nil, nil, errors.Wrapf(ErrKeyNotFound, "Key %s", name)
+		}
+		return nil, nil, errGetSecret
+	}
+	if keySecret.Type != secrets2.Type_TransitKey {
+		return nil, nil, errors.Wrapf(ErrKeyNotFound, "Secret %s is not a transit key", name)
+	}
+
+	keyData, errOpen := encryption.Open(m.encryptionKey, keySecret.Value)
+	if errOpen != nil {
+		return nil, keySecret, errOpen
+	}
+	var key Key
+	errUnmarshal := json.Unmarshal(keyData, &key)
+	if errUnmarshal != nil {
+		return nil, keySecret, errors.Wrapf(errUnmarshal, "Failed to deserialize key %s", name)
+	}
+	return &key, keySecret, nil
+}
+
+func (m *TransitService) saveKey(ctx context.Context, Localizer *i18n.Localizer, folderID uint, key *Key) (*secrets2.Secret, error) {
+	keyData, errMarshal := json.Marshal(key)
+	if errMarshal != nil {
+		return nil, errors.Wrapf(errMarshal, "Failed to serialize key %s", key.Name)
+	}
+	sealedKey, errSeal := encryption.Seal(m.encryptionKey, keyData)
+	if errSeal != nil {
+		return nil, errSeal
+	}
+	return m.secretsSvc.SaveSecret(ctx, Localizer, secrets2.Secret{FolderID: folderID, Name: key.Name, Value: sealedKey,
+		Type: secrets2.Type_TransitKey})
+}
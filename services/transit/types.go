@@ -0,0 +1,37 @@
+package transit
+
+import "time"
+
+type (
+	// Key Named key with all of its versions, stored encrypted and never returned
+	Key struct {
+		Name                 string             `json:"Name"`
+		Type                 string             `json:"Type"`
+		LatestVersion        int                `json:"LatestVersion"`
+		MinDecryptionVersion int                `json:"MinDecryptionVersion"`
+		Versions             map[int]KeyVersion `json:"Versions"`
+	}
+
+	// KeyVersion Key material of a single version, ed25519 keys keep the seed of private key
+	KeyVersion struct {
+		Key       []byte    `json:"Key"`
+		HMACKey   []byte    `json:"HMACKey"`
+		CreatedAt time.Time `json:"CreatedAt"`
+	}
+
+	KeyInfo struct {
+		UID                  string
+		Name                 string
+		Type                 string
+		LatestVersion        int
+		MinDecryptionVersion int
+		Versions             []KeyVersionInfo
+	}
+
+	KeyVersionInfo struct {
+		Version   int
+		CreatedAt time.Time
+		// PublicKey Base64-encoded public key of ed25519 keys
+		PublicKey string
+	}
+)
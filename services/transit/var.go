@@ -0,0 +1,27 @@
+package transit
+
+import (
+	"github.com/pkg/errors"
+	"regexp"
+)
+
+var (
+	ErrKeyNotFound          = errors.New("Transit key not found")
+	ErrKeyExists            = errors.New("Transit key already exists")
+	ErrNameTaken            = errors.New("Secret with the same name already exists in the folder")
+	ErrInvalidName          = errors.New("Key name may only contain letters, digits, dashes and underscores")
+	ErrInvalidKeyType       = errors.New("Invalid key type")
+	ErrUnsupportedOperation = errors.New("Operation is not supported by the key type")
+	ErrInvalidFormat        = errors.New("Value is not in the hideout:v<version>:<base64> format")
+	ErrVersionNotFound      = errors.New("Key version not found")
+	ErrVersionBelowMinimum  = errors.New("Key version is below the minimum decryption version")
+	ErrInvalidMinVersion    = errors.New("Minimum decryption version must be between 1 and the latest key version")
+	ErrInvalidHashAlgorithm = errors.New("Invalid hash algorithm")
+	ErrDecryptionFailed     = errors.New("Failed to decrypt ciphertext")
+
+	KeyTypesMap = map[string]bool{KeyType_AES256GCM: true, KeyType_ChaCha20Poly1305: true, KeyType_Ed25519: true}
+
+	HashAlgorithmsMap = map[string]bool{HashAlgorithm_SHA256: true, HashAlgorithm_SHA512: true}
+
+	nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
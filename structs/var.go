@@ -2,14 +2,23 @@ package structs
 
 import (
 	"github.com/redis/go-redis/v9"
+	"go.etcd.io/bbolt"
 	"gorm.io/gorm"
+	"hideout/internal/common/model"
 	"hideout/internal/folders"
 	"hideout/internal/secrets"
+	"hideout/internal/store"
 )
 
 var (
 	Folders []folders.Folder
 	Secrets []secrets.Secret // Secret folder map
-	Redis   *redis.Client
-	Gorm    *gorm.DB
+
+	FoldersPreload model.Preload // Lock and generation of local writes of preloaded folders
+	SecretsPreload model.Preload // Lock and generation of local writes of preloaded secrets
+
+	Redis    *redis.Client
+	Gorm     *gorm.DB
+	Store    *store.Store // Single file store of folders and secrets
+	Embedded *bbolt.DB    // Embedded key-value store of folders and/or secrets
 )